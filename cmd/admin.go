@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
@@ -25,10 +26,12 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/auth/source/smtp"
+	org_service "code.gitea.io/gitea/services/org"
 	repo_service "code.gitea.io/gitea/services/repository"
 	user_service "code.gitea.io/gitea/services/user"
 
@@ -46,6 +49,7 @@ var (
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
+			subcmdSCIM,
 		},
 	}
 
@@ -173,6 +177,18 @@ var (
 				Usage: "Token name",
 				Value: "gitea-admin",
 			},
+			cli.StringFlag{
+				Name:  "scopes",
+				Usage: "Comma separated list of scopes granted to the token, all permissions are granted if not set",
+			},
+			cli.StringFlag{
+				Name:  "expires",
+				Usage: "Expiry date of the token in the format YYYY-MM-DD, the token never expires if not set",
+			},
+			cli.StringFlag{
+				Name:  "allowed-repos",
+				Usage: "Comma separated list of owners or owner/repo pairs the token is restricted to",
+			},
 			cli.BoolFlag{
 				Name:  "raw",
 				Usage: "Display only the token value",
@@ -359,6 +375,15 @@ var (
 			Value: "",
 			Usage: "Group Claim value for restricted users",
 		},
+		cli.StringFlag{
+			Name:  "group-team-map",
+			Value: "",
+			Usage: "JSON mapping between groups and org teams",
+		},
+		cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Activate automatic team membership removal depending on groups",
+		},
 	}
 
 	microcmdAuthUpdateOauth = cli.Command{
@@ -699,6 +724,20 @@ func runGenerateAccessToken(c *cli.Context) error {
 		UID:  user.ID,
 	}
 
+	if t.Scope, err = models.ParseAccessTokenScope(c.String("scopes")); err != nil {
+		return err
+	}
+	if err = t.SetAllowedRepos(ctx, strings.Split(c.String("allowed-repos"), ",")); err != nil {
+		return err
+	}
+	if c.IsSet("expires") {
+		expiresAt, err := time.ParseInLocation("2006-01-02", c.String("expires"), time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid expiry date: %v", err)
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(expiresAt.AddDate(0, 0, 1).Unix() - 1)
+	}
+
 	if err := models.NewAccessToken(t); err != nil {
 		return err
 	}
@@ -827,6 +866,8 @@ func parseOAuth2Config(c *cli.Context) *oauth2.Source {
 		GroupClaimName:                c.String("group-claim-name"),
 		AdminGroup:                    c.String("admin-group"),
 		RestrictedGroup:               c.String("restricted-group"),
+		GroupTeamMap:                  c.String("group-team-map"),
+		GroupTeamMapRemoval:           c.Bool("group-team-map-removal"),
 	}
 }
 
@@ -838,11 +879,16 @@ func runAddOauth(c *cli.Context) error {
 		return err
 	}
 
+	config := parseOAuth2Config(c)
+	if _, err := org_service.ParseGroupTeamMap(config.GroupTeamMap); err != nil {
+		return fmt.Errorf("invalid --group-team-map: %v", err)
+	}
+
 	return auth.CreateSource(&auth.Source{
 		Type:     auth.OAuth2,
 		Name:     c.String("name"),
 		IsActive: true,
-		Cfg:      parseOAuth2Config(c),
+		Cfg:      config,
 	})
 }
 
@@ -909,6 +955,15 @@ func runUpdateOauth(c *cli.Context) error {
 	if c.IsSet("restricted-group") {
 		oAuth2Config.RestrictedGroup = c.String("restricted-group")
 	}
+	if c.IsSet("group-team-map") {
+		if _, err := org_service.ParseGroupTeamMap(c.String("group-team-map")); err != nil {
+			return fmt.Errorf("invalid --group-team-map: %v", err)
+		}
+		oAuth2Config.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		oAuth2Config.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
 
 	// update custom URL mapping
 	customURLMapping := &oauth2.CustomURLMapping{}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/organization"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	subcmdSCIM = cli.Command{
+		Name:  "scim",
+		Usage: "Manage the tokens of SCIM identity providers",
+		Subcommands: []cli.Command{
+			microcmdSCIMCreateToken,
+			microcmdSCIMListTokens,
+			microcmdSCIMDeleteToken,
+		},
+	}
+
+	microcmdSCIMCreateToken = cli.Command{
+		Name:  "create-token",
+		Usage: "Create a token which allows an identity provider to provision users and the teams of an organization",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "org",
+				Usage: "Organization whose teams are managed with the token",
+			},
+			cli.StringFlag{
+				Name:  "name",
+				Usage: "Token name",
+				Value: "scim",
+			},
+			cli.Int64Flag{
+				Name:  "auth-source",
+				Usage: "ID of the authentication source of provisioned users, local users are created if not set",
+			},
+			cli.BoolFlag{
+				Name:  "raw",
+				Usage: "Display only the token value",
+			},
+		},
+		Action: runSCIMCreateToken,
+	}
+
+	microcmdSCIMListTokens = cli.Command{
+		Name:   "list-tokens",
+		Usage:  "List SCIM tokens",
+		Action: runSCIMListTokens,
+	}
+
+	microcmdSCIMDeleteToken = cli.Command{
+		Name:  "delete-token",
+		Usage: "Delete a SCIM token",
+		Flags: []cli.Flag{
+			cli.Int64Flag{
+				Name:  "id",
+				Usage: "ID of the token",
+			},
+		},
+		Action: runSCIMDeleteToken,
+	}
+)
+
+func runSCIMCreateToken(c *cli.Context) error {
+	if !c.IsSet("org") {
+		return fmt.Errorf("--org flag is missing")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	org, err := organization.GetOrgByName(c.String("org"))
+	if err != nil {
+		return err
+	}
+
+	t := &auth.SCIMToken{
+		Name:  c.String("name"),
+		OrgID: org.ID,
+	}
+	if c.IsSet("auth-source") {
+		source, err := auth.GetSourceByID(c.Int64("auth-source"))
+		if err != nil {
+			return err
+		}
+		t.SourceID = source.ID
+	}
+
+	if err := auth.NewSCIMToken(t); err != nil {
+		return err
+	}
+
+	if c.Bool("raw") {
+		fmt.Printf("%s\n", t.Token)
+	} else {
+		fmt.Printf("SCIM token was successfully created: %s\n", t.Token)
+	}
+	return nil
+}
+
+func runSCIMListTokens(_ *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	tokens, err := auth.ListSCIMTokens()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "ID\tName\tOrganization\tAuth Source\tLast Used\n")
+	for _, t := range tokens {
+		orgName := "<deleted>"
+		if org, err := organization.GetOrgByID(t.OrgID); err == nil {
+			orgName = org.Name
+		}
+		lastUsed := "never"
+		if t.LastUsed > 0 {
+			lastUsed = t.LastUsed.FormatLong()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", t.ID, t.Name, orgName, t.SourceID, lastUsed)
+	}
+	return w.Flush()
+}
+
+func runSCIMDeleteToken(c *cli.Context) error {
+	if !c.IsSet("id") {
+		return fmt.Errorf("--id flag is missing")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	return auth.DeleteSCIMTokenByID(c.Int64("id"))
+}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli"
+)
+
+// exitTempFail tells the mail transfer agent to retry the delivery later (EX_TEMPFAIL)
+const exitTempFail = 75
+
+// CmdIncomingMail represents the command which receives a mail from the mail transfer agent
+var CmdIncomingMail = cli.Command{
+	Name:  "incoming-mail",
+	Usage: "Handle an incoming mail read from stdin",
+	Description: `Pass a reply to a notification mail to the running Gitea instance.
+Configure your mail transfer agent to pipe mails sent to [email.incoming] REPLY_TO_ADDRESS to this command.`,
+	Action: runIncomingMail,
+}
+
+func runIncomingMail(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	setting.LoadFromExisting()
+
+	message, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Unable to read the mail: %v", err), exitTempFail)
+	}
+
+	statusCode, msg := private.ReceiveEmail(ctx, message)
+	if statusCode >= http.StatusInternalServerError {
+		return cli.NewExitError(fmt.Sprintf("Unable to handle the mail: %s", msg), exitTempFail)
+	} else if statusCode != http.StatusOK {
+		return cli.NewExitError(fmt.Sprintf("The mail was rejected: %s", msg), 1)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/lfstransfer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/pprof"
 	"code.gitea.io/gitea/modules/private"
@@ -34,6 +35,7 @@ import (
 
 const (
 	lfsAuthenticateVerb = "git-lfs-authenticate"
+	lfsTransferVerb     = "git-lfs-transfer"
 )
 
 // CmdServ represents the available serv sub-command.
@@ -71,6 +73,7 @@ var (
 		"git-upload-archive": perm.AccessModeRead,
 		"git-receive-pack":   perm.AccessModeWrite,
 		lfsAuthenticateVerb:  perm.AccessModeNone,
+		lfsTransferVerb:      perm.AccessModeNone,
 	}
 	alphaDashDotPattern = regexp.MustCompile(`[^\w-\.]`)
 )
@@ -166,10 +169,13 @@ func runServ(c *cli.Context) error {
 	}
 
 	var lfsVerb string
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if !setting.LFS.StartServer {
 			return fail("Unknown git command", "LFS authentication request over SSH denied, LFS support is disabled")
 		}
+		if verb == lfsTransferVerb && !setting.LFS.AllowPureSSH {
+			return fail("Unknown git command", "LFS transfer over SSH denied, LFS_ALLOW_PURE_SSH is disabled")
+		}
 
 		if len(words) > 2 {
 			lfsVerb = words[2]
@@ -214,7 +220,7 @@ func runServ(c *cli.Context) error {
 		return fail("Unknown git command", "Unknown git command %s", verb)
 	}
 
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if lfsVerb == "upload" {
 			requestedMode = perm.AccessModeWrite
 		} else if lfsVerb == "download" {
@@ -251,20 +257,7 @@ func runServ(c *cli.Context) error {
 	if verb == lfsAuthenticateVerb {
 		url := fmt.Sprintf("%s%s/%s.git/info/lfs", setting.AppURL, url.PathEscape(results.OwnerName), url.PathEscape(results.RepoName))
 
-		now := time.Now()
-		claims := lfs.Claims{
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(now.Add(setting.LFS.HTTPAuthExpiry)),
-				NotBefore: jwt.NewNumericDate(now),
-			},
-			RepoID: results.RepoID,
-			Op:     lfsVerb,
-			UserID: results.UserID,
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-		// Sign and get the complete encoded token as a string using the secret
-		tokenString, err := token.SignedString(setting.LFS.JWTSecretBytes)
+		authorization, err := getLFSAuthorization(results, lfsVerb)
 		if err != nil {
 			return fail("Internal error", "Failed to sign JWT token: %v", err)
 		}
@@ -273,7 +266,7 @@ func runServ(c *cli.Context) error {
 			Header: make(map[string]string),
 			Href:   url,
 		}
-		tokenAuthentication.Header["Authorization"] = fmt.Sprintf("Bearer %s", tokenString)
+		tokenAuthentication.Header["Authorization"] = authorization
 
 		enc := json.NewEncoder(os.Stdout)
 		err = enc.Encode(tokenAuthentication)
@@ -283,6 +276,17 @@ func runServ(c *cli.Context) error {
 		return nil
 	}
 
+	// LFS transfer over the SSH connection
+	if verb == lfsTransferVerb {
+		backend := lfstransfer.NewHTTPBackend(results.OwnerName, results.RepoName, func() (string, error) {
+			return getLFSAuthorization(results, lfsVerb)
+		})
+		if err := lfstransfer.NewTransfer(backend, lfsVerb, results.UserName, os.Stdin, os.Stdout).Serve(ctx); err != nil {
+			return fail("Internal error", "Failed to transfer LFS objects: %v", err)
+		}
+		return nil
+	}
+
 	// Special handle for Windows.
 	if setting.IsWindows {
 		verb = strings.Replace(verb, "-", " ", 1)
@@ -322,3 +326,26 @@ func runServ(c *cli.Context) error {
 
 	return nil
 }
+
+// getLFSAuthorization returns the Authorization header value for the LFS server,
+// a new token is signed for every call as a transfer can outlast a single token.
+func getLFSAuthorization(results *private.ServCommandResults, op string) (string, error) {
+	now := time.Now()
+	claims := lfs.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(setting.LFS.HTTPAuthExpiry)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		RepoID: results.RepoID,
+		Op:     op,
+		UserID: results.UserID,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Sign and get the complete encoded token as a string using the secret
+	tokenString, err := token.SignedString(setting.LFS.JWTSecretBytes)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Bearer %s", tokenString), nil
+}
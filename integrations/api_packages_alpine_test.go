@@ -0,0 +1,209 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageAlpine(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "gitea-test"
+	packageVersion := "1.4.1-r3"
+
+	createPackage := func(name, version, architecture string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		pkginfo := fmt.Sprintf("pkgname = %s\npkgver = %s\npkgdesc = Gitea Test Package\nurl = https://gitea.io/\narch = %s\nlicense = MIT\nsize = 42\n", name, version, architecture)
+		tw.WriteHeader(&tar.Header{
+			Name: ".PKGINFO",
+			Mode: 0o600,
+			Size: int64(len(pkginfo)),
+		})
+		tw.Write([]byte(pkginfo))
+		tw.Close()
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	branches := []string{"v3.16", "v3.17"}
+	repositories := []string{"main", "testing"}
+	architectures := []string{"x86_64", "aarch64"}
+
+	rootURL := fmt.Sprintf("/api/packages/%s/alpine", user.Name)
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Contains(t, resp.Body.String(), "-----BEGIN PUBLIC KEY-----")
+		assert.Contains(t, resp.Header().Get("Content-Disposition"), ".rsa.pub")
+	})
+
+	for _, branch := range branches {
+		for _, repository := range repositories {
+			for _, architecture := range architectures {
+				t.Run(fmt.Sprintf("[Branch:%s,Repository:%s,Architecture:%s]", branch, repository, architecture), func(t *testing.T) {
+					t.Run("Upload", func(t *testing.T) {
+						defer PrintCurrentTest(t)()
+
+						uploadURL := fmt.Sprintf("%s/%s/%s", rootURL, branch, repository)
+
+						req := NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader([]byte{}))
+						MakeRequest(t, req, http.StatusUnauthorized)
+
+						req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader([]byte{}))
+						req = AddBasicAuthHeader(req, user.Name)
+						MakeRequest(t, req, http.StatusBadRequest)
+
+						req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(createPackage("", "", architecture)))
+						req = AddBasicAuthHeader(req, user.Name)
+						MakeRequest(t, req, http.StatusBadRequest)
+
+						req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(createPackage(packageName, packageVersion, architecture)))
+						req = AddBasicAuthHeader(req, user.Name)
+						MakeRequest(t, req, http.StatusCreated)
+
+						pv, err := packages.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages.TypeAlpine, packageName, packageVersion)
+						assert.NoError(t, err)
+
+						pd, err := packages.GetPackageDescriptor(db.DefaultContext, pv)
+						assert.NoError(t, err)
+						assert.Nil(t, pd.SemVer)
+						assert.IsType(t, &alpine_module.VersionMetadata{}, pd.Metadata)
+						assert.Equal(t, packageName, pd.Package.Name)
+						assert.Equal(t, packageVersion, pd.Version.Version)
+
+						pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pv.ID)
+						assert.NoError(t, err)
+						found := false
+						for _, pf := range pfs {
+							if pf.CompositeKey == fmt.Sprintf("%s|%s|%s", branch, repository, architecture) && pf.Name == fmt.Sprintf("%s-%s.apk", packageName, packageVersion) {
+								found = true
+								assert.True(t, pf.IsLead)
+
+								pfps, err := packages.GetProperties(db.DefaultContext, packages.PropertyTypeFile, pf.ID)
+								assert.NoError(t, err)
+
+								for _, pfp := range pfps {
+									switch pfp.Name {
+									case alpine_module.PropertyBranch:
+										assert.Equal(t, branch, pfp.Value)
+									case alpine_module.PropertyRepository:
+										assert.Equal(t, repository, pfp.Value)
+									case alpine_module.PropertyArchitecture:
+										assert.Equal(t, architecture, pfp.Value)
+									}
+								}
+							}
+						}
+						assert.True(t, found)
+
+						req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(createPackage(packageName, packageVersion, architecture)))
+						req = AddBasicAuthHeader(req, user.Name)
+						MakeRequest(t, req, http.StatusBadRequest)
+					})
+
+					t.Run("Index", func(t *testing.T) {
+						defer PrintCurrentTest(t)()
+
+						req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s/APKINDEX.tar.gz", rootURL, branch, repository, architecture))
+						resp := MakeRequest(t, req, http.StatusOK)
+
+						br := bufio.NewReader(resp.Body)
+
+						zr, err := gzip.NewReader(br)
+						assert.NoError(t, err)
+
+						var content string
+						for {
+							zr.Multistream(false)
+
+							tr := tar.NewReader(zr)
+							for {
+								hd, err := tr.Next()
+								if err == io.EOF {
+									break
+								}
+								assert.NoError(t, err)
+
+								if strings.HasPrefix(hd.Name, ".SIGN.RSA.") {
+									assert.True(t, strings.HasSuffix(hd.Name, ".rsa.pub"))
+								}
+								if hd.Name == "APKINDEX" {
+									b, err := io.ReadAll(tr)
+									assert.NoError(t, err)
+									content = string(b)
+								}
+							}
+
+							if err := zr.Reset(br); err == io.EOF {
+								break
+							}
+						}
+
+						assert.Contains(t, content, "P:"+packageName+"\n")
+						assert.Contains(t, content, "V:"+packageVersion+"\n")
+						assert.Contains(t, content, "A:"+architecture+"\n")
+						assert.Contains(t, content, "I:42\n")
+					})
+
+					t.Run("Download", func(t *testing.T) {
+						defer PrintCurrentTest(t)()
+
+						req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s/%s-%s.apk", rootURL, branch, repository, architecture, packageName, packageVersion))
+						MakeRequest(t, req, http.StatusOK)
+					})
+				})
+			}
+		}
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		branch := branches[0]
+		repository := repositories[0]
+
+		for _, architecture := range architectures {
+			url := fmt.Sprintf("%s/%s/%s/%s/%s-%s.apk", rootURL, branch, repository, architecture, packageName, packageVersion)
+
+			req := NewRequest(t, "DELETE", url)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", url)
+			req = AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "DELETE", url)
+			req = AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s/APKINDEX.tar.gz", rootURL, branch, repository, architecture))
+			MakeRequest(t, req, http.StatusNotFound)
+		}
+	})
+}
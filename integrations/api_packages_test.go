@@ -8,12 +8,14 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
 	container_model "code.gitea.io/gitea/models/packages/container"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
 	api "code.gitea.io/gitea/modules/structs"
@@ -105,6 +107,141 @@ func TestPackageAPI(t *testing.T) {
 	})
 }
 
+func TestPackageRepositoryLink(t *testing.T) {
+	defer prepareTestEnv(t)()
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}).(*user_model.User)
+	collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4}).(*repo_model.Repository)
+	ownerToken := getTokenForLoggedInUser(t, loginUser(t, owner.Name))
+	collaboratorToken := getTokenForLoggedInUser(t, loginUser(t, collaborator.Name))
+
+	packageName := "test-package"
+	packageVersion := "1.0.3"
+
+	url := fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", owner.Name, packageName, packageVersion)
+	req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{}))
+	AddBasicAuthHeader(req, owner.Name)
+	MakeRequest(t, req, http.StatusCreated)
+
+	linkURL := fmt.Sprintf("/api/v1/packages/%s/generic/%s/-", owner.Name, packageName)
+
+	t.Run("Link", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "POST", fmt.Sprintf("%s/link/%s?token=%s", linkURL, repo.Name, collaboratorToken))
+		MakeRequest(t, req, http.StatusForbidden)
+
+		req = NewRequest(t, "POST", fmt.Sprintf("%s/link/unknown-repo?token=%s", linkURL, ownerToken))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "POST", fmt.Sprintf("/api/v1/packages/%s/generic/unknown-package/-/link/%s?token=%s", owner.Name, repo.Name, ownerToken))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "POST", fmt.Sprintf("%s/link/%s?token=%s", linkURL, repo.Name, ownerToken))
+		MakeRequest(t, req, http.StatusNoContent)
+
+		p, err := packages_model.GetPackageByName(db.DefaultContext, owner.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Equal(t, repo.ID, p.RepoID)
+	})
+
+	t.Run("RepositoryList", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/%s/%s/packages", owner.Name, repo.Name))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), packageName)
+	})
+
+	t.Run("RepositoryWriteAccess", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/packages/%s/generic/%s/%s?token=%s", owner.Name, packageName, packageVersion, collaboratorToken))
+		MakeRequest(t, req, http.StatusNoContent)
+	})
+
+	t.Run("Unlink", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		url := fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", owner.Name, packageName, packageVersion)
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, owner.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		req = NewRequest(t, "POST", fmt.Sprintf("%s/unlink?token=%s", linkURL, ownerToken))
+		MakeRequest(t, req, http.StatusNoContent)
+
+		p, err := packages_model.GetPackageByName(db.DefaultContext, owner.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), p.RepoID)
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/packages/%s/generic/%s/%s?token=%s", owner.Name, packageName, packageVersion, collaboratorToken))
+		MakeRequest(t, req, http.StatusForbidden)
+	})
+}
+
+func TestPackageRepositoryLinkRegistryAccess(t *testing.T) {
+	defer prepareTestEnv(t)()
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}).(*user_model.User)
+	collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4}).(*repo_model.Repository)
+	ownerToken := getTokenForLoggedInUser(t, loginUser(t, owner.Name))
+
+	putFile := func(t *testing.T, doer *user_model.User, url string, expectedStatus int) {
+		req := NewRequestWithBody(t, "PUT", url, strings.NewReader("test"))
+		AddBasicAuthHeader(req, doer.Name)
+		MakeRequest(t, req, expectedStatus)
+	}
+	link := func(t *testing.T, packageType, packageName string) {
+		req := NewRequest(t, "POST", fmt.Sprintf("/api/v1/packages/%s/%s/%s/-/link/%s?token=%s", owner.Name, packageType, packageName, repo.Name, ownerToken))
+		MakeRequest(t, req, http.StatusNoContent)
+	}
+
+	t.Run("Generic", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		root := fmt.Sprintf("/api/packages/%s/generic", owner.Name)
+
+		putFile(t, owner, root+"/linked-package/1.0.0/file.bin", http.StatusCreated)
+		putFile(t, owner, root+"/other-package/1.0.0/file.bin", http.StatusCreated)
+		putFile(t, collaborator, root+"/linked-package/1.0.1/file.bin", http.StatusUnauthorized)
+
+		link(t, "generic", "linked-package")
+
+		putFile(t, collaborator, root+"/linked-package/1.0.1/file.bin", http.StatusCreated)
+		putFile(t, collaborator, root+"/other-package/1.0.1/file.bin", http.StatusUnauthorized)
+		putFile(t, collaborator, root+"/new-package/1.0.0/file.bin", http.StatusUnauthorized)
+
+		req := NewRequest(t, "DELETE", root+"/linked-package/1.0.1/file.bin")
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "DELETE", root+"/other-package/1.0.0/file.bin")
+		AddBasicAuthHeader(req, collaborator.Name)
+		MakeRequest(t, req, http.StatusUnauthorized)
+	})
+
+	t.Run("Maven", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		// the package name is only known after the handler parsed the path
+		root := fmt.Sprintf("/api/packages/%s/maven/com/gitea", owner.Name)
+
+		putFile(t, owner, root+"/linked-project/1.0.0/linked-project-1.0.0.jar", http.StatusCreated)
+		putFile(t, owner, root+"/other-project/1.0.0/other-project-1.0.0.jar", http.StatusCreated)
+		putFile(t, collaborator, root+"/linked-project/1.0.1/linked-project-1.0.1.jar", http.StatusUnauthorized)
+
+		link(t, "maven", "com.gitea-linked-project")
+
+		putFile(t, collaborator, root+"/linked-project/1.0.1/linked-project-1.0.1.jar", http.StatusCreated)
+		putFile(t, collaborator, root+"/other-project/1.0.1/other-project-1.0.1.jar", http.StatusUnauthorized)
+
+		pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, owner.ID, packages_model.TypeMaven, "com.gitea-linked-project")
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 2)
+	})
+}
+
 func TestPackageCleanup(t *testing.T) {
 	defer prepareTestEnv(t)()
 
@@ -127,3 +264,75 @@ func TestPackageCleanup(t *testing.T) {
 	_, err = packages_model.GetInternalVersionByNameAndVersion(db.DefaultContext, 2, packages_model.TypeContainer, "test", container_model.UploadVersion)
 	assert.ErrorIs(t, err, packages_model.ErrPackageNotExist)
 }
+
+func TestPackageCleanupRules(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "cleanup-package"
+
+	for _, version := range []string{"1.0.0", "1.0.1-SNAPSHOT", "1.0.2-SNAPSHOT", "1.0.3"} {
+		url := fmt.Sprintf("/api/packages/%s/generic/%s/%s/file.bin", user.Name, packageName, version)
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+	}
+
+	pcr, err := packages_model.InsertCleanupRule(db.DefaultContext, &packages_model.PackageCleanupRule{
+		Enabled:        true,
+		OwnerID:        user.ID,
+		Type:           packages_model.TypeGeneric,
+		NamePattern:    "cleanup-*",
+		VersionPattern: `.*-snapshot`,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Preview", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		session := loginUser(t, user.Name)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/user/settings/packages/rules/%d/preview", pcr.ID))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		body := resp.Body.String()
+		assert.Contains(t, body, "1.0.1-SNAPSHOT")
+		assert.Contains(t, body, "1.0.2-SNAPSHOT")
+
+		other := loginUser(t, "user4")
+		req = NewRequest(t, "GET", fmt.Sprintf("/user/settings/packages/rules/%d/preview", pcr.ID))
+		other.MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Execute", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		pvs, err := packages_service.GetCleanupRuleCandidates(db.DefaultContext, pcr)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 2)
+
+		assert.NoError(t, packages_service.ExecuteCleanupRules(db.DefaultContext))
+
+		pvs, err = packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 2)
+		for _, pv := range pvs {
+			assert.NotContains(t, pv.Version, "SNAPSHOT")
+		}
+	})
+
+	t.Run("DisabledRule", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		pcr.Enabled = false
+		pcr.VersionPattern = ""
+		pcr.KeepCount = 1
+		assert.NoError(t, packages_model.UpdateCleanupRule(db.DefaultContext, pcr))
+
+		assert.NoError(t, packages_service.ExecuteCleanupRules(db.DefaultContext))
+
+		pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, packageName)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 2)
+	})
+}
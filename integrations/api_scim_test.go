@@ -0,0 +1,127 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/routers/api/scim"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCIMUserScope(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// the token belongs to org3, its members are user2, user4 and user28
+	token := &auth_model.SCIMToken{Name: "test", OrgID: 3}
+	assert.NoError(t, auth_model.NewSCIMToken(token))
+
+	patchUser := func(t *testing.T, userID int64, expectedStatus int) {
+		req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/scim/v2/Users/%d", userID), &scim.PatchRequest{
+			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			Operations: []scim.PatchOperation{
+				{Op: "replace", Path: "emails[type eq \"work\"].value", Value: "attacker@example.com"},
+			},
+		})
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, expectedStatus)
+	}
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		// user5 is only a member of org6
+		patchUser(t, 5, http.StatusNotFound)
+		unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5, Email: "user5@example.com"})
+
+		req := NewRequest(t, "DELETE", "/scim/v2/Users/5")
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusNotFound)
+		unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5, ProhibitLogin: false})
+
+		req = NewRequest(t, "GET", "/scim/v2/Users?filter="+`userName eq "user5"`)
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var list scim.ListResponse
+		DecodeJSON(t, resp, &list)
+		assert.EqualValues(t, 0, list.TotalResults)
+	})
+
+	t.Run("SiteAdmin", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		patchUser(t, 1, http.StatusNotFound)
+	})
+
+	t.Run("AddToGroup", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		// the owners team of org3
+		req := NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/1", &scim.PatchRequest{
+			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			Operations: []scim.PatchOperation{
+				{Op: "add", Path: "members", Value: []map[string]string{{"value": "5"}}},
+			},
+		})
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusBadRequest)
+	})
+
+	t.Run("CreatedUser", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		// the token has no authentication source, so a local user is created which is no member of org3 yet
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Users", &scim.User{
+			Schemas:  []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+			UserName: "scim-user",
+			Emails:   []scim.Email{{Value: "scim-user@example.com", Primary: true}},
+		})
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		var created scim.User
+		DecodeJSON(t, resp, &created)
+
+		req = NewRequest(t, "GET", "/scim/v2/Users/"+created.ID)
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/1", &scim.PatchRequest{
+			Schemas: []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			Operations: []scim.PatchOperation{
+				{Op: "add", Path: "members", Value: []map[string]string{{"value": created.ID}}},
+			},
+		})
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusOK)
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-user"}).(*user_model.User)
+		unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: 1, UID: u.ID})
+
+		// the user stays managed by org3 after it has been removed from all teams
+		req = NewRequest(t, "DELETE", "/scim/v2/Users/"+created.ID)
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &organization.TeamUser{TeamID: 1, UID: u.ID})
+
+		req = NewRequest(t, "GET", "/scim/v2/Users/"+created.ID)
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		MakeRequest(t, req, http.StatusOK)
+	})
+
+	t.Run("OrganizationMember", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		patchUser(t, 4, http.StatusOK)
+		unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4, Email: "attacker@example.com"})
+		unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionAdminUserEdit, ActorID: 3, TargetID: 4})
+	})
+}
@@ -25,6 +25,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 	"code.gitea.io/gitea/modules/translation/i18n"
+	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	files_service "code.gitea.io/gitea/services/repository/files"
@@ -349,6 +350,73 @@ func TestCantMergeUnrelated(t *testing.T) {
 	})
 }
 
+func TestFastForwardOnlyMerge(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "base", "README.md", "Hello, World (Edited Base)\n")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "master", "diverged", "CONTRIBUTING.md", "Diverged\n")
+		testEditFileToNewBranch(t, session, "user1", "repo1", "base", "ahead", "CONTRIBUTING.md", "Ahead\n")
+
+		// Enable the fast-forward-only merge style and create the pull requests
+		token := getTokenForLoggedInUser(t, session)
+		allowFastForwardOnly := true
+		req := NewRequestWithJSON(t, http.MethodPatch, fmt.Sprintf("/api/v1/repos/%s/%s?token=%s", "user1", "repo1", token), &api.EditRepoOption{
+			AllowFastForwardOnly: &allowFastForwardOnly,
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var apiRepo api.Repository
+		DecodeJSON(t, resp, &apiRepo)
+		assert.True(t, apiRepo.AllowFastForwardOnly)
+
+		for _, head := range []string{"diverged", "ahead"} {
+			req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls?token=%s", "user1", "repo1", token), &api.CreatePullRequestOption{
+				Head:  head,
+				Base:  "base",
+				Title: "create a " + head + " pr",
+			})
+			session.MakeRequest(t, req, http.StatusCreated)
+		}
+
+		user1 := unittest.AssertExistsAndLoadBean(t, &user_model.User{
+			Name: "user1",
+		}).(*user_model.User)
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{
+			OwnerID: user1.ID,
+			Name:    "repo1",
+		}).(*repo_model.Repository)
+
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo_model.RepoPath(user1.Name, repo1.Name))
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+
+		pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{
+			HeadRepoID: repo1.ID,
+			BaseRepoID: repo1.ID,
+			HeadBranch: "diverged",
+			BaseBranch: "base",
+		}).(*models.PullRequest)
+		err = pull.Merge(git.DefaultContext, pr, user1, gitRepo, repo_model.MergeStyleFastForwardOnly, "", "DIVERGED")
+		assert.Error(t, err, "Merge should return an error as the base is not an ancestor of the head")
+		assert.True(t, models.IsErrMergeDivergingFastForwardOnly(err), "Merge error is not a diverging fast-forward-only error")
+
+		pr = unittest.AssertExistsAndLoadBean(t, &models.PullRequest{
+			HeadRepoID: repo1.ID,
+			BaseRepoID: repo1.ID,
+			HeadBranch: "ahead",
+			BaseBranch: "base",
+		}).(*models.PullRequest)
+		err = pull.Merge(git.DefaultContext, pr, user1, gitRepo, repo_model.MergeStyleFastForwardOnly, "", "AHEAD")
+		assert.NoError(t, err)
+
+		baseCommitID, err := gitRepo.GetBranchCommitID("base")
+		assert.NoError(t, err)
+		headCommitID, err := gitRepo.GetBranchCommitID("ahead")
+		assert.NoError(t, err)
+		assert.Equal(t, headCommitID, baseCommitID, "The base branch should have been fast-forwarded to the head")
+	})
+}
+
 func TestConflictChecking(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
@@ -419,3 +487,65 @@ func TestConflictChecking(t *testing.T) {
 		assert.False(t, conflictingPR.Mergeable())
 	})
 }
+
+func TestMergeQueueBranchProtection(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+
+		user1 := unittest.AssertExistsAndLoadBean(t, &user_model.User{
+			Name: "user1",
+		}).(*user_model.User)
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{
+			OwnerID: user1.ID,
+			Name:    "repo1",
+		}).(*repo_model.Repository)
+
+		gitRepo, err := git.OpenRepository(git.DefaultContext, repo_model.RepoPath(user1.Name, repo1.Name))
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+
+		token := getTokenForLoggedInUser(t, session)
+		// "*" only matches the base branch while "**" matches the merge queue branches as well
+		for i, rule := range []string{"master", "*", "**"} {
+			req := NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/branch_protections?token=%s", "user1", "repo1", token), &api.CreateBranchProtectionOption{
+				BranchName:          rule,
+				EnableStatusCheck:   true,
+				StatusCheckContexts: []string{"ci"},
+				EnableMergeQueue:    true,
+			})
+			session.MakeRequest(t, req, http.StatusCreated)
+
+			head := fmt.Sprintf("queued-%d", i)
+			testEditFileToNewBranch(t, session, "user1", "repo1", "master", head, fmt.Sprintf("queued-%d.md", i), "Queued\n")
+			req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls?token=%s", "user1", "repo1", token), &api.CreatePullRequestOption{
+				Head:  head,
+				Base:  "master",
+				Title: "queue the " + head + " pr",
+			})
+			resp := session.MakeRequest(t, req, http.StatusCreated)
+			var apiPull api.PullRequest
+			DecodeJSON(t, resp, &apiPull)
+
+			// the pull request can't be queued before its conflict check finished
+			assert.Eventually(t, func() bool {
+				pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: apiPull.ID}).(*models.PullRequest)
+				return pr.Status == models.PullRequestStatusMergeable
+			}, 30*time.Second, 100*time.Millisecond)
+
+			req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/merge?token=%s", "user1", "repo1", apiPull.Index, token), &forms.MergePullRequestForm{
+				Do: string(repo_model.MergeStyleMerge),
+			})
+			session.MakeRequest(t, req, http.StatusCreated)
+
+			// the push of the merge commit must not be rejected by the protection of the base branch
+			branch := fmt.Sprintf("%smaster/%d", pull.MergeQueueBranchPrefix, apiPull.Index)
+			assert.Eventually(t, func() bool {
+				return gitRepo.IsBranchExist(branch)
+			}, 30*time.Second, 100*time.Millisecond, "The merge queue should have pushed %s", branch)
+
+			req = NewRequest(t, http.MethodDelete, fmt.Sprintf("/api/v1/repos/%s/%s/branch_protections/%s?token=%s", "user1", "repo1", url.PathEscape(rule), token))
+			session.MakeRequest(t, req, http.StatusNoContent)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Action is the kind of a security relevant action
+type Action string
+
+// The audited actions
+const (
+	ActionUserLogin             Action = "user_login"
+	ActionUserLoginFailed       Action = "user_login_failed"
+	ActionUserTwoFactorEnable   Action = "user_2fa_enable"
+	ActionUserTwoFactorDisable  Action = "user_2fa_disable"
+	ActionUserTwoFactorScratch  Action = "user_2fa_regenerate_scratch"
+	ActionUserWebAuthnAdd       Action = "user_webauthn_add"
+	ActionUserWebAuthnRemove    Action = "user_webauthn_remove"
+	ActionUserAccessTokenAdd    Action = "user_access_token_add"
+	ActionUserAccessTokenRemove Action = "user_access_token_remove"
+
+	ActionAdminUserCreate Action = "admin_user_create"
+	ActionAdminUserEdit   Action = "admin_user_edit"
+	ActionAdminUserDelete Action = "admin_user_delete"
+
+	ActionTeamCreate       Action = "team_create"
+	ActionTeamEdit         Action = "team_edit"
+	ActionTeamDelete       Action = "team_delete"
+	ActionTeamMemberAdd    Action = "team_member_add"
+	ActionTeamMemberRemove Action = "team_member_remove"
+
+	ActionRepoVisibility          Action = "repo_visibility"
+	ActionRepoCollaboratorAdd     Action = "repo_collaborator_add"
+	ActionRepoCollaboratorEdit    Action = "repo_collaborator_edit"
+	ActionRepoCollaboratorRemove  Action = "repo_collaborator_remove"
+	ActionRepoBranchProtectEdit   Action = "repo_branch_protection_edit"
+	ActionRepoBranchProtectRemove Action = "repo_branch_protection_remove"
+	ActionRepoDeployKeyAdd        Action = "repo_deploy_key_add"
+	ActionRepoDeployKeyRemove     Action = "repo_deploy_key_remove"
+)
+
+// AllActions contains all audited actions in the order they are displayed
+var AllActions = []Action{
+	ActionUserLogin,
+	ActionUserLoginFailed,
+	ActionUserTwoFactorEnable,
+	ActionUserTwoFactorDisable,
+	ActionUserTwoFactorScratch,
+	ActionUserWebAuthnAdd,
+	ActionUserWebAuthnRemove,
+	ActionUserAccessTokenAdd,
+	ActionUserAccessTokenRemove,
+	ActionAdminUserCreate,
+	ActionAdminUserEdit,
+	ActionAdminUserDelete,
+	ActionTeamCreate,
+	ActionTeamEdit,
+	ActionTeamDelete,
+	ActionTeamMemberAdd,
+	ActionTeamMemberRemove,
+	ActionRepoVisibility,
+	ActionRepoCollaboratorAdd,
+	ActionRepoCollaboratorEdit,
+	ActionRepoCollaboratorRemove,
+	ActionRepoBranchProtectEdit,
+	ActionRepoBranchProtectRemove,
+	ActionRepoDeployKeyAdd,
+	ActionRepoDeployKeyRemove,
+}
+
+// IsValid returns true if the action is known
+func (a Action) IsValid() bool {
+	for _, action := range AllActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetType is the kind of object an action was performed on
+type TargetType string
+
+// The types of audited objects
+const (
+	TargetUser            TargetType = "user"
+	TargetTeam            TargetType = "team"
+	TargetRepository      TargetType = "repository"
+	TargetAccessToken     TargetType = "access_token"
+	TargetDeployKey       TargetType = "deploy_key"
+	TargetProtectedBranch TargetType = "protected_branch"
+	TargetCollaborator    TargetType = "collaborator"
+	TargetTwoFactor       TargetType = "two_factor"
+	TargetWebAuthn        TargetType = "webauthn"
+)
+
+// Change is a field which has been changed by an action
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// OldString returns the formatted old value or an empty string if the field had no value
+func (c *Change) OldString() string {
+	return formatValue(c.Old)
+}
+
+// NewString returns the formatted new value or an empty string if the field has no value
+func (c *Change) NewString() string {
+	return formatValue(c.New)
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// Changes is a list of changed fields
+type Changes []*Change
+
+// Add records the change of a field if the values differ
+func (c *Changes) Add(field string, oldValue, newValue interface{}) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+	*c = append(*c, &Change{Field: field, Old: oldValue, New: newValue})
+}
+
+// Event is an append-only record of a security relevant action
+type Event struct {
+	ID         int64  `xorm:"pk autoincr"`
+	Action     Action `xorm:"INDEX NOT NULL"`
+	ActorID    int64  `xorm:"INDEX NOT NULL DEFAULT 0"` // 0 if the action was performed anonymously
+	ActorName  string
+	IP         string
+	TargetType TargetType `xorm:"NOT NULL DEFAULT ''"`
+	TargetID   int64      `xorm:"NOT NULL DEFAULT 0"`
+	TargetName string
+	OwnerID    int64   `xorm:"INDEX NOT NULL DEFAULT 0"` // user or organization owning the target
+	Changes    Changes `xorm:"TEXT JSON"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TableName sets the table name of the audit events
+func (e *Event) TableName() string {
+	return "audit_event"
+}
+
+func init() {
+	db.RegisterModel(new(Event))
+}
+
+// TrStr returns the translation key of the action
+func (e *Event) TrStr() string {
+	return fmt.Sprintf("audit.action.%s", e.Action)
+}
+
+// Insert stores a new audit event
+func Insert(ctx context.Context, e *Event) error {
+	return db.Insert(ctx, e)
+}
+
+// FindEventsOptions are the options to filter audit events
+type FindEventsOptions struct {
+	db.ListOptions
+	Action  Action
+	ActorID int64
+	OwnerID int64
+	Since   timeutil.TimeStamp
+	Until   timeutil.TimeStamp
+}
+
+func (opts *FindEventsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Action != "" {
+		cond = cond.And(builder.Eq{"action": opts.Action})
+	}
+	if opts.ActorID != 0 {
+		cond = cond.And(builder.Eq{"actor_id": opts.ActorID})
+	}
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.Since != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Until != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Until})
+	}
+	return cond
+}
+
+// FindEvents returns the audit events matching the options, newest first
+func FindEvents(ctx context.Context, opts *FindEventsOptions) ([]*Event, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).Desc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+
+	events := make([]*Event, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&events)
+	return events, count, err
+}
+
+// IterateEvents calls f for every audit event matching the options, oldest first
+func IterateEvents(ctx context.Context, opts *FindEventsOptions, f func(*Event) error) error {
+	const batchSize = 100
+
+	var lastID int64
+	for {
+		events := make([]*Event, 0, batchSize)
+		if err := db.GetEngine(ctx).
+			Where(opts.toConds().And(builder.Gt{"id": lastID})).
+			Asc("id").
+			Limit(batchSize).
+			Find(&events); err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := f(e); err != nil {
+				return err
+			}
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
+
+// DeleteOldEvents deletes the audit events which are older than the retention period
+func DeleteOldEvents(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	_, err := db.GetEngine(ctx).Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(&Event{})
+	return err
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChanges_Add(t *testing.T) {
+	var changes Changes
+	changes.Add("is_admin", false, true)
+	changes.Add("email", "a@example.com", "a@example.com")
+	changes.Add("teams", []string{"a"}, []string{"a"})
+	assert.Equal(t, Changes{{Field: "is_admin", Old: false, New: true}}, changes)
+}
+
+func TestFindEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	events, count, err := FindEvents(db.DefaultContext, &FindEventsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	if assert.Len(t, events, 3) {
+		assert.EqualValues(t, 3, events[0].ID)
+		assert.Equal(t, Changes{{Field: "is_admin", Old: false, New: true}}, events[0].Changes)
+	}
+
+	events, count, err = FindEvents(db.DefaultContext, &FindEventsOptions{ActorID: 2, OwnerID: 3})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, ActionTeamMemberAdd, events[0].Action)
+	}
+
+	_, count, err = FindEvents(db.DefaultContext, &FindEventsOptions{Action: ActionUserLogin, Since: 946684801})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
+
+func TestIterateEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	var ids []int64
+	assert.NoError(t, IterateEvents(db.DefaultContext, &FindEventsOptions{}, func(e *Event) error {
+		ids = append(ids, e.ID)
+		return nil
+	}))
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+}
+
+func TestDeleteOldEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, Insert(db.DefaultContext, &Event{Action: ActionUserLogin, ActorID: 2, OwnerID: 2}))
+	assert.NoError(t, DeleteOldEvents(db.DefaultContext, 24*time.Hour))
+
+	unittest.AssertCount(t, &Event{}, 1)
+}
+
+func TestChange_String(t *testing.T) {
+	c := &Change{Field: "is_admin", Old: false, New: true}
+	assert.Equal(t, "false", c.OldString())
+	assert.Equal(t, "true", c.NewString())
+
+	c = &Change{Field: "member", New: "user2"}
+	assert.Empty(t, c.OldString())
+	assert.Equal(t, "user2", c.NewString())
+}
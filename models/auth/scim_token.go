@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// SCIMToken is a bearer token issued by a site administrator which allows an identity provider
+// to provision users and the teams of an organization via SCIM.
+type SCIMToken struct {
+	ID             int64 `xorm:"pk autoincr"`
+	Name           string
+	OrgID          int64  `xorm:"INDEX NOT NULL"`
+	SourceID       int64  `xorm:"NOT NULL DEFAULT 0"` // authentication source of provisioned users, 0 for local users
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	LastUsed    timeutil.TimeStamp
+}
+
+func init() {
+	db.RegisterModel(new(SCIMToken))
+}
+
+// ErrSCIMTokenNotExist represents a "SCIMTokenNotExist" kind of error.
+type ErrSCIMTokenNotExist struct {
+	ID int64
+}
+
+// IsErrSCIMTokenNotExist checks if an error is a ErrSCIMTokenNotExist.
+func IsErrSCIMTokenNotExist(err error) bool {
+	_, ok := err.(ErrSCIMTokenNotExist)
+	return ok
+}
+
+func (err ErrSCIMTokenNotExist) Error() string {
+	return fmt.Sprintf("scim token does not exist [id: %d]", err.ID)
+}
+
+// NewSCIMToken creates a new SCIM token. The plain token is only available in t.Token after the creation.
+func NewSCIMToken(t *SCIMToken) error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	token, err := util.CryptoRandomBytes(20)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = hex.EncodeToString(token)
+	t.TokenHash = HashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	return db.Insert(db.DefaultContext, t)
+}
+
+// GetSCIMTokenByToken returns the SCIM token matching the plain token
+func GetSCIMTokenByToken(token string) (*SCIMToken, error) {
+	if len(token) != 40 {
+		return nil, ErrSCIMTokenNotExist{}
+	}
+
+	var tokens []*SCIMToken
+	if err := db.GetEngine(db.DefaultContext).Where("token_last_eight = ?", token[len(token)-8:]).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(HashToken(token, t.TokenSalt))) == 1 {
+			return t, nil
+		}
+	}
+	return nil, ErrSCIMTokenNotExist{}
+}
+
+// UpdateSCIMTokenLastUsed records the usage of the token
+func UpdateSCIMTokenLastUsed(t *SCIMToken) error {
+	t.LastUsed = timeutil.TimeStampNow()
+	_, err := db.GetEngine(db.DefaultContext).ID(t.ID).Cols("last_used").Update(t)
+	return err
+}
+
+// ListSCIMTokens returns all SCIM tokens
+func ListSCIMTokens() ([]*SCIMToken, error) {
+	tokens := make([]*SCIMToken, 0, 5)
+	return tokens, db.GetEngine(db.DefaultContext).OrderBy("id").Find(&tokens)
+}
+
+// DeleteSCIMTokenByID deletes the SCIM token with the id
+func DeleteSCIMTokenByID(id int64) error {
+	cnt, err := db.GetEngine(db.DefaultContext).ID(id).Delete(new(SCIMToken))
+	if err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrSCIMTokenNotExist{ID: id}
+	}
+	return nil
+}
@@ -36,6 +36,22 @@ func IsErrWebAuthnCredentialNotExist(err error) bool {
 	return ok
 }
 
+// ErrPasskeyRequired represents a "PasskeyRequired" kind of error.
+type ErrPasskeyRequired struct {
+	UID  int64
+	Name string
+}
+
+func (err ErrPasskeyRequired) Error() string {
+	return fmt.Sprintf("user must sign in with a passkey [uid: %d, name: %s]", err.UID, err.Name)
+}
+
+// IsErrPasskeyRequired checks if an error is a ErrPasskeyRequired.
+func IsErrPasskeyRequired(err error) bool {
+	_, ok := err.(ErrPasskeyRequired)
+	return ok
+}
+
 // WebAuthnCredential represents the WebAuthn credential data for a public-key
 // credential conformant to WebAuthn Level 1
 type WebAuthnCredential struct {
@@ -49,6 +65,7 @@ type WebAuthnCredential struct {
 	AAGUID          []byte
 	SignCount       uint32 `xorm:"BIGINT"`
 	CloneWarning    bool
+	Passkey         bool               `xorm:"NOT NULL DEFAULT false"` // discoverable credential usable for passwordless sign-in
 	CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
 }
@@ -178,12 +195,33 @@ func getWebAuthnCredentialByCredID(ctx context.Context, userID int64, credID str
 	return cred, nil
 }
 
+// GetPasskeyByCredID returns the passkey with the credential ID. Passkeys identify their user themselves.
+func GetPasskeyByCredID(credID string) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := db.GetEngine(db.DefaultContext).Where("credential_id = ? AND passkey = ?", credID, true).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{CredentialID: credID}
+	}
+	return cred, nil
+}
+
+// HasPasskeyByUID returns whether the user has registered a passkey
+func HasPasskeyByUID(uid int64) (bool, error) {
+	return db.GetEngine(db.DefaultContext).Where("user_id = ? AND passkey = ?", uid, true).Exist(&WebAuthnCredential{})
+}
+
 // CreateCredential will create a new WebAuthnCredential from the given Credential
 func CreateCredential(userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
-	return createCredential(db.DefaultContext, userID, name, cred)
+	return createCredential(db.DefaultContext, userID, name, cred, false)
+}
+
+// CreatePasskey will create a new WebAuthnCredential from the given discoverable Credential
+func CreatePasskey(userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	return createCredential(db.DefaultContext, userID, name, cred, true)
 }
 
-func createCredential(ctx context.Context, userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+func createCredential(ctx context.Context, userID int64, name string, cred *webauthn.Credential, passkey bool) (*WebAuthnCredential, error) {
 	c := &WebAuthnCredential{
 		UserID:          userID,
 		Name:            name,
@@ -193,6 +231,7 @@ func createCredential(ctx context.Context, userID int64, name string, cred *weba
 		AAGUID:          cred.Authenticator.AAGUID,
 		SignCount:       cred.Authenticator.SignCount,
 		CloneWarning:    false,
+		Passkey:         passkey,
 	}
 
 	if err := db.Insert(ctx, c); err != nil {
@@ -67,3 +67,29 @@ func TestCreateCredential(t *testing.T) {
 
 	unittest.AssertExistsIf(t, true, &WebAuthnCredential{Name: "WebAuthn Created Credential", UserID: 1})
 }
+
+func TestCreatePasskey(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	has, err := HasPasskeyByUID(32)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	res, err := CreatePasskey(32, "Passkey", &webauthn.Credential{ID: []byte("Passkey")})
+	assert.NoError(t, err)
+	assert.True(t, res.Passkey)
+
+	has, err = HasPasskeyByUID(32)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	cred, err := GetPasskeyByCredID(res.CredentialID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 32, cred.UserID)
+
+	// credentials registered as second factor are no passkeys
+	res, err = CreateCredential(32, "Security key", &webauthn.Credential{ID: []byte("Security key")})
+	assert.NoError(t, err)
+	_, err = GetPasskeyByCredID(res.CredentialID)
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+}
@@ -24,12 +24,14 @@ import (
 	"github.com/gobwas/glob"
 )
 
-// ProtectedBranch struct
+// ProtectedBranch struct. BranchName is either the name of a branch or
+// a glob pattern like "release/*" which protects all matching branches.
 type ProtectedBranch struct {
-	ID                            int64  `xorm:"pk autoincr"`
-	RepoID                        int64  `xorm:"UNIQUE(s)"`
-	BranchName                    string `xorm:"UNIQUE(s)"`
-	CanPush                       bool   `xorm:"NOT NULL DEFAULT false"`
+	ID                            int64     `xorm:"pk autoincr"`
+	RepoID                        int64     `xorm:"UNIQUE(s)"`
+	BranchName                    string    `xorm:"UNIQUE(s)"`
+	globRule                      glob.Glob `xorm:"-"`
+	CanPush                       bool      `xorm:"NOT NULL DEFAULT false"`
 	EnableWhitelist               bool
 	WhitelistUserIDs              []int64  `xorm:"JSON TEXT"`
 	WhitelistTeamIDs              []int64  `xorm:"JSON TEXT"`
@@ -50,6 +52,8 @@ type ProtectedBranch struct {
 	RequireSignedCommits          bool     `xorm:"NOT NULL DEFAULT false"`
 	ProtectedFilePatterns         string   `xorm:"TEXT"`
 	UnprotectedFilePatterns       string   `xorm:"TEXT"`
+	RequireCodeOwnerReview        bool     `xorm:"NOT NULL DEFAULT false"`
+	EnableMergeQueue              bool     `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -66,6 +70,60 @@ func (protectBranch *ProtectedBranch) IsProtected() bool {
 	return protectBranch.ID > 0
 }
 
+// IsBranchNameGlob returns if the protected branch rule name is a glob pattern.
+// Git forbids these characters in branch names, so they can't be part of an exact name.
+func IsBranchNameGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// IsValidBranchNameGlob returns if the glob pattern of a protected branch rule can be compiled
+func IsValidBranchNameGlob(pattern string) bool {
+	_, err := glob.Compile(pattern, '/')
+	return err == nil
+}
+
+// IsGlob returns if the rule protects all branches matching its glob pattern
+func (protectBranch *ProtectedBranch) IsGlob() bool {
+	return IsBranchNameGlob(protectBranch.BranchName)
+}
+
+// Match returns if the rule applies to the branch
+func (protectBranch *ProtectedBranch) Match(branchName string) bool {
+	if protectBranch.BranchName == branchName {
+		return true
+	}
+	if !protectBranch.IsGlob() {
+		return false
+	}
+
+	if protectBranch.globRule == nil {
+		var err error
+		protectBranch.globRule, err = glob.Compile(protectBranch.BranchName, '/')
+		if err != nil {
+			log.Warn("Invalid glob rule for ProtectedBranch[%d]: %s %v", protectBranch.ID, protectBranch.BranchName, err)
+			return false
+		}
+	}
+	return protectBranch.globRule.Match(branchName)
+}
+
+// specificity returns how specific the glob pattern of the rule is by counting its literal characters
+func (protectBranch *ProtectedBranch) specificity() int {
+	n := 0
+	inClass := false
+	for _, c := range protectBranch.BranchName {
+		switch {
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c != '*' && c != '?' && !inClass:
+			n++
+		}
+	}
+	return n
+}
+
 // CanUserPush returns if some user could push to this protected branch
 func (protectBranch *ProtectedBranch) CanUserPush(userID int64) bool {
 	if !protectBranch.CanPush {
@@ -220,6 +278,12 @@ func (protectBranch *ProtectedBranch) MergeBlockedByOfficialReviewRequests(pr *P
 	return has
 }
 
+// IsMergeQueueEnabled returns true if pull requests are merged through the merge queue.
+// The queue is only used if status checks are enabled as it waits for them to succeed.
+func (protectBranch *ProtectedBranch) IsMergeQueueEnabled() bool {
+	return protectBranch.EnableMergeQueue && protectBranch.EnableStatusCheck
+}
+
 // MergeBlockedByOutdatedBranch returns true if merge is blocked by an outdated head branch
 func (protectBranch *ProtectedBranch) MergeBlockedByOutdatedBranch(pr *PullRequest) bool {
 	return protectBranch.BlockOnOutdatedBranch && pr.CommitsBehind > 0
@@ -304,7 +368,45 @@ func (protectBranch *ProtectedBranch) IsUnprotectedFile(patterns []glob.Glob, pa
 	return r
 }
 
-// GetProtectedBranchBy getting protected branch by ID/Name
+// GetMatchingProtectedBranchRule returns the rule which protects the branch or nil if the branch is unprotected.
+// A rule with the exact name of the branch takes precedence. Otherwise the glob rule with the most literal
+// characters wins, ties are decided in favor of the older rule.
+func GetMatchingProtectedBranchRule(repoID int64, branchName string) (*ProtectedBranch, error) {
+	return getMatchingProtectedBranchRule(db.GetEngine(db.DefaultContext), repoID, branchName)
+}
+
+func getMatchingProtectedBranchRule(e db.Engine, repoID int64, branchName string) (*ProtectedBranch, error) {
+	rule, err := getProtectedBranchBy(e, repoID, branchName)
+	if err != nil || rule != nil {
+		return rule, err
+	}
+
+	rules := make([]*ProtectedBranch, 0, 5)
+	if err := e.Where("repo_id = ?", repoID).Asc("id").Find(&rules); err != nil {
+		return nil, err
+	}
+	return FindMatchingProtectedBranchRule(rules, branchName), nil
+}
+
+// FindMatchingProtectedBranchRule returns the most specific rule of the list which protects the branch
+func FindMatchingProtectedBranchRule(rules []*ProtectedBranch, branchName string) *ProtectedBranch {
+	var matched *ProtectedBranch
+	for _, rule := range rules {
+		if rule.BranchName == branchName {
+			return rule
+		}
+		if !rule.IsGlob() || !rule.Match(branchName) {
+			continue
+		}
+		if matched == nil || rule.specificity() > matched.specificity() {
+			matched = rule
+		}
+	}
+	return matched
+}
+
+// GetProtectedBranchBy gets the protected branch rule by its exact name.
+// Use GetMatchingProtectedBranchRule to find the rule which protects a branch.
 func GetProtectedBranchBy(repoID int64, branchName string) (*ProtectedBranch, error) {
 	return getProtectedBranchBy(db.GetEngine(db.DefaultContext), repoID, branchName)
 }
@@ -397,21 +499,16 @@ func UpdateProtectBranch(ctx context.Context, repo *repo_model.Repository, prote
 // GetProtectedBranches get all protected branches
 func GetProtectedBranches(repoID int64) ([]*ProtectedBranch, error) {
 	protectedBranches := make([]*ProtectedBranch, 0)
-	return protectedBranches, db.GetEngine(db.DefaultContext).Find(&protectedBranches, &ProtectedBranch{RepoID: repoID})
+	return protectedBranches, db.GetEngine(db.DefaultContext).Asc("id").Find(&protectedBranches, &ProtectedBranch{RepoID: repoID})
 }
 
-// IsProtectedBranch checks if branch is protected
+// IsProtectedBranch checks if branch is protected by a rule
 func IsProtectedBranch(repoID int64, branchName string) (bool, error) {
-	protectedBranch := &ProtectedBranch{
-		RepoID:     repoID,
-		BranchName: branchName,
-	}
-
-	has, err := db.GetEngine(db.DefaultContext).Exist(protectedBranch)
+	rule, err := GetMatchingProtectedBranchRule(repoID, branchName)
 	if err != nil {
 		return true, err
 	}
-	return has, nil
+	return rule != nil, nil
 }
 
 // updateApprovalWhitelist checks whether the user whitelist changed and returns a whitelist with
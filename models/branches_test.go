@@ -159,3 +159,39 @@ func TestOnlyGetDeletedBranchOnCorrectRepo(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, deletedBranch)
 }
+
+func TestFindMatchingProtectedBranchRule(t *testing.T) {
+	rules := []*ProtectedBranch{
+		{ID: 1, BranchName: "*"},
+		{ID: 2, BranchName: "release/*"},
+		{ID: 3, BranchName: "release/v1.*"},
+		{ID: 4, BranchName: "release/v1.0"},
+		{ID: 5, BranchName: "feature/**"},
+		{ID: 6, BranchName: "main"},
+	}
+
+	cases := []struct {
+		branch string
+		ruleID int64
+	}{
+		{"main", 6},
+		{"develop", 1},
+		{"release/v2.0", 2},
+		{"release/v1.2", 3},
+		{"release/v1.0", 4},
+		{"feature/a/b", 5},
+		{"hotfix/a", 0},
+	}
+	for _, c := range cases {
+		rule := FindMatchingProtectedBranchRule(rules, c.branch)
+		if c.ruleID == 0 {
+			assert.Nil(t, rule, c.branch)
+		} else if assert.NotNil(t, rule, c.branch) {
+			assert.EqualValues(t, c.ruleID, rule.ID, c.branch)
+		}
+	}
+
+	assert.True(t, IsBranchNameGlob("release/*"))
+	assert.False(t, IsBranchNameGlob("release/v1.0"))
+	assert.False(t, IsValidBranchNameGlob("release/[v"))
+}
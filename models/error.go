@@ -160,6 +160,36 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return "access token is empty"
 }
 
+// ErrAccessTokenInvalidScope represents a "AccessTokenInvalidScope" kind of error.
+type ErrAccessTokenInvalidScope struct {
+	Scope string
+}
+
+// IsErrAccessTokenInvalidScope checks if an error is a ErrAccessTokenInvalidScope.
+func IsErrAccessTokenInvalidScope(err error) bool {
+	_, ok := err.(ErrAccessTokenInvalidScope)
+	return ok
+}
+
+func (err ErrAccessTokenInvalidScope) Error() string {
+	return fmt.Sprintf("access token scope is invalid [scope: %s]", err.Scope)
+}
+
+// ErrAccessTokenInvalidAllowedRepo represents a "AccessTokenInvalidAllowedRepo" kind of error.
+type ErrAccessTokenInvalidAllowedRepo struct {
+	Repo string
+}
+
+// IsErrAccessTokenInvalidAllowedRepo checks if an error is a ErrAccessTokenInvalidAllowedRepo.
+func IsErrAccessTokenInvalidAllowedRepo(err error) bool {
+	_, ok := err.(ErrAccessTokenInvalidAllowedRepo)
+	return ok
+}
+
+func (err ErrAccessTokenInvalidAllowedRepo) Error() string {
+	return fmt.Sprintf("access token repository is invalid [repo: %s]", err.Repo)
+}
+
 //.____   ____________________
 //|    |  \_   _____/   _____/
 //|    |   |    __) \_____  \
@@ -882,6 +912,23 @@ func (err ErrMergeUnrelatedHistories) Error() string {
 	return fmt.Sprintf("Merge UnrelatedHistories Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
 }
 
+// ErrMergeDivergingFastForwardOnly represents an error if a fast-forward-only merge fails because the branches diverge
+type ErrMergeDivergingFastForwardOnly struct {
+	StdOut string
+	StdErr string
+	Err    error
+}
+
+// IsErrMergeDivergingFastForwardOnly checks if an error is a ErrMergeDivergingFastForwardOnly.
+func IsErrMergeDivergingFastForwardOnly(err error) bool {
+	_, ok := err.(ErrMergeDivergingFastForwardOnly)
+	return ok
+}
+
+func (err ErrMergeDivergingFastForwardOnly) Error() string {
+	return fmt.Sprintf("Merge DivergingFastForwardOnly Error: %v: %s\n%s", err.Err, err.StdErr, err.StdOut)
+}
+
 // ErrRebaseConflicts represents an error if rebase fails with a conflict
 type ErrRebaseConflicts struct {
 	Style     repo_model.MergeStyle
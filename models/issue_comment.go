@@ -110,6 +110,14 @@ const (
 	CommentTypeDismissReview
 	// 33 Change issue ref
 	CommentTypeChangeIssueRef
+	// 34 pr was scheduled to auto merge when checks succeed
+	CommentTypePRScheduledToAutoMerge
+	// 35 pr was un scheduled to auto merge when checks succeed
+	CommentTypePRUnScheduledToAutoMerge
+	// 36 pr was added to the merge queue
+	CommentTypePRAddedToMergeQueue
+	// 37 pr was removed from the merge queue
+	CommentTypePRRemovedFromMergeQueue
 )
 
 var commentStrings = []string{
@@ -147,6 +155,10 @@ var commentStrings = []string{
 	"project_board",
 	"dismiss_review",
 	"change_issue_ref",
+	"pull_scheduled_merge",
+	"pull_cancel_scheduled_merge",
+	"pull_added_to_merge_queue",
+	"pull_removed_from_merge_queue",
 }
 
 func (t CommentType) String() string {
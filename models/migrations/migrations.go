@@ -383,6 +383,28 @@ var migrations = []Migration{
 	NewMigration("Add package tables", addPackageTables),
 	// v213 -> v214
 	NewMigration("Add allow edits from maintainers to PullRequest table", addAllowMaintainerEdit),
+	// v214 -> v215
+	NewMigration("Add package cleanup rule table", addPackageCleanupRuleTable),
+	// v215 -> v216
+	NewMigration("Add pull auto merge table", addPullAutoMergeTable),
+	// v216 -> v217
+	NewMigration("Add require code owner review to protected branch", addRequireCodeOwnerReviewToProtectedBranch),
+	// v217 -> v218
+	NewMigration("Add merge queue", addMergeQueue),
+	// v218 -> v219
+	NewMigration("Add SCIM token and user tables", addSCIMTables),
+	// v219 -> v220
+	NewMigration("Add scope, expiry and allowed repositories to access token", addScopeToAccessToken),
+	// v220 -> v221
+	NewMigration("Add audit event table", addAuditEventTable),
+	// v221 -> v222
+	NewMigration("Add passkey to webauthn credential", addPasskeyToWebAuthnCredential),
+	// v222 -> v223
+	NewMigration("Add SSH keypair to push mirror", addSSHKeypairToPushMirror),
+	// v223 -> v224
+	NewMigration("Add quota size to user", addQuotaSizeToUser),
+	// v224 -> v225
+	NewMigration("Add push rule table", addPushRuleTable),
 }
 
 // GetCurrentDBVersion returns the current db version
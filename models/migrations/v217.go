@@ -0,0 +1,37 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return err
+	}
+
+	type MergeQueueEntry struct {
+		ID             int64              `xorm:"pk autoincr"`
+		RepoID         int64              `xorm:"INDEX(s) NOT NULL"`
+		BaseBranch     string             `xorm:"INDEX(s) NOT NULL"`
+		PullID         int64              `xorm:"UNIQUE NOT NULL"`
+		DoerID         int64              `xorm:"NOT NULL"`
+		MergeStyle     string             `xorm:"varchar(30)"`
+		Message        string             `xorm:"LONGTEXT"`
+		ParentCommitID string             `xorm:"VARCHAR(40)"`
+		HeadCommitID   string             `xorm:"VARCHAR(40)"`
+		CommitID       string             `xorm:"VARCHAR(40)"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(MergeQueueEntry))
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addSCIMTables(x *xorm.Engine) error {
+	type SCIMToken struct {
+		ID             int64 `xorm:"pk autoincr"`
+		Name           string
+		OrgID          int64  `xorm:"INDEX NOT NULL"`
+		SourceID       int64  `xorm:"NOT NULL DEFAULT 0"`
+		TokenHash      string `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		LastUsed    timeutil.TimeStamp
+	}
+
+	type SCIMUser struct {
+		ID     int64 `xorm:"pk autoincr"`
+		OrgID  int64 `xorm:"UNIQUE(s) NOT NULL"`
+		UserID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	}
+
+	return x.Sync2(new(SCIMToken), new(SCIMUser))
+}
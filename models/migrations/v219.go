@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addScopeToAccessToken(x *xorm.Engine) error {
+	type AccessToken struct {
+		Scope           string             `xorm:"NOT NULL DEFAULT ''"`
+		ExpiresUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		AllowedOwnerIDs []int64            `xorm:"TEXT JSON"`
+		AllowedRepoIDs  []int64            `xorm:"TEXT JSON"`
+	}
+
+	if err := x.Sync2(new(AccessToken)); err != nil {
+		return err
+	}
+
+	// existing tokens keep the full permissions of their user
+	_, err := x.Exec("UPDATE access_token SET scope = ? WHERE scope = ''", "all")
+	return err
+}
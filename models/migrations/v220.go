@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAuditEventTable(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID         int64  `xorm:"pk autoincr"`
+		Action     string `xorm:"INDEX NOT NULL"`
+		ActorID    int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		ActorName  string
+		IP         string
+		TargetType string `xorm:"NOT NULL DEFAULT ''"`
+		TargetID   int64  `xorm:"NOT NULL DEFAULT 0"`
+		TargetName string
+		OwnerID    int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Changes    string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(AuditEvent))
+}
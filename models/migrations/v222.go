@@ -0,0 +1,19 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addSSHKeypairToPushMirror(x *xorm.Engine) error {
+	type pushMirror struct {
+		PublicKey  string `xorm:"VARCHAR(100)"`
+		PrivateKey string `xorm:"TEXT"`
+		HostKey    string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(pushMirror))
+}
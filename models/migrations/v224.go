@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPushRuleTable(x *xorm.Engine) error {
+	type PushRule struct {
+		ID                    int64              `xorm:"pk autoincr"`
+		OwnerID               int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		RepoID                int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		MaxFileSize           int64              `xorm:"NOT NULL DEFAULT 0"`
+		ForbiddenFilePatterns string             `xorm:"TEXT"`
+		RejectSecrets         bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix           timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(PushRule))
+}
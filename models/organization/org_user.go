@@ -62,6 +62,49 @@ func IsOrganizationMember(ctx context.Context, orgID, uid int64) (bool, error) {
 		Exist()
 }
 
+// IsManagedUser returns true if the user can be managed by the provisioning client (SCIM) of the organization
+// which is bound to the given authentication source. These are the users of the authentication source and
+// the local users which have been created by the client or are members of the organization.
+// Site administrators are never managed.
+func IsManagedUser(ctx context.Context, orgID, sourceID int64, u *user_model.User) (bool, error) {
+	if u.Type != user_model.UserTypeIndividual || u.IsAdmin {
+		return false, nil
+	}
+	if u.LoginSource != 0 {
+		return sourceID != 0 && u.LoginSource == sourceID, nil
+	}
+	if isSCIMUser, err := IsSCIMUser(ctx, orgID, u.ID); err != nil || isSCIMUser {
+		return isSCIMUser, err
+	}
+	return IsOrganizationMember(ctx, orgID, u.ID)
+}
+
+// FindManagedUsers returns the users which can be managed by the provisioning client of the organization, see IsManagedUser
+func FindManagedUsers(ctx context.Context, orgID, sourceID int64, listOptions db.ListOptions) ([]*user_model.User, int64, error) {
+	managedCond := builder.And(
+		builder.Eq{"login_source": 0},
+		builder.Or(
+			builder.In("id", builder.Select("user_id").From("scim_user").Where(builder.Eq{"org_id": orgID})),
+			builder.In("id", builder.Select("uid").From("org_user").Where(builder.Eq{"org_id": orgID})),
+		),
+	)
+	if sourceID != 0 {
+		managedCond = builder.Or(managedCond, builder.Eq{"login_source": sourceID})
+	}
+	cond := builder.And(
+		builder.Eq{"type": user_model.UserTypeIndividual, "is_admin": false},
+		managedCond,
+	)
+
+	sess := db.GetEngine(ctx).Where(cond).OrderBy("id ASC")
+	if listOptions.Page != 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	users := make([]*user_model.User, 0, listOptions.PageSize)
+	count, err := sess.FindAndCount(&users)
+	return users, count, err
+}
+
 // IsPublicMembership returns true if the given user's membership of given org is public.
 func IsPublicMembership(orgID, uid int64) (bool, error) {
 	return db.GetEngine(db.DefaultContext).
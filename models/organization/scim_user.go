@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package organization
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// SCIMUser records a local user which has been created by the provisioning client (SCIM) of an organization.
+// The user stays managed by the organization even if it is not a member of any of its teams.
+type SCIMUser struct {
+	ID     int64 `xorm:"pk autoincr"`
+	OrgID  int64 `xorm:"UNIQUE(s) NOT NULL"`
+	UserID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(SCIMUser))
+}
+
+// AddSCIMUser records that the user has been created by the provisioning client of the organization
+func AddSCIMUser(ctx context.Context, orgID, userID int64) error {
+	return db.Insert(ctx, &SCIMUser{OrgID: orgID, UserID: userID})
+}
+
+// IsSCIMUser returns true if the user has been created by the provisioning client of the organization
+func IsSCIMUser(ctx context.Context, orgID, userID int64) (bool, error) {
+	return db.GetEngine(ctx).Exist(&SCIMUser{OrgID: orgID, UserID: userID})
+}
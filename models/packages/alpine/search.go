@@ -0,0 +1,146 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"context"
+	"strconv"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+
+	"xorm.io/builder"
+)
+
+// PackageSearchOptions are options for SearchPackages
+type PackageSearchOptions struct {
+	OwnerID      int64
+	Branch       string
+	Repository   string
+	Architecture string
+}
+
+func (opts *PackageSearchOptions) toCond() builder.Cond {
+	var cond builder.Cond = builder.Eq{
+		"package.type":                packages.TypeAlpine,
+		"package.owner_id":            opts.OwnerID,
+		"package_version.is_internal": false,
+	}
+
+	props := make(map[string]string)
+	if opts.Branch != "" {
+		props[alpine_module.PropertyBranch] = opts.Branch
+	}
+	if opts.Repository != "" {
+		props[alpine_module.PropertyRepository] = opts.Repository
+	}
+
+	if len(props) != 0 || opts.Architecture != "" {
+		var propsCond builder.Cond = builder.Eq{
+			"package_property.ref_type": packages.PropertyTypeFile,
+		}
+		propsCond = propsCond.And(builder.Expr("package_property.ref_id = package_file.id"))
+
+		propsCondBlock := builder.NewCond()
+		for name, value := range props {
+			propsCondBlock = propsCondBlock.Or(builder.Eq{
+				"package_property.name":  name,
+				"package_property.value": value,
+			})
+		}
+		count := len(props)
+		if opts.Architecture != "" {
+			// packages which are independent of the architecture are part of every architecture
+			propsCondBlock = propsCondBlock.Or(builder.Eq{
+				"package_property.name": alpine_module.PropertyArchitecture,
+			}.And(builder.In("package_property.value", opts.Architecture, alpine_module.ArchitectureNoarch)))
+			count++
+		}
+		propsCond = propsCond.And(propsCondBlock)
+
+		cond = cond.And(builder.Eq{
+			strconv.Itoa(count): builder.Select("COUNT(*)").Where(propsCond).From("package_property"),
+		})
+	}
+
+	return cond
+}
+
+// SearchPackages gets the package files matching the search options
+func SearchPackages(ctx context.Context, opts *PackageSearchOptions) ([]*packages.PackageFileDescriptor, error) {
+	pfs := make([]*packages.PackageFile, 0, 10)
+	err := db.GetEngine(ctx).
+		Table("package_file").
+		Select("package_file.*").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where(opts.toCond()).
+		Asc("package.lower_name", "package_version.created_unix").
+		Find(&pfs)
+	if err != nil {
+		return nil, err
+	}
+
+	pfds := make([]*packages.PackageFileDescriptor, 0, len(pfs))
+	for _, pf := range pfs {
+		pfd, err := packages.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return nil, err
+		}
+		pfds = append(pfds, pfd)
+	}
+	return pfds, nil
+}
+
+// GetBranches gets all available branches
+func GetBranches(ctx context.Context, ownerID int64) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, nil, alpine_module.PropertyBranch)
+}
+
+// GetRepositories gets all available repositories of the given branch
+func GetRepositories(ctx context.Context, ownerID int64, branch string) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, map[string]string{
+		alpine_module.PropertyBranch: branch,
+	}, alpine_module.PropertyRepository)
+}
+
+// GetArchitectures gets all available architectures of the given branch and repository
+func GetArchitectures(ctx context.Context, ownerID int64, branch, repository string) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, map[string]string{
+		alpine_module.PropertyBranch:     branch,
+		alpine_module.PropertyRepository: repository,
+	}, alpine_module.PropertyArchitecture)
+}
+
+func getDistinctPropertyValues(ctx context.Context, ownerID int64, filter map[string]string, propName string) ([]string, error) {
+	var cond builder.Cond = builder.Eq{
+		"package_property.ref_type": packages.PropertyTypeFile,
+		"package_property.name":     propName,
+		"package.type":              packages.TypeAlpine,
+		"package.owner_id":          ownerID,
+	}
+	for name, value := range filter {
+		innerCond := builder.
+			Expr("pp.ref_id = package_property.ref_id").
+			And(builder.Eq{
+				"pp.ref_type": packages.PropertyTypeFile,
+				"pp.name":     name,
+				"pp.value":    value,
+			})
+		cond = cond.And(builder.Exists(builder.Select("pp.ref_id").From("package_property pp").Where(innerCond)))
+	}
+
+	values := make([]string, 0, 5)
+	return values, db.GetEngine(ctx).
+		Table("package_property").
+		Distinct("package_property.value").
+		Join("INNER", "package_file", "package_file.id = package_property.ref_id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where(cond).
+		Asc("package_property.value").
+		Find(&values)
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// ErrPackageCleanupRuleNotExist indicates a package cleanup rule not exist error
+var ErrPackageCleanupRuleNotExist = errors.New("Package cleanup rule does not exist")
+
+func init() {
+	db.RegisterModel(new(PackageCleanupRule))
+}
+
+// PackageCleanupRule represents a rule which describes which package versions of an owner can be removed
+type PackageCleanupRule struct {
+	ID             int64              `xorm:"pk autoincr"`
+	Enabled        bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	OwnerID        int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Type           Type               `xorm:"INDEX NOT NULL"`
+	NamePattern    string             `xorm:"NOT NULL DEFAULT ''"`
+	KeepCount      int                `xorm:"NOT NULL DEFAULT 0"`
+	RemoveDays     int                `xorm:"NOT NULL DEFAULT 0"`
+	VersionPattern string             `xorm:"NOT NULL DEFAULT ''"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix    timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+
+	NameMatcher    glob.Glob      `xorm:"-"`
+	VersionMatcher *regexp.Regexp `xorm:"-"`
+}
+
+// CompilePatterns compiles the name and version patterns of the rule.
+// An empty pattern matches everything. The version pattern is case-insensitive.
+func (pcr *PackageCleanupRule) CompilePatterns() error {
+	pcr.NameMatcher = nil
+	pcr.VersionMatcher = nil
+
+	if pcr.NamePattern != "" {
+		g, err := glob.Compile(pcr.NamePattern)
+		if err != nil {
+			return fmt.Errorf("invalid name pattern: %w", err)
+		}
+		pcr.NameMatcher = g
+	}
+
+	if pcr.VersionPattern != "" {
+		re, err := regexp.Compile(`(?i)\A` + pcr.VersionPattern + `\z`)
+		if err != nil {
+			return fmt.Errorf("invalid version pattern: %w", err)
+		}
+		pcr.VersionMatcher = re
+	}
+
+	return nil
+}
+
+// MatchesName tests if the package name is covered by the rule
+func (pcr *PackageCleanupRule) MatchesName(name string) bool {
+	return pcr.NameMatcher == nil || pcr.NameMatcher.Match(name)
+}
+
+// MatchesVersion tests if the package version is covered by the rule
+func (pcr *PackageCleanupRule) MatchesVersion(version string) bool {
+	return pcr.VersionMatcher == nil || pcr.VersionMatcher.MatchString(version)
+}
+
+// InsertCleanupRule inserts a cleanup rule
+func InsertCleanupRule(ctx context.Context, pcr *PackageCleanupRule) (*PackageCleanupRule, error) {
+	return pcr, db.Insert(ctx, pcr)
+}
+
+// GetCleanupRuleByID gets a cleanup rule by its id
+func GetCleanupRuleByID(ctx context.Context, id int64) (*PackageCleanupRule, error) {
+	pcr := &PackageCleanupRule{}
+
+	has, err := db.GetEngine(ctx).ID(id).Get(pcr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageCleanupRuleNotExist
+	}
+	return pcr, nil
+}
+
+// UpdateCleanupRule updates a cleanup rule
+func UpdateCleanupRule(ctx context.Context, pcr *PackageCleanupRule) error {
+	_, err := db.GetEngine(ctx).ID(pcr.ID).AllCols().Update(pcr)
+	return err
+}
+
+// GetCleanupRulesByOwner gets all cleanup rules of an owner
+func GetCleanupRulesByOwner(ctx context.Context, ownerID int64) ([]*PackageCleanupRule, error) {
+	pcrs := make([]*PackageCleanupRule, 0, 10)
+	return pcrs, db.GetEngine(ctx).
+		Where("owner_id = ?", ownerID).
+		OrderBy("type, id").
+		Find(&pcrs)
+}
+
+// DeleteCleanupRuleByID deletes a cleanup rule by its id
+func DeleteCleanupRuleByID(ctx context.Context, ruleID int64) error {
+	_, err := db.GetEngine(ctx).ID(ruleID).Delete(&PackageCleanupRule{})
+	return err
+}
+
+// DeleteCleanupRulesByOwner deletes all cleanup rules of an owner
+func DeleteCleanupRulesByOwner(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&PackageCleanupRule{})
+	return err
+}
+
+// GetEnabledCleanupRules gets all enabled cleanup rules
+func GetEnabledCleanupRules(ctx context.Context) ([]*PackageCleanupRule, error) {
+	pcrs := make([]*PackageCleanupRule, 0, 10)
+	return pcrs, db.GetEngine(ctx).
+		Where("enabled = ?", true).
+		Find(&pcrs)
+}
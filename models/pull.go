@@ -221,7 +221,7 @@ func (pr *PullRequest) LoadProtectedBranchCtx(ctx context.Context) (err error) {
 				return
 			}
 		}
-		pr.ProtectedBranch, err = getProtectedBranchBy(db.GetEngine(ctx), pr.BaseRepo.ID, pr.BaseBranch)
+		pr.ProtectedBranch, err = getMatchingProtectedBranchRule(db.GetEngine(ctx), pr.BaseRepo.ID, pr.BaseBranch)
 	}
 	return
 }
@@ -432,6 +432,14 @@ func (pr *PullRequest) SetMerged() (bool, error) {
 		return false, fmt.Errorf("Failed to update pr[%d]: %v", pr.ID, err)
 	}
 
+	// a pending scheduled merge is obsolete now
+	if err := DeleteScheduledAutoMerge(ctx, pr.ID); err != nil {
+		return false, err
+	}
+	if err := DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+		return false, err
+	}
+
 	if err := committer.Commit(); err != nil {
 		return false, fmt.Errorf("Commit: %v", err)
 	}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PullAutoMerge represents a pull request scheduled to be merged when all checks succeed
+type PullAutoMerge struct {
+	ID          int64                 `xorm:"pk autoincr"`
+	PullID      int64                 `xorm:"UNIQUE"`
+	DoerID      int64                 `xorm:"NOT NULL"`
+	Doer        *user_model.User      `xorm:"-"`
+	MergeStyle  repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message     string                `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp    `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(PullAutoMerge))
+}
+
+// ErrAlreadyScheduledToAutoMerge represents an error that the pull request is already scheduled to be merged automatically
+type ErrAlreadyScheduledToAutoMerge struct {
+	PullID int64
+}
+
+// IsErrAlreadyScheduledToAutoMerge checks if an error is an ErrAlreadyScheduledToAutoMerge.
+func IsErrAlreadyScheduledToAutoMerge(err error) bool {
+	_, ok := err.(ErrAlreadyScheduledToAutoMerge)
+	return ok
+}
+
+func (err ErrAlreadyScheduledToAutoMerge) Error() string {
+	return fmt.Sprintf("pull request is already scheduled to auto merge [pull_id: %d]", err.PullID)
+}
+
+// LoadDoer loads the user who scheduled the merge
+func (pam *PullAutoMerge) LoadDoer(ctx context.Context) (err error) {
+	if pam.Doer != nil {
+		return nil
+	}
+	pam.Doer, err = user_model.GetUserByIDCtx(ctx, pam.DoerID)
+	return err
+}
+
+// ScheduleAutoMerge schedules a pull request to be merged when all checks succeed
+func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, pullID int64, style repo_model.MergeStyle, message string) error {
+	e := db.GetEngine(ctx)
+
+	exist, err := e.Exist(&PullAutoMerge{PullID: pullID})
+	if err != nil {
+		return err
+	} else if exist {
+		return ErrAlreadyScheduledToAutoMerge{PullID: pullID}
+	}
+
+	_, err = e.Insert(&PullAutoMerge{
+		DoerID:     doer.ID,
+		PullID:     pullID,
+		MergeStyle: style,
+		Message:    message,
+	})
+	return err
+}
+
+// GetScheduledAutoMergeByPullID gets the scheduled merge of a pull request if there is one
+func GetScheduledAutoMergeByPullID(ctx context.Context, pullID int64) (bool, *PullAutoMerge, error) {
+	pam := &PullAutoMerge{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(pam)
+	if err != nil || !has {
+		return false, nil, err
+	}
+	return true, pam, nil
+}
+
+// GetScheduledAutoMergePullIDsByBaseRepo gets the ids of all unmerged pull requests of the base repository which are scheduled to auto merge
+func GetScheduledAutoMergePullIDsByBaseRepo(ctx context.Context, repoID int64) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	return ids, db.GetEngine(ctx).
+		Table("pull_auto_merge").
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Where("pull_request.base_repo_id = ? AND pull_request.has_merged = ?", repoID, false).
+		Cols("pull_auto_merge.pull_id").
+		Find(&ids)
+}
+
+// DeleteScheduledAutoMerge deletes the scheduled merge of a pull request
+func DeleteScheduledAutoMerge(ctx context.Context, pullID int64) error {
+	_, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Delete(&PullAutoMerge{})
+	return err
+}
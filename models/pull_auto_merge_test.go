@@ -0,0 +1,49 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	pr := unittest.AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	exist, _, err := GetScheduledAutoMergeByPullID(db.DefaultContext, pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	assert.NoError(t, ScheduleAutoMerge(db.DefaultContext, doer, pr.ID, repo_model.MergeStyleSquash, "squash message"))
+
+	err = ScheduleAutoMerge(db.DefaultContext, doer, pr.ID, repo_model.MergeStyleMerge, "")
+	assert.True(t, IsErrAlreadyScheduledToAutoMerge(err))
+
+	exist, scheduled, err := GetScheduledAutoMergeByPullID(db.DefaultContext, pr.ID)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, doer.ID, scheduled.DoerID)
+	assert.Equal(t, repo_model.MergeStyleSquash, scheduled.MergeStyle)
+	assert.Equal(t, "squash message", scheduled.Message)
+
+	assert.NoError(t, scheduled.LoadDoer(db.DefaultContext))
+	assert.Equal(t, doer.ID, scheduled.Doer.ID)
+
+	ids, err := GetScheduledAutoMergePullIDsByBaseRepo(db.DefaultContext, pr.BaseRepoID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pr.ID}, ids)
+
+	assert.NoError(t, DeleteScheduledAutoMerge(db.DefaultContext, pr.ID))
+	unittest.AssertNotExistsBean(t, &PullAutoMerge{PullID: pr.ID})
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch
+type MergeQueueEntry struct {
+	ID         int64                 `xorm:"pk autoincr"`
+	RepoID     int64                 `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch string                `xorm:"INDEX(s) NOT NULL"`
+	PullID     int64                 `xorm:"UNIQUE NOT NULL"`
+	DoerID     int64                 `xorm:"NOT NULL"`
+	Doer       *user_model.User      `xorm:"-"`
+	MergeStyle repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message    string                `xorm:"LONGTEXT"`
+	// ParentCommitID is the commit the tested merge commit was built on
+	ParentCommitID string `xorm:"VARCHAR(40)"`
+	// HeadCommitID is the head commit of the pull request the tested merge commit was built from
+	HeadCommitID string `xorm:"VARCHAR(40)"`
+	// CommitID is the merge commit whose status checks decide about the merge
+	CommitID    string             `xorm:"VARCHAR(40)"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(MergeQueueEntry))
+}
+
+// ErrAlreadyInMergeQueue represents an error that the pull request is already in the merge queue
+type ErrAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrAlreadyInMergeQueue checks if an error is an ErrAlreadyInMergeQueue.
+func IsErrAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrAlreadyInMergeQueue)
+	return ok
+}
+
+func (err ErrAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// LoadDoer loads the user who added the pull request to the queue
+func (e *MergeQueueEntry) LoadDoer(ctx context.Context) (err error) {
+	if e.Doer != nil {
+		return nil
+	}
+	e.Doer, err = user_model.GetUserByIDCtx(ctx, e.DoerID)
+	return err
+}
+
+// AddToMergeQueue appends a pull request to the merge queue of its base branch
+func AddToMergeQueue(ctx context.Context, doer *user_model.User, pr *PullRequest, style repo_model.MergeStyle, message string) error {
+	e := db.GetEngine(ctx)
+
+	exist, err := e.Exist(&MergeQueueEntry{PullID: pr.ID})
+	if err != nil {
+		return err
+	} else if exist {
+		return ErrAlreadyInMergeQueue{PullID: pr.ID}
+	}
+
+	_, err = e.Insert(&MergeQueueEntry{
+		RepoID:     pr.BaseRepoID,
+		BaseBranch: pr.BaseBranch,
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	})
+	return err
+}
+
+// GetMergeQueueEntryByPullID gets the merge queue entry of a pull request if there is one
+func GetMergeQueueEntryByPullID(ctx context.Context, pullID int64) (bool, *MergeQueueEntry, error) {
+	entry := &MergeQueueEntry{}
+	has, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(entry)
+	if err != nil || !has {
+		return false, nil, err
+	}
+	return true, entry, nil
+}
+
+// GetMergeQueueEntries returns the entries of the merge queue of a branch in queue order
+func GetMergeQueueEntries(ctx context.Context, repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueueBranches returns the branches of the repository which have a non empty merge queue
+func GetMergeQueueBranches(ctx context.Context, repoID int64) ([]string, error) {
+	branches := make([]string, 0, 2)
+	return branches, db.GetEngine(ctx).
+		Table("merge_queue_entry").
+		Where("repo_id = ?", repoID).
+		Distinct("base_branch").
+		Find(&branches)
+}
+
+// UpdateMergeQueueEntryCommit stores the merge commit which is tested for the entry
+func UpdateMergeQueueEntryCommit(ctx context.Context, entry *MergeQueueEntry) error {
+	_, err := db.GetEngine(ctx).ID(entry.ID).Cols("parent_commit_id", "head_commit_id", "commit_id").Update(entry)
+	return err
+}
+
+// DeleteMergeQueueEntry removes a pull request from the merge queue
+func DeleteMergeQueueEntry(ctx context.Context, pullID int64) error {
+	_, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Delete(&MergeQueueEntry{})
+	return err
+}
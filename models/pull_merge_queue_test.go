@@ -0,0 +1,64 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	pr2 := unittest.AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	pr5 := unittest.AssertExistsAndLoadBean(t, &PullRequest{ID: 5}).(*PullRequest)
+
+	exist, _, err := GetMergeQueueEntryByPullID(db.DefaultContext, pr2.ID)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	assert.NoError(t, AddToMergeQueue(db.DefaultContext, doer, pr2, repo_model.MergeStyleSquash, "squash message"))
+	assert.NoError(t, AddToMergeQueue(db.DefaultContext, doer, pr5, repo_model.MergeStyleMerge, ""))
+
+	err = AddToMergeQueue(db.DefaultContext, doer, pr2, repo_model.MergeStyleMerge, "")
+	assert.True(t, IsErrAlreadyInMergeQueue(err))
+
+	exist, entry, err := GetMergeQueueEntryByPullID(db.DefaultContext, pr2.ID)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "master", entry.BaseBranch)
+	assert.Equal(t, repo_model.MergeStyleSquash, entry.MergeStyle)
+	assert.Equal(t, "squash message", entry.Message)
+	assert.Empty(t, entry.CommitID)
+
+	assert.NoError(t, entry.LoadDoer(db.DefaultContext))
+	assert.Equal(t, doer.ID, entry.Doer.ID)
+
+	entry.ParentCommitID = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	entry.HeadCommitID = "1032bbf17fbc0d9c95bb5418dabe8f8c99278700"
+	entry.CommitID = "985f0301dba5e7b34be866819cd15ad3d8f508ee"
+	assert.NoError(t, UpdateMergeQueueEntryCommit(db.DefaultContext, entry))
+	unittest.AssertExistsAndLoadBean(t, &MergeQueueEntry{PullID: pr2.ID, HeadCommitID: entry.HeadCommitID, CommitID: entry.CommitID})
+
+	branches, err := GetMergeQueueBranches(db.DefaultContext, pr2.BaseRepoID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"master", "branch1"}, branches)
+
+	entries, err := GetMergeQueueEntries(db.DefaultContext, pr2.BaseRepoID, "master")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, pr2.ID, entries[0].PullID)
+	}
+
+	assert.NoError(t, DeleteMergeQueueEntry(db.DefaultContext, pr2.ID))
+	unittest.AssertNotExistsBean(t, &MergeQueueEntry{PullID: pr2.ID})
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Usage is the storage used by the repositories and packages of an owner
+type Usage struct {
+	OwnerID     int64
+	Git         int64
+	LFS         int64
+	Attachments int64
+	Packages    int64
+}
+
+// Total returns the total storage of the usage
+func (u *Usage) Total() int64 {
+	return u.Git + u.LFS + u.Attachments + u.Packages
+}
+
+type ownerSize struct {
+	OwnerID int64
+	Size    int64
+}
+
+// sumByOwner adds the sizes of the query to the usages
+func sumByOwner(ctx context.Context, usages map[int64]*Usage, ownerID int64, table, joins, column string, add func(*Usage, int64)) error {
+	sess := db.GetEngine(ctx).Table(table).
+		Select("repository.owner_id AS owner_id, SUM(" + column + ") AS size").
+		GroupBy("repository.owner_id")
+	if joins != "" {
+		sess = sess.Join("INNER", "repository", joins)
+	}
+	if ownerID > 0 {
+		sess = sess.Where("repository.owner_id = ?", ownerID)
+	}
+
+	sizes := make([]*ownerSize, 0, 10)
+	if err := sess.Find(&sizes); err != nil {
+		return err
+	}
+	for _, s := range sizes {
+		usage, ok := usages[s.OwnerID]
+		if !ok {
+			usage = &Usage{OwnerID: s.OwnerID}
+			usages[s.OwnerID] = usage
+		}
+		add(usage, s.Size)
+	}
+	return nil
+}
+
+func getUsages(ctx context.Context, ownerID int64) (map[int64]*Usage, error) {
+	usages := make(map[int64]*Usage)
+
+	// the size of a repository includes its LFS objects, they are subtracted afterwards
+	if err := sumByOwner(ctx, usages, ownerID, "repository", "", "repository.size", func(u *Usage, size int64) {
+		u.Git += size
+	}); err != nil {
+		return nil, fmt.Errorf("sum repository sizes: %v", err)
+	}
+	if err := sumByOwner(ctx, usages, ownerID, "lfs_meta_object", "repository.id = lfs_meta_object.repository_id", "lfs_meta_object.size", func(u *Usage, size int64) {
+		u.LFS += size
+	}); err != nil {
+		return nil, fmt.Errorf("sum lfs object sizes: %v", err)
+	}
+	if err := sumByOwner(ctx, usages, ownerID, "attachment", "repository.id = attachment.repo_id", "attachment.size", func(u *Usage, size int64) {
+		u.Attachments += size
+	}); err != nil {
+		return nil, fmt.Errorf("sum attachment sizes: %v", err)
+	}
+
+	sess := db.GetEngine(ctx).Table("package_file").
+		Select("package.owner_id AS owner_id, SUM(package_blob.size) AS size").
+		Join("INNER", "package_blob", "package_blob.id = package_file.blob_id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		GroupBy("package.owner_id")
+	if ownerID > 0 {
+		sess = sess.Where("package.owner_id = ?", ownerID)
+	}
+	sizes := make([]*ownerSize, 0, 10)
+	if err := sess.Find(&sizes); err != nil {
+		return nil, fmt.Errorf("sum package sizes: %v", err)
+	}
+	for _, s := range sizes {
+		usage, ok := usages[s.OwnerID]
+		if !ok {
+			usage = &Usage{OwnerID: s.OwnerID}
+			usages[s.OwnerID] = usage
+		}
+		usage.Packages += s.Size
+	}
+
+	for _, usage := range usages {
+		usage.Git -= usage.LFS
+		if usage.Git < 0 {
+			usage.Git = 0
+		}
+	}
+	return usages, nil
+}
+
+// GetUsage returns the storage used by the owner
+func GetUsage(ctx context.Context, ownerID int64) (*Usage, error) {
+	usages, err := getUsages(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if usage, ok := usages[ownerID]; ok {
+		return usage, nil
+	}
+	return &Usage{OwnerID: ownerID}, nil
+}
+
+// GetAllUsages returns the storage used by all owners which use any, sorted by the total size
+func GetAllUsages(ctx context.Context) ([]*Usage, error) {
+	usages, err := getUsages(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Usage, 0, len(usages))
+	for _, usage := range usages {
+		if usage.Total() > 0 {
+			list = append(list, usage)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total() != list[j].Total() {
+			return list[i].Total() > list[j].Total()
+		}
+		return list[i].OwnerID < list[j].OwnerID
+	})
+	return list, nil
+}
+
+// ErrQuotaExceeded represents a "QuotaExceeded" kind of error.
+type ErrQuotaExceeded struct {
+	OwnerName string
+	Limit     int64
+	Used      int64
+}
+
+// IsErrQuotaExceeded checks if an error is a ErrQuotaExceeded.
+func IsErrQuotaExceeded(err error) bool {
+	_, ok := err.(ErrQuotaExceeded)
+	return ok
+}
+
+func (err ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("the storage quota of %s is exceeded [used: %s, limit: %s]", err.OwnerName, humanize.IBytes(uint64(err.Used)), humanize.IBytes(uint64(err.Limit)))
+}
+
+// CheckQuota returns ErrQuotaExceeded if storing additional bytes would exceed the quota of the owner.
+// If the additional size is unknown (0) the owner must not have reached the limit yet.
+func CheckQuota(ctx context.Context, owner *user_model.User, additional int64) error {
+	if !setting.Quota.Enabled || owner == nil {
+		return nil
+	}
+
+	limit := owner.MaxQuotaSize()
+	if limit < 0 {
+		return nil
+	}
+
+	usage, err := GetUsage(ctx, owner.ID)
+	if err != nil {
+		return err
+	}
+	used := usage.Total()
+	if additional > 0 {
+		used += additional
+	}
+	if used > limit || (additional <= 0 && used >= limit) {
+		return ErrQuotaExceeded{OwnerName: owner.Name, Limit: limit, Used: used}
+	}
+	return nil
+}
+
+// CheckQuotaByOwnerID is like CheckQuota but loads the owner first
+func CheckQuotaByOwnerID(ctx context.Context, ownerID, additional int64) error {
+	if !setting.Quota.Enabled {
+		return nil
+	}
+	owner, err := user_model.GetUserByIDCtx(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	return CheckQuota(ctx, owner, additional)
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUsage(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// repo1 of user2 contains 100 bytes of git data and 50 bytes of LFS objects
+	_, err := db.GetEngine(db.DefaultContext).Exec("UPDATE repository SET size = ? WHERE id = ?", 150, 1)
+	assert.NoError(t, err)
+	_, err = db.GetEngine(db.DefaultContext).Exec("INSERT INTO lfs_meta_object (oid, size, repository_id) VALUES (?, ?, ?)", "oid", 50, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.Attachment{UUID: "quota-test", RepoID: 1, Size: 25}))
+
+	usage, err := GetUsage(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 100, usage.Git)
+	assert.EqualValues(t, 50, usage.LFS)
+	assert.EqualValues(t, 25, usage.Attachments)
+	assert.EqualValues(t, 0, usage.Packages)
+	assert.EqualValues(t, 175, usage.Total())
+
+	usage, err = GetUsage(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, usage.Total())
+
+	usages, err := GetAllUsages(db.DefaultContext)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, usages) {
+		assert.EqualValues(t, 2, usages[0].OwnerID)
+	}
+
+	defer func(enabled bool, size int64) {
+		setting.Quota.Enabled = enabled
+		setting.Quota.DefaultTotalSize = size
+	}(setting.Quota.Enabled, setting.Quota.DefaultTotalSize)
+	setting.Quota.Enabled = true
+	setting.Quota.DefaultTotalSize = 200
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	assert.NoError(t, CheckQuota(db.DefaultContext, user, 25))
+	err = CheckQuota(db.DefaultContext, user, 26)
+	assert.True(t, IsErrQuotaExceeded(err))
+
+	user.QuotaSize = 175
+	assert.True(t, IsErrQuotaExceeded(CheckQuota(db.DefaultContext, user, 0)))
+
+	user.QuotaSize = -1
+	setting.Quota.DefaultTotalSize = -1
+	assert.NoError(t, CheckQuota(db.DefaultContext, user, 1<<40))
+}
@@ -689,6 +689,10 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err := sess.Where("pull_id IN (SELECT id FROM pull_request WHERE base_repo_id = ?)", repoID).Delete(&PullAutoMerge{}); err != nil {
+		return err
+	}
+
 	if err := db.DeleteBeans(ctx,
 		&Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
@@ -699,6 +703,7 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		&webhook.HookTask{RepoID: repoID},
 		&LFSLock{RepoID: repoID},
 		&repo_model.LanguageStat{RepoID: repoID},
+		&MergeQueueEntry{RepoID: repoID},
 		&issues_model.Milestone{RepoID: repoID},
 		&repo_model.Mirror{RepoID: repoID},
 		&Notification{RepoID: repoID},
@@ -706,6 +711,7 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		&ProtectedTag{RepoID: repoID},
 		&PullRequest{BaseRepoID: repoID},
 		&repo_model.PushMirror{RepoID: repoID},
+		&repo_model.PushRule{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&repo_model.RepoIndexerStatus{RepoID: repoID},
 		&repo_model.Redirect{RedirectRepoID: repoID},
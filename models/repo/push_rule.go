@@ -0,0 +1,153 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+func init() {
+	db.RegisterModel(new(PushRule))
+}
+
+// PushRule represents the rules which are checked for every commit pushed to a repository.
+// A rule belongs either to a single repository or, with a RepoID of 0, to all repositories of an owner.
+type PushRule struct {
+	ID                    int64              `xorm:"pk autoincr"`
+	OwnerID               int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	RepoID                int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	MaxFileSize           int64              `xorm:"NOT NULL DEFAULT 0"`
+	ForbiddenFilePatterns string             `xorm:"TEXT"`
+	RejectSecrets         bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix           timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// IsEmpty returns true if the rule does not check anything
+func (pr *PushRule) IsEmpty() bool {
+	return pr.MaxFileSize <= 0 && len(pr.GetForbiddenFilePatterns()) == 0 && !pr.RejectSecrets
+}
+
+// GetForbiddenFilePatterns returns the forbidden file patterns, one per line
+func (pr *PushRule) GetForbiddenFilePatterns() []string {
+	var patterns []string
+	for _, line := range strings.Split(pr.ForbiddenFilePatterns, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
+// CompileForbiddenFilePatterns compiles the forbidden file patterns
+func (pr *PushRule) CompileForbiddenFilePatterns() ([]glob.Glob, error) {
+	patterns := pr.GetForbiddenFilePatterns()
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// MatchForbiddenFilePattern returns the first pattern which matches the path.
+// Patterns without a slash are matched against the file name, all others against the full path.
+func (pr *PushRule) MatchForbiddenFilePattern(globs []glob.Glob, filePath string) (string, bool) {
+	patterns := pr.GetForbiddenFilePatterns()
+	for i, g := range globs {
+		target := filePath
+		if !strings.Contains(patterns[i], "/") {
+			target = path.Base(filePath)
+		}
+		if g.Match(target) {
+			return patterns[i], true
+		}
+	}
+	return "", false
+}
+
+// Merge combines the rule with another rule, the stricter setting of both wins
+func (pr *PushRule) Merge(other *PushRule) *PushRule {
+	merged := &PushRule{
+		OwnerID:       pr.OwnerID,
+		RepoID:        pr.RepoID,
+		MaxFileSize:   pr.MaxFileSize,
+		RejectSecrets: pr.RejectSecrets || other.RejectSecrets,
+	}
+	if other.MaxFileSize > 0 && (merged.MaxFileSize <= 0 || other.MaxFileSize < merged.MaxFileSize) {
+		merged.MaxFileSize = other.MaxFileSize
+	}
+	merged.ForbiddenFilePatterns = strings.Join(append(pr.GetForbiddenFilePatterns(), other.GetForbiddenFilePatterns()...), "\n")
+	return merged
+}
+
+func getPushRule(ctx context.Context, ownerID, repoID int64) (*PushRule, error) {
+	pr := &PushRule{}
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ?", ownerID, repoID).
+		Get(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return &PushRule{OwnerID: ownerID, RepoID: repoID}, nil
+	}
+	return pr, nil
+}
+
+// GetRepoPushRule returns the push rule of the repository, an empty rule is returned if none is stored
+func GetRepoPushRule(ctx context.Context, repoID int64) (*PushRule, error) {
+	return getPushRule(ctx, 0, repoID)
+}
+
+// GetOwnerPushRule returns the push rule of the owner, an empty rule is returned if none is stored
+func GetOwnerPushRule(ctx context.Context, ownerID int64) (*PushRule, error) {
+	return getPushRule(ctx, ownerID, 0)
+}
+
+// GetEffectivePushRule returns the push rule of the repository combined with the push rule of its owner
+func GetEffectivePushRule(ctx context.Context, repo *Repository) (*PushRule, error) {
+	repoRule, err := GetRepoPushRule(ctx, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	ownerRule, err := GetOwnerPushRule(ctx, repo.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	return repoRule.Merge(ownerRule), nil
+}
+
+// SavePushRule inserts or updates a push rule. Empty rules are deleted.
+func SavePushRule(ctx context.Context, pr *PushRule) error {
+	if pr.IsEmpty() {
+		_, err := db.GetEngine(ctx).
+			Where("owner_id = ? AND repo_id = ?", pr.OwnerID, pr.RepoID).
+			Delete(&PushRule{})
+		return err
+	}
+	if pr.ID == 0 {
+		return db.Insert(ctx, pr)
+	}
+	_, err := db.GetEngine(ctx).ID(pr.ID).AllCols().Update(pr)
+	return err
+}
+
+// DeletePushRuleByOwner deletes the push rule of an owner
+func DeletePushRuleByOwner(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = 0", ownerID).Delete(&PushRule{})
+	return err
+}
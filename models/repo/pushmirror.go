@@ -6,12 +6,16 @@ package repo
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
+	gossh "golang.org/x/crypto/ssh"
 	"xorm.io/xorm"
 )
 
@@ -25,6 +29,13 @@ type PushMirror struct {
 	Repo       *Repository `xorm:"-"`
 	RemoteName string
 
+	// PublicKey and PrivateKey are only set for mirrors which push over SSH,
+	// the private key is stored encrypted with the secret key.
+	PublicKey  string `xorm:"VARCHAR(100)"`
+	PrivateKey string `xorm:"TEXT"`
+	// HostKey is the verified key of the SSH server in the authorized_keys format
+	HostKey string `xorm:"TEXT"`
+
 	Interval       time.Duration
 	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
 	LastUpdateUnix timeutil.TimeStamp `xorm:"INDEX last_update"`
@@ -58,6 +69,48 @@ func (m *PushMirror) GetRemoteName() string {
 	return m.RemoteName
 }
 
+// UseSSH returns true if the mirror authenticates with its own SSH keypair.
+func (m *PushMirror) UseSSH() bool {
+	return m.PublicKey != ""
+}
+
+// SetPrivateKey encrypts and sets the private key of the SSH keypair.
+func (m *PushMirror) SetPrivateKey(privateKey []byte) error {
+	encrypted, err := secret.EncryptSecret(setting.SecretKey, string(privateKey))
+	if err != nil {
+		return err
+	}
+	m.PrivateKey = encrypted
+	return nil
+}
+
+// GetPrivateKey returns the decrypted private key of the SSH keypair.
+func (m *PushMirror) GetPrivateKey() ([]byte, error) {
+	privateKey, err := secret.DecryptSecret(setting.SecretKey, m.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(privateKey), nil
+}
+
+// SetHostKey sets the host key of the SSH server
+func (m *PushMirror) SetHostKey(key gossh.PublicKey) {
+	m.HostKey = strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
+}
+
+// HostKeyFingerprint returns the SHA256 fingerprint of the host key of the SSH server
+func (m *PushMirror) HostKeyFingerprint() string {
+	if m.HostKey == "" {
+		return ""
+	}
+	key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(m.HostKey))
+	if err != nil {
+		log.Error("Invalid host key of push mirror[%d]: %v", m.ID, err)
+		return ""
+	}
+	return gossh.FingerprintSHA256(key)
+}
+
 // InsertPushMirror inserts a push-mirror to database
 func InsertPushMirror(m *PushMirror) error {
 	_, err := db.GetEngine(db.DefaultContext).Insert(m)
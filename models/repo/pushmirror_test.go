@@ -5,6 +5,9 @@
 package repo
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func TestPushMirrorsIterate(t *testing.T) {
@@ -48,3 +52,33 @@ func TestPushMirrorsIterate(t *testing.T) {
 		return nil
 	})
 }
+
+func TestPushMirrorPrivateKey(t *testing.T) {
+	m := &PushMirror{}
+	assert.False(t, m.UseSSH())
+
+	m.PublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGq1 push-mirror"
+	assert.True(t, m.UseSSH())
+
+	assert.NoError(t, m.SetPrivateKey([]byte("private key")))
+	assert.NotContains(t, m.PrivateKey, "private key")
+
+	privateKey, err := m.GetPrivateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "private key", string(privateKey))
+}
+
+func TestPushMirrorHostKey(t *testing.T) {
+	m := &PushMirror{}
+	assert.Empty(t, m.HostKeyFingerprint())
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	key, err := gossh.NewPublicKey(publicKey)
+	assert.NoError(t, err)
+
+	m.SetHostKey(key)
+	assert.True(t, strings.HasPrefix(m.HostKey, "ssh-ed25519 AAAA"))
+	assert.NotContains(t, m.HostKey, "\n")
+	assert.Equal(t, gossh.FingerprintSHA256(key), m.HostKeyFingerprint())
+}
@@ -137,6 +137,11 @@ func getCollaboration(e db.Engine, repoID, uid int64) (*Collaboration, error) {
 	return collaboration, err
 }
 
+// GetCollaboration returns the collaboration of the user on the repository or nil if the user is no collaborator
+func GetCollaboration(repoID, uid int64) (*Collaboration, error) {
+	return getCollaboration(db.GetEngine(db.DefaultContext), repoID, uid)
+}
+
 func isCollaborator(e db.Engine, repoID, userID int64) (bool, error) {
 	return e.Get(&Collaboration{RepoID: repoID, UserID: userID})
 }
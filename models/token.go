@@ -6,12 +6,17 @@
 package models
 
 import (
+	"context"
 	"crypto/subtle"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -25,16 +30,21 @@ var successfulAccessTokenCache *lru.Cache
 
 // AccessToken represents a personal access token.
 type AccessToken struct {
-	ID             int64 `xorm:"pk autoincr"`
-	UID            int64 `xorm:"INDEX"`
-	Name           string
-	Token          string `xorm:"-"`
-	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
-	TokenSalt      string
-	TokenLastEight string `xorm:"token_last_eight"`
+	ID              int64 `xorm:"pk autoincr"`
+	UID             int64 `xorm:"INDEX"`
+	Name            string
+	Token           string `xorm:"-"`
+	TokenHash       string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt       string
+	TokenLastEight  string           `xorm:"token_last_eight"`
+	Scope           AccessTokenScope `xorm:"NOT NULL DEFAULT ''"`
+	AllowedOwnerIDs []int64          `xorm:"TEXT JSON"` // the token can access all repositories and packages of these owners
+	AllowedRepoIDs  []int64          `xorm:"TEXT JSON"` // the token can access these repositories, no restriction if both lists are empty
+	AllowedRepos    []string         `xorm:"-"`         // "owner/repo" or "owner", loaded by LoadAllowedRepos
 
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
+	ExpiresUnix       timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"` // 0 means the token never expires
 	HasRecentActivity bool               `xorm:"-"`
 	HasUsed           bool               `xorm:"-"`
 }
@@ -45,6 +55,56 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsExpired returns true if the token can't be used anymore
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// IsRestricted returns true if the token can only access some repositories
+func (t *AccessToken) IsRestricted() bool {
+	return len(t.AllowedOwnerIDs) > 0 || len(t.AllowedRepoIDs) > 0
+}
+
+// AllowsOwner returns true if the token can access all repositories and packages of the owner
+func (t *AccessToken) AllowsOwner(ownerID int64) bool {
+	return !t.IsRestricted() || util.IsInt64InSlice(ownerID, t.AllowedOwnerIDs)
+}
+
+// AllowsRepo returns true if the token can access the repository
+func (t *AccessToken) AllowsRepo(repo *repo_model.Repository) bool {
+	return t.AllowsOwner(repo.OwnerID) || util.IsInt64InSlice(repo.ID, t.AllowedRepoIDs)
+}
+
+// LoadAllowedRepos loads the names of the owners and repositories the token is restricted to.
+// Owners and repositories which were deleted since the token was created are left out.
+func (t *AccessToken) LoadAllowedRepos(ctx context.Context) error {
+	if !t.IsRestricted() || t.AllowedRepos != nil {
+		return nil
+	}
+	t.AllowedRepos = make([]string, 0, len(t.AllowedOwnerIDs)+len(t.AllowedRepoIDs))
+	for _, id := range t.AllowedOwnerIDs {
+		owner, err := user_model.GetUserByIDCtx(ctx, id)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return err
+		}
+		t.AllowedRepos = append(t.AllowedRepos, owner.Name)
+	}
+	for _, id := range t.AllowedRepoIDs {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, id)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				continue
+			}
+			return err
+		}
+		t.AllowedRepos = append(t.AllowedRepos, repo.FullName())
+	}
+	return nil
+}
+
 func init() {
 	db.RegisterModel(new(AccessToken), func() error {
 		if setting.SuccessfulTokensCacheSize > 0 {
@@ -60,6 +120,49 @@ func init() {
 	})
 }
 
+var allowedRepoPattern = regexp.MustCompile(`^[-.\w]+(/[-.\w]+)?$`)
+
+// SetAllowedRepos restricts the token to the repositories ("owner/repo") and owners ("owner").
+// The names are resolved to IDs, so the restriction doesn't move to another owner or repository
+// which later takes over a name.
+func (t *AccessToken) SetAllowedRepos(ctx context.Context, repos []string) error {
+	t.AllowedOwnerIDs, t.AllowedRepoIDs, t.AllowedRepos = nil, nil, nil
+	for _, name := range repos {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !allowedRepoPattern.MatchString(name) {
+			return ErrAccessTokenInvalidAllowedRepo{Repo: name}
+		}
+
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) == 1 {
+			owner, err := user_model.GetUserByNameCtx(ctx, name)
+			if err != nil {
+				if user_model.IsErrUserNotExist(err) {
+					return ErrAccessTokenInvalidAllowedRepo{Repo: name}
+				}
+				return err
+			}
+			t.AllowedOwnerIDs = append(t.AllowedOwnerIDs, owner.ID)
+			t.AllowedRepos = append(t.AllowedRepos, owner.Name)
+			continue
+		}
+
+		repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, parts[0], parts[1])
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				return ErrAccessTokenInvalidAllowedRepo{Repo: name}
+			}
+			return err
+		}
+		t.AllowedRepoIDs = append(t.AllowedRepoIDs, repo.ID)
+		t.AllowedRepos = append(t.AllowedRepos, repo.FullName())
+	}
+	return nil
+}
+
 // NewAccessToken creates new access token.
 func NewAccessToken(t *AccessToken) error {
 	salt, err := util.CryptoRandomString(10)
@@ -141,6 +244,18 @@ func GetAccessTokenBySHA(token string) (*AccessToken, error) {
 	return nil, ErrAccessTokenNotExist{token}
 }
 
+// GetAccessTokenByID returns access token by given id
+func GetAccessTokenByID(id int64) (*AccessToken, error) {
+	t := &AccessToken{}
+	has, err := db.GetEngine(db.DefaultContext).ID(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAccessTokenNotExist{}
+	}
+	return t, nil
+}
+
 // AccessTokenByNameExists checks if a token name has been used already by a user.
 func AccessTokenByNameExists(token *AccessToken) (bool, error) {
 	return db.GetEngine(db.DefaultContext).Table("access_token").Where("name = ?", token.Name).And("uid = ?", token.UID).Exist()
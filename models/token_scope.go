@@ -0,0 +1,113 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// AccessTokenScope is a comma separated list of the permissions granted to an access token
+type AccessTokenScope string
+
+// The scopes of access tokens
+const (
+	AccessTokenScopeAll          AccessTokenScope = "all"
+	AccessTokenScopeRepoRead     AccessTokenScope = "repo:read"
+	AccessTokenScopeRepoWrite    AccessTokenScope = "repo:write"
+	AccessTokenScopeIssue        AccessTokenScope = "issue"
+	AccessTokenScopePackageRead  AccessTokenScope = "package:read"
+	AccessTokenScopePackageWrite AccessTokenScope = "package:write"
+	AccessTokenScopeOrg          AccessTokenScope = "org"
+	AccessTokenScopeUser         AccessTokenScope = "user"
+	AccessTokenScopeAdmin        AccessTokenScope = "admin"
+)
+
+// AllAccessTokenScopes contains the scopes in the order they are displayed
+var AllAccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeAll,
+	AccessTokenScopeRepoRead,
+	AccessTokenScopeRepoWrite,
+	AccessTokenScopeIssue,
+	AccessTokenScopePackageRead,
+	AccessTokenScopePackageWrite,
+	AccessTokenScopeOrg,
+	AccessTokenScopeUser,
+	AccessTokenScopeAdmin,
+}
+
+// impliedAccessTokenScopes contains the scopes which are granted by another scope
+var impliedAccessTokenScopes = map[AccessTokenScope][]AccessTokenScope{
+	AccessTokenScopeRepoWrite:    {AccessTokenScopeRepoRead, AccessTokenScopeIssue},
+	AccessTokenScopePackageWrite: {AccessTokenScopePackageRead},
+}
+
+// ParseAccessTokenScope validates and normalizes a comma separated list of scopes.
+// An empty list grants all permissions.
+func ParseAccessTokenScope(s string) (AccessTokenScope, error) {
+	return ParseAccessTokenScopes(strings.Split(s, ","))
+}
+
+// ParseAccessTokenScopes validates and normalizes a list of scopes. An empty list grants all permissions.
+func ParseAccessTokenScopes(scopes []string) (AccessTokenScope, error) {
+	set := make(map[AccessTokenScope]bool, len(scopes))
+	for _, s := range scopes {
+		scope := AccessTokenScope(strings.ToLower(strings.TrimSpace(s)))
+		if scope == "" {
+			continue
+		}
+		if !scope.isKnown() {
+			return "", ErrAccessTokenInvalidScope{Scope: s}
+		}
+		set[scope] = true
+	}
+	if len(set) == 0 || set[AccessTokenScopeAll] {
+		return AccessTokenScopeAll, nil
+	}
+
+	list := make([]string, 0, len(set))
+	for scope := range set {
+		list = append(list, string(scope))
+	}
+	sort.Strings(list)
+	return AccessTokenScope(strings.Join(list, ",")), nil
+}
+
+func (s AccessTokenScope) isKnown() bool {
+	for _, scope := range AllAccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the single scopes of the list
+func (s AccessTokenScope) Scopes() []AccessTokenScope {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(string(s), ",")
+	scopes := make([]AccessTokenScope, 0, len(parts))
+	for _, part := range parts {
+		scopes = append(scopes, AccessTokenScope(part))
+	}
+	return scopes
+}
+
+// HasScope returns true if the list grants the scope directly or through a broader scope
+func (s AccessTokenScope) HasScope(scope AccessTokenScope) bool {
+	for _, granted := range s.Scopes() {
+		if granted == AccessTokenScopeAll || granted == scope {
+			return true
+		}
+		for _, implied := range impliedAccessTokenScopes[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessTokenScope(t *testing.T) {
+	cases := map[string]AccessTokenScope{
+		"":                           AccessTokenScopeAll,
+		"all":                        AccessTokenScopeAll,
+		"repo:read,all":              AccessTokenScopeAll,
+		"package:write":              AccessTokenScopePackageWrite,
+		" Repo:Write , issue,issue ": "issue,repo:write",
+	}
+	for s, expected := range cases {
+		scope, err := ParseAccessTokenScope(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, scope, s)
+	}
+
+	_, err := ParseAccessTokenScope("repo:delete")
+	assert.True(t, IsErrAccessTokenInvalidScope(err))
+}
+
+func TestAccessTokenScope_HasScope(t *testing.T) {
+	assert.True(t, AccessTokenScopeAll.HasScope(AccessTokenScopeAdmin))
+
+	scope := AccessTokenScope("package:write,repo:write")
+	assert.True(t, scope.HasScope(AccessTokenScopePackageRead))
+	assert.True(t, scope.HasScope(AccessTokenScopeRepoRead))
+	assert.True(t, scope.HasScope(AccessTokenScopeIssue))
+	assert.False(t, scope.HasScope(AccessTokenScopeAdmin))
+	assert.False(t, scope.HasScope(AccessTokenScopeUser))
+
+	assert.False(t, AccessTokenScopeRepoRead.HasScope(AccessTokenScopeRepoWrite))
+}
+
+func TestAccessToken_AllowsRepo(t *testing.T) {
+	repo1 := &repo_model.Repository{ID: 1, OwnerID: 2}
+	repo2 := &repo_model.Repository{ID: 2, OwnerID: 2}
+	repo3 := &repo_model.Repository{ID: 3, OwnerID: 3}
+
+	token := &AccessToken{}
+	assert.True(t, token.AllowsRepo(repo1))
+
+	token.AllowedOwnerIDs = []int64{3}
+	token.AllowedRepoIDs = []int64{1}
+	assert.True(t, token.AllowsRepo(repo1))
+	assert.False(t, token.AllowsRepo(repo2))
+	assert.True(t, token.AllowsRepo(repo3))
+	assert.True(t, token.AllowsOwner(3))
+	assert.False(t, token.AllowsOwner(2))
+}
+
+func TestAccessToken_SetAllowedRepos(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &AccessToken{}
+	assert.NoError(t, token.SetAllowedRepos(db.DefaultContext, []string{" user3", "User2/Repo1", ""}))
+	assert.Equal(t, []int64{3}, token.AllowedOwnerIDs)
+	assert.Equal(t, []int64{1}, token.AllowedRepoIDs)
+	assert.Equal(t, []string{"user3", "user2/repo1"}, token.AllowedRepos)
+
+	err := token.SetAllowedRepos(db.DefaultContext, []string{"user2/repo1/extra"})
+	assert.True(t, IsErrAccessTokenInvalidAllowedRepo(err))
+	err = token.SetAllowedRepos(db.DefaultContext, []string{"user2/does-not-exist"})
+	assert.True(t, IsErrAccessTokenInvalidAllowedRepo(err))
+	err = token.SetAllowedRepos(db.DefaultContext, []string{"does-not-exist"})
+	assert.True(t, IsErrAccessTokenInvalidAllowedRepo(err))
+
+	// the names are loaded from the IDs
+	token = &AccessToken{AllowedOwnerIDs: []int64{3}, AllowedRepoIDs: []int64{1, 99999}}
+	assert.NoError(t, token.LoadAllowedRepos(db.DefaultContext))
+	assert.Equal(t, []string{"user3", "user2/repo1"}, token.AllowedRepos)
+}
@@ -108,6 +108,8 @@ type User struct {
 	LastRepoVisibility bool
 	// Maximum repository creation limit, -1 means use global default
 	MaxRepoCreation int `xorm:"NOT NULL DEFAULT -1"`
+	// Maximum storage in bytes used by the repositories and packages of the user, -1 uses the instance default
+	QuotaSize int64 `xorm:"NOT NULL DEFAULT -1"`
 
 	// IsActive true: primary email is activated, user can access Web UI and Git SSH.
 	// false: an inactive user can only log in Web UI for account operations (ex: activate the account by email), no other access.
@@ -177,6 +179,10 @@ func (u *User) BeforeUpdate() {
 		u.MaxRepoCreation = -1
 	}
 
+	if u.QuotaSize < -1 {
+		u.QuotaSize = -1
+	}
+
 	// Organization does not need email
 	u.Email = strings.ToLower(u.Email)
 	if !u.IsOrganization() {
@@ -248,6 +254,14 @@ func (u *User) MaxCreationLimit() int {
 	return u.MaxRepoCreation
 }
 
+// MaxQuotaSize returns the storage limit of the user in bytes, -1 means unlimited
+func (u *User) MaxQuotaSize() int64 {
+	if u.QuotaSize <= -1 {
+		return setting.Quota.DefaultTotalSize
+	}
+	return u.QuotaSize
+}
+
 // CanCreateRepo returns if user login can create a repository
 // NOTE: functions calling this assume a failure due to repository count limit; if new checks are added, those functions should be revised
 func (u *User) CanCreateRepo() bool {
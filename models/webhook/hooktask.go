@@ -50,6 +50,11 @@ const (
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
 	HookEventPackage                   HookEventType = "package"
+	HookEventStatus                    HookEventType = "status"
+	HookEventWiki                      HookEventType = "wiki"
+	HookEventStar                      HookEventType = "star"
+	HookEventMember                    HookEventType = "member"
+	HookEventTeam                      HookEventType = "team"
 )
 
 // Event returns the HookEventType as an event string
@@ -80,6 +85,16 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventStatus:
+		return "status"
+	case HookEventWiki:
+		return "wiki"
+	case HookEventStar:
+		return "star"
+	case HookEventMember:
+		return "member"
+	case HookEventTeam:
+		return "team"
 	}
 	return ""
 }
@@ -135,6 +135,11 @@ type HookEvents struct {
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
 	Package              bool `json:"package"`
+	Status               bool `json:"status"`
+	Wiki                 bool `json:"wiki"`
+	Star                 bool `json:"star"`
+	Member               bool `json:"member"`
+	Team                 bool `json:"team"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -346,6 +351,36 @@ func (w *Webhook) HasPackageEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Package)
 }
 
+// HasStatusEvent returns if hook enabled commit status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
+// HasWikiEvent returns if hook enabled wiki event.
+func (w *Webhook) HasWikiEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Wiki)
+}
+
+// HasStarEvent returns if hook enabled star event.
+func (w *Webhook) HasStarEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Star)
+}
+
+// HasMemberEvent returns if hook enabled organization member event.
+func (w *Webhook) HasMemberEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Member)
+}
+
+// HasTeamEvent returns if hook enabled team event.
+func (w *Webhook) HasTeamEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Team)
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
@@ -376,6 +411,11 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
 		{w.HasPackageEvent, HookEventPackage},
+		{w.HasStatusEvent, HookEventStatus},
+		{w.HasWikiEvent, HookEventWiki},
+		{w.HasStarEvent, HookEventStar},
+		{w.HasMemberEvent, HookEventMember},
+		{w.HasTeamEvent, HookEventTeam},
 	}
 }
 
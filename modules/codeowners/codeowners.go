@@ -0,0 +1,129 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Paths contains the locations which are searched for a CODEOWNERS file, in order of precedence
+var Paths = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+
+// Rule represents a single line of a CODEOWNERS file
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	regexp *regexp.Regexp
+}
+
+// File represents a parsed CODEOWNERS file
+type File struct {
+	Rules []*Rule
+}
+
+// Parse reads a CODEOWNERS file. Lines which can not be parsed are returned as warnings.
+func Parse(r io.Reader) (*File, []string, error) {
+	f := &File{}
+	var warnings []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		re, err := compilePattern(fields[0])
+		if err != nil {
+			warnings = append(warnings, "invalid pattern "+fields[0]+": "+err.Error())
+			continue
+		}
+
+		rule := &Rule{
+			Pattern: fields[0],
+			Owners:  make([]string, 0, len(fields)-1),
+			regexp:  re,
+		}
+		for _, owner := range fields[1:] {
+			if !strings.Contains(owner, "@") {
+				warnings = append(warnings, "invalid owner "+owner)
+				continue
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return f, warnings, nil
+}
+
+// Match returns the rule which applies to the path. Like gitignore the last matching rule wins.
+// A rule without owners can be used to unassign a path. nil is returned if no rule matches.
+func (f *File) Match(path string) *Rule {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].regexp.MatchString(path) {
+			return f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Owners returns the owners of the path
+func (f *File) Owners(path string) []string {
+	if rule := f.Match(path); rule != nil {
+		return rule.Owners
+	}
+	return nil
+}
+
+// compilePattern converts a gitignore style pattern into a regular expression.
+// Patterns without an inner slash match at any depth, a leading slash anchors the pattern
+// at the repository root. A pattern matching a directory matches everything below it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				if i+1 < len(p) && p[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("(?:/.*)?$")
+
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const content = `# comment line
+*                 @user1
+*.go              @user2 @org1/team1   # trailing comment
+/docs/            docs@example.com
+src/**/test/      @org1/testers
+/build/logs
+invalid           user3
+`
+
+func TestParse(t *testing.T) {
+	f, warnings, err := Parse(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"invalid owner user3"}, warnings)
+	assert.Len(t, f.Rules, 6)
+	assert.Equal(t, "*.go", f.Rules[1].Pattern)
+	assert.Equal(t, []string{"@user2", "@org1/team1"}, f.Rules[1].Owners)
+	assert.Empty(t, f.Rules[4].Owners)
+}
+
+func TestMatch(t *testing.T) {
+	f, _, err := Parse(strings.NewReader(content))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		Path   string
+		Owners []string
+	}{
+		{"README.md", []string{"@user1"}},
+		{"main.go", []string{"@user2", "@org1/team1"}},
+		{"modules/sub/file.go", []string{"@user2", "@org1/team1"}},
+		{"docs/index.md", []string{"docs@example.com"}},
+		{"docs/sub/file.go", []string{"docs@example.com"}},
+		{"other/docs/index.md", []string{"@user1"}},
+		{"src/test/a.txt", []string{"@org1/testers"}},
+		{"src/a/b/test/c/d.txt", []string{"@org1/testers"}},
+		{"build/logs/out.log", []string{}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.Owners, f.Owners(c.Path), c.Path)
+	}
+
+	f, _, err = Parse(strings.NewReader("/docs/*.md @user1\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, f.Match("README.md"))
+	assert.NotNil(t, f.Match("docs/index.md"))
+}
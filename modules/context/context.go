@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -90,8 +91,18 @@ func (ctx *Context) GetData() map[string]interface{} {
 	return ctx.Data
 }
 
-// IsUserSiteAdmin returns true if current user is a site admin
+// AccessToken returns the personal access token used to authenticate the request, nil for other authentication methods
+func (ctx *Context) AccessToken() *models.AccessToken {
+	t, _ := ctx.Data["ApiToken"].(*models.AccessToken)
+	return t
+}
+
+// IsUserSiteAdmin returns true if current user is a site admin.
+// Access tokens without the admin scope don't grant the administrator permissions.
 func (ctx *Context) IsUserSiteAdmin() bool {
+	if t := ctx.AccessToken(); t != nil && !t.Scope.HasScope(models.AccessTokenScopeAdmin) {
+		return false
+	}
 	return ctx.IsSigned && ctx.Doer.IsAdmin
 }
 
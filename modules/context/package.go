@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"net/http"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 )
 
@@ -73,6 +76,21 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 	packageType := ctx.Params("type")
 	name := ctx.Params("name")
 	version := ctx.Params("version")
+
+	// users with write access to the linked repository may modify the package too
+	if packageType != "" && name != "" && ctx.Doer != nil && ctx.Package.AccessMode < perm.AccessModeWrite {
+		accessMode, err := linkedRepositoryAccessMode(ctx, packages_model.Type(packageType), name)
+		if err != nil {
+			errCb(http.StatusInternalServerError, "linkedRepositoryAccessMode", err)
+			return
+		}
+		if accessMode > ctx.Package.AccessMode {
+			ctx.Package.AccessMode = accessMode
+		}
+	}
+
+	ctx.Package.AccessMode = limitAccessModeByToken(ctx, ctx.Package.AccessMode)
+
 	if packageType != "" && name != "" && version != "" {
 		pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.Type(packageType), name, version)
 		if err != nil {
@@ -92,6 +110,104 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 	}
 }
 
+// CanWrite returns whether the doer may modify the package with the given type and name.
+// Besides users with write access to the packages of the owner, users with write access
+// to the repository linked to the package may modify it.
+func (p *Package) CanWrite(ctx *Context, packageType packages_model.Type, name string) (bool, error) {
+	if p.AccessMode >= perm.AccessModeWrite || ctx.IsUserSiteAdmin() {
+		return true, nil
+	}
+	if ctx.Doer == nil {
+		return false, nil
+	}
+
+	accessMode, err := linkedRepositoryAccessMode(ctx, packageType, name)
+	if err != nil {
+		return false, err
+	}
+	return limitAccessModeByToken(ctx, accessMode) >= perm.AccessModeWrite, nil
+}
+
+// CanWriteAnyLinked returns whether the doer may modify at least one package of the given type.
+// It is used to reject requests early if the package name is not known before the request body is read,
+// the handler must check the package with CanWrite afterwards.
+func (p *Package) CanWriteAnyLinked(ctx *Context, packageType packages_model.Type) (bool, error) {
+	if p.AccessMode >= perm.AccessModeWrite || ctx.IsUserSiteAdmin() {
+		return true, nil
+	}
+	if ctx.Doer == nil || limitAccessModeByToken(ctx, perm.AccessModeWrite) < perm.AccessModeWrite {
+		return false, nil
+	}
+
+	pkgs, err := packages_model.GetPackagesByType(ctx, p.Owner.ID, packageType)
+	if err != nil {
+		return false, err
+	}
+	checked := make(map[int64]bool)
+	for _, pkg := range pkgs {
+		if pkg.RepoID == 0 || checked[pkg.RepoID] {
+			continue
+		}
+		checked[pkg.RepoID] = true
+
+		accessMode, err := repositoryAccessMode(ctx, pkg.RepoID)
+		if err != nil {
+			return false, err
+		}
+		if accessMode >= perm.AccessModeWrite {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// limitAccessModeByToken applies the restrictions of the used personal access token.
+// Tokens may be limited to reading packages or to the packages of some owners.
+func limitAccessModeByToken(ctx *Context, accessMode perm.AccessMode) perm.AccessMode {
+	if t := ctx.AccessToken(); t != nil {
+		if !t.AllowsOwner(ctx.Package.Owner.ID) || !t.Scope.HasScope(models.AccessTokenScopePackageRead) {
+			return perm.AccessModeNone
+		} else if !t.Scope.HasScope(models.AccessTokenScopePackageWrite) && accessMode > perm.AccessModeRead {
+			return perm.AccessModeRead
+		}
+	}
+	return accessMode
+}
+
+func linkedRepositoryAccessMode(ctx *Context, packageType packages_model.Type, name string) (perm.AccessMode, error) {
+	p, err := packages_model.GetPackageByName(ctx, ctx.Package.Owner.ID, packageType, name)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			return perm.AccessModeNone, nil
+		}
+		return perm.AccessModeNone, err
+	}
+	if p.RepoID == 0 {
+		return perm.AccessModeNone, nil
+	}
+
+	return repositoryAccessMode(ctx, p.RepoID)
+}
+
+func repositoryAccessMode(ctx *Context, repoID int64) (perm.AccessMode, error) {
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return perm.AccessModeNone, nil
+		}
+		return perm.AccessModeNone, err
+	}
+
+	repoPerm, err := models.GetUserRepoPermission(ctx, repo, ctx.Doer)
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+	if repoPerm.CanWrite(unit.TypeCode) {
+		return perm.AccessModeWrite, nil
+	}
+	return perm.AccessModeNone, nil
+}
+
 // PackageContexter initializes a package context for a request.
 func PackageContexter() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,90 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/ratelimit"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RateLimit limits the requests of tokens, users and IP addresses. It must run after the authentication.
+func RateLimit() func(ctx *Context) {
+	if !setting.RateLimit.Enabled {
+		return func(ctx *Context) {}
+	}
+	return func(ctx *Context) {
+		if exceeded, retryAfter := checkRateLimit(ctx); exceeded {
+			ctx.PlainText(http.StatusTooManyRequests, "Rate limit exceeded, retry in "+strconv.Itoa(retryAfter)+" seconds")
+		}
+	}
+}
+
+// APIRateLimit limits the requests of tokens, users and IP addresses to the API. It must run after the authentication.
+func APIRateLimit() func(ctx *APIContext) {
+	if !setting.RateLimit.Enabled {
+		return func(ctx *APIContext) {}
+	}
+	return func(ctx *APIContext) {
+		if exceeded, retryAfter := checkRateLimit(ctx.Context); exceeded {
+			ctx.Error(http.StatusTooManyRequests, "RateLimit", "rate limit exceeded, retry in "+strconv.Itoa(retryAfter)+" seconds")
+		}
+	}
+}
+
+// rateLimitBuckets returns the buckets a request is counted in.
+// Anonymous requests are counted separately from authenticated ones of the same IP address.
+func rateLimitBuckets(ctx *Context) []ratelimit.Bucket {
+	ip := ctx.Req.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	if ctx.Doer == nil {
+		return []ratelimit.Bucket{
+			{Key: "anonymous:" + ip, Limit: setting.RateLimit.AnonymousLimit},
+		}
+	}
+
+	buckets := []ratelimit.Bucket{
+		{Key: "user:" + strconv.FormatInt(ctx.Doer.ID, 10), Limit: setting.RateLimit.UserLimit},
+		{Key: "ip:" + ip, Limit: setting.RateLimit.IPLimit},
+	}
+	if t := ctx.AccessToken(); t != nil {
+		buckets = append(buckets, ratelimit.Bucket{Key: "token:" + strconv.FormatInt(t.ID, 10), Limit: setting.RateLimit.TokenLimit})
+	}
+	return buckets
+}
+
+// checkRateLimit counts the request and sets the rate limit headers.
+// It returns true and the seconds until the limit resets if the request exceeds a limit.
+func checkRateLimit(ctx *Context) (bool, int) {
+	result, err := ratelimit.GetLimiter().Hit(rateLimitBuckets(ctx)...)
+	if err != nil {
+		// an unavailable cache must not take the instance down
+		log.Error("Unable to check the rate limit: %v", err)
+		return false, 0
+	}
+	if result == nil {
+		return false, 0
+	}
+
+	header := ctx.Resp.Header()
+	header.Set("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+	header.Set("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+	if !result.Exceeded {
+		return false, 0
+	}
+
+	retryAfter := int(time.Until(result.Reset).Seconds()) + 1
+	header.Set("Retry-After", strconv.Itoa(retryAfter))
+	return true, retryAfter
+}
@@ -110,7 +110,7 @@ type CanCommitToBranchResults struct {
 // CanCommitToBranch returns true if repository is editable and user has proper access level
 //   and branch is not protected for push
 func (r *Repository) CanCommitToBranch(ctx context.Context, doer *user_model.User) (CanCommitToBranchResults, error) {
-	protectedBranch, err := models.GetProtectedBranchBy(r.Repository.ID, r.BranchName)
+	protectedBranch, err := models.GetMatchingProtectedBranchRule(r.Repository.ID, r.BranchName)
 	if err != nil {
 		return CanCommitToBranchResults{}, err
 	}
@@ -357,6 +357,12 @@ func repoAssignment(ctx *Context, repo *repo_model.Repository) {
 		return
 	}
 
+	// Personal access tokens are accepted for downloads, they need to be allowed to read the repository
+	if t := ctx.AccessToken(); t != nil && (!t.Scope.HasScope(models.AccessTokenScopeRepoRead) || !t.AllowsRepo(repo)) {
+		ctx.NotFound("access token is not allowed to access the repository", nil)
+		return
+	}
+
 	// Check access.
 	if !ctx.Repo.Permission.HasAccess() {
 		if ctx.FormString("go-get") == "1" {
@@ -528,6 +534,7 @@ func RepoAssignment(ctx *Context) (cancel context.CancelFunc) {
 	ctx.Data["Repository"] = repo
 	ctx.Data["Owner"] = ctx.Repo.Repository.Owner
 	ctx.Data["IsRepositoryOwner"] = ctx.Repo.IsOwner()
+	ctx.Data["IsPackageEnabled"] = setting.Packages.Enabled
 	ctx.Data["IsRepositoryAdmin"] = ctx.Repo.IsAdmin()
 	ctx.Data["RepoOwnerIsOrganization"] = repo.Owner.IsOrganization()
 	ctx.Data["CanWriteCode"] = ctx.Repo.CanWrite(unit_model.TypeCode)
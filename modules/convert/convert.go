@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
@@ -132,6 +133,7 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 		MergeWhitelistTeams:           mergeWhitelistTeams,
 		EnableStatusCheck:             bp.EnableStatusCheck,
 		StatusCheckContexts:           bp.StatusCheckContexts,
+		EnableMergeQueue:              bp.EnableMergeQueue,
 		RequiredApprovals:             bp.RequiredApprovals,
 		EnableApprovalsWhitelist:      bp.EnableApprovalsWhitelist,
 		ApprovalsWhitelistUsernames:   approvalsWhitelistUsernames,
@@ -140,6 +142,7 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 		BlockOnOfficialReviewRequests: bp.BlockOnOfficialReviewRequests,
 		BlockOnOutdatedBranch:         bp.BlockOnOutdatedBranch,
 		DismissStaleApprovals:         bp.DismissStaleApprovals,
+		RequireCodeOwnerReview:        bp.RequireCodeOwnerReview,
 		RequireSignedCommits:          bp.RequireSignedCommits,
 		ProtectedFilePatterns:         bp.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       bp.UnprotectedFilePatterns,
@@ -366,6 +369,26 @@ func ToOAuth2Application(app *auth.OAuth2Application) *api.OAuth2Application {
 	}
 }
 
+// ToAccessToken convert from models.AccessToken to api.AccessToken
+func ToAccessToken(t *models.AccessToken) *api.AccessToken {
+	token := &api.AccessToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+		Scopes:         make([]string, 0, 1),
+		AllowedRepos:   t.AllowedRepos,
+	}
+	for _, scope := range t.Scope.Scopes() {
+		token.Scopes = append(token.Scopes, string(scope))
+	}
+	if t.ExpiresUnix > 0 {
+		expiresAt := t.ExpiresUnix.AsTime()
+		token.ExpiresAt = &expiresAt
+	}
+	return token
+}
+
 // ToLFSLock convert a LFSLock to api.LFSLock
 func ToLFSLock(l *models.LFSLock) *api.LFSLock {
 	u, err := user_model.GetUserByID(l.OwnerID)
@@ -381,3 +404,28 @@ func ToLFSLock(l *models.LFSLock) *api.LFSLock {
 		},
 	}
 }
+
+// ToAuditEvent convert audit_model.Event to api.AuditEvent
+func ToAuditEvent(e *audit_model.Event) *api.AuditEvent {
+	changes := make([]*api.AuditEventChange, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		changes = append(changes, &api.AuditEventChange{
+			Field: c.Field,
+			Old:   c.Old,
+			New:   c.New,
+		})
+	}
+	return &api.AuditEvent{
+		ID:         e.ID,
+		Action:     string(e.Action),
+		ActorID:    e.ActorID,
+		ActorName:  e.ActorName,
+		IP:         e.IP,
+		TargetType: string(e.TargetType),
+		TargetID:   e.TargetID,
+		TargetName: e.TargetName,
+		OwnerID:    e.OwnerID,
+		Changes:    changes,
+		Created:    e.CreatedUnix.AsTime(),
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
-	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
 // ToWikiCommit convert a git commit into a WikiCommit
@@ -49,8 +48,7 @@ func ToWikiCommitList(commits []*git.Commit, total int64) *api.WikiCommitList {
 }
 
 // ToWikiPageMetaData converts meta information to a WikiPageMetaData
-func ToWikiPageMetaData(title string, lastCommit *git.Commit, repo *repo_model.Repository) *api.WikiPageMetaData {
-	suburl := wiki_service.NameToSubURL(title)
+func ToWikiPageMetaData(title, suburl string, lastCommit *git.Commit, repo *repo_model.Repository) *api.WikiPageMetaData {
 	return &api.WikiPageMetaData{
 		Title:      title,
 		HTMLURL:    util.URLJoin(repo.HTMLURL(), "wiki", suburl),
@@ -4,7 +4,32 @@
 
 package git
 
+import "strings"
+
 // GetRefs returns all references of the repository.
 func (repo *Repository) GetRefs() ([]*Reference, error) {
 	return repo.GetRefsFiltered("")
 }
+
+// GetRefsBySha returns the names of all references starting with prefix which point to the commit
+func (repo *Repository) GetRefsBySha(sha, prefix string) ([]string, error) {
+	if CheckGitVersionAtLeast("2.7.0") == nil {
+		stdout, _, err := NewCommand(repo.Ctx, "for-each-ref", "--points-at", sha, "--format=%(refname)", prefix).RunStdString(&RunOpts{Dir: repo.Path})
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(stdout), nil
+	}
+
+	refs, err := repo.GetRefsFiltered(prefix)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Object.String() == sha {
+			names = append(names, ref.Name)
+		}
+	}
+	return names, nil
+}
@@ -50,3 +50,18 @@ func TestRepository_GetRefsFiltered(t *testing.T) {
 		assert.Equal(t, "3ad28a9149a2864384548f3d17ed7f38014c9e8a", refs[0].Object.String())
 	}
 }
+
+func TestRepository_GetRefsBySha(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := openRepositoryWithDefaultContext(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	refs, err := bareRepo1.GetRefsBySha("2839944139e0de9737a044f78b0e4b40d989a9e3", BranchPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{BranchPrefix + "branch1"}, refs)
+
+	refs, err = bareRepo1.GetRefsBySha("2839944139e0de9737a044f78b0e4b40d989a9e3", TagPrefix)
+	assert.NoError(t, err)
+	assert.Empty(t, refs)
+}
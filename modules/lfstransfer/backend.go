@@ -0,0 +1,242 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// httpBackend forwards the requests to the LFS server of the local instance,
+// which applies the same permission checks, quotas and limits as for LFS over HTTP.
+type httpBackend struct {
+	client        *http.Client
+	baseURL       string
+	authorization func() (string, error)
+}
+
+// NewHTTPBackend creates a backend for the repository which authenticates every request
+// with the Authorization header value returned by the authorization function.
+func NewHTTPBackend(ownerName, repoName string, authorization func() (string, error)) Backend {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         setting.Domain,
+		},
+	}
+	if setting.Protocol == setting.HTTPUnix {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", setting.HTTPAddr)
+		}
+	}
+
+	return &httpBackend{
+		client:        &http.Client{Transport: transport},
+		baseURL:       fmt.Sprintf("%s%s/%s.git/info/lfs/", setting.LocalURL, url.PathEscape(ownerName), url.PathEscape(repoName)),
+		authorization: authorization,
+	}
+}
+
+// bodyReader signals when the transport is done with the request body
+type bodyReader struct {
+	io.Reader
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (r *bodyReader) Close() error {
+	r.once.Do(func() {
+		close(r.closed)
+	})
+	return nil
+}
+
+func (b *httpBackend) do(ctx context.Context, method, path string, body io.Reader, contentLength int64, contentType string) (*http.Response, error) {
+	var rc *bodyReader
+	if body != nil {
+		rc = &bodyReader{Reader: body, closed: make(chan struct{})}
+		body = rc
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = contentLength
+		req.Header.Set("Content-Type", contentType)
+	}
+	authorization, err := b.authorization()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", lfs.MediaType)
+
+	resp, err := b.client.Do(req)
+	if rc != nil {
+		// the body may still be read after a response was received, it must not be touched before it is closed
+		select {
+		case <-rc.closed:
+		case <-ctx.Done():
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+
+		var lockErr api.LFSLockError
+		if err := json.NewDecoder(resp.Body).Decode(&lockErr); err != nil || lockErr.Message == "" {
+			lockErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, &StatusError{Code: resp.StatusCode, Message: lockErr.Message, Lock: lockErr.Lock}
+	}
+	return resp, nil
+}
+
+func (b *httpBackend) doJSON(ctx context.Context, method, path string, request, response interface{}) error {
+	var body io.Reader
+	var contentLength int64
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentLength = int64(len(data))
+	}
+
+	resp, err := b.do(ctx, method, path, body, contentLength, lfs.MediaType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// Batch implements Backend
+func (b *httpBackend) Batch(ctx context.Context, operation string, pointers []lfs.Pointer, ref string) ([]BatchItem, error) {
+	request := &lfs.BatchRequest{
+		Operation: operation,
+		Transfers: []string{"basic"},
+		Objects:   pointers,
+	}
+	if ref != "" {
+		request.Ref = &lfs.Reference{Name: ref}
+	}
+
+	var response lfs.BatchResponse
+	if err := b.doJSON(ctx, http.MethodPost, "objects/batch", request, &response); err != nil {
+		return nil, err
+	}
+
+	items := make([]BatchItem, 0, len(response.Objects))
+	for _, obj := range response.Objects {
+		if obj.Error != nil {
+			// objects which are missing for a download are reported as not present
+			if operation != "download" || obj.Error.Code != http.StatusNotFound {
+				return nil, &StatusError{Code: obj.Error.Code, Message: fmt.Sprintf("%s: %s", obj.Oid, obj.Error.Message)}
+			}
+			items = append(items, BatchItem{Pointer: obj.Pointer})
+			continue
+		}
+
+		_, hasAction := obj.Actions[operation]
+		items = append(items, BatchItem{
+			Pointer: obj.Pointer,
+			Present: hasAction == (operation == "download"),
+		})
+	}
+	return items, nil
+}
+
+// Upload implements Backend
+func (b *httpBackend) Upload(ctx context.Context, p lfs.Pointer, r io.Reader) error {
+	resp, err := b.do(ctx, http.MethodPut, fmt.Sprintf("objects/%s/%d", url.PathEscape(p.Oid), p.Size), r, p.Size, "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Verify implements Backend
+func (b *httpBackend) Verify(ctx context.Context, p lfs.Pointer) error {
+	return b.doJSON(ctx, http.MethodPost, "verify", &p, nil)
+}
+
+// Download implements Backend
+func (b *httpBackend) Download(ctx context.Context, oid string) (io.ReadCloser, int64, error) {
+	resp, err := b.do(ctx, http.MethodGet, "objects/"+url.PathEscape(oid), nil, 0, "")
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.ContentLength < 0 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("unknown size of object %s", oid)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// CreateLock implements Backend
+func (b *httpBackend) CreateLock(ctx context.Context, path string) (*api.LFSLock, error) {
+	var response api.LFSLockResponse
+	if err := b.doJSON(ctx, http.MethodPost, "locks", &api.LFSLockRequest{Path: path}, &response); err != nil {
+		return nil, err
+	}
+	return response.Lock, nil
+}
+
+// ListLocks implements Backend
+func (b *httpBackend) ListLocks(ctx context.Context, opts ListLocksOptions) ([]*api.LFSLock, string, error) {
+	query := url.Values{}
+	if opts.Path != "" {
+		query.Set("path", opts.Path)
+	}
+	if opts.ID != "" {
+		query.Set("id", opts.ID)
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var response api.LFSLockList
+	if err := b.doJSON(ctx, http.MethodGet, "locks?"+query.Encode(), nil, &response); err != nil {
+		return nil, "", err
+	}
+	return response.Locks, response.Next, nil
+}
+
+// Unlock implements Backend
+func (b *httpBackend) Unlock(ctx context.Context, id string, force bool) (*api.LFSLock, error) {
+	var response api.LFSLockResponse
+	if err := b.doJSON(ctx, http.MethodPost, "locks/"+url.PathEscape(id)+"/unlock", &api.LFSLockDeleteRequest{Force: force}, &response); err != nil {
+		return nil, err
+	}
+	return response.Lock, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, lfs.MediaType, r.Header.Get("Accept"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /user2/repo1.git/info/lfs/objects/batch":
+			var br lfs.BatchRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&br))
+			assert.Equal(t, "refs/heads/main", br.Ref.Name)
+
+			resp := lfs.BatchResponse{}
+			for _, p := range br.Objects {
+				obj := &lfs.ObjectResponse{Pointer: p}
+				switch {
+				case p.Oid == missingOid:
+					obj.Error = &lfs.ObjectError{Code: http.StatusNotFound, Message: "Not Found"}
+				case br.Operation == "download":
+					obj.Actions = map[string]*lfs.Link{"download": {Href: "http://example.com"}}
+				}
+				resp.Objects = append(resp.Objects, obj)
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "PUT /user2/repo1.git/info/lfs/objects/" + testOid + "/5":
+			w.WriteHeader(http.StatusInsufficientStorage)
+			_ = json.NewEncoder(w).Encode(lfs.ErrorResponse{Message: "quota exceeded"})
+		case "POST /user2/repo1.git/info/lfs/locks":
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(api.LFSLockError{Message: "already created lock", Lock: &api.LFSLock{ID: "3", Path: "file.bin"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(localURL string) {
+		setting.LocalURL = localURL
+	}(setting.LocalURL)
+	setting.LocalURL = server.URL + "/"
+
+	backend := NewHTTPBackend("user2", "repo1", func() (string, error) {
+		return "Bearer token", nil
+	})
+	ctx := context.Background()
+
+	pointers := []lfs.Pointer{{Oid: testOid, Size: 5}, {Oid: missingOid, Size: 1}}
+	items, err := backend.Batch(ctx, "download", pointers, "refs/heads/main")
+	assert.NoError(t, err)
+	assert.Equal(t, []BatchItem{{Pointer: pointers[0], Present: true}, {Pointer: pointers[1]}}, items)
+
+	_, err = backend.Batch(ctx, "upload", pointers, "refs/heads/main")
+	assert.EqualError(t, err, "status 404: "+missingOid+": Not Found")
+
+	items, err = backend.Batch(ctx, "upload", pointers[:1], "refs/heads/main")
+	assert.NoError(t, err)
+	assert.Equal(t, []BatchItem{{Pointer: pointers[0], Present: true}}, items)
+
+	err = backend.Upload(ctx, pointers[0], strings.NewReader(testContent))
+	assert.EqualError(t, err, "status 507: quota exceeded")
+
+	_, err = backend.CreateLock(ctx, "file.bin")
+	var statusErr *StatusError
+	if assert.ErrorAs(t, err, &statusErr) {
+		assert.Equal(t, http.StatusConflict, statusErr.Code)
+		assert.Equal(t, "3", statusErr.Lock.ID)
+	}
+
+	_, _, err = backend.Download(ctx, testOid)
+	assert.EqualError(t, err, "status 404: Not Found")
+}
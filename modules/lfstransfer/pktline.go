@@ -0,0 +1,151 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxPacketDataLength is the maximum length of the data of a single packet
+const maxPacketDataLength = 65516
+
+type packetType int
+
+const (
+	dataPacket packetType = iota
+	// flushPacket "0000"
+	flushPacket
+	// delimPacket "0001"
+	delimPacket
+)
+
+// pktline reads and writes packets in the git pkt-line format
+type pktline struct {
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func newPktline(r io.Reader, w io.Writer) *pktline {
+	return &pktline{
+		r: bufio.NewReader(r),
+		w: bufio.NewWriter(w),
+	}
+}
+
+func (p *pktline) readPacket() (packetType, []byte, error) {
+	var lengthHex [4]byte
+	if _, err := io.ReadFull(p.r, lengthHex[:]); err != nil {
+		return 0, nil, err
+	}
+	length, err := strconv.ParseUint(string(lengthHex[:]), 16, 16)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid packet length %q", lengthHex)
+	}
+
+	switch {
+	case length == 0:
+		return flushPacket, nil, nil
+	case length == 1:
+		return delimPacket, nil, nil
+	case length < 4:
+		return 0, nil, fmt.Errorf("invalid packet length %q", lengthHex)
+	}
+
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return 0, nil, err
+	}
+	return dataPacket, data, nil
+}
+
+// readText reads a packet and strips the trailing newline of its data
+func (p *pktline) readText() (packetType, string, error) {
+	typ, data, err := p.readPacket()
+	return typ, strings.TrimSuffix(string(data), "\n"), err
+}
+
+func (p *pktline) writePacket(data []byte) error {
+	if len(data) > maxPacketDataLength {
+		return fmt.Errorf("packet of %d bytes exceeds the maximum length", len(data))
+	}
+	if _, err := fmt.Fprintf(p.w, "%04x", len(data)+4); err != nil {
+		return err
+	}
+	_, err := p.w.Write(data)
+	return err
+}
+
+func (p *pktline) writeText(s string) error {
+	return p.writePacket([]byte(s + "\n"))
+}
+
+func (p *pktline) writeDelim() error {
+	_, err := p.w.WriteString("0001")
+	return err
+}
+
+// writeFlush writes a flush packet and sends all buffered packets
+func (p *pktline) writeFlush() error {
+	if _, err := p.w.WriteString("0000"); err != nil {
+		return err
+	}
+	return p.w.Flush()
+}
+
+// writeData splits the content of the reader into data packets
+func (p *pktline) writeData(r io.Reader) error {
+	buf := make([]byte, maxPacketDataLength)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := p.writePacket(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// dataReader reads the content of data packets up to the next flush packet
+type dataReader struct {
+	p    *pktline
+	buf  []byte
+	done bool
+}
+
+func (r *dataReader) Read(b []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		typ, data, err := r.p.readPacket()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch typ {
+		case flushPacket:
+			r.done = true
+		case delimPacket:
+			return 0, fmt.Errorf("unexpected delim packet in data")
+		default:
+			r.buf = data
+		}
+	}
+
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
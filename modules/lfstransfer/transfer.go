@@ -0,0 +1,463 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package lfstransfer implements the server side of the git-lfs-transfer SSH protocol
+// https://github.com/git-lfs/git-lfs/blob/main/docs/proposals/ssh_adapter.md
+package lfstransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// BatchItem is the state of an object requested in a batch
+type BatchItem struct {
+	lfs.Pointer
+	// Present is true if the object is stored on the server
+	Present bool
+}
+
+// ListLocksOptions filter the listed locks
+type ListLocksOptions struct {
+	Path   string
+	ID     string
+	Cursor string
+	Limit  int
+}
+
+// Backend stores the LFS objects and locks of a repository
+type Backend interface {
+	Batch(ctx context.Context, operation string, pointers []lfs.Pointer, ref string) ([]BatchItem, error)
+	Upload(ctx context.Context, p lfs.Pointer, r io.Reader) error
+	Verify(ctx context.Context, p lfs.Pointer) error
+	Download(ctx context.Context, oid string) (io.ReadCloser, int64, error)
+	CreateLock(ctx context.Context, path string) (*api.LFSLock, error)
+	ListLocks(ctx context.Context, opts ListLocksOptions) ([]*api.LFSLock, string, error)
+	Unlock(ctx context.Context, id string, force bool) (*api.LFSLock, error)
+}
+
+// StatusError is an error reported to the client with a status code
+type StatusError struct {
+	Code    int
+	Message string
+	// Lock is the conflicting lock of a failed lock request
+	Lock *api.LFSLock
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", err.Code, err.Message)
+}
+
+func newStatusError(code int, format string, args ...interface{}) *StatusError {
+	return &StatusError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Transfer serves a single git-lfs-transfer session
+type Transfer struct {
+	backend   Backend
+	operation string
+	userName  string
+	p         *pktline
+}
+
+// NewTransfer creates a session for the operation ("upload" or "download") of the user
+func NewTransfer(backend Backend, operation, userName string, r io.Reader, w io.Writer) *Transfer {
+	return &Transfer{
+		backend:   backend,
+		operation: operation,
+		userName:  userName,
+		p:         newPktline(r, w),
+	}
+}
+
+// Serve processes the requests of the client until it quits or closes the connection
+func (t *Transfer) Serve(ctx context.Context) error {
+	if err := t.p.writeText("version=1"); err != nil {
+		return err
+	}
+	if err := t.p.writeFlush(); err != nil {
+		return err
+	}
+
+	negotiated := false
+	for {
+		typ, command, err := t.p.readText()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if typ != dataPacket {
+			return fmt.Errorf("expected a command")
+		}
+
+		args, hasDelim, err := t.readArgs()
+		if err != nil {
+			return err
+		}
+
+		name, param := command, ""
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			name, param = command[:i], command[i+1:]
+		}
+		log.Trace("git-lfs-transfer: %s %s", name, param)
+
+		if !negotiated && name != "version" && name != "quit" {
+			err = t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "version not negotiated"))
+		} else {
+			switch name {
+			case "version":
+				if param != "1" {
+					err = t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unsupported version %q", param))
+					break
+				}
+				negotiated = true
+				err = t.discardAndReply(hasDelim, nil)
+			case "batch":
+				err = t.handleBatch(ctx, args, hasDelim)
+			case "put-object":
+				err = t.handlePutObject(ctx, param, args, hasDelim)
+			case "verify-object":
+				err = t.handleVerifyObject(ctx, param, args, hasDelim)
+			case "get-object":
+				err = t.handleGetObject(ctx, param, hasDelim)
+			case "lock":
+				err = t.handleLock(ctx, args, hasDelim)
+			case "list-lock":
+				err = t.handleListLock(ctx, args, hasDelim)
+			case "unlock":
+				err = t.handleUnlock(ctx, param, args, hasDelim)
+			case "quit":
+				if err := t.discardAndReply(hasDelim, nil); err != nil {
+					return err
+				}
+				return nil
+			default:
+				err = t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unknown command %q", name))
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readArgs reads the "key=value" arguments of a request up to the delim or flush packet
+func (t *Transfer) readArgs() (map[string]string, bool, error) {
+	args := make(map[string]string)
+	for {
+		typ, line, err := t.p.readText()
+		if err != nil {
+			return nil, false, err
+		}
+		switch typ {
+		case flushPacket:
+			return args, false, nil
+		case delimPacket:
+			return args, true, nil
+		}
+		key, value := line, ""
+		if i := strings.IndexByte(line, '='); i >= 0 {
+			key, value = line[:i], line[i+1:]
+		}
+		args[key] = value
+	}
+}
+
+// readLines reads the text lines following the delim packet of a request
+func (t *Transfer) readLines(hasDelim bool) ([]string, error) {
+	if !hasDelim {
+		return nil, nil
+	}
+	var lines []string
+	for {
+		typ, line, err := t.p.readText()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case flushPacket:
+			return lines, nil
+		case delimPacket:
+			return nil, fmt.Errorf("unexpected delim packet")
+		}
+		lines = append(lines, line)
+	}
+}
+
+// discardAndReply skips the remaining packets of the request and replies with the status of the error
+func (t *Transfer) discardAndReply(hasDelim bool, err error) error {
+	if hasDelim {
+		if _, err := io.Copy(io.Discard, &dataReader{p: t.p}); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return t.writeError(err)
+	}
+	return t.writeStatus(http.StatusOK, nil, nil)
+}
+
+// writeStatus writes a response, the lines are only written after a delim packet if they are not nil
+func (t *Transfer) writeStatus(code int, args, lines []string) error {
+	if err := t.p.writeText("status " + strconv.Itoa(code)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := t.p.writeText(arg); err != nil {
+			return err
+		}
+	}
+	if lines != nil {
+		if err := t.p.writeDelim(); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := t.p.writeText(line); err != nil {
+				return err
+			}
+		}
+	}
+	return t.p.writeFlush()
+}
+
+func (t *Transfer) writeError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		log.Error("git-lfs-transfer: %v", err)
+		statusErr = newStatusError(http.StatusInternalServerError, "internal server error")
+	}
+
+	var args []string
+	if statusErr.Lock != nil {
+		args = lockArgs(statusErr.Lock)
+	}
+	return t.writeStatus(statusErr.Code, args, []string{statusErr.Message})
+}
+
+func (t *Transfer) requireUpload() error {
+	if t.operation != "upload" {
+		return newStatusError(http.StatusForbidden, "write access is required")
+	}
+	return nil
+}
+
+func parsePointer(oid, size string) (lfs.Pointer, error) {
+	p := lfs.Pointer{Oid: oid}
+	var err error
+	if p.Size, err = strconv.ParseInt(size, 10, 64); err != nil || !p.IsValid() {
+		return p, newStatusError(http.StatusBadRequest, "invalid object %s %s", oid, size)
+	}
+	return p, nil
+}
+
+func (t *Transfer) handleBatch(ctx context.Context, args map[string]string, hasDelim bool) error {
+	lines, err := t.readLines(hasDelim)
+	if err != nil {
+		return err
+	}
+
+	if algo, ok := args["hash-algo"]; ok && algo != "sha256" {
+		return t.writeError(newStatusError(http.StatusConflict, "unsupported hash algorithm %q", algo))
+	}
+	if transfer, ok := args["transfer"]; ok && transfer != "basic" && transfer != "ssh" {
+		return t.writeError(newStatusError(http.StatusConflict, "unsupported transfer %q", transfer))
+	}
+
+	pointers := make([]lfs.Pointer, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return t.writeError(newStatusError(http.StatusBadRequest, "invalid object %q", line))
+		}
+		p, err := parsePointer(fields[0], fields[1])
+		if err != nil {
+			return t.writeError(err)
+		}
+		pointers = append(pointers, p)
+	}
+
+	items, err := t.backend.Batch(ctx, t.operation, pointers, args["refname"])
+	if err != nil {
+		return t.writeError(err)
+	}
+
+	results := make([]string, 0, len(items))
+	for _, item := range items {
+		action := "noop"
+		if t.operation == "upload" && !item.Present {
+			action = "upload"
+		} else if t.operation == "download" && item.Present {
+			action = "download"
+		}
+		results = append(results, fmt.Sprintf("%s %d %s", item.Oid, item.Size, action))
+	}
+	return t.writeStatus(http.StatusOK, []string{"hash-algo=sha256"}, results)
+}
+
+func (t *Transfer) handlePutObject(ctx context.Context, oid string, args map[string]string, hasDelim bool) error {
+	if err := t.requireUpload(); err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	p, err := parsePointer(oid, args["size"])
+	if err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	if !hasDelim {
+		return t.writeError(newStatusError(http.StatusBadRequest, "missing object data"))
+	}
+
+	r := &dataReader{p: t.p}
+	uploadErr := t.backend.Upload(ctx, p, r)
+	// the remaining data has to be consumed before replying, even if the upload failed
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if uploadErr != nil {
+		return t.writeError(uploadErr)
+	}
+	return t.writeStatus(http.StatusOK, nil, nil)
+}
+
+func (t *Transfer) handleVerifyObject(ctx context.Context, oid string, args map[string]string, hasDelim bool) error {
+	if err := t.requireUpload(); err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	p, err := parsePointer(oid, args["size"])
+	if err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	return t.discardAndReply(hasDelim, t.backend.Verify(ctx, p))
+}
+
+func (t *Transfer) handleGetObject(ctx context.Context, oid string, hasDelim bool) error {
+	if hasDelim {
+		return t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unexpected data"))
+	}
+
+	content, size, err := t.backend.Download(ctx, oid)
+	if err != nil {
+		return t.writeError(err)
+	}
+	defer content.Close()
+
+	if err := t.p.writeText("status 200"); err != nil {
+		return err
+	}
+	if err := t.p.writeText("size=" + strconv.FormatInt(size, 10)); err != nil {
+		return err
+	}
+	if err := t.p.writeDelim(); err != nil {
+		return err
+	}
+	if err := t.p.writeData(content); err != nil {
+		return err
+	}
+	return t.p.writeFlush()
+}
+
+func lockArgs(lock *api.LFSLock) []string {
+	ownerName := ""
+	if lock.Owner != nil {
+		ownerName = lock.Owner.Name
+	}
+	return []string{
+		"id=" + lock.ID,
+		"path=" + lock.Path,
+		"locked-at=" + lock.LockedAt.UTC().Format(time.RFC3339),
+		"ownername=" + ownerName,
+	}
+}
+
+func (t *Transfer) handleLock(ctx context.Context, args map[string]string, hasDelim bool) error {
+	if err := t.requireUpload(); err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	if hasDelim {
+		return t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unexpected data"))
+	}
+	if args["path"] == "" {
+		return t.writeError(newStatusError(http.StatusBadRequest, "missing path"))
+	}
+
+	lock, err := t.backend.CreateLock(ctx, args["path"])
+	if err != nil {
+		return t.writeError(err)
+	}
+	return t.writeStatus(http.StatusCreated, lockArgs(lock), nil)
+}
+
+func (t *Transfer) handleListLock(ctx context.Context, args map[string]string, hasDelim bool) error {
+	if hasDelim {
+		return t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unexpected data"))
+	}
+
+	opts := ListLocksOptions{
+		Path:   args["path"],
+		ID:     args["id"],
+		Cursor: args["cursor"],
+	}
+	if limit, ok := args["limit"]; ok {
+		var err error
+		if opts.Limit, err = strconv.Atoi(limit); err != nil || opts.Limit < 0 {
+			return t.writeError(newStatusError(http.StatusBadRequest, "invalid limit %q", limit))
+		}
+	}
+
+	locks, next, err := t.backend.ListLocks(ctx, opts)
+	if err != nil {
+		return t.writeError(err)
+	}
+
+	var respArgs []string
+	if next != "" {
+		respArgs = append(respArgs, "next-cursor="+next)
+	}
+	lines := make([]string, 0, len(locks)*5)
+	for _, lock := range locks {
+		ownerName, owner := "", "theirs"
+		if lock.Owner != nil {
+			ownerName = lock.Owner.Name
+			if strings.EqualFold(ownerName, t.userName) {
+				owner = "ours"
+			}
+		}
+		lines = append(lines,
+			"lock "+lock.ID,
+			"path "+lock.ID+" "+lock.Path,
+			"locked-at "+lock.ID+" "+lock.LockedAt.UTC().Format(time.RFC3339),
+			"ownername "+lock.ID+" "+ownerName,
+			"owner "+lock.ID+" "+owner,
+		)
+	}
+	return t.writeStatus(http.StatusOK, respArgs, lines)
+}
+
+func (t *Transfer) handleUnlock(ctx context.Context, id string, args map[string]string, hasDelim bool) error {
+	if err := t.requireUpload(); err != nil {
+		return t.discardAndReply(hasDelim, err)
+	}
+	if hasDelim {
+		return t.discardAndReply(hasDelim, newStatusError(http.StatusBadRequest, "unexpected data"))
+	}
+	if id == "" {
+		return t.writeError(newStatusError(http.StatusBadRequest, "missing lock id"))
+	}
+
+	lock, err := t.backend.Unlock(ctx, id, args["force"] == "true")
+	if err != nil {
+		return t.writeError(err)
+	}
+	return t.writeStatus(http.StatusOK, lockArgs(lock), nil)
+}
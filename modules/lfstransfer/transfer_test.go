@@ -0,0 +1,266 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfstransfer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/lfs"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testOid     = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	testContent = "hello"
+	missingOid  = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+type memoryBackend struct {
+	objects map[string][]byte
+	locks   []*api.LFSLock
+}
+
+func (b *memoryBackend) Batch(_ context.Context, _ string, pointers []lfs.Pointer, _ string) ([]BatchItem, error) {
+	items := make([]BatchItem, 0, len(pointers))
+	for _, p := range pointers {
+		_, ok := b.objects[p.Oid]
+		items = append(items, BatchItem{Pointer: p, Present: ok})
+	}
+	return items, nil
+}
+
+func (b *memoryBackend) Upload(_ context.Context, p lfs.Pointer, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != p.Size {
+		return &StatusError{Code: http.StatusUnprocessableEntity, Message: lfs.ErrSizeMismatch.Error()}
+	}
+	b.objects[p.Oid] = data
+	return nil
+}
+
+func (b *memoryBackend) Verify(_ context.Context, p lfs.Pointer) error {
+	if _, ok := b.objects[p.Oid]; !ok {
+		return &StatusError{Code: http.StatusNotFound, Message: "not found"}
+	}
+	return nil
+}
+
+func (b *memoryBackend) Download(_ context.Context, oid string) (io.ReadCloser, int64, error) {
+	data, ok := b.objects[oid]
+	if !ok {
+		return nil, 0, &StatusError{Code: http.StatusNotFound, Message: "not found"}
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (b *memoryBackend) CreateLock(_ context.Context, path string) (*api.LFSLock, error) {
+	for _, lock := range b.locks {
+		if lock.Path == path {
+			return nil, &StatusError{Code: http.StatusConflict, Message: "already created lock", Lock: lock}
+		}
+	}
+	lock := &api.LFSLock{
+		ID:       strconv.Itoa(len(b.locks) + 1),
+		Path:     path,
+		LockedAt: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC),
+		Owner:    &api.LFSLockOwner{Name: "user2"},
+	}
+	b.locks = append(b.locks, lock)
+	return lock, nil
+}
+
+func (b *memoryBackend) ListLocks(_ context.Context, _ ListLocksOptions) ([]*api.LFSLock, string, error) {
+	return b.locks, "", nil
+}
+
+func (b *memoryBackend) Unlock(_ context.Context, id string, _ bool) (*api.LFSLock, error) {
+	for i, lock := range b.locks {
+		if lock.ID == id {
+			b.locks = append(b.locks[:i], b.locks[i+1:]...)
+			return lock, nil
+		}
+	}
+	return nil, &StatusError{Code: http.StatusNotFound, Message: "not found"}
+}
+
+// request encodes a client request, a nil packet is a flush and an empty packet a delim packet
+func request(packets ...[]byte) string {
+	var sb strings.Builder
+	for _, p := range packets {
+		switch {
+		case p == nil:
+			sb.WriteString("0000")
+		case len(p) == 0:
+			sb.WriteString("0001")
+		default:
+			fmt.Fprintf(&sb, "%04x%s", len(p)+4, p)
+		}
+	}
+	return sb.String()
+}
+
+func text(s string) []byte {
+	return []byte(s + "\n")
+}
+
+var delim = []byte{}
+
+// readResponses decodes the server output into responses, delim packets are returned as "--"
+func readResponses(t *testing.T, output []byte) [][]string {
+	p := newPktline(bytes.NewReader(output), io.Discard)
+	var responses [][]string
+	var current []string
+	for {
+		typ, line, err := p.readText()
+		if err == io.EOF {
+			assert.Empty(t, current)
+			return responses
+		}
+		assert.NoError(t, err)
+		switch typ {
+		case flushPacket:
+			responses = append(responses, current)
+			current = nil
+		case delimPacket:
+			current = append(current, "--")
+		default:
+			current = append(current, line)
+		}
+	}
+}
+
+func serve(t *testing.T, backend Backend, operation, input string) [][]string {
+	var output bytes.Buffer
+	err := NewTransfer(backend, operation, "user2", strings.NewReader(input), &output).Serve(context.Background())
+	assert.NoError(t, err)
+	return readResponses(t, output.Bytes())
+}
+
+func TestTransferUpload(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+
+	responses := serve(t, backend, "upload", request(
+		text("version 1"), nil,
+		text("batch"), text("hash-algo=sha256"), text("refname=refs/heads/main"), delim,
+		text(testOid+" 5"), nil,
+		text("put-object "+testOid), text("size=5"), delim, []byte(testContent), nil,
+		text("verify-object "+testOid), text("size=5"), nil,
+		text("batch"), delim, text(testOid+" 5"), nil,
+		text("put-object "+missingOid), text("size=3"), delim, []byte("abcd"), nil,
+		text("quit"), nil,
+	))
+
+	assert.Equal(t, [][]string{
+		{"version=1"},
+		{"status 200"},
+		{"status 200", "hash-algo=sha256", "--", testOid + " 5 upload"},
+		{"status 200"},
+		{"status 200"},
+		{"status 200", "hash-algo=sha256", "--", testOid + " 5 noop"},
+		{"status 422", "--", lfs.ErrSizeMismatch.Error()},
+		{"status 200"},
+	}, responses)
+	assert.Equal(t, testContent, string(backend.objects[testOid]))
+}
+
+func TestTransferDownload(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{testOid: []byte(testContent)}}
+
+	responses := serve(t, backend, "download", request(
+		text("version 1"), nil,
+		text("batch"), delim, text(testOid+" 5"), text(missingOid+" 1"), nil,
+		text("get-object "+testOid), nil,
+		text("get-object "+missingOid), nil,
+		text("put-object "+testOid), text("size=5"), delim, []byte(testContent), nil,
+		text("batch"), text("hash-algo=sha1"), delim, text(testOid+" 5"), nil,
+	))
+
+	assert.Equal(t, [][]string{
+		{"version=1"},
+		{"status 200"},
+		{"status 200", "hash-algo=sha256", "--", testOid + " 5 download", missingOid + " 1 noop"},
+		{"status 200", "size=5", "--", testContent},
+		{"status 404", "--", "not found"},
+		{"status 403", "--", "write access is required"},
+		{"status 409", "--", `unsupported hash algorithm "sha1"`},
+	}, responses)
+}
+
+func TestTransferLocks(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+	backend.locks = []*api.LFSLock{{
+		ID:       "7",
+		Path:     "other.bin",
+		LockedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC),
+		Owner:    &api.LFSLockOwner{Name: "user5"},
+	}}
+
+	responses := serve(t, backend, "upload", request(
+		text("version 1"), nil,
+		text("lock"), text("path=file.bin"), text("refname=refs/heads/main"), nil,
+		text("lock"), text("path=file.bin"), nil,
+		text("list-lock"), text("limit=10"), nil,
+		text("unlock 2"), text("force=true"), nil,
+		text("unlock 9"), nil,
+	))
+
+	assert.Equal(t, [][]string{
+		{"version=1"},
+		{"status 200"},
+		{"status 201", "id=2", "path=file.bin", "locked-at=2022-08-01T10:00:00Z", "ownername=user2"},
+		{"status 409", "id=2", "path=file.bin", "locked-at=2022-08-01T10:00:00Z", "ownername=user2", "--", "already created lock"},
+		{
+			"status 200", "--",
+			"lock 7", "path 7 other.bin", "locked-at 7 2022-07-01T10:00:00Z", "ownername 7 user5", "owner 7 theirs",
+			"lock 2", "path 2 file.bin", "locked-at 2 2022-08-01T10:00:00Z", "ownername 2 user2", "owner 2 ours",
+		},
+		{"status 200", "id=2", "path=file.bin", "locked-at=2022-08-01T10:00:00Z", "ownername=user2"},
+		{"status 404", "--", "not found"},
+	}, responses)
+}
+
+func TestTransferRequiresVersion(t *testing.T) {
+	responses := serve(t, &memoryBackend{}, "download", request(
+		text("batch"), delim, text(testOid+" 5"), nil,
+		text("version 2"), nil,
+	))
+
+	assert.Equal(t, [][]string{
+		{"version=1"},
+		{"status 400", "--", "version not negotiated"},
+		{"status 400", "--", `unsupported version "2"`},
+	}, responses)
+}
+
+func TestDataReader(t *testing.T) {
+	var buf bytes.Buffer
+	p := newPktline(nil, &buf)
+	content := bytes.Repeat([]byte("x"), 2*maxPacketDataLength+10)
+	assert.NoError(t, p.writeData(bytes.NewReader(content)))
+	assert.NoError(t, p.writeFlush())
+	assert.Equal(t, 3*4+len(content)+4, buf.Len())
+
+	r := &dataReader{p: newPktline(&buf, io.Discard)}
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	r = &dataReader{p: newPktline(strings.NewReader(request([]byte("abc"))), io.Discard)}
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
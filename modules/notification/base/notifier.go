@@ -6,6 +6,7 @@ package base
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -57,4 +58,15 @@ type Notifier interface {
 	NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository)
 	NotifyPackageCreate(doer *user_model.User, pd *packages_model.PackageDescriptor)
 	NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor)
+	NotifyCreateCommitStatus(doer *user_model.User, repo *repo_model.Repository, status *models.CommitStatus)
+	NotifyNewWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string)
+	NotifyEditWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string)
+	NotifyRenameWikiPage(doer *user_model.User, repo *repo_model.Repository, oldPage, newPage, comment string)
+	NotifyDeleteWikiPage(doer *user_model.User, repo *repo_model.Repository, page string)
+	NotifyStarRepository(doer *user_model.User, repo *repo_model.Repository, star bool)
+	NotifyAddTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User)
+	NotifyRemoveTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User)
+	NotifyNewTeam(doer *user_model.User, team *organization.Team)
+	NotifyUpdateTeam(doer *user_model.User, team *organization.Team)
+	NotifyDeleteTeam(doer *user_model.User, team *organization.Team)
 }
@@ -6,6 +6,7 @@ package base
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -182,3 +183,47 @@ func (*NullNotifier) NotifyPackageCreate(doer *user_model.User, pd *packages_mod
 // NotifyPackageDelete places a place holder function
 func (*NullNotifier) NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor) {
 }
+
+// NotifyCreateCommitStatus places a place holder function
+func (*NullNotifier) NotifyCreateCommitStatus(doer *user_model.User, repo *repo_model.Repository, status *models.CommitStatus) {
+}
+
+// NotifyNewWikiPage places a place holder function
+func (*NullNotifier) NotifyNewWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+}
+
+// NotifyEditWikiPage places a place holder function
+func (*NullNotifier) NotifyEditWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+}
+
+// NotifyRenameWikiPage places a place holder function
+func (*NullNotifier) NotifyRenameWikiPage(doer *user_model.User, repo *repo_model.Repository, oldPage, newPage, comment string) {
+}
+
+// NotifyDeleteWikiPage places a place holder function
+func (*NullNotifier) NotifyDeleteWikiPage(doer *user_model.User, repo *repo_model.Repository, page string) {
+}
+
+// NotifyStarRepository places a place holder function
+func (*NullNotifier) NotifyStarRepository(doer *user_model.User, repo *repo_model.Repository, star bool) {
+}
+
+// NotifyAddTeamMember places a place holder function
+func (*NullNotifier) NotifyAddTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+}
+
+// NotifyRemoveTeamMember places a place holder function
+func (*NullNotifier) NotifyRemoveTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+}
+
+// NotifyNewTeam places a place holder function
+func (*NullNotifier) NotifyNewTeam(doer *user_model.User, team *organization.Team) {
+}
+
+// NotifyUpdateTeam places a place holder function
+func (*NullNotifier) NotifyUpdateTeam(doer *user_model.User, team *organization.Team) {
+}
+
+// NotifyDeleteTeam places a place holder function
+func (*NullNotifier) NotifyDeleteTeam(doer *user_model.User, team *organization.Team) {
+}
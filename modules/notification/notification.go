@@ -6,6 +6,7 @@ package notification
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -321,3 +322,80 @@ func NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescri
 		notifier.NotifyPackageDelete(doer, pd)
 	}
 }
+
+// NotifyCreateCommitStatus notifies creation of a commit status to notifiers
+func NotifyCreateCommitStatus(doer *user_model.User, repo *repo_model.Repository, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(doer, repo, status)
+	}
+}
+
+// NotifyNewWikiPage notifies creation of a wiki page to notifiers
+func NotifyNewWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyEditWikiPage notifies an edit of a wiki page to notifiers
+func NotifyEditWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyEditWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyRenameWikiPage notifies renaming of a wiki page to notifiers
+func NotifyRenameWikiPage(doer *user_model.User, repo *repo_model.Repository, oldPage, newPage, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRenameWikiPage(doer, repo, oldPage, newPage, comment)
+	}
+}
+
+// NotifyDeleteWikiPage notifies deletion of a wiki page to notifiers
+func NotifyDeleteWikiPage(doer *user_model.User, repo *repo_model.Repository, page string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteWikiPage(doer, repo, page)
+	}
+}
+
+// NotifyStarRepository notifies starring or unstarring of a repository to notifiers
+func NotifyStarRepository(doer *user_model.User, repo *repo_model.Repository, star bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyStarRepository(doer, repo, star)
+	}
+}
+
+// NotifyAddTeamMember notifies adding a member to a team to notifiers
+func NotifyAddTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyAddTeamMember(doer, team, member)
+	}
+}
+
+// NotifyRemoveTeamMember notifies removing a member from a team to notifiers
+func NotifyRemoveTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRemoveTeamMember(doer, team, member)
+	}
+}
+
+// NotifyNewTeam notifies creation of a team to notifiers
+func NotifyNewTeam(doer *user_model.User, team *organization.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewTeam(doer, team)
+	}
+}
+
+// NotifyUpdateTeam notifies changes of a team to notifiers
+func NotifyUpdateTeam(doer *user_model.User, team *organization.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateTeam(doer, team)
+	}
+}
+
+// NotifyDeleteTeam notifies deletion of a team to notifiers
+func NotifyDeleteTeam(doer *user_model.User, team *organization.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteTeam(doer, team)
+	}
+}
@@ -5,10 +5,14 @@
 package webhook
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -887,3 +891,172 @@ func notifyPackage(sender *user_model.User, pd *packages_model.PackageDescriptor
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
+
+func (m *webhookNotifier) NotifyCreateCommitStatus(doer *user_model.User, repo *repo_model.Repository, status *models.CommitStatus) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("webhook.NotifyCreateCommitStatus Status: %s in %s[%d]", status.SHA, repo.FullName(), repo.ID))
+	defer finished()
+
+	if err := webhook_services.PrepareWebhooks(repo, webhook.HookEventStatus, &api.CommitStatusPayload{
+		ID:           status.Index,
+		SHA:          status.SHA,
+		Context:      status.Context,
+		State:        status.State,
+		Description:  status.Description,
+		TargetURL:    status.TargetURL,
+		PullRequests: openPullRequestsByHeadCommit(ctx, repo, status.SHA),
+		Repository:   convert.ToRepo(repo, perm.AccessModeNone),
+		Sender:       convert.ToUser(doer, nil),
+		Created:      status.CreatedUnix.AsTime(),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
+
+// openPullRequestsByHeadCommit returns the open pull requests of the repository whose head is the commit
+func openPullRequestsByHeadCommit(ctx context.Context, repo *repo_model.Repository, sha string) []*api.PullRequest {
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return nil
+	}
+	defer closer.Close()
+
+	refs, err := gitRepo.GetRefsBySha(sha, git.PullPrefix)
+	if err != nil {
+		log.Error("GetRefsBySha[%s]: %v", sha, err)
+		return nil
+	}
+
+	apiPullRequests := make([]*api.PullRequest, 0, len(refs))
+	for _, ref := range refs {
+		index, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(ref, git.PullPrefix), "/head"), 10, 64)
+		if err != nil {
+			continue
+		}
+		pr, err := models.GetPullRequestByIndexCtx(ctx, repo.ID, index)
+		if err != nil {
+			if !models.IsErrPullRequestNotExist(err) {
+				log.Error("GetPullRequestByIndex[%d]: %v", index, err)
+			}
+			continue
+		}
+		if err := pr.LoadIssueCtx(ctx); err != nil {
+			log.Error("LoadIssue[%d]: %v", pr.ID, err)
+			continue
+		}
+		if pr.HasMerged || pr.Issue.IsClosed {
+			continue
+		}
+		if apiPullRequest := convert.ToAPIPullRequest(ctx, pr, nil); apiPullRequest != nil {
+			apiPullRequests = append(apiPullRequests, apiPullRequest)
+		}
+	}
+	return apiPullRequests
+}
+
+func sendWikiHook(doer *user_model.User, repo *repo_model.Repository, action api.HookWikiAction, page, oldPage, comment string) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, webhook.HookEventWiki, &api.WikiPayload{
+		Action:     action,
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+		Page:       page,
+		OldPage:    oldPage,
+		Comment:    comment,
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) NotifyNewWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiCreated, page, "", comment)
+}
+
+func (m *webhookNotifier) NotifyEditWikiPage(doer *user_model.User, repo *repo_model.Repository, page, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiEdited, page, "", comment)
+}
+
+func (m *webhookNotifier) NotifyRenameWikiPage(doer *user_model.User, repo *repo_model.Repository, oldPage, newPage, comment string) {
+	sendWikiHook(doer, repo, api.HookWikiRenamed, newPage, oldPage, comment)
+}
+
+func (m *webhookNotifier) NotifyDeleteWikiPage(doer *user_model.User, repo *repo_model.Repository, page string) {
+	sendWikiHook(doer, repo, api.HookWikiDeleted, page, "", "")
+}
+
+func (m *webhookNotifier) NotifyStarRepository(doer *user_model.User, repo *repo_model.Repository, star bool) {
+	action := api.HookStarStarred
+	if !star {
+		action = api.HookStarUnstarred
+	}
+
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, webhook.HookEventStar, &api.StarPayload{
+		Action:     action,
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
+
+func sendMemberHook(doer *user_model.User, team *organization.Team, member *user_model.User, action api.HookMemberAction) {
+	org, err := organization.GetOrgByID(team.OrgID)
+	if err != nil {
+		log.Error("GetOrgByID[%d]: %v", team.OrgID, err)
+		return
+	}
+	if team.Units == nil {
+		if err := team.GetUnits(); err != nil {
+			log.Error("GetUnits[%d]: %v", team.ID, err)
+			return
+		}
+	}
+
+	if err := webhook_services.PrepareOrgWebhooks(org, webhook.HookEventMember, &api.MemberPayload{
+		Action:       action,
+		Member:       convert.ToUser(member, nil),
+		Team:         convert.ToTeam(team),
+		Organization: convert.ToOrganization(org),
+		Sender:       convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareOrgWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) NotifyAddTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+	sendMemberHook(doer, team, member, api.HookMemberAdded)
+}
+
+func (m *webhookNotifier) NotifyRemoveTeamMember(doer *user_model.User, team *organization.Team, member *user_model.User) {
+	sendMemberHook(doer, team, member, api.HookMemberRemoved)
+}
+
+func sendTeamHook(doer *user_model.User, team *organization.Team, action api.HookTeamAction) {
+	org, err := organization.GetOrgByID(team.OrgID)
+	if err != nil {
+		log.Error("GetOrgByID[%d]: %v", team.OrgID, err)
+		return
+	}
+
+	if err := webhook_services.PrepareOrgWebhooks(org, webhook.HookEventTeam, &api.TeamPayload{
+		Action:       action,
+		Team:         convert.ToTeam(team),
+		Organization: convert.ToOrganization(org),
+		Sender:       convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareOrgWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) NotifyNewTeam(doer *user_model.User, team *organization.Team) {
+	sendTeamHook(doer, team, api.HookTeamCreated)
+}
+
+func (m *webhookNotifier) NotifyUpdateTeam(doer *user_model.User, team *organization.Team) {
+	sendTeamHook(doer, team, api.HookTeamEdited)
+}
+
+func (m *webhookNotifier) NotifyDeleteTeam(doer *user_model.User, team *organization.Team) {
+	sendTeamHook(doer, team, api.HookTeamDeleted)
+}
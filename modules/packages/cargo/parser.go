@@ -0,0 +1,224 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	// PropertyYanked is the name of the version property which marks a yanked version
+	PropertyYanked = "cargo.yanked"
+
+	// maxMetadataSize limits the size of the JSON metadata block of an upload
+	maxMetadataSize = 10 * 1024 * 1024
+)
+
+var (
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("package version is invalid")
+	// ErrInvalidUpload indicates a malformed publish request body
+	ErrInvalidUpload = errors.New("upload is invalid")
+)
+
+// https://doc.rust-lang.org/cargo/reference/manifest.html#the-name-field
+var namePattern = regexp.MustCompile(`\A[a-zA-Z][a-zA-Z0-9-_]{0,63}\z`)
+
+// Package represents a Cargo package
+type Package struct {
+	Name        string
+	Version     string
+	Metadata    *Metadata
+	Content     io.Reader
+	ContentSize int64
+}
+
+// Metadata represents the metadata of a Cargo package
+type Metadata struct {
+	Description      string              `json:"description,omitempty"`
+	Readme           string              `json:"readme,omitempty"`
+	Authors          []string            `json:"authors,omitempty"`
+	License          string              `json:"license,omitempty"`
+	ProjectURL       string              `json:"project_url,omitempty"`
+	RepositoryURL    string              `json:"repository_url,omitempty"`
+	DocumentationURL string              `json:"documentation_url,omitempty"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	Categories       []string            `json:"categories,omitempty"`
+	Features         map[string][]string `json:"features,omitempty"`
+	Dependencies     []*Dependency       `json:"dependencies,omitempty"`
+	Links            string              `json:"links,omitempty"`
+}
+
+// Dependency represents a dependency of a Cargo package
+type Dependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          *string  `json:"target"`
+	Kind            string   `json:"kind"`
+	Registry        *string  `json:"registry"`
+	Package         *string  `json:"package"`
+}
+
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type publishMetadata struct {
+	Name          string              `json:"name"`
+	Vers          string              `json:"vers"`
+	Deps          []publishDependency `json:"deps"`
+	Features      map[string][]string `json:"features"`
+	Authors       []string            `json:"authors"`
+	Description   string              `json:"description"`
+	Documentation string              `json:"documentation"`
+	Homepage      string              `json:"homepage"`
+	Readme        string              `json:"readme"`
+	ReadmeFile    string              `json:"readme_file"`
+	Keywords      []string            `json:"keywords"`
+	Categories    []string            `json:"categories"`
+	License       string              `json:"license"`
+	LicenseFile   string              `json:"license_file"`
+	Repository    string              `json:"repository"`
+	Links         string              `json:"links"`
+}
+
+type publishDependency struct {
+	Name               string   `json:"name"`
+	VersionReq         string   `json:"version_req"`
+	Features           []string `json:"features"`
+	Optional           bool     `json:"optional"`
+	DefaultFeatures    bool     `json:"default_features"`
+	Target             *string  `json:"target"`
+	Kind               string   `json:"kind"`
+	Registry           *string  `json:"registry"`
+	ExplicitNameInToml string   `json:"explicit_name_in_toml"`
+}
+
+// ParsePackage reads the body of a publish request.
+// The body consists of a length prefixed JSON metadata block followed by the length prefixed .crate file.
+func ParsePackage(r io.Reader) (*Package, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidUpload
+	}
+	if size > maxMetadataSize {
+		return nil, ErrInvalidUpload
+	}
+
+	p, err := parsePackage(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidUpload
+	}
+
+	p.Content = io.LimitReader(r, int64(size))
+	p.ContentSize = int64(size)
+
+	return p, nil
+}
+
+func parsePackage(r io.Reader) (*Package, error) {
+	var meta publishMetadata
+	if err := json.NewDecoder(r).Decode(&meta); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(meta.Name) {
+		return nil, ErrInvalidName
+	}
+
+	if _, err := version.NewSemver(meta.Vers); err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	if !validation.IsValidURL(meta.Homepage) {
+		meta.Homepage = ""
+	}
+	if !validation.IsValidURL(meta.Documentation) {
+		meta.Documentation = ""
+	}
+	if !validation.IsValidURL(meta.Repository) {
+		meta.Repository = ""
+	}
+
+	dependencies := make([]*Dependency, 0, len(meta.Deps))
+	for _, dep := range meta.Deps {
+		d := &Dependency{
+			Name:            dep.Name,
+			Req:             dep.VersionReq,
+			Features:        dep.Features,
+			Optional:        dep.Optional,
+			DefaultFeatures: dep.DefaultFeatures,
+			Target:          dep.Target,
+			Kind:            dep.Kind,
+			Registry:        dep.Registry,
+		}
+		// A renamed dependency is stored with its local name and the original package name
+		if dep.ExplicitNameInToml != "" {
+			pkg := dep.Name
+			d.Name = dep.ExplicitNameInToml
+			d.Package = &pkg
+		}
+		if d.Features == nil {
+			d.Features = []string{}
+		}
+		dependencies = append(dependencies, d)
+	}
+
+	features := meta.Features
+	if features == nil {
+		features = make(map[string][]string)
+	}
+
+	return &Package{
+		Name:    meta.Name,
+		Version: meta.Vers,
+		Metadata: &Metadata{
+			Description:      meta.Description,
+			Readme:           meta.Readme,
+			Authors:          meta.Authors,
+			License:          meta.License,
+			ProjectURL:       meta.Homepage,
+			RepositoryURL:    meta.Repository,
+			DocumentationURL: meta.Documentation,
+			Keywords:         meta.Keywords,
+			Categories:       meta.Categories,
+			Features:         features,
+			Dependencies:     dependencies,
+			Links:            meta.Links,
+		},
+	}, nil
+}
+
+// IndexPath returns the path of the sparse index file of the package relative to the index root
+// https://doc.rust-lang.org/cargo/reference/registries.html#index-format
+func IndexPath(name string) string {
+	name = strings.ToLower(name)
+
+	switch len(name) {
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
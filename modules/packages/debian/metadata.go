@@ -0,0 +1,268 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	PropertyDistribution = "debian.distribution"
+	PropertyComponent    = "debian.component"
+	PropertyArchitecture = "debian.architecture"
+	PropertyControl      = "debian.control"
+
+	SettingKeyPrivate = "debian.key.private"
+	SettingKeyPublic  = "debian.key.public"
+
+	// RepositoryPackage is the name of the internal package which holds the generated repository files
+	RepositoryPackage = "_debian"
+	// RepositoryVersion is the name of the internal version which holds the generated repository files
+	RepositoryVersion = "_repository"
+
+	ArchitectureAll = "all"
+
+	controlTar = "control.tar"
+)
+
+var (
+	// ErrMissingControlFile indicates a missing control file
+	ErrMissingControlFile = errors.New("control file is missing")
+	// ErrUnsupportedCompression indicates an unsupported compression format
+	ErrUnsupportedCompression = errors.New("unsupported compression algorithm")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("package version is invalid")
+	// ErrInvalidArchitecture indicates an invalid package architecture
+	ErrInvalidArchitecture = errors.New("package architecture is invalid")
+	// ErrInvalidArchive indicates an invalid .deb archive
+	ErrInvalidArchive = errors.New("archive is invalid")
+
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source
+	namePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9+-.]+\z`)
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+	versionPattern = regexp.MustCompile(`\A(?:[0-9]:)?[a-zA-Z0-9.+~]+(?:-[a-zA-Z0-9.+-~]+)?\z`)
+)
+
+// Package represents a Debian package
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Control      string
+	Metadata     *Metadata
+}
+
+// Metadata represents the metadata of a Debian package
+type Metadata struct {
+	Maintainer   string   `json:"maintainer,omitempty"`
+	ProjectURL   string   `json:"project_url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// ParsePackage parses the Debian package file
+// https://manpages.debian.org/bullseye/dpkg-dev/deb.5.en.html
+func ParsePackage(r io.Reader) (*Package, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	if string(magic) != "!<arch>\n" {
+		return nil, ErrInvalidArchive
+	}
+
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, ErrInvalidArchive
+		}
+
+		name := strings.TrimRight(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return nil, ErrInvalidArchive
+		}
+
+		if strings.HasPrefix(name, controlTar) {
+			var inner io.Reader
+			switch name[len(controlTar):] {
+			case "":
+				inner = io.LimitReader(br, size)
+			case ".gz":
+				gzr, err := gzip.NewReader(io.LimitReader(br, size))
+				if err != nil {
+					return nil, err
+				}
+				defer gzr.Close()
+
+				inner = gzr
+			case ".xz":
+				xzr, err := xz.NewReader(io.LimitReader(br, size))
+				if err != nil {
+					return nil, err
+				}
+
+				inner = xzr
+			case ".zst":
+				zr, err := zstd.NewReader(io.LimitReader(br, size))
+				if err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+
+				inner = zr
+			default:
+				return nil, ErrUnsupportedCompression
+			}
+
+			return parseControlTar(inner)
+		}
+
+		// entries are aligned to an even offset
+		if _, err := br.Discard(int(size + size%2)); err != nil {
+			return nil, ErrInvalidArchive
+		}
+	}
+
+	return nil, ErrMissingControlFile
+}
+
+func parseControlTar(r io.Reader) (*Package, error) {
+	tr := tar.NewReader(r)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if hd.FileInfo().Name() == "control" {
+			return ParseControlFile(tr)
+		}
+	}
+
+	return nil, ErrMissingControlFile
+}
+
+// ParseControlFile parses a Debian control file to retrieve the metadata
+func ParseControlFile(r io.Reader) (*Package, error) {
+	p := &Package{
+		Metadata: &Metadata{},
+	}
+
+	key := ""
+	var depends strings.Builder
+	var control strings.Builder
+
+	s := bufio.NewScanner(io.LimitReader(r, 10*1024*1024))
+	for s.Scan() {
+		line := s.Text()
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			switch key {
+			case "Description":
+				p.Metadata.Description += "\n" + trimmed
+			case "Depends":
+				depends.WriteString(trimmed)
+			}
+		} else {
+			parts := strings.SplitN(trimmed, ":", 2)
+			if len(parts) < 2 {
+				continue
+			}
+
+			key = parts[0]
+			value := strings.TrimSpace(parts[1])
+			switch key {
+			case "Package":
+				p.Name = value
+			case "Version":
+				p.Version = value
+			case "Architecture":
+				p.Architecture = value
+			case "Maintainer":
+				a, err := mail.ParseAddress(value)
+				if err != nil || a.Name == "" {
+					p.Metadata.Maintainer = value
+				} else {
+					p.Metadata.Maintainer = a.Name
+				}
+			case "Description":
+				p.Metadata.Description = value
+			case "Depends":
+				depends.WriteString(value)
+			case "Homepage":
+				if validation.IsValidURL(value) {
+					p.Metadata.ProjectURL = value
+				}
+			}
+		}
+
+		control.WriteString(line)
+		control.WriteString("\n")
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if p.Architecture == "" {
+		return nil, ErrInvalidArchitecture
+	}
+
+	dependencies := strings.Split(depends.String(), ",")
+	for i := range dependencies {
+		dependencies[i] = strings.TrimSpace(dependencies[i])
+	}
+	p.Metadata.Dependencies = dependencies
+	if len(dependencies) == 1 && dependencies[0] == "" {
+		p.Metadata.Dependencies = nil
+	}
+
+	p.Control = strings.TrimSpace(control.String())
+
+	return p, nil
+}
+
+// IsValidDistributionOrComponent tests if a distribution or component name is usable in a repository path
+func IsValidDistributionOrComponent(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/|\\ ") && name != "." && name != ".."
+}
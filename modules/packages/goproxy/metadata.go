@@ -0,0 +1,190 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	PropertyGoMod = "go.mod"
+
+	maxGoModFileSize = 16 * 1024 * 1024 // https://go.dev/ref/mod#zip-path-size-constraints
+)
+
+var (
+	// ErrInvalidStructure indicates an invalid module zip structure
+	ErrInvalidStructure = errors.New("module zip has an invalid structure")
+	// ErrInvalidModule indicates an invalid module path or version
+	ErrInvalidModule = errors.New("module path or version is invalid")
+	// ErrModulePathMismatch indicates a go.mod file which declares another module path
+	ErrModulePathMismatch = errors.New("module path does not match the go.mod file")
+	// ErrGoModFileTooLarge indicates a go.mod file which is too large
+	ErrGoModFileTooLarge = fmt.Errorf("go.mod file is too large (max %d bytes)", maxGoModFileSize)
+
+	// https://go.dev/ref/mod#versions
+	versionPattern = regexp.MustCompile(`\Av(?:0|[1-9]\d*)\.(?:0|[1-9]\d*)\.(?:0|[1-9]\d*)(?:-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?\z`)
+)
+
+// Package represents a Go module
+type Package struct {
+	Name    string
+	Version string
+	GoMod   string
+}
+
+// ParsePackage parses the module zip.
+// All files must be located in a "<module>@<version>/" directory.
+// https://go.dev/ref/mod#zip-files
+func ParsePackage(r io.ReaderAt, size int64) (*Package, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidStructure
+	}
+
+	var p *Package
+	prefix := ""
+
+	for _, file := range archive.File {
+		if p == nil {
+			// the module path may contain slashes, the version does not
+			at := strings.Index(file.Name, "@")
+			if at == -1 {
+				return nil, ErrInvalidStructure
+			}
+			slash := strings.Index(file.Name[at:], "/")
+			if slash == -1 {
+				return nil, ErrInvalidStructure
+			}
+
+			name := file.Name[:at]
+			version := file.Name[at+1 : at+slash]
+			if !IsValidModulePath(name) || !versionPattern.MatchString(version) {
+				return nil, ErrInvalidModule
+			}
+
+			p = &Package{
+				Name:    name,
+				Version: version,
+			}
+			prefix = file.Name[:at+slash+1]
+		}
+
+		if !strings.HasPrefix(file.Name, prefix) {
+			return nil, ErrInvalidStructure
+		}
+
+		if file.Name == prefix+"go.mod" {
+			if file.UncompressedSize64 > maxGoModFileSize {
+				return nil, ErrGoModFileTooLarge
+			}
+
+			f, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			if parseModulePath(data) != p.Name {
+				return nil, ErrModulePathMismatch
+			}
+
+			p.GoMod = string(data)
+		}
+	}
+
+	if p == nil {
+		return nil, ErrInvalidStructure
+	}
+
+	if p.GoMod == "" {
+		// modules without go.mod file get a synthesized one
+		p.GoMod = "module " + p.Name
+	}
+
+	return p, nil
+}
+
+// parseModulePath extracts the module path from the module directive of a go.mod file
+func parseModulePath(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path
+		}
+		return fields[1]
+	}
+	return ""
+}
+
+// IsValidModulePath checks if the module path is usable
+// https://go.dev/ref/mod#go-mod-file-ident
+func IsValidModulePath(path string) bool {
+	if path == "" || strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") {
+		return false
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" || elem == "." || elem == ".." || strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return false
+		}
+		for _, r := range elem {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-._~", r)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// UnescapePath decodes a module path or version escaped by the go command.
+// Upper-case letters are escaped as an exclamation mark followed by the lower-case letter.
+// https://go.dev/ref/mod#goproxy-protocol
+func UnescapePath(escaped string) (string, bool) {
+	var sb strings.Builder
+	bang := false
+	for _, r := range escaped {
+		if r > unicode.MaxASCII || unicode.IsUpper(r) {
+			return "", false
+		}
+		if bang {
+			bang = false
+			if r < 'a' || r > 'z' {
+				return "", false
+			}
+			sb.WriteRune(unicode.ToUpper(r))
+			continue
+		}
+		if r == '!' {
+			bang = true
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if bang {
+		return "", false
+	}
+	return sb.String(), true
+}
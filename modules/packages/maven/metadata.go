@@ -13,13 +13,14 @@ import (
 
 // Metadata represents the metadata of a Maven package
 type Metadata struct {
-	GroupID      string        `json:"group_id,omitempty"`
-	ArtifactID   string        `json:"artifact_id,omitempty"`
-	Name         string        `json:"name,omitempty"`
-	Description  string        `json:"description,omitempty"`
-	ProjectURL   string        `json:"project_url,omitempty"`
-	Licenses     []string      `json:"licenses,omitempty"`
-	Dependencies []*Dependency `json:"dependencies,omitempty"`
+	GroupID       string        `json:"group_id,omitempty"`
+	ArtifactID    string        `json:"artifact_id,omitempty"`
+	Name          string        `json:"name,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	ProjectURL    string        `json:"project_url,omitempty"`
+	RepositoryURL string        `json:"repository_url,omitempty"`
+	Licenses      []string      `json:"licenses,omitempty"`
+	Dependencies  []*Dependency `json:"dependencies,omitempty"`
 }
 
 // Dependency represents a dependency of a Maven package
@@ -37,7 +38,10 @@ type pomStruct struct {
 	Name        string   `xml:"name"`
 	Description string   `xml:"description"`
 	URL         string   `xml:"url"`
-	Licenses    []struct {
+	SCM         struct {
+		URL string `xml:"url"`
+	} `xml:"scm"`
+	Licenses []struct {
 		Name         string `xml:"name"`
 		URL          string `xml:"url"`
 		Distribution string `xml:"distribution"`
@@ -60,6 +64,9 @@ func ParsePackageMetaData(r io.Reader) (*Metadata, error) {
 	if !validation.IsValidURL(pom.URL) {
 		pom.URL = ""
 	}
+	if !validation.IsValidURL(pom.SCM.URL) {
+		pom.SCM.URL = ""
+	}
 
 	licenses := make([]string, 0, len(pom.Licenses))
 	for _, l := range pom.Licenses {
@@ -78,12 +85,13 @@ func ParsePackageMetaData(r io.Reader) (*Metadata, error) {
 	}
 
 	return &Metadata{
-		GroupID:      pom.GroupID,
-		ArtifactID:   pom.ArtifactID,
-		Name:         pom.Name,
-		Description:  pom.Description,
-		ProjectURL:   pom.URL,
-		Licenses:     licenses,
-		Dependencies: dependencies,
+		GroupID:       pom.GroupID,
+		ArtifactID:    pom.ArtifactID,
+		Name:          pom.Name,
+		Description:   pom.Description,
+		ProjectURL:    pom.URL,
+		RepositoryURL: pom.SCM.URL,
+		Licenses:      licenses,
+		Dependencies:  dependencies,
 	}, nil
 }
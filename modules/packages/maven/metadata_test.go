@@ -18,6 +18,7 @@ const (
 	name                 = "My Gitea Project"
 	description          = "Package Description"
 	projectURL           = "https://gitea.io"
+	repositoryURL        = "https://gitea.io/gitea/gitea"
 	license              = "MIT"
 	dependencyGroupID    = "org.gitea.core"
 	dependencyArtifactID = "git"
@@ -32,6 +33,9 @@ const pomContent = `<?xml version="1.0"?>
   <name>` + name + `</name>
   <description>` + description + `</description>
   <url>` + projectURL + `</url>
+  <scm>
+    <url>` + repositoryURL + `</url>
+  </scm>
   <licenses>
     <license>
       <name>` + license + `</name>
@@ -63,6 +67,7 @@ func TestParsePackageMetaData(t *testing.T) {
 		assert.Equal(t, name, m.Name)
 		assert.Equal(t, description, m.Description)
 		assert.Equal(t, projectURL, m.ProjectURL)
+		assert.Equal(t, repositoryURL, m.RepositoryURL)
 		assert.Len(t, m.Licenses, 1)
 		assert.Equal(t, license, m.Licenses[0])
 		assert.Len(t, m.Dependencies, 1)
@@ -175,6 +175,9 @@ func ParsePackage(r io.Reader) (*Package, error) {
 		if !validation.IsValidURL(meta.Homepage) {
 			meta.Homepage = ""
 		}
+		if !validation.IsValidURL(strings.TrimPrefix(meta.Repository.URL, "git+")) {
+			meta.Repository.URL = ""
+		}
 
 		p := &Package{
 			Name:     meta.Name,
@@ -187,6 +190,7 @@ func ParsePackage(r io.Reader) (*Package, error) {
 				Author:                  meta.Author.Name,
 				License:                 meta.License,
 				ProjectURL:              meta.Homepage,
+				RepositoryURL:           meta.Repository.URL,
 				Keywords:                meta.Keywords,
 				Dependencies:            meta.Dependencies,
 				DevelopmentDependencies: meta.DevDependencies,
@@ -232,7 +232,11 @@ func TestParsePackage(t *testing.T) {
 						Author:      User{Name: packageAuthor},
 						License:     "MIT",
 						Homepage:    "https://gitea.io/",
-						Readme:      packageDescription,
+						Repository: Repository{
+							Type: "git",
+							URL:  "git+https://gitea.io/gitea/gitea.git",
+						},
+						Readme: packageDescription,
 						Dependencies: map[string]string{
 							"package": "1.2.0",
 						},
@@ -266,6 +270,7 @@ func TestParsePackage(t *testing.T) {
 		assert.Equal(t, packageAuthor, p.Metadata.Author)
 		assert.Equal(t, "MIT", p.Metadata.License)
 		assert.Equal(t, "https://gitea.io/", p.Metadata.ProjectURL)
+		assert.Equal(t, "git+https://gitea.io/gitea/gitea.git", p.Metadata.RepositoryURL)
 		assert.Contains(t, p.Metadata.Dependencies, "package")
 		assert.Equal(t, "1.2.0", p.Metadata.Dependencies["package"])
 	})
@@ -57,3 +57,31 @@ func SendEmail(ctx context.Context, subject, message string, to []string) (int,
 
 	return http.StatusOK, fmt.Sprintf("Sent %s email(s) to %s users", body, users)
 }
+
+// ReceiveEmail passes a raw incoming mail to the incoming mail handler
+func ReceiveEmail(ctx context.Context, message []byte) (int, string) {
+	reqURL := setting.LocalURL + "api/internal/mail/receive"
+
+	req := newInternalRequest(ctx, reqURL, "POST")
+	req = req.Header("Content-Type", "message/rfc822")
+	req.Body(message)
+	resp, err := req.Response()
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Sprintf("Unable to contact gitea: %v", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Sprintf("Response body error: %v", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		var ret Response
+		if err := json.Unmarshal(body, &ret); err != nil {
+			return resp.StatusCode, string(body)
+		}
+		return resp.StatusCode, ret.Err
+	}
+
+	return http.StatusOK, "Received"
+}
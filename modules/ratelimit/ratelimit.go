@@ -0,0 +1,141 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	mc "gitea.com/go-chi/cache"
+)
+
+// Bucket is a counter of requests with its limit per window
+type Bucket struct {
+	Key   string
+	Limit int64
+}
+
+// Result is the state of the most restrictive bucket after a request has been counted
+type Result struct {
+	Limit     int64
+	Remaining int64
+	Reset     time.Time
+	Exceeded  bool
+}
+
+// Limiter counts requests in fixed windows. The counters are stored in a cache,
+// so the limits are shared by all instances using the same Redis or memcache server.
+type Limiter struct {
+	cache  mc.Cache
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter storing its counters in the cache
+func NewLimiter(c mc.Cache, window time.Duration) *Limiter {
+	if window < time.Second {
+		window = time.Second
+	}
+	return &Limiter{
+		cache:  c,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+var (
+	limiter     *Limiter
+	limiterOnce sync.Once
+)
+
+// GetLimiter returns the limiter configured by the rate limit settings.
+// It uses the cache service if it is enabled and a private memory cache otherwise.
+func GetLimiter() *Limiter {
+	limiterOnce.Do(func() {
+		c := cache.GetCache()
+		if c == nil {
+			var err error
+			c, err = mc.NewCacher(mc.Options{Adapter: "memory", Interval: 60})
+			if err != nil {
+				log.Fatal("Unable to create the rate limit cache: %v", err)
+			}
+		}
+		limiter = NewLimiter(c, setting.RateLimit.Window)
+	})
+	return limiter
+}
+
+// Hit counts a request in all buckets. Buckets without a limit are ignored.
+// The result is nil if no bucket has a limit.
+func (l *Limiter) Hit(buckets ...Bucket) (*Result, error) {
+	windowSeconds := int64(l.window / time.Second)
+	window := l.now().Unix() / windowSeconds
+	windowKey := strconv.FormatInt(window, 10)
+
+	var result *Result
+	for _, b := range buckets {
+		if b.Limit <= 0 {
+			continue
+		}
+		count, err := l.incr("ratelimit:"+b.Key+":"+windowKey, windowSeconds)
+		if err != nil {
+			return nil, err
+		}
+		remaining := b.Limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		r := &Result{
+			Limit:     b.Limit,
+			Remaining: remaining,
+			Reset:     time.Unix((window+1)*windowSeconds, 0),
+			Exceeded:  count > b.Limit,
+		}
+		// report an exceeded bucket first, otherwise the one with the fewest remaining requests
+		if result == nil || (r.Exceeded && !result.Exceeded) || (r.Exceeded == result.Exceeded && r.Remaining < result.Remaining) {
+			result = r
+		}
+	}
+	return result, nil
+}
+
+// incr increments the counter of the key and returns the new value.
+// Concurrent requests starting a window may both store 1, so a few more requests than the limit may pass.
+func (l *Limiter) incr(key string, ttl int64) (int64, error) {
+	if l.cache.IsExist(key) {
+		if err := l.cache.Incr(key); err == nil {
+			if count, ok := toInt64(l.cache.Get(key)); ok {
+				return count, nil
+			}
+		}
+	}
+	// the counter doesn't exist yet or has just expired
+	if err := l.cache.Put(key, int64(1), ttl); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	case []byte:
+		i, err := strconv.ParseInt(string(v), 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
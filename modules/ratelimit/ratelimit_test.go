@@ -0,0 +1,62 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	mc "gitea.com/go-chi/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Hit(t *testing.T) {
+	c, err := mc.NewCacher(mc.Options{Adapter: "memory", Interval: 60})
+	assert.NoError(t, err)
+
+	now := time.Unix(1656000000, 0)
+	l := NewLimiter(c, time.Minute)
+	l.now = func() time.Time { return now }
+
+	user := Bucket{Key: "user:1", Limit: 3}
+	ip := Bucket{Key: "ip:127.0.0.1", Limit: 10}
+
+	r, err := l.Hit(user, ip)
+	assert.NoError(t, err)
+	assert.False(t, r.Exceeded)
+	assert.EqualValues(t, 3, r.Limit)
+	assert.EqualValues(t, 2, r.Remaining)
+	assert.Equal(t, time.Unix(1656000060, 0), r.Reset)
+
+	for i := 0; i < 2; i++ {
+		r, err = l.Hit(user, ip)
+		assert.NoError(t, err)
+		assert.False(t, r.Exceeded)
+	}
+	assert.EqualValues(t, 0, r.Remaining)
+
+	r, err = l.Hit(user, ip)
+	assert.NoError(t, err)
+	assert.True(t, r.Exceeded)
+	assert.EqualValues(t, 3, r.Limit)
+
+	// the ip bucket has counted all requests
+	r, err = l.Hit(ip)
+	assert.NoError(t, err)
+	assert.False(t, r.Exceeded)
+	assert.EqualValues(t, 5, r.Remaining)
+
+	// a new window resets the counters
+	now = now.Add(time.Minute)
+	r, err = l.Hit(user, ip)
+	assert.NoError(t, err)
+	assert.False(t, r.Exceeded)
+	assert.EqualValues(t, 2, r.Remaining)
+
+	// buckets without a limit are ignored
+	r, err = l.Hit(Bucket{Key: "token:1"})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
@@ -0,0 +1,63 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IncomingEmailTokenPlaceholder is replaced by the reply token in ReplyToAddress
+const IncomingEmailTokenPlaceholder = "%{token}"
+
+// IncomingEmail settings of the incoming email handling.
+// If Host is empty the mailbox is not polled and messages can only be
+// delivered by piping them to the incoming-mail command.
+var IncomingEmail = struct {
+	Enabled              bool
+	ReplyToAddress       string
+	Host                 string
+	Port                 int
+	UseTLS               bool `ini:"USE_TLS"`
+	SkipTLSVerify        bool `ini:"SKIP_TLS_VERIFY"`
+	Username             string
+	Password             string
+	Mailbox              string
+	PollInterval         time.Duration
+	DeleteHandledMessage bool
+	MaximumMessageSize   int64
+}{
+	Enabled:              false,
+	Port:                 993,
+	UseTLS:               true,
+	Mailbox:              "INBOX",
+	PollInterval:         time.Minute,
+	DeleteHandledMessage: true,
+	MaximumMessageSize:   10 * 1024 * 1024,
+}
+
+func newIncomingEmailService() {
+	if err := Cfg.Section("email.incoming").MapTo(&IncomingEmail); err != nil {
+		log.Fatal("Failed to map email.incoming settings: %v", err)
+	}
+	if !IncomingEmail.Enabled {
+		return
+	}
+
+	if strings.Count(IncomingEmail.ReplyToAddress, IncomingEmailTokenPlaceholder) != 1 {
+		log.Fatal("email.incoming.REPLY_TO_ADDRESS must contain %s exactly once", IncomingEmailTokenPlaceholder)
+	}
+	if _, err := mail.ParseAddress(strings.Replace(IncomingEmail.ReplyToAddress, IncomingEmailTokenPlaceholder, "token", 1)); err != nil {
+		log.Fatal("Invalid email.incoming.REPLY_TO_ADDRESS (%s): %v", IncomingEmail.ReplyToAddress, err)
+	}
+	if IncomingEmail.PollInterval < 10*time.Second {
+		IncomingEmail.PollInterval = 10 * time.Second
+	}
+
+	log.Info("Incoming Email Service Enabled")
+}
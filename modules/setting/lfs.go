@@ -22,6 +22,7 @@ var LFS = struct {
 	HTTPAuthExpiry  time.Duration `ini:"LFS_HTTP_AUTH_EXPIRY"`
 	MaxFileSize     int64         `ini:"LFS_MAX_FILE_SIZE"`
 	LocksPagingNum  int           `ini:"LFS_LOCKS_PAGING_NUM"`
+	AllowPureSSH    bool          `ini:"LFS_ALLOW_PURE_SSH"`
 
 	Storage
 }{}
@@ -16,9 +16,10 @@ import (
 var (
 	Packages = struct {
 		Storage
-		Enabled           bool
-		ChunkedUploadPath string
-		RegistryHost      string
+		Enabled              bool
+		ChunkedUploadPath    string
+		RegistryHost         string
+		DebianSigningKeyPath string
 	}{
 		Enabled: true,
 	}
@@ -40,6 +41,10 @@ func newPackages() {
 		Packages.ChunkedUploadPath = filepath.ToSlash(filepath.Join(AppDataPath, Packages.ChunkedUploadPath))
 	}
 
+	if Packages.DebianSigningKeyPath != "" && !filepath.IsAbs(Packages.DebianSigningKeyPath) {
+		Packages.DebianSigningKeyPath = filepath.Join(CustomPath, Packages.DebianSigningKeyPath)
+	}
+
 	if err := os.MkdirAll(Packages.ChunkedUploadPath, os.ModePerm); err != nil {
 		log.Error("Unable to create chunked upload directory: %s (%v)", Packages.ChunkedUploadPath, err)
 	}
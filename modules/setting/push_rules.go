@@ -0,0 +1,35 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"regexp"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SecretPattern is a named regular expression which detects a secret in pushed content
+type SecretPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// PushRules settings
+var PushRules = struct {
+	// SecretPatterns are checked in addition to the built-in patterns
+	SecretPatterns []SecretPattern
+}{}
+
+func newPushRulesService() {
+	PushRules.SecretPatterns = nil
+	for _, key := range Cfg.Section("push_rules.secret_patterns").Keys() {
+		re, err := regexp.Compile(key.Value())
+		if err != nil {
+			log.Error("Invalid secret pattern %s in [push_rules.secret_patterns]: %v", key.Name(), err)
+			continue
+		}
+		PushRules.SecretPatterns = append(PushRules.SecretPatterns, SecretPattern{Name: key.Name(), Pattern: re})
+	}
+}
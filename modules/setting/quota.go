@@ -0,0 +1,48 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Quota settings of the storage used by users and organizations
+var Quota = struct {
+	Enabled          bool
+	DefaultTotalSize int64 `ini:"-"`
+}{
+	Enabled:          false,
+	DefaultTotalSize: -1,
+}
+
+func newQuotaService() {
+	sec := Cfg.Section("quota")
+	if err := sec.MapTo(&Quota); err != nil {
+		log.Fatal("Failed to map quota settings: %v", err)
+	}
+
+	Quota.DefaultTotalSize = mustQuotaSize(sec.Key("DEFAULT_TOTAL_SIZE").MustString("-1"))
+
+	if Quota.Enabled {
+		log.Info("Quota Service Enabled")
+	}
+}
+
+// mustQuotaSize parses a human readable size, a negative value means unlimited
+func mustQuotaSize(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "-") {
+		return -1
+	}
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		log.Fatal("Failed to parse quota size %q: %v", value, err)
+	}
+	return int64(size)
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// RateLimit settings of the API, the package registry and Git over HTTP.
+// A limit of 0 disables the bucket.
+var RateLimit = struct {
+	Enabled        bool
+	Window         time.Duration
+	TokenLimit     int64
+	UserLimit      int64
+	IPLimit        int64 `ini:"IP_LIMIT"`
+	AnonymousLimit int64
+}{
+	Enabled:        false,
+	Window:         time.Minute,
+	TokenLimit:     600,
+	UserLimit:      1200,
+	IPLimit:        3000,
+	AnonymousLimit: 120,
+}
+
+func newRateLimitService() {
+	if err := Cfg.Section("rate_limit").MapTo(&RateLimit); err != nil {
+		log.Fatal("Failed to map rate limit settings: %v", err)
+	}
+	if RateLimit.Window < time.Second {
+		RateLimit.Window = time.Second
+	}
+
+	if RateLimit.Enabled {
+		log.Info("Rate Limit Service Enabled")
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import "code.gitea.io/gitea/modules/log"
+
+// SCIM settings
+var (
+	SCIM = struct {
+		Enabled bool
+	}{
+		Enabled: false,
+	}
+)
+
+func newSCIM() {
+	if err := Cfg.Section("scim").MapTo(&SCIM); err != nil {
+		log.Fatal("Failed to map SCIM settings: %v", err)
+	}
+}
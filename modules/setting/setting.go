@@ -1024,6 +1024,8 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 
 	newPackages()
 
+	newSCIM()
+
 	if err = Cfg.Section("ui").MapTo(&UI); err != nil {
 		log.Fatal("Failed to map UI settings: %v", err)
 	} else if err = Cfg.Section("markdown").MapTo(&Markdown); err != nil {
@@ -1285,9 +1287,13 @@ func NewServices() {
 	newCacheService()
 	newSessionService()
 	newCORSService()
+	newRateLimitService()
+	newQuotaService()
+	newPushRulesService()
 	newMailService()
 	newRegisterMailService()
 	newNotifyMailService()
+	newIncomingEmailService()
 	newProxyService()
 	newWebhookService()
 	newMigrationsService()
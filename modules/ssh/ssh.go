@@ -7,10 +7,13 @@ package ssh
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/binary"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -369,3 +372,108 @@ func GenKeyPair(keyPath string) error {
 	_, err = p.Write(public)
 	return err
 }
+
+// GenEd25519KeyPair makes a pair of Ed25519 keys for SSH client authentication.
+// Public key is encoded in the format for inclusion in an OpenSSH authorized_keys file.
+// Private key is PEM encoded in the OpenSSH private key format.
+func GenEd25519KeyPair(comment string) (string, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pub, err := gossh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return "", nil, err
+	}
+
+	// see PROTOCOL.key of OpenSSH for the format of the unencrypted private key
+	private := struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  binary.BigEndian.Uint32(check[:]),
+		Check2:  binary.BigEndian.Uint32(check[:]),
+		Keytype: gossh.KeyAlgoED25519,
+		Pub:     publicKey,
+		Priv:    privateKey,
+		Comment: comment,
+	}
+	for i := len(gossh.Marshal(private)); i%8 != 0; i++ {
+		private.Pad = append(private.Pad, byte(len(private.Pad)+1))
+	}
+
+	key := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       pub.Marshal(),
+		PrivKeyBlock: gossh.Marshal(private),
+	}
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), gossh.Marshal(key)...),
+	})
+
+	public := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(pub)))
+	if comment != "" {
+		public += " " + comment
+	}
+	return public, privateKeyPEM, nil
+}
+
+var errHostKeyScanned = errors.New("host key scanned")
+
+// ScanHostKey connects to the SSH server at addr and returns its host key like ssh-keyscan does.
+// The connection is closed right after the key exchange, the client does not authenticate.
+func ScanHostKey(ctx context.Context, addr string) (gossh.PublicKey, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var hostKey gossh.PublicKey
+	_, _, _, err = gossh.NewClientConn(conn, addr, &gossh.ClientConfig{
+		HostKeyCallback: func(_ string, _ net.Addr, key gossh.PublicKey) error {
+			hostKey = key
+			return errHostKeyScanned
+		},
+	})
+	if hostKey != nil {
+		return hostKey, nil
+	}
+	return nil, err
+}
+
+// ParseHostKey parses a host key in the authorized_keys format like "ssh-ed25519 AAAA..."
+// or in the known_hosts format of the output of ssh-keyscan. The first key is returned.
+func ParseHostKey(s string) (gossh.PublicKey, error) {
+	if marker, _, key, _, _, err := gossh.ParseKnownHosts([]byte(s)); err == nil && marker == "" {
+		return key, nil
+	}
+	key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(s))
+	return key, err
+}
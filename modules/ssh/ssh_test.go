@@ -0,0 +1,85 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestGenEd25519KeyPair(t *testing.T) {
+	public, private, err := GenEd25519KeyPair("push-mirror")
+	assert.NoError(t, err)
+
+	pub, comment, _, _, err := gossh.ParseAuthorizedKey([]byte(public))
+	assert.NoError(t, err)
+	assert.Equal(t, gossh.KeyAlgoED25519, pub.Type())
+	assert.Equal(t, "push-mirror", comment)
+
+	key, err := gossh.ParseRawPrivateKey(private)
+	assert.NoError(t, err)
+	privateKey, ok := key.(*ed25519.PrivateKey)
+	if assert.True(t, ok) {
+		signer, err := gossh.NewSignerFromKey(*privateKey)
+		assert.NoError(t, err)
+		assert.Equal(t, pub.Marshal(), signer.PublicKey().Marshal())
+	}
+}
+
+func TestScanHostKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signer, err := gossh.NewSignerFromKey(privateKey)
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		config := &gossh.ServerConfig{NoClientAuth: true}
+		config.AddHostKey(signer)
+		_, _, _, _ = gossh.NewServerConn(conn, config)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	hostKey, err := ScanHostKey(ctx, listener.Addr().String())
+	assert.NoError(t, err)
+	if assert.NotNil(t, hostKey) {
+		assert.Equal(t, signer.PublicKey().Marshal(), hostKey.Marshal())
+	}
+}
+
+func TestParseHostKey(t *testing.T) {
+	public, _, err := GenEd25519KeyPair("")
+	assert.NoError(t, err)
+
+	for _, s := range []string{
+		public,
+		"example.com " + public,
+		"# example.com:22 SSH-2.0-OpenSSH_8.9\n[example.com]:2222 " + public + "\n",
+	} {
+		key, err := ParseHostKey(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, public, string(gossh.MarshalAuthorizedKey(key)[:len(public)]), s)
+		}
+	}
+
+	_, err = ParseHostKey("not a key")
+	assert.Error(t, err)
+}
@@ -42,6 +42,7 @@ type EditUserOption struct {
 	AllowGitHook            *bool   `json:"allow_git_hook"`
 	AllowImportLocal        *bool   `json:"allow_import_local"`
 	MaxRepoCreation         *int    `json:"max_repo_creation"`
+	QuotaSize               *int64  `json:"quota_size"`
 	ProhibitLogin           *bool   `json:"prohibit_login"`
 	AllowCreateOrganization *bool   `json:"allow_create_organization"`
 	Restricted              *bool   `json:"restricted"`
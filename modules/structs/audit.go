@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// AuditEvent represents a security relevant action recorded in the audit log
+type AuditEvent struct {
+	ID     int64  `json:"id"`
+	Action string `json:"action"`
+	// 0 if the action was performed anonymously, for example a failed sign-in
+	ActorID    int64  `json:"actor_id"`
+	ActorName  string `json:"actor_name"`
+	IP         string `json:"ip"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	TargetName string `json:"target_name"`
+	// the user or organization owning the target
+	OwnerID int64               `json:"owner_id"`
+	Changes []*AuditEventChange `json:"changes"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// AuditEventChange represents a field changed by an audited action
+type AuditEventChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
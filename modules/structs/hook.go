@@ -111,6 +111,11 @@ var (
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
 	_ Payloader = &PackagePayload{}
+	_ Payloader = &CommitStatusPayload{}
+	_ Payloader = &WikiPayload{}
+	_ Payloader = &StarPayload{}
+	_ Payloader = &MemberPayload{}
+	_ Payloader = &TeamPayload{}
 )
 
 // _________                        __
@@ -450,3 +455,123 @@ type PackagePayload struct {
 func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// CommitStatusPayload represents a payload information of commit status event.
+type CommitStatusPayload struct {
+	ID           int64             `json:"id"`
+	SHA          string            `json:"sha"`
+	Context      string            `json:"context"`
+	State        CommitStatusState `json:"state"`
+	Description  string            `json:"description"`
+	TargetURL    string            `json:"target_url"`
+	PullRequests []*PullRequest    `json:"pull_requests"`
+	Repository   *Repository       `json:"repository"`
+	Sender       *User             `json:"sender"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// JSONPayload implements Payload
+func (p *CommitStatusPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookWikiAction an action that happens to a wiki page
+type HookWikiAction string
+
+const (
+	// HookWikiCreated created
+	HookWikiCreated HookWikiAction = "created"
+	// HookWikiEdited edited
+	HookWikiEdited HookWikiAction = "edited"
+	// HookWikiDeleted deleted
+	HookWikiDeleted HookWikiAction = "deleted"
+	// HookWikiRenamed renamed
+	HookWikiRenamed HookWikiAction = "renamed"
+)
+
+// WikiPayload represents a payload information of wiki event.
+type WikiPayload struct {
+	Action     HookWikiAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+	Page       string         `json:"page"`
+	OldPage    string         `json:"old_page,omitempty"`
+	Comment    string         `json:"comment"`
+}
+
+// JSONPayload implements Payload
+func (p *WikiPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookStarAction an action that happens to a star
+type HookStarAction string
+
+const (
+	// HookStarStarred starred
+	HookStarStarred HookStarAction = "starred"
+	// HookStarUnstarred unstarred
+	HookStarUnstarred HookStarAction = "unstarred"
+)
+
+// StarPayload represents a payload information of star event.
+type StarPayload struct {
+	Action     HookStarAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *StarPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookMemberAction an action that happens to a team member
+type HookMemberAction string
+
+const (
+	// HookMemberAdded added
+	HookMemberAdded HookMemberAction = "added"
+	// HookMemberRemoved removed
+	HookMemberRemoved HookMemberAction = "removed"
+)
+
+// MemberPayload represents a payload information of organization member event.
+type MemberPayload struct {
+	Action       HookMemberAction `json:"action"`
+	Member       *User            `json:"member"`
+	Team         *Team            `json:"team"`
+	Organization *Organization    `json:"organization"`
+	Sender       *User            `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *MemberPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookTeamAction an action that happens to a team
+type HookTeamAction string
+
+const (
+	// HookTeamCreated created
+	HookTeamCreated HookTeamAction = "created"
+	// HookTeamEdited edited
+	HookTeamEdited HookTeamAction = "edited"
+	// HookTeamDeleted deleted
+	HookTeamDeleted HookTeamAction = "deleted"
+)
+
+// TeamPayload represents a payload information of team event.
+type TeamPayload struct {
+	Action       HookTeamAction `json:"action"`
+	Team         *Team          `json:"team"`
+	Organization *Organization  `json:"organization"`
+	Sender       *User          `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *TeamPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
@@ -12,10 +12,14 @@ import (
 // AccessToken represents an API access token.
 // swagger:response AccessToken
 type AccessToken struct {
-	ID             int64  `json:"id"`
-	Name           string `json:"name"`
-	Token          string `json:"sha1"`
-	TokenLastEight string `json:"token_last_eight"`
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Token          string   `json:"sha1"`
+	TokenLastEight string   `json:"token_last_eight"`
+	Scopes         []string `json:"scopes"`
+	// swagger:strfmt date-time
+	ExpiresAt    *time.Time `json:"expires_at"`
+	AllowedRepos []string   `json:"allowed_repos"`
 }
 
 // AccessTokenList represents a list of API access token.
@@ -26,6 +30,13 @@ type AccessTokenList []*AccessToken
 // swagger:parameters userCreateToken
 type CreateAccessTokenOption struct {
 	Name string `json:"name" binding:"Required"`
+	// scopes of the token (all, repo:read, repo:write, issue, package:read, package:write, org, user, admin), all if empty
+	Scopes []string `json:"scopes"`
+	// the token can't be used after this date, it never expires if empty
+	// swagger:strfmt date-time
+	ExpiresAt *time.Time `json:"expires_at"`
+	// repositories ("owner/repo") or owners ("owner") the token is restricted to, all if empty
+	AllowedRepos []string `json:"allowed_repos"`
 }
 
 // CreateOAuth2ApplicationOptions holds options to create an oauth2 application
@@ -9,14 +9,19 @@ import (
 	"regexp"
 	"strings"
 
+	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/alpine"
+	"code.gitea.io/gitea/routers/api/packages/cargo"
 	"code.gitea.io/gitea/routers/api/packages/composer"
 	"code.gitea.io/gitea/routers/api/packages/conan"
 	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/debian"
 	"code.gitea.io/gitea/routers/api/packages/generic"
+	"code.gitea.io/gitea/routers/api/packages/goproxy"
 	"code.gitea.io/gitea/routers/api/packages/helm"
 	"code.gitea.io/gitea/routers/api/packages/maven"
 	"code.gitea.io/gitea/routers/api/packages/npm"
@@ -37,6 +42,43 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 	}
 }
 
+// reqPackageWriteAccess requires write access to the package whose name is read from the request by nameFn.
+// Users with write access to the repository linked to the package are allowed too.
+func reqPackageWriteAccess(packageType packages_model.Type, nameFn func(ctx *context.Context) string) func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		canWrite, err := ctx.Package.CanWrite(ctx, packageType, nameFn(ctx))
+		if err != nil {
+			ctx.ServerError("CanWrite", err)
+			return
+		}
+		if !canWrite {
+			reqPackageAccess(perm.AccessModeWrite)(ctx)
+		}
+	}
+}
+
+// reqPackageUploadAccess is used by upload routes which get the package name from the request body.
+// Users with write access to a repository linked to a package of the type pass, the handler must
+// verify the access with helper.CheckPackageWriteAccess after the package name is known.
+func reqPackageUploadAccess(packageType packages_model.Type) func(ctx *context.Context) {
+	return func(ctx *context.Context) {
+		canWrite, err := ctx.Package.CanWriteAnyLinked(ctx, packageType)
+		if err != nil {
+			ctx.ServerError("CanWriteAnyLinked", err)
+			return
+		}
+		if !canWrite {
+			reqPackageAccess(perm.AccessModeWrite)(ctx)
+		}
+	}
+}
+
+func paramFn(name string) func(ctx *context.Context) string {
+	return func(ctx *context.Context) string {
+		return ctx.Params(name)
+	}
+}
+
 func Routes() *web.Route {
 	r := web.NewRoute()
 
@@ -55,8 +97,44 @@ func Routes() *web.Route {
 	r.Use(func(ctx *context.Context) {
 		ctx.Doer = authGroup.Verify(ctx.Req, ctx.Resp, ctx, ctx.Session)
 	})
+	r.Use(context.RateLimit())
+
+	reqConanWriteAccess := reqPackageWriteAccess(packages_model.TypeConan, paramFn("name"))
 
 	r.Group("/{username}", func() {
+		r.Group("/alpine", func() {
+			r.Get("/key", alpine.GetRepositoryKey)
+			r.Group("/{branch}/{repository}", func() {
+				r.Put("", reqPackageUploadAccess(packages_model.TypeAlpine), alpine.UploadPackageFile)
+				r.Group("/{architecture}", func() {
+					r.Get("/APKINDEX.tar.gz", alpine.GetRepositoryFile)
+					r.Group("/{filename}", func() {
+						r.Get("", alpine.DownloadPackageFile)
+						r.Delete("", reqPackageUploadAccess(packages_model.TypeAlpine), alpine.DeletePackageFile)
+					})
+				})
+			})
+		})
+		r.Group("/cargo", func() {
+			r.Group("/api/v1/crates", func() {
+				r.Get("", cargo.SearchPackages)
+				r.Put("/new", reqPackageUploadAccess(packages_model.TypeCargo), cargo.UploadPackage)
+				r.Group("/{package}", func() {
+					r.Group("/{version}", func() {
+						r.Get("/download", cargo.DownloadPackageFile)
+						r.Delete("/yank", reqPackageWriteAccess(packages_model.TypeCargo, paramFn("package")), cargo.YankPackage)
+						r.Put("/unyank", reqPackageWriteAccess(packages_model.TypeCargo, paramFn("package")), cargo.UnyankPackage)
+					})
+					r.Get("/owners", cargo.ListOwners)
+				})
+			})
+			r.Get("/config.json", cargo.RepositoryConfig)
+			r.Get("/1/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/2/{package}", cargo.EnumeratePackageVersions)
+			// Use dummy placeholders because these parts are not of interest
+			r.Get("/3/{_}/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/{_}/{__}/{package}", cargo.EnumeratePackageVersions)
+		})
 		r.Group("/composer", func() {
 			r.Get("/packages.json", composer.ServiceIndex)
 			r.Get("/search.json", composer.SearchPackages)
@@ -64,7 +142,7 @@ func Routes() *web.Route {
 			r.Get("/p2/{vendorname}/{projectname}~dev.json", composer.PackageMetadata)
 			r.Get("/p2/{vendorname}/{projectname}.json", composer.PackageMetadata)
 			r.Get("/files/{package}/{version}/{filename}", composer.DownloadPackageFile)
-			r.Put("", reqPackageAccess(perm.AccessModeWrite), composer.UploadPackage)
+			r.Put("", reqPackageUploadAccess(packages_model.TypeComposer), composer.UploadPackage)
 		})
 		r.Group("/conan", func() {
 			r.Group("/v1", func() {
@@ -77,17 +155,17 @@ func Routes() *web.Route {
 					r.Get("/search", conan.SearchRecipes)
 					r.Group("/{name}/{version}/{user}/{channel}", func() {
 						r.Get("", conan.RecipeSnapshot)
-						r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeleteRecipeV1)
+						r.Delete("", reqConanWriteAccess, conan.DeleteRecipeV1)
 						r.Get("/search", conan.SearchPackagesV1)
 						r.Get("/digest", conan.RecipeDownloadURLs)
-						r.Post("/upload_urls", reqPackageAccess(perm.AccessModeWrite), conan.RecipeUploadURLs)
+						r.Post("/upload_urls", reqConanWriteAccess, conan.RecipeUploadURLs)
 						r.Get("/download_urls", conan.RecipeDownloadURLs)
 						r.Group("/packages", func() {
-							r.Post("/delete", reqPackageAccess(perm.AccessModeWrite), conan.DeletePackageV1)
+							r.Post("/delete", reqConanWriteAccess, conan.DeletePackageV1)
 							r.Group("/{package_reference}", func() {
 								r.Get("", conan.PackageSnapshot)
 								r.Get("/digest", conan.PackageDownloadURLs)
-								r.Post("/upload_urls", reqPackageAccess(perm.AccessModeWrite), conan.PackageUploadURLs)
+								r.Post("/upload_urls", reqConanWriteAccess, conan.PackageUploadURLs)
 								r.Get("/download_urls", conan.PackageDownloadURLs)
 							})
 						})
@@ -96,11 +174,11 @@ func Routes() *web.Route {
 				r.Group("/files/{name}/{version}/{user}/{channel}/{recipe_revision}", func() {
 					r.Group("/recipe/{filename}", func() {
 						r.Get("", conan.DownloadRecipeFile)
-						r.Put("", reqPackageAccess(perm.AccessModeWrite), conan.UploadRecipeFile)
+						r.Put("", reqConanWriteAccess, conan.UploadRecipeFile)
 					})
 					r.Group("/package/{package_reference}/{package_revision}/{filename}", func() {
 						r.Get("", conan.DownloadPackageFile)
-						r.Put("", reqPackageAccess(perm.AccessModeWrite), conan.UploadPackageFile)
+						r.Put("", reqConanWriteAccess, conan.UploadPackageFile)
 					})
 				}, conan.ExtractPathParameters)
 			})
@@ -113,35 +191,35 @@ func Routes() *web.Route {
 				r.Group("/conans", func() {
 					r.Get("/search", conan.SearchRecipes)
 					r.Group("/{name}/{version}/{user}/{channel}", func() {
-						r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeleteRecipeV2)
+						r.Delete("", reqConanWriteAccess, conan.DeleteRecipeV2)
 						r.Get("/search", conan.SearchPackagesV2)
 						r.Get("/latest", conan.LatestRecipeRevision)
 						r.Group("/revisions", func() {
 							r.Get("", conan.ListRecipeRevisions)
 							r.Group("/{recipe_revision}", func() {
-								r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeleteRecipeV2)
+								r.Delete("", reqConanWriteAccess, conan.DeleteRecipeV2)
 								r.Get("/search", conan.SearchPackagesV2)
 								r.Group("/files", func() {
 									r.Get("", conan.ListRecipeRevisionFiles)
 									r.Group("/{filename}", func() {
 										r.Get("", conan.DownloadRecipeFile)
-										r.Put("", reqPackageAccess(perm.AccessModeWrite), conan.UploadRecipeFile)
+										r.Put("", reqConanWriteAccess, conan.UploadRecipeFile)
 									})
 								})
 								r.Group("/packages", func() {
-									r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeletePackageV2)
+									r.Delete("", reqConanWriteAccess, conan.DeletePackageV2)
 									r.Group("/{package_reference}", func() {
-										r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeletePackageV2)
+										r.Delete("", reqConanWriteAccess, conan.DeletePackageV2)
 										r.Get("/latest", conan.LatestPackageRevision)
 										r.Group("/revisions", func() {
 											r.Get("", conan.ListPackageRevisions)
 											r.Group("/{package_revision}", func() {
-												r.Delete("", reqPackageAccess(perm.AccessModeWrite), conan.DeletePackageV2)
+												r.Delete("", reqConanWriteAccess, conan.DeletePackageV2)
 												r.Group("/files", func() {
 													r.Get("", conan.ListPackageRevisionFiles)
 													r.Group("/{filename}", func() {
 														r.Get("", conan.DownloadPackageFile)
-														r.Put("", reqPackageAccess(perm.AccessModeWrite), conan.UploadPackageFile)
+														r.Put("", reqConanWriteAccess, conan.UploadPackageFile)
 													})
 												})
 											})
@@ -154,22 +232,84 @@ func Routes() *web.Route {
 				})
 			})
 		})
+		r.Group("/debian", func() {
+			r.Get("/repository.key", debian.GetRepositoryKey)
+			r.Group("/dists/{distribution}", func() {
+				r.Get("/{filename}", debian.GetRepositoryFile)
+				r.Get("/{component}/{architecture}/{filename}", debian.GetRepositoryIndexFile)
+			})
+			r.Group("/pool/{distribution}/{component}", func() {
+				r.Get("/{name}_{version}_{architecture}.deb", debian.DownloadPackageFile)
+				r.Put("/upload", reqPackageUploadAccess(packages_model.TypeDebian), debian.UploadPackageFile)
+				r.Delete("/{name}/{version}/{architecture}", reqPackageWriteAccess(packages_model.TypeDebian, paramFn("name")), debian.DeletePackageFile)
+			})
+		})
 		r.Group("/generic", func() {
 			r.Group("/{packagename}/{packageversion}/{filename}", func() {
 				r.Get("", generic.DownloadPackageFile)
 				r.Group("", func() {
 					r.Put("", generic.UploadPackage)
 					r.Delete("", generic.DeletePackage)
-				}, reqPackageAccess(perm.AccessModeWrite))
+				}, reqPackageWriteAccess(packages_model.TypeGeneric, paramFn("packagename")))
+			})
+		})
+		r.Group("/go", func() {
+			r.Put("/upload", reqPackageUploadAccess(packages_model.TypeGo), goproxy.UploadPackage)
+			r.Get("/sumdb/sum.golang.org/supported", func(ctx *context.Context) {
+				// the checksum database is not proxied, the go command should use it directly
+				ctx.Status(http.StatusNotFound)
+			})
+
+			// Manual mapping of routes because the module path can contain slashes which chi does not support
+			r.Get("/*", func(ctx *context.Context) {
+				path := ctx.Params("*")
+
+				if strings.HasSuffix(path, "/@latest") {
+					if !goproxy.SetModuleParams(ctx, path[:len(path)-len("/@latest")], "") {
+						return
+					}
+					goproxy.LatestPackageVersion(ctx)
+					return
+				}
+
+				parts := strings.SplitN(path, "/@v/", 2)
+				if len(parts) != 2 {
+					ctx.Status(http.StatusNotFound)
+					return
+				}
+
+				if parts[1] == "list" {
+					if !goproxy.SetModuleParams(ctx, parts[0], "") {
+						return
+					}
+					goproxy.EnumeratePackageVersions(ctx)
+					return
+				}
+
+				for suffix, handler := range map[string]func(*context.Context){
+					".info": goproxy.PackageVersionMetadata,
+					".mod":  goproxy.PackageVersionGoModContent,
+					".zip":  goproxy.DownloadPackageFile,
+				} {
+					if strings.HasSuffix(parts[1], suffix) {
+						if !goproxy.SetModuleParams(ctx, parts[0], parts[1][:len(parts[1])-len(suffix)]) {
+							return
+						}
+						handler(ctx)
+						return
+					}
+				}
+
+				ctx.Status(http.StatusNotFound)
 			})
 		})
 		r.Group("/helm", func() {
 			r.Get("/index.yaml", helm.Index)
 			r.Get("/{filename}", helm.DownloadPackageFile)
-			r.Post("/api/charts", reqPackageAccess(perm.AccessModeWrite), helm.UploadPackage)
+			r.Post("/api/charts", reqPackageUploadAccess(packages_model.TypeHelm), helm.UploadPackage)
 		})
 		r.Group("/maven", func() {
-			r.Put("/*", reqPackageAccess(perm.AccessModeWrite), maven.UploadPackageFile)
+			r.Put("/*", reqPackageUploadAccess(packages_model.TypeMaven), maven.UploadPackageFile)
 			r.Get("/*", maven.DownloadPackageFile)
 		})
 		r.Group("/nuget", func() {
@@ -186,19 +326,19 @@ func Routes() *web.Route {
 			r.Group("", func() {
 				r.Put("/", nuget.UploadPackage)
 				r.Put("/symbolpackage", nuget.UploadSymbolPackage)
-				r.Delete("/{id}/{version}", nuget.DeletePackage)
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqPackageUploadAccess(packages_model.TypeNuGet))
+			r.Delete("/{id}/{version}", reqPackageWriteAccess(packages_model.TypeNuGet, paramFn("id")), nuget.DeletePackage)
 			r.Get("/symbols/{filename}/{guid:[0-9a-f]{32}}FFFFFFFF/{filename2}", nuget.DownloadSymbolFile)
 		})
 		r.Group("/npm", func() {
 			r.Group("/@{scope}/{id}", func() {
 				r.Get("", npm.PackageMetadata)
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), npm.UploadPackage)
+				r.Put("", reqPackageWriteAccess(packages_model.TypeNpm, npm.PackageNameFromParams), npm.UploadPackage)
 				r.Get("/-/{version}/{filename}", npm.DownloadPackageFile)
 			})
 			r.Group("/{id}", func() {
 				r.Get("", npm.PackageMetadata)
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), npm.UploadPackage)
+				r.Put("", reqPackageWriteAccess(packages_model.TypeNpm, npm.PackageNameFromParams), npm.UploadPackage)
 				r.Get("/-/{version}/{filename}", npm.DownloadPackageFile)
 			})
 			r.Group("/-/package/@{scope}/{id}/dist-tags", func() {
@@ -206,18 +346,18 @@ func Routes() *web.Route {
 				r.Group("/{tag}", func() {
 					r.Put("", npm.AddPackageTag)
 					r.Delete("", npm.DeletePackageTag)
-				}, reqPackageAccess(perm.AccessModeWrite))
+				}, reqPackageWriteAccess(packages_model.TypeNpm, npm.PackageNameFromParams))
 			})
 			r.Group("/-/package/{id}/dist-tags", func() {
 				r.Get("", npm.ListPackageTags)
 				r.Group("/{tag}", func() {
 					r.Put("", npm.AddPackageTag)
 					r.Delete("", npm.DeletePackageTag)
-				}, reqPackageAccess(perm.AccessModeWrite))
+				}, reqPackageWriteAccess(packages_model.TypeNpm, npm.PackageNameFromParams))
 			})
 		})
 		r.Group("/pypi", func() {
-			r.Post("/", reqPackageAccess(perm.AccessModeWrite), pypi.UploadPackageFile)
+			r.Post("/", reqPackageUploadAccess(packages_model.TypePyPI), pypi.UploadPackageFile)
 			r.Get("/files/{id}/{version}/{filename}", pypi.DownloadPackageFile)
 			r.Get("/simple/{id}", pypi.PackageMetadata)
 		})
@@ -230,7 +370,7 @@ func Routes() *web.Route {
 			r.Group("/api/v1/gems", func() {
 				r.Post("/", rubygems.UploadPackageFile)
 				r.Delete("/yank", rubygems.DeletePackage)
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqPackageUploadAccess(packages_model.TypeRubyGems))
 		})
 	}, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
 
@@ -254,6 +394,9 @@ func ContainerRoutes() *web.Route {
 	r.Use(func(ctx *context.Context) {
 		ctx.Doer = authGroup.Verify(ctx.Req, ctx.Resp, ctx, ctx.Session)
 	})
+	r.Use(context.RateLimit())
+
+	reqContainerWriteAccess := reqPackageWriteAccess(packages_model.TypeContainer, paramFn("image"))
 
 	r.Get("", container.ReqContainerAccess, container.DetermineSupport)
 	r.Get("/token", container.Authenticate)
@@ -265,17 +408,17 @@ func ContainerRoutes() *web.Route {
 					r.Patch("", container.UploadBlob)
 					r.Put("", container.EndUploadBlob)
 				})
-			}, reqPackageAccess(perm.AccessModeWrite))
+			}, reqContainerWriteAccess)
 			r.Group("/blobs/{digest}", func() {
 				r.Head("", container.HeadBlob)
 				r.Get("", container.GetBlob)
-				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteBlob)
+				r.Delete("", reqContainerWriteAccess, container.DeleteBlob)
 			})
 			r.Group("/manifests/{reference}", func() {
-				r.Put("", reqPackageAccess(perm.AccessModeWrite), container.UploadManifest)
+				r.Put("", reqContainerWriteAccess, container.UploadManifest)
 				r.Head("", container.HeadManifest)
 				r.Get("", container.GetManifest)
-				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteManifest)
+				r.Delete("", reqContainerWriteAccess, container.DeleteManifest)
 			})
 			r.Get("/tags/list", container.GetTagList)
 		}, container.VerifyImageName)
@@ -297,13 +440,13 @@ func ContainerRoutes() *web.Route {
 			isDelete := ctx.Req.Method == "DELETE"
 
 			if isPost && strings.HasSuffix(path, "/blobs/uploads") {
-				reqPackageAccess(perm.AccessModeWrite)(ctx)
+				ctx.SetParams("image", path[:len(path)-14])
+				container.VerifyImageName(ctx)
 				if ctx.Written() {
 					return
 				}
 
-				ctx.SetParams("image", path[:len(path)-14])
-				container.VerifyImageName(ctx)
+				reqContainerWriteAccess(ctx)
 				if ctx.Written() {
 					return
 				}
@@ -324,13 +467,13 @@ func ContainerRoutes() *web.Route {
 
 			m := blobsUploadsPattern.FindStringSubmatch(path)
 			if len(m) == 3 && (isPut || isPatch) {
-				reqPackageAccess(perm.AccessModeWrite)(ctx)
+				ctx.SetParams("image", m[1])
+				container.VerifyImageName(ctx)
 				if ctx.Written() {
 					return
 				}
 
-				ctx.SetParams("image", m[1])
-				container.VerifyImageName(ctx)
+				reqContainerWriteAccess(ctx)
 				if ctx.Written() {
 					return
 				}
@@ -359,7 +502,7 @@ func ContainerRoutes() *web.Route {
 				} else if isGet {
 					container.GetBlob(ctx)
 				} else {
-					reqPackageAccess(perm.AccessModeWrite)(ctx)
+					reqContainerWriteAccess(ctx)
 					if ctx.Written() {
 						return
 					}
@@ -382,7 +525,7 @@ func ContainerRoutes() *web.Route {
 				} else if isGet {
 					container.GetManifest(ctx)
 				} else {
-					reqPackageAccess(perm.AccessModeWrite)(ctx)
+					reqContainerWriteAccess(ctx)
 					if ctx.Written() {
 						return
 					}
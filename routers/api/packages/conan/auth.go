@@ -21,7 +21,7 @@ func (a *Auth) Name() string {
 
 // Verify extracts the user from the Bearer token
 func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataStore, sess auth.SessionStore) *user_model.User {
-	uid, err := packages.ParseAuthorizationToken(req)
+	uid, accessTokenID, err := packages.ParseAuthorizationToken(req)
 	if err != nil {
 		log.Trace("ParseAuthorizationToken: %v", err)
 		return nil
@@ -30,6 +30,9 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 	if uid == 0 {
 		return nil
 	}
+	if accessTokenID != 0 && auth.CheckAccessTokenByID(accessTokenID, store) == nil {
+		return nil
+	}
 
 	u, err := user_model.GetUserByID(uid)
 	if err != nil {
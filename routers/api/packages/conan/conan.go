@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
 	conan_model "code.gitea.io/gitea/models/packages/conan"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -118,7 +119,12 @@ func Authenticate(ctx *context.Context) {
 		return
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(ctx.Doer)
+	var accessTokenID int64
+	if t := ctx.AccessToken(); t != nil {
+		accessTokenID = t.ID
+	}
+
+	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, accessTokenID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -422,6 +428,10 @@ func uploadFile(ctx *context.Context, fileFilter stringSet, fileKey string) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
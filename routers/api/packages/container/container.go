@@ -16,6 +16,7 @@ import (
 
 	packages_model "code.gitea.io/gitea/models/packages"
 	container_model "code.gitea.io/gitea/models/packages/container"
+	quota_model "code.gitea.io/gitea/models/quota"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
@@ -141,7 +142,12 @@ func Authenticate(ctx *context.Context) {
 		u = user_model.NewGhostUser()
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(u)
+	var accessTokenID int64
+	if t := ctx.AccessToken(); t != nil {
+		accessTokenID = t.ID
+	}
+
+	token, err := packages_service.CreateAuthorizationToken(u, accessTokenID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -152,6 +158,19 @@ func Authenticate(ctx *context.Context) {
 	})
 }
 
+// checkQuota returns false and writes the error response if storing size bytes exceeds the quota of the owner
+func checkQuota(ctx *context.Context, size int64) bool {
+	if err := quota_model.CheckQuota(ctx, ctx.Package.Owner, size); err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()).WithStatusCode(http.StatusRequestEntityTooLarge))
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return false
+	}
+	return true
+}
+
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#mounting-a-blob-from-another-repository
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#single-post
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-a-blob-in-chunks
@@ -189,6 +208,10 @@ func InitiateUploadBlob(ctx *context.Context) {
 			return
 		}
 
+		if !checkQuota(ctx, buf.Size()) {
+			return
+		}
+
 		if _, err := saveAsPackageBlob(buf, &packages_service.PackageInfo{Owner: ctx.Package.Owner, Name: image}); err != nil {
 			apiError(ctx, http.StatusInternalServerError, err)
 			return
@@ -248,6 +271,15 @@ func UploadBlob(ctx *context.Context) {
 		return
 	}
 
+	// the size of streamed chunks is unknown
+	size := uploader.Size()
+	if ctx.Req.ContentLength > 0 {
+		size += ctx.Req.ContentLength
+	}
+	if !checkQuota(ctx, size) {
+		return
+	}
+
 	if err := uploader.Append(ctx, ctx.Req.Body); err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -281,6 +281,11 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 		}
 	}
 
+	if err := packages_service.LinkToRepositoryFromURL(ctx, p, mci.Owner, metadata.RepositoryURL); err != nil {
+		log.Error("Error linking package to repository: %v", err)
+		return nil, err
+	}
+
 	metadata.IsTagged = mci.IsTagged
 
 	metadataJSON, err := json.Marshal(metadata)
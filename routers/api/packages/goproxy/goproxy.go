@@ -0,0 +1,275 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package goproxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	goproxy_module "code.gitea.io/gitea/modules/packages/goproxy"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/hashicorp/go-version"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// VersionInfo is the response of the .info and @latest endpoints
+// https://go.dev/ref/mod#goproxy-protocol
+type VersionInfo struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+// EnumeratePackageVersions lists all known versions of a module
+func EnumeratePackageVersions(ctx *context.Context) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, ctx.Params("name"))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	sortVersions(pvs)
+
+	var sb strings.Builder
+	for _, pv := range pvs {
+		fmt.Fprintln(&sb, pv.Version)
+	}
+
+	ctx.PlainText(http.StatusOK, sb.String())
+}
+
+// PackageVersionMetadata returns the metadata of a module version
+func PackageVersionMetadata(ctx *context.Context) {
+	pv, err := resolvePackage(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &VersionInfo{
+		Version: pv.Version,
+		Time:    pv.CreatedUnix.AsTime(),
+	})
+}
+
+// PackageVersionGoModContent serves the go.mod file of a module version
+func PackageVersionGoModContent(ctx *context.Context) {
+	pv, err := resolvePackage(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeVersion, pv.ID, goproxy_module.PropertyGoMod)
+	if err != nil || len(pps) != 1 {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.PlainText(http.StatusOK, pps[0].Value)
+}
+
+// DownloadPackageFile serves the module zip of a module version
+func DownloadPackageFile(ctx *context.Context) {
+	pv, err := resolvePackage(ctx)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil || len(pfs) != 1 {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, _, err := packages_service.GetPackageFileStream(ctx, pfs[0])
+	if err != nil {
+		if err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pfs[0].Name)
+}
+
+// LatestPackageVersion returns the metadata of the latest module version
+func LatestPackageVersion(ctx *context.Context) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeGo, ctx.Params("name"))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	sortVersions(pvs)
+
+	// prefer the highest release version over pre-release versions
+	latest := pvs[len(pvs)-1]
+	for i := len(pvs) - 1; i >= 0; i-- {
+		if v, err := version.NewSemver(pvs[i].Version); err == nil && v.Prerelease() == "" {
+			latest = pvs[i]
+			break
+		}
+	}
+
+	ctx.JSON(http.StatusOK, &VersionInfo{
+		Version: latest.Version,
+		Time:    latest.CreatedUnix.AsTime(),
+	})
+}
+
+// UploadPackage adds a module zip.
+// The module path and version are taken from the zip structure.
+func UploadPackage(ctx *context.Context) {
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		log.Error("Error creating hashed buffer: %v", err)
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := goproxy_module.ParsePackage(buf, buf.Size())
+	if err != nil {
+		if errors.Is(err, goproxy_module.ErrInvalidStructure) ||
+			errors.Is(err, goproxy_module.ErrInvalidModule) ||
+			errors.Is(err, goproxy_module.ErrModulePathMismatch) ||
+			errors.Is(err, goproxy_module.ErrGoModFileTooLarge) {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeGo, pck.Name, apiError) {
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeGo,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Properties: map[string]string{
+				goproxy_module.PropertyGoMod: pck.GoMod,
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: fmt.Sprintf("%v.zip", pck.Version),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrDuplicatePackageVersion {
+			apiError(ctx, http.StatusConflict, err)
+			return
+		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// SetModuleParams decodes the escaped module path and version of a GOPROXY request into the context params
+func SetModuleParams(ctx *context.Context, escapedName, escapedVersion string) bool {
+	name, ok := goproxy_module.UnescapePath(escapedName)
+	if !ok {
+		apiError(ctx, http.StatusBadRequest, errors.New("invalid module path"))
+		return false
+	}
+	ver, ok := goproxy_module.UnescapePath(escapedVersion)
+	if !ok {
+		apiError(ctx, http.StatusBadRequest, errors.New("invalid module version"))
+		return false
+	}
+	ctx.SetParams("name", name)
+	ctx.SetParams("version", ver)
+	return true
+}
+
+func resolvePackage(ctx *context.Context) (*packages_model.PackageVersion, error) {
+	return packages_model.GetVersionByNameAndVersion(db.DefaultContext, ctx.Package.Owner.ID, packages_model.TypeGo, ctx.Params("name"), ctx.Params("version"))
+}
+
+// sortVersions sorts the versions ascending by semantic version
+func sortVersions(pvs []*packages_model.PackageVersion) {
+	sort.Slice(pvs, func(i, j int) bool {
+		vi, erri := version.NewSemver(pvs[i].Version)
+		vj, errj := version.NewSemver(pvs[j].Version)
+		if erri != nil || errj != nil {
+			return pvs[i].Version < pvs[j].Version
+		}
+		return vi.LessThan(vj)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -162,6 +163,10 @@ func UploadPackage(ctx *context.Context) {
 		return
 	}
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeHelm, metadata.Name, apiError) {
+		return
+	}
+
 	if _, err := buf.Seek(0, io.SeekStart); err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -193,6 +198,10 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusConflict, err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
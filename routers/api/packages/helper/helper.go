@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 
+	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -36,3 +37,20 @@ func LogAndProcessError(ctx *context.Context, status int, obj interface{}, cb fu
 		cb(message)
 	}
 }
+
+// CheckPackageWriteAccess checks if the doer may modify the package with the given type and name.
+// Upload handlers which know the package name only after reading the request call it before
+// the package gets modified. If the access is denied the error is passed to errCb.
+func CheckPackageWriteAccess(ctx *context.Context, packageType packages_model.Type, name string, errCb func(*context.Context, int, interface{})) bool {
+	canWrite, err := ctx.Package.CanWrite(ctx, packageType, name)
+	if err != nil {
+		errCb(ctx, http.StatusInternalServerError, err)
+		return false
+	}
+	if !canWrite {
+		ctx.Resp.Header().Set("WWW-Authenticate", `Basic realm="Gitea Package API"`)
+		errCb(ctx, http.StatusUnauthorized, "user should have specific permission or be a site admin")
+		return false
+	}
+	return true
+}
@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	nuget_module "code.gitea.io/gitea/modules/packages/nuget"
@@ -193,6 +194,10 @@ func UploadPackage(ctx *context.Context) {
 		return
 	}
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeNuGet, np.ID, apiError) {
+		return
+	}
+
 	_, _, err := packages_service.CreatePackageAndAddFile(
 		&packages_service.PackageCreationInfo{
 			PackageInfo: packages_service.PackageInfo{
@@ -218,6 +223,10 @@ func UploadPackage(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -238,6 +247,10 @@ func UploadSymbolPackage(ctx *context.Context) {
 		return
 	}
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeNuGet, np.ID, apiError) {
+		return
+	}
+
 	pdbs, err := nuget_module.ExtractPortablePdb(buf, buf.Size())
 	if err != nil {
 		apiError(ctx, http.StatusBadRequest, err)
@@ -268,6 +281,10 @@ func UploadSymbolPackage(ctx *context.Context) {
 		},
 	)
 	if err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		switch err {
 		case packages_model.ErrPackageNotExist:
 			apiError(ctx, http.StatusNotFound, err)
@@ -295,6 +312,10 @@ func UploadSymbolPackage(ctx *context.Context) {
 			},
 		)
 		if err != nil {
+			if quota_model.IsErrQuotaExceeded(err) {
+				apiError(ctx, http.StatusRequestEntityTooLarge, err)
+				return
+			}
 			switch err {
 			case packages_model.ErrDuplicatePackageFile:
 				apiError(ctx, http.StatusBadRequest, err)
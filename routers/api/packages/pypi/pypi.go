@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
@@ -128,6 +129,10 @@ func UploadPackageFile(ctx *context.Context) {
 		return
 	}
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypePyPI, packageName, apiError) {
+		return
+	}
+
 	projectURL := ctx.Req.FormValue("home_page")
 	if !validation.IsValidURL(projectURL) {
 		projectURL = ""
@@ -166,6 +171,10 @@ func UploadPackageFile(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
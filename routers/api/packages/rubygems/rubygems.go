@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/modules/context"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	rubygems_module "code.gitea.io/gitea/modules/packages/rubygems"
@@ -217,6 +218,10 @@ func UploadPackageFile(ctx *context.Context) {
 		return
 	}
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeRubyGems, rp.Name, apiError) {
+		return
+	}
+
 	var filename string
 	if rp.Metadata.Platform == "" || rp.Metadata.Platform == "ruby" {
 		filename = strings.ToLower(fmt.Sprintf("%s-%s.gem", rp.Name, rp.Version))
@@ -249,6 +254,10 @@ func UploadPackageFile(ctx *context.Context) {
 			apiError(ctx, http.StatusBadRequest, err)
 			return
 		}
+		if quota_model.IsErrQuotaExceeded(err) {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -266,6 +275,10 @@ func DeletePackage(ctx *context.Context) {
 	packageName := ctx.FormString("gem_name")
 	packageVersion := ctx.FormString("version")
 
+	if !helper.CheckPackageWriteAccess(ctx, packages_model.TypeRubyGems, packageName, apiError) {
+		return
+	}
+
 	err := packages_service.RemovePackageVersionByNameAndVersion(
 		ctx.Doer,
 		&packages_service.PackageInfo{
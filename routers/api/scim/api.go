@@ -0,0 +1,259 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package scim implements a SCIM 2.0 endpoint which allows identity providers to provision
+// users and the teams of an organization (RFC 7643, RFC 7644).
+//
+// Identity providers authenticate with a bearer token issued by a site administrator
+// with `gitea admin scim create-token`. A token belongs to an organization whose teams are
+// exposed as groups.
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const scimTokenKey = "SCIMToken"
+
+// Routes returns the routes of the SCIM endpoint
+func Routes() *web.Route {
+	r := web.NewRoute()
+
+	r.Use(context.APIContexter())
+	r.Use(reqToken())
+
+	r.Get("/ServiceProviderConfig", ServiceProviderConfig)
+	r.Get("/ResourceTypes", ResourceTypes)
+	r.Group("/Users", func() {
+		r.Get("", ListUsers)
+		r.Post("", CreateUser)
+		r.Group("/{id}", func() {
+			r.Get("", GetUser)
+			r.Put("", ReplaceUser)
+			r.Patch("", PatchUser)
+			r.Delete("", DeleteUser)
+		})
+	})
+	r.Group("/Groups", func() {
+		r.Get("", ListGroups)
+		r.Post("", CreateGroup)
+		r.Group("/{id}", func() {
+			r.Get("", GetGroup)
+			r.Put("", ReplaceGroup)
+			r.Patch("", PatchGroup)
+			r.Delete("", DeleteGroup)
+		})
+	})
+
+	return r
+}
+
+// reqToken authenticates the identity provider with its bearer token and loads the organization of the token
+func reqToken() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		var token string
+		if auth := ctx.Req.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			token = strings.TrimSpace(auth[7:])
+		}
+		if token == "" {
+			ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="Gitea SCIM"`)
+			writeError(ctx, http.StatusUnauthorized, "", "a bearer token is required")
+			return
+		}
+
+		t, err := auth_model.GetSCIMTokenByToken(token)
+		if err != nil {
+			if auth_model.IsErrSCIMTokenNotExist(err) {
+				log.Info("Failed SCIM authentication attempt from %s", ctx.RemoteAddr())
+				ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="Gitea SCIM", error="invalid_token"`)
+				writeError(ctx, http.StatusUnauthorized, "", "invalid token")
+			} else {
+				serverError(ctx, "GetSCIMTokenByToken", err)
+			}
+			return
+		}
+
+		org, err := organization.GetOrgByID(t.OrgID)
+		if err != nil {
+			if organization.IsErrOrgNotExist(err) {
+				writeError(ctx, http.StatusUnauthorized, "", "the organization of the token does not exist")
+			} else {
+				serverError(ctx, "GetOrgByID", err)
+			}
+			return
+		}
+
+		if err := auth_model.UpdateSCIMTokenLastUsed(t); err != nil {
+			log.Error("UpdateSCIMTokenLastUsed: %v", err)
+		}
+
+		ctx.Org.Organization = org
+		ctx.Data[scimTokenKey] = t
+	}
+}
+
+func getToken(ctx *context.APIContext) *auth_model.SCIMToken {
+	return ctx.Data[scimTokenKey].(*auth_model.SCIMToken)
+}
+
+// getDoer returns the user recorded as the doer of changes made by the identity provider
+func getDoer(ctx *context.APIContext) *user_model.User {
+	return ctx.Org.Organization.AsUser()
+}
+
+func baseURL() string {
+	return setting.AppURL + "scim/v2/"
+}
+
+func writeJSON(ctx *context.APIContext, status int, obj interface{}) {
+	ctx.Resp.Header().Set("Content-Type", "application/scim+json; charset=utf-8")
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("Error writing SCIM response: %v", err)
+	}
+}
+
+func writeError(ctx *context.APIContext, status int, scimType, detail string) {
+	writeJSON(ctx, status, &Error{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func serverError(ctx *context.APIContext, name string, err error) {
+	log.ErrorWithSkip(1, "%s: %v", name, err)
+	writeError(ctx, http.StatusInternalServerError, "", "internal server error")
+}
+
+// decodeBody decodes the JSON body of the request. It writes an error response if the body is invalid.
+func decodeBody(ctx *context.APIContext, v interface{}) bool {
+	if err := json.NewDecoder(ctx.Req.Body).Decode(v); err != nil {
+		writeError(ctx, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return false
+	}
+	return true
+}
+
+// getFilter parses the filter parameter of a query. It writes an error response if the filter is not supported.
+func getFilter(ctx *context.APIContext) (*filter, bool) {
+	s := ctx.FormString("filter")
+	if s == "" {
+		return nil, true
+	}
+	f, err := parseFilter(s)
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, "invalidFilter", err.Error())
+		return nil, false
+	}
+	return f, true
+}
+
+// getListOptions converts the startIndex and count parameters of a query.
+// The start index is rounded down to a multiple of the page size, the returned start index is the one used.
+func getListOptions(ctx *context.APIContext) (db.ListOptions, int) {
+	count := ctx.FormInt("count")
+	if count <= 0 {
+		count = setting.API.DefaultPagingNum
+	}
+	if count > setting.API.MaxResponseItems {
+		count = setting.API.MaxResponseItems
+	}
+	startIndex := ctx.FormInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	page := (startIndex-1)/count + 1
+	return db.ListOptions{Page: page, PageSize: count}, (page-1)*count + 1
+}
+
+func writeListResponse(ctx *context.APIContext, resources []interface{}, total int64, startIndex int) {
+	writeJSON(ctx, http.StatusOK, &ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// parseID parses the id parameter. It writes an error response if the id is invalid.
+func parseID(ctx *context.APIContext) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(ctx, http.StatusNotFound, "", "resource not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// ServiceProviderConfig describes the supported features
+func ServiceProviderConfig(ctx *context.APIContext) {
+	supported := func(b bool) map[string]interface{} {
+		return map[string]interface{}{"supported": b}
+	}
+	writeJSON(ctx, http.StatusOK, map[string]interface{}{
+		"schemas":          []string{schemaServiceProviderConfig},
+		"documentationUri": "https://docs.gitea.io/en-us/authentication/#scim-20-provisioning",
+		"patch":            supported(true),
+		"bulk": map[string]interface{}{
+			"supported":      false,
+			"maxOperations":  0,
+			"maxPayloadSize": 0,
+		},
+		"filter": map[string]interface{}{
+			"supported":  true,
+			"maxResults": setting.API.MaxResponseItems,
+		},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]interface{}{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "Bearer Token",
+				"description": "Authentication with a token issued by a site administrator",
+				"primary":     true,
+			},
+		},
+		"meta": &Meta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     baseURL() + "ServiceProviderConfig",
+		},
+	})
+}
+
+// ResourceTypes lists the supported resource types
+func ResourceTypes(ctx *context.APIContext) {
+	resourceType := func(name, endpoint, schema string) interface{} {
+		return map[string]interface{}{
+			"schemas":  []string{schemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta": &Meta{
+				ResourceType: "ResourceType",
+				Location:     baseURL() + "ResourceTypes/" + name,
+			},
+		}
+	}
+	writeListResponse(ctx, []interface{}{
+		resourceType("User", "/Users", schemaUser),
+		resourceType("Group", "/Groups", schemaGroup),
+	}, 2, 1)
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// filter is an attribute filter of the form `attribute eq "value"`.
+// Other operators and logical expressions are not supported.
+type filter struct {
+	Attribute string // lower case
+	Value     string
+}
+
+// attributePath is a parsed PATCH path like `emails[type eq "work"].value`
+type attributePath struct {
+	Attribute    string // lower case
+	Filter       *filter
+	SubAttribute string // lower case
+}
+
+func splitToken(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+func parseFilter(s string) (*filter, error) {
+	attribute, rest := splitToken(s)
+	op, value := splitToken(rest)
+	if attribute == "" || op == "" || value == "" {
+		return nil, fmt.Errorf("invalid filter %q", s)
+	}
+	if !strings.EqualFold(op, "eq") {
+		return nil, fmt.Errorf("unsupported filter operator %q", op)
+	}
+
+	if strings.HasPrefix(value, `"`) {
+		var v string
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("invalid filter value %s", value)
+		}
+		value = v
+	} else if strings.ContainsAny(value, " \t") {
+		return nil, fmt.Errorf("unsupported filter %q", s)
+	}
+
+	return &filter{
+		Attribute: strings.ToLower(attribute),
+		Value:     value,
+	}, nil
+}
+
+func parsePath(path string) (*attributePath, error) {
+	lower := strings.ToLower(path)
+	for _, schema := range []string{schemaUser, schemaGroup} {
+		if prefix := strings.ToLower(schema) + ":"; strings.HasPrefix(lower, prefix) {
+			path = path[len(prefix):]
+			lower = lower[len(prefix):]
+		}
+	}
+	// attributes of extension schemas are not supported, keep them as they are
+	if strings.HasPrefix(lower, "urn:") {
+		return &attributePath{Attribute: lower}, nil
+	}
+
+	p := &attributePath{}
+	if i := strings.IndexByte(path, '['); i >= 0 {
+		j := strings.LastIndexByte(path, ']')
+		if j < i {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		f, err := parseFilter(path[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+		p.Filter = f
+		if rest := path[j+1:]; rest != "" {
+			if rest[0] != '.' {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			p.SubAttribute = strings.ToLower(rest[1:])
+		}
+		path = path[:i]
+	} else if i := strings.IndexByte(path, '.'); i >= 0 {
+		p.SubAttribute = strings.ToLower(path[i+1:])
+		path = path[:i]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	p.Attribute = strings.ToLower(path)
+	return p, nil
+}
@@ -0,0 +1,432 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	org_service "code.gitea.io/gitea/services/org"
+
+	"gitea.com/go-chi/binding"
+)
+
+func groupLocation(id int64) string {
+	return baseURL() + "Groups/" + strconv.FormatInt(id, 10)
+}
+
+func toGroup(t *organization.Team, withMembers bool) (*Group, error) {
+	group := &Group{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Location:     groupLocation(t.ID),
+		},
+	}
+	if withMembers {
+		if err := t.GetMembersCtx(db.DefaultContext); err != nil {
+			return nil, err
+		}
+		for _, u := range t.Members {
+			group.Members = append(group.Members, Member{
+				Value:   strconv.FormatInt(u.ID, 10),
+				Display: u.Name,
+				Ref:     userLocation(u.ID),
+			})
+		}
+	}
+	return group, nil
+}
+
+// excludesMembers checks if the client requested to omit the members which can be expensive for large teams
+func excludesMembers(ctx *context.APIContext) bool {
+	for _, attribute := range strings.Split(ctx.FormString("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attribute), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeGroup(ctx *context.APIContext, status int, t *organization.Team) {
+	group, err := toGroup(t, !excludesMembers(ctx))
+	if err != nil {
+		serverError(ctx, "toGroup", err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Resp.Header().Set("Location", group.Meta.Location)
+	}
+	writeJSON(ctx, status, group)
+}
+
+func writeGroupError(ctx *context.APIContext, name string, err error) {
+	switch {
+	case organization.IsErrTeamAlreadyExist(err):
+		writeError(ctx, http.StatusConflict, "uniqueness", err.Error())
+	case organization.IsErrLastOrgOwner(err):
+		writeError(ctx, http.StatusBadRequest, "mutability", "the last owner of the organization can't be removed")
+	case isErrInvalidValue(err), user_model.IsErrUserNotExist(err), db.IsErrNameReserved(err):
+		writeError(ctx, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		serverError(ctx, name, err)
+	}
+}
+
+// getGroup returns the team of the id parameter. It writes an error response if the team does not belong to the organization of the token.
+func getGroup(ctx *context.APIContext) *organization.Team {
+	id, ok := parseID(ctx)
+	if !ok {
+		return nil
+	}
+	t, err := organization.GetTeamByID(id)
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			writeError(ctx, http.StatusNotFound, "", "group not found")
+		} else {
+			serverError(ctx, "GetTeamByID", err)
+		}
+		return nil
+	}
+	if t.OrgID != ctx.Org.Organization.ID {
+		writeError(ctx, http.StatusNotFound, "", "group not found")
+		return nil
+	}
+	return t
+}
+
+func validateTeamName(name string) error {
+	if name == "" || len(name) > 30 || binding.AlphaDashDotPattern.MatchString(name) {
+		return invalidValue("invalid displayName %q, it must be a valid team name", name)
+	}
+	return nil
+}
+
+func renameTeam(ctx *context.APIContext, t *organization.Team, name string) error {
+	if name == t.Name {
+		return nil
+	}
+	if err := validateTeamName(name); err != nil {
+		return err
+	}
+	if t.IsOwnerTeam() {
+		return invalidValue("the owners team can't be renamed")
+	}
+	t.Name = name
+	t.LowerName = strings.ToLower(name)
+	return org_service.UpdateTeam(ctx, getDoer(ctx), t, false, false)
+}
+
+// parseMembers returns the user ids of the members
+func parseMembers(value interface{}) ([]int64, error) {
+	var members []Member
+	if err := convertValue(value, &members); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member.Value, 10, 64)
+		if err != nil {
+			return nil, invalidValue("invalid member %q", member.Value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// addMembers adds the users to the team. Only users which are managed by the token can be added.
+func addMembers(ctx *context.APIContext, t *organization.Team, ids []int64) error {
+	for _, id := range ids {
+		isMember, err := organization.IsTeamMember(ctx, t.OrgID, t.ID, id)
+		if err != nil {
+			return err
+		} else if isMember {
+			continue
+		}
+
+		u, err := user_model.GetUserByID(id)
+		if err != nil {
+			return err
+		}
+		managed, err := organization.IsManagedUser(ctx, ctx.Org.Organization.ID, getToken(ctx).SourceID, u)
+		if err != nil {
+			return err
+		}
+		if !managed {
+			return invalidValue("invalid member %d", id)
+		}
+		if err := org_service.AddTeamMember(ctx, getDoer(ctx), t, u.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeMembers(ctx *context.APIContext, t *organization.Team, ids []int64) error {
+	for _, id := range ids {
+		if err := org_service.RemoveTeamMember(ctx, getDoer(ctx), t, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMembers changes the members of the team to the given users
+func setMembers(ctx *context.APIContext, t *organization.Team, ids []int64) error {
+	// add first to keep the owners team from becoming empty
+	if err := addMembers(ctx, t, ids); err != nil {
+		return err
+	}
+
+	keep := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	if err := t.GetMembersCtx(db.DefaultContext); err != nil {
+		return err
+	}
+	var remove []int64
+	for _, u := range t.Members {
+		if !keep[u.ID] {
+			remove = append(remove, u.ID)
+		}
+	}
+	return removeMembers(ctx, t, remove)
+}
+
+// ListGroups lists or searches the teams of the organization
+func ListGroups(ctx *context.APIContext) {
+	f, ok := getFilter(ctx)
+	if !ok {
+		return
+	}
+
+	var teams []*organization.Team
+	var total int64
+	listOptions, startIndex := getListOptions(ctx)
+
+	if f != nil {
+		var t *organization.Team
+		var err error
+		switch f.Attribute {
+		case "displayname":
+			t, err = organization.GetTeam(ctx.Org.Organization.ID, f.Value)
+		case "id":
+			if id, _ := strconv.ParseInt(f.Value, 10, 64); id > 0 {
+				t, err = organization.GetTeamByID(id)
+			}
+		case "externalid":
+			// external ids are not stored, the identity provider falls back to the displayName
+		default:
+			writeError(ctx, http.StatusBadRequest, "invalidFilter", "unsupported filter attribute "+f.Attribute)
+			return
+		}
+		if err != nil && !organization.IsErrTeamNotExist(err) {
+			serverError(ctx, "GetTeam", err)
+			return
+		}
+		if t != nil && t.OrgID == ctx.Org.Organization.ID {
+			teams = append(teams, t)
+			total = 1
+		}
+		startIndex = 1
+	} else {
+		var err error
+		teams, total, err = organization.SearchTeam(&organization.SearchTeamOptions{
+			OrgID:       ctx.Org.Organization.ID,
+			ListOptions: listOptions,
+		})
+		if err != nil {
+			serverError(ctx, "SearchTeam", err)
+			return
+		}
+	}
+
+	withMembers := !excludesMembers(ctx)
+	resources := make([]interface{}, 0, len(teams))
+	for _, t := range teams {
+		group, err := toGroup(t, withMembers)
+		if err != nil {
+			serverError(ctx, "toGroup", err)
+			return
+		}
+		resources = append(resources, group)
+	}
+	writeListResponse(ctx, resources, total, startIndex)
+}
+
+// GetGroup returns a team
+func GetGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if ctx.Written() {
+		return
+	}
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// CreateGroup creates a team with read permission. Repositories are added to the team by the organization owners.
+func CreateGroup(ctx *context.APIContext) {
+	var group Group
+	if !decodeBody(ctx, &group) {
+		return
+	}
+	if err := validateTeamName(group.DisplayName); err != nil {
+		writeGroupError(ctx, "validateTeamName", err)
+		return
+	}
+
+	members, err := parseMembers(group.Members)
+	if err != nil {
+		writeGroupError(ctx, "parseMembers", err)
+		return
+	}
+
+	t := &organization.Team{
+		OrgID:      ctx.Org.Organization.ID,
+		Name:       group.DisplayName,
+		AccessMode: perm.AccessModeRead,
+	}
+	for _, tp := range unit_model.AllRepoUnitTypes {
+		t.Units = append(t.Units, &organization.TeamUnit{
+			OrgID:      t.OrgID,
+			Type:       tp,
+			AccessMode: perm.AccessModeRead,
+		})
+	}
+	if err := org_service.NewTeam(ctx, getDoer(ctx), t); err != nil {
+		writeGroupError(ctx, "NewTeam", err)
+		return
+	}
+	if err := addMembers(ctx, t, members); err != nil {
+		writeGroupError(ctx, "addMembers", err)
+		return
+	}
+	log.Trace("SCIM: team %s created in organization %s", t.Name, ctx.Org.Organization.Name)
+
+	writeGroup(ctx, http.StatusCreated, t)
+}
+
+// ReplaceGroup changes the name and the members of a team
+func ReplaceGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var group Group
+	if !decodeBody(ctx, &group) {
+		return
+	}
+	if err := renameTeam(ctx, t, group.DisplayName); err != nil {
+		writeGroupError(ctx, "renameTeam", err)
+		return
+	}
+	ids, err := parseMembers(group.Members)
+	if err == nil {
+		err = setMembers(ctx, t, ids)
+	}
+	if err != nil {
+		writeGroupError(ctx, "setMembers", err)
+		return
+	}
+
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// PatchGroup changes the name of a team or adds and removes members
+func PatchGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var req PatchRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	err := applyPatch(&req, func(op string, path *attributePath, value interface{}) error {
+		switch path.Attribute {
+		case "displayname":
+			if op == "remove" {
+				return invalidValue("displayName can't be removed")
+			}
+			name, err := stringValue(value)
+			if err != nil {
+				return err
+			}
+			return renameTeam(ctx, t, name)
+		case "members":
+			if path.Filter != nil {
+				// members[value eq "id"]
+				if op != "remove" || path.Filter.Attribute != "value" {
+					return invalidValue("unsupported members filter")
+				}
+				id, err := strconv.ParseInt(path.Filter.Value, 10, 64)
+				if err != nil {
+					return invalidValue("invalid member %q", path.Filter.Value)
+				}
+				return removeMembers(ctx, t, []int64{id})
+			}
+
+			var ids []int64
+			if value != nil {
+				var err error
+				if ids, err = parseMembers(value); err != nil {
+					return err
+				}
+			}
+			switch op {
+			case "add":
+				return addMembers(ctx, t, ids)
+			case "replace":
+				return setMembers(ctx, t, ids)
+			default:
+				if value == nil {
+					return setMembers(ctx, t, nil)
+				}
+				return removeMembers(ctx, t, ids)
+			}
+		}
+		// attributes which can't be stored are ignored
+		return nil
+	})
+	if err != nil {
+		writeGroupError(ctx, "PatchGroup", err)
+		return
+	}
+
+	writeGroup(ctx, http.StatusOK, t)
+}
+
+// DeleteGroup deletes a team
+func DeleteGroup(ctx *context.APIContext) {
+	t := getGroup(ctx)
+	if ctx.Written() {
+		return
+	}
+	if t.IsOwnerTeam() {
+		writeError(ctx, http.StatusBadRequest, "mutability", "the owners team can't be deleted")
+		return
+	}
+
+	if err := org_service.DeleteTeam(ctx, getDoer(ctx), t); err != nil {
+		serverError(ctx, "DeleteTeam", err)
+		return
+	}
+	log.Trace("SCIM: team %s deleted in organization %s", t.Name, ctx.Org.Organization.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
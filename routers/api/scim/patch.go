@@ -0,0 +1,104 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// errInvalidValue is returned if a request contains an invalid value
+type errInvalidValue struct {
+	Message string
+}
+
+func (err errInvalidValue) Error() string {
+	return err.Message
+}
+
+func invalidValue(format string, args ...interface{}) error {
+	return errInvalidValue{Message: fmt.Sprintf(format, args...)}
+}
+
+func isErrInvalidValue(err error) bool {
+	_, ok := err.(errInvalidValue)
+	return ok
+}
+
+func stringValue(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", invalidValue("invalid value %v, a string is required", v)
+	}
+	return s, nil
+}
+
+func boolValue(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		// some identity providers send booleans as strings
+		if parsed, err := strconv.ParseBool(strings.ToLower(b)); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, invalidValue("invalid value %v, a boolean is required", v)
+}
+
+// convertValue converts a decoded JSON value into the target type
+func convertValue(v, target interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return invalidValue("invalid value: %v", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return invalidValue("invalid value: %v", err)
+	}
+	return nil
+}
+
+// applyPatch calls apply for every operation of the request in order. The operation name is passed in lower case.
+// An operation without a path sets every attribute of its object value.
+func applyPatch(req *PatchRequest, apply func(op string, path *attributePath, value interface{}) error) error {
+	for _, operation := range req.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return invalidValue("invalid operation %q", operation.Op)
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return invalidValue("remove operations require a path")
+			}
+			values, ok := operation.Value.(map[string]interface{})
+			if !ok {
+				return invalidValue("operations without a path require an object value")
+			}
+			for key, value := range values {
+				path, err := parsePath(key)
+				if err != nil {
+					return invalidValue("%v", err)
+				}
+				if err := apply(op, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := parsePath(operation.Path)
+		if err != nil {
+			return invalidValue("%v", err)
+		}
+		if err := apply(op, path, operation.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
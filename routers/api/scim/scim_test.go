@@ -0,0 +1,139 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter(`userName eq "Test.User@example.com"`)
+	assert.NoError(t, err)
+	assert.Equal(t, &filter{Attribute: "username", Value: "Test.User@example.com"}, f)
+
+	f, err = parseFilter(`displayName EQ "a \"quoted\" name"`)
+	assert.NoError(t, err)
+	assert.Equal(t, &filter{Attribute: "displayname", Value: `a "quoted" name`}, f)
+
+	f, err = parseFilter(`id eq 42`)
+	assert.NoError(t, err)
+	assert.Equal(t, &filter{Attribute: "id", Value: "42"}, f)
+
+	for _, s := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName co "test"`,
+		`userName eq "a" and active eq true`,
+		`userName eq "a`,
+		`userName eq a b`,
+	} {
+		_, err := parseFilter(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	cases := map[string]*attributePath{
+		"active":         {Attribute: "active"},
+		"name.givenName": {Attribute: "name", SubAttribute: "givenname"},
+		`emails[type eq "work"].value`: {
+			Attribute:    "emails",
+			Filter:       &filter{Attribute: "type", Value: "work"},
+			SubAttribute: "value",
+		},
+		`members[value eq "2"]`: {
+			Attribute: "members",
+			Filter:    &filter{Attribute: "value", Value: "2"},
+		},
+		"urn:ietf:params:scim:schemas:core:2.0:User:userName": {Attribute: "username"},
+		"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department": {
+			Attribute: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:user:department",
+		},
+	}
+	for path, expected := range cases {
+		p, err := parsePath(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expected, p, path)
+	}
+
+	for _, path := range []string{"", "emails[type]", `emails[type eq "work"]value`, `[value eq "1"]`} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestPatchUser(t *testing.T) {
+	// operations sent by common identity providers
+	body := `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "Add", "path": "emails[type eq \"work\"].value", "value": "new@example.com"},
+			{"op": "replace", "value": {"userName": "new-name", "name": {"givenName": "New", "familyName": "Name"}, "title": "ignored"}},
+			{"op": "remove", "path": "title"}
+		]
+	}`
+	var req PatchRequest
+	assert.NoError(t, json.NewDecoder(strings.NewReader(body)).Decode(&req))
+
+	update := &userUpdate{}
+	var ops []string
+	err := applyPatch(&req, func(op string, path *attributePath, value interface{}) error {
+		ops = append(ops, op)
+		if op == "remove" {
+			return nil
+		}
+		return update.set(path, value)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replace", "add", "replace", "replace", "replace", "remove"}, ops)
+	if assert.NotNil(t, update.Active) {
+		assert.False(t, *update.Active)
+	}
+	if assert.NotNil(t, update.Email) {
+		assert.Equal(t, "new@example.com", *update.Email)
+	}
+	if assert.NotNil(t, update.UserName) {
+		assert.Equal(t, "new-name", *update.UserName)
+	}
+	if assert.NotNil(t, update.FullName) {
+		assert.Equal(t, "New Name", *update.FullName)
+	}
+
+	for _, body := range []string{
+		`{"Operations": [{"op": "move", "path": "active", "value": true}]}`,
+		`{"Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`,
+		`{"Operations": [{"op": "replace", "path": "userName", "value": 1}]}`,
+		`{"Operations": [{"op": "remove"}]}`,
+		`{"Operations": [{"op": "replace", "value": "active"}]}`,
+	} {
+		var req PatchRequest
+		assert.NoError(t, json.NewDecoder(strings.NewReader(body)).Decode(&req))
+		err := applyPatch(&req, func(op string, path *attributePath, value interface{}) error {
+			return (&userUpdate{}).set(path, value)
+		})
+		assert.True(t, isErrInvalidValue(err), body)
+	}
+}
+
+func TestParseMembers(t *testing.T) {
+	ids, err := parseMembers([]interface{}{
+		map[string]interface{}{"value": "1"},
+		map[string]interface{}{"value": "23", "display": "user23"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 23}, ids)
+
+	_, err = parseMembers([]interface{}{map[string]interface{}{"value": "user"}})
+	assert.True(t, isErrInvalidValue(err))
+	_, err = parseMembers("1")
+	assert.True(t, isErrInvalidValue(err))
+}
@@ -0,0 +1,483 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/audit"
+	org_service "code.gitea.io/gitea/services/org"
+)
+
+func userLocation(id int64) string {
+	return baseURL() + "Users/" + strconv.FormatInt(id, 10)
+}
+
+func toUser(ctx *context.APIContext, u *user_model.User) (*User, error) {
+	active := u.IsActive && !u.ProhibitLogin
+	created := u.CreatedUnix.AsTime()
+	modified := u.UpdatedUnix.AsTime()
+
+	user := &User{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.DisplayName(),
+		Emails: []Email{
+			{
+				Value:   u.Email,
+				Type:    "work",
+				Primary: true,
+			},
+		},
+		Active: &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     userLocation(u.ID),
+		},
+	}
+	if u.FullName != "" {
+		user.Name = &Name{Formatted: u.FullName}
+	}
+
+	teams, err := organization.GetUserOrgTeams(db.DefaultContext, ctx.Org.Organization.ID, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range teams {
+		user.Groups = append(user.Groups, Member{
+			Value:   strconv.FormatInt(t.ID, 10),
+			Display: t.Name,
+			Ref:     groupLocation(t.ID),
+		})
+	}
+	return user, nil
+}
+
+// userUpdate contains the attributes of a user which are changed by a request
+type userUpdate struct {
+	UserName *string
+	Email    *string
+	FullName *string
+	Active   *bool
+}
+
+func fullNameOf(user *User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.Name == nil {
+		return ""
+	}
+	if user.Name.Formatted != "" {
+		return user.Name.Formatted
+	}
+	return strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+}
+
+func primaryEmailOf(emails []Email) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+func newUserUpdate(user *User) *userUpdate {
+	update := &userUpdate{
+		UserName: &user.UserName,
+		Active:   user.Active,
+	}
+	if email := primaryEmailOf(user.Emails); email != "" {
+		update.Email = &email
+	}
+	fullName := fullNameOf(user)
+	update.FullName = &fullName
+	return update
+}
+
+// set applies the value of a PATCH operation to the update. Attributes which can't be stored are ignored.
+func (update *userUpdate) set(path *attributePath, value interface{}) error {
+	switch path.Attribute {
+	case "active":
+		active, err := boolValue(value)
+		if err != nil {
+			return err
+		}
+		update.Active = &active
+	case "username":
+		s, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		update.UserName = &s
+	case "displayname":
+		s, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		update.FullName = &s
+	case "name":
+		if path.SubAttribute == "" {
+			var name Name
+			if err := convertValue(value, &name); err != nil {
+				return err
+			}
+			fullName := fullNameOf(&User{Name: &name})
+			update.FullName = &fullName
+		} else if path.SubAttribute == "formatted" {
+			s, err := stringValue(value)
+			if err != nil {
+				return err
+			}
+			update.FullName = &s
+		}
+	case "emails":
+		if path.SubAttribute == "value" {
+			s, err := stringValue(value)
+			if err != nil {
+				return err
+			}
+			update.Email = &s
+		} else if path.SubAttribute == "" {
+			var emails []Email
+			if err := convertValue(value, &emails); err != nil {
+				return err
+			}
+			if email := primaryEmailOf(emails); email != "" {
+				update.Email = &email
+			}
+		}
+	}
+	return nil
+}
+
+// applyUserUpdate stores the changed attributes. It writes an error response if the update fails.
+func applyUserUpdate(ctx *context.APIContext, u *user_model.User, update *userUpdate) bool {
+	before := *u
+
+	if update.UserName != nil && *update.UserName != "" && *update.UserName != u.Name {
+		if err := user_model.ChangeUserName(u, *update.UserName); err != nil {
+			writeUserError(ctx, "ChangeUserName", err)
+			return false
+		}
+		u.Name = *update.UserName
+		u.LowerName = strings.ToLower(u.Name)
+	}
+
+	emailChanged := false
+	if update.Email != nil && *update.Email != "" && !strings.EqualFold(*update.Email, u.Email) {
+		if err := user_model.ValidateEmail(*update.Email); err != nil {
+			writeUserError(ctx, "ValidateEmail", err)
+			return false
+		}
+		if used, err := user_model.IsEmailUsed(db.DefaultContext, *update.Email); err != nil {
+			serverError(ctx, "IsEmailUsed", err)
+			return false
+		} else if used {
+			writeUserError(ctx, "IsEmailUsed", user_model.ErrEmailAlreadyUsed{Email: *update.Email})
+			return false
+		}
+		u.Email = *update.Email
+		emailChanged = true
+	}
+	if update.FullName != nil {
+		u.FullName = *update.FullName
+	}
+	if update.Active != nil {
+		u.ProhibitLogin = !*update.Active
+		if *update.Active {
+			u.IsActive = true
+		}
+	}
+
+	if err := user_model.UpdateUser(u, emailChanged, "email", "full_name", "prohibit_login", "is_active"); err != nil {
+		writeUserError(ctx, "UpdateUser", err)
+		return false
+	}
+	audit.Record(getDoer(ctx), ctx.RemoteAddr(), audit_model.ActionAdminUserEdit, audit.UserTarget(u), audit.UserChanges(&before, u)...)
+	return true
+}
+
+func writeUserError(ctx *context.APIContext, name string, err error) {
+	switch {
+	case user_model.IsErrUserAlreadyExist(err), user_model.IsErrEmailAlreadyUsed(err):
+		writeError(ctx, http.StatusConflict, "uniqueness", err.Error())
+	case user_model.IsErrEmailCharIsNotSupported(err), user_model.IsErrEmailInvalid(err),
+		db.IsErrNameReserved(err), db.IsErrNamePatternNotAllowed(err), db.IsErrNameCharsNotAllowed(err):
+		writeError(ctx, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		serverError(ctx, name, err)
+	}
+}
+
+func writeUser(ctx *context.APIContext, status int, u *user_model.User) {
+	user, err := toUser(ctx, u)
+	if err != nil {
+		serverError(ctx, "toUser", err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Resp.Header().Set("Location", user.Meta.Location)
+	}
+	writeJSON(ctx, status, user)
+}
+
+// getUser returns the individual user of the id parameter. It writes an error response if the user does not exist
+// or is not managed by the token: only the members of the organization and the users of the authentication source
+// of the token can be changed, site administrators never.
+func getUser(ctx *context.APIContext) *user_model.User {
+	id, ok := parseID(ctx)
+	if !ok {
+		return nil
+	}
+	u, err := user_model.GetUserByID(id)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			writeError(ctx, http.StatusNotFound, "", "user not found")
+		} else {
+			serverError(ctx, "GetUserByID", err)
+		}
+		return nil
+	}
+	managed, err := organization.IsManagedUser(ctx, ctx.Org.Organization.ID, getToken(ctx).SourceID, u)
+	if err != nil {
+		serverError(ctx, "IsManagedUser", err)
+		return nil
+	}
+	if !managed {
+		writeError(ctx, http.StatusNotFound, "", "user not found")
+		return nil
+	}
+	return u
+}
+
+// ListUsers lists or searches users
+func ListUsers(ctx *context.APIContext) {
+	f, ok := getFilter(ctx)
+	if !ok {
+		return
+	}
+
+	var users []*user_model.User
+	var total int64
+	listOptions, startIndex := getListOptions(ctx)
+
+	if f != nil {
+		var u *user_model.User
+		var err error
+		switch f.Attribute {
+		case "username":
+			u, err = user_model.GetUserByName(f.Value)
+		case "emails", "emails.value":
+			u, err = user_model.GetUserByEmail(f.Value)
+		case "id":
+			if id, _ := strconv.ParseInt(f.Value, 10, 64); id > 0 {
+				u, err = user_model.GetUserByID(id)
+			}
+		case "externalid":
+			// external ids are not stored, the identity provider falls back to the userName
+		default:
+			writeError(ctx, http.StatusBadRequest, "invalidFilter", "unsupported filter attribute "+f.Attribute)
+			return
+		}
+		if err != nil && !user_model.IsErrUserNotExist(err) {
+			serverError(ctx, "GetUser", err)
+			return
+		}
+		if u != nil {
+			managed, err := organization.IsManagedUser(ctx, ctx.Org.Organization.ID, getToken(ctx).SourceID, u)
+			if err != nil {
+				serverError(ctx, "IsManagedUser", err)
+				return
+			}
+			if managed {
+				users = append(users, u)
+				total = 1
+			}
+		}
+		startIndex = 1
+	} else {
+		var err error
+		users, total, err = organization.FindManagedUsers(ctx, ctx.Org.Organization.ID, getToken(ctx).SourceID, listOptions)
+		if err != nil {
+			serverError(ctx, "FindManagedUsers", err)
+			return
+		}
+	}
+
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		user, err := toUser(ctx, u)
+		if err != nil {
+			serverError(ctx, "toUser", err)
+			return
+		}
+		resources = append(resources, user)
+	}
+	writeListResponse(ctx, resources, total, startIndex)
+}
+
+// GetUser returns a user
+func GetUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if ctx.Written() {
+		return
+	}
+	writeUser(ctx, http.StatusOK, u)
+}
+
+// CreateUser creates a user. The user is bound to the authentication source of the token.
+func CreateUser(ctx *context.APIContext) {
+	var user User
+	if !decodeBody(ctx, &user) {
+		return
+	}
+
+	email := primaryEmailOf(user.Emails)
+	if user.UserName == "" || email == "" {
+		writeError(ctx, http.StatusBadRequest, "invalidValue", "userName and emails are required")
+		return
+	}
+
+	u := &user_model.User{
+		Name:      user.UserName,
+		Email:     email,
+		FullName:  fullNameOf(&user),
+		LoginType: auth_model.Plain,
+		LoginName: user.UserName,
+	}
+	if user.Active != nil {
+		u.ProhibitLogin = !*user.Active
+	}
+
+	if sourceID := getToken(ctx).SourceID; sourceID > 0 {
+		source, err := auth_model.GetSourceByID(sourceID)
+		if err != nil {
+			serverError(ctx, "GetSourceByID", err)
+			return
+		}
+		u.LoginType = source.Type
+		u.LoginSource = source.ID
+	}
+
+	if err := user_model.CreateUser(u, &user_model.CreateUserOverwriteOptions{
+		IsActive: util.OptionalBoolTrue,
+	}); err != nil {
+		writeUserError(ctx, "CreateUser", err)
+		return
+	}
+	// local users are managed by the organization of the token, users of a source by the source
+	if u.LoginSource == 0 {
+		if err := organization.AddSCIMUser(db.DefaultContext, ctx.Org.Organization.ID, u.ID); err != nil {
+			serverError(ctx, "AddSCIMUser", err)
+			return
+		}
+	}
+	log.Trace("SCIM: user %s created for organization %s", u.Name, ctx.Org.Organization.Name)
+	audit.Record(getDoer(ctx), ctx.RemoteAddr(), audit_model.ActionAdminUserCreate, audit.UserTarget(u))
+
+	writeUser(ctx, http.StatusCreated, u)
+}
+
+// ReplaceUser updates all attributes of a user
+func ReplaceUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var user User
+	if !decodeBody(ctx, &user) {
+		return
+	}
+	if !applyUserUpdate(ctx, u, newUserUpdate(&user)) {
+		return
+	}
+	writeUser(ctx, http.StatusOK, u)
+}
+
+// PatchUser updates some attributes of a user
+func PatchUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var req PatchRequest
+	if !decodeBody(ctx, &req) {
+		return
+	}
+
+	update := &userUpdate{}
+	err := applyPatch(&req, func(op string, path *attributePath, value interface{}) error {
+		if op == "remove" {
+			// attributes can't be removed, ignore the operation
+			return nil
+		}
+		return update.set(path, value)
+	})
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	if !applyUserUpdate(ctx, u, update) {
+		return
+	}
+	writeUser(ctx, http.StatusOK, u)
+}
+
+// DeleteUser deactivates a user and removes it from the teams of the organization.
+// Like users removed from an LDAP directory, the user is not deleted to keep its repositories.
+func DeleteUser(ctx *context.APIContext) {
+	u := getUser(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	before := *u
+	u.ProhibitLogin = true
+	if err := user_model.UpdateUserCols(db.DefaultContext, u, "prohibit_login"); err != nil {
+		serverError(ctx, "UpdateUserCols", err)
+		return
+	}
+	audit.Record(getDoer(ctx), ctx.RemoteAddr(), audit_model.ActionAdminUserEdit, audit.UserTarget(u), audit.UserChanges(&before, u)...)
+
+	teams, err := organization.GetUserOrgTeams(db.DefaultContext, ctx.Org.Organization.ID, u.ID)
+	if err != nil {
+		serverError(ctx, "GetUserOrgTeams", err)
+		return
+	}
+	for _, t := range teams {
+		if err := org_service.RemoveTeamMember(ctx, getDoer(ctx), t, u.ID); err != nil {
+			if organization.IsErrLastOrgOwner(err) {
+				log.Warn("SCIM: user %s is the last owner of organization %s and can't be removed", u.Name, ctx.Org.Organization.Name)
+				continue
+			}
+			serverError(ctx, "RemoveTeamMember", err)
+			return
+		}
+	}
+	log.Trace("SCIM: user %s deactivated for organization %s", u.Name, ctx.Org.Organization.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
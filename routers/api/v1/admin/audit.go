@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ExportAuditEvents api for exporting the instance-wide audit log
+func ExportAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /admin/audit admin adminExportAuditEvents
+	// ---
+	// summary: Export the audit log as JSON lines, oldest event first
+	// produces:
+	// - application/x-ndjson
+	// parameters:
+	// - name: action
+	//   in: query
+	//   description: only export events of this action
+	//   type: string
+	// - name: actor
+	//   in: query
+	//   description: only export events performed by this user
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: only export events created at or after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: only export events created before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.ExportAuditEvents(ctx, 0)
+}
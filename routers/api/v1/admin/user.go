@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -27,6 +28,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
 )
@@ -136,6 +138,7 @@ func CreateUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserCreate, audit.UserTarget(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -172,6 +175,7 @@ func EditUser(ctx *context.APIContext) {
 	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditUserOption)
+	before := *ctx.ContextUser
 
 	parseAuthSource(ctx, ctx.ContextUser, form.SourceID, form.LoginName)
 	if ctx.Written() {
@@ -259,6 +263,9 @@ func EditUser(ctx *context.APIContext) {
 	if form.MaxRepoCreation != nil {
 		ctx.ContextUser.MaxRepoCreation = *form.MaxRepoCreation
 	}
+	if form.QuotaSize != nil {
+		ctx.ContextUser.QuotaSize = *form.QuotaSize
+	}
 	if form.AllowCreateOrganization != nil {
 		ctx.ContextUser.AllowCreateOrganization = *form.AllowCreateOrganization
 	}
@@ -280,6 +287,7 @@ func EditUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserEdit, audit.UserTarget(ctx.ContextUser), audit.UserChanges(&before, ctx.ContextUser)...)
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx.ContextUser, ctx.Doer))
 }
@@ -321,6 +329,7 @@ func DeleteUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account deleted by admin(%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserDelete, audit.UserTarget(ctx.ContextUser))
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -96,6 +96,7 @@ import (
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
 
 	"gitea.com/go-chi/binding"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 )
 
@@ -107,7 +108,7 @@ func sudo() func(ctx *context.APIContext) {
 		}
 
 		if len(sudo) > 0 {
-			if ctx.IsSigned && ctx.Doer.IsAdmin {
+			if ctx.IsUserSiteAdmin() {
 				user, err := user_model.GetUserByName(sudo)
 				if err != nil {
 					if user_model.IsErrUserNotExist(err) {
@@ -183,6 +184,11 @@ func repoAssignment() func(ctx *context.APIContext) {
 		repo.Owner = owner
 		ctx.Repo.Repository = repo
 
+		if t := ctx.AccessToken(); t != nil && !t.AllowsRepo(repo) {
+			ctx.Error(http.StatusForbidden, "repoAssignment", "token is not allowed to access the repository")
+			return
+		}
+
 		ctx.Repo.Permission, err = models.GetUserRepoPermission(ctx, repo, ctx.Doer)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
@@ -205,6 +211,67 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.APIContext)
 	}
 }
 
+// reqTokenScope checks the scope of the access token used to authenticate the request.
+// Reading requests need the read or the write scope, all other requests the write scope.
+func reqTokenScope(readScope, writeScope models.AccessTokenScope) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		checkTokenScope(ctx, readScope, writeScope)
+	}
+}
+
+// issueTokenScopeRoutes are the repository routes which can be changed with the issue scope
+var issueTokenScopeRoutes = []string{"/issues", "/labels", "/milestones", "/pulls"}
+
+// pullTokenScopeRoutes change the code of the repository and need the repo:write scope like all other repository routes
+var pullTokenScopeRoutes = []string{"/merge", "/update", "/merge_queue"}
+
+// reqRepoTokenScope checks the scope of the access token for the routes of a repository.
+// Issues, labels, milestones and pull requests can be changed with the issue scope, everything else needs repo:write.
+func reqRepoTokenScope() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		writeScope := models.AccessTokenScopeRepoWrite
+		route := chi.RouteContext(ctx.Req.Context()).RoutePattern()
+		if idx := strings.Index(route, "/{reponame}"); idx >= 0 {
+			route = route[idx+len("/{reponame}"):]
+		}
+		for _, prefix := range issueTokenScopeRoutes {
+			if strings.HasPrefix(route, prefix) {
+				writeScope = models.AccessTokenScopeIssue
+			}
+		}
+		for _, suffix := range pullTokenScopeRoutes {
+			if strings.HasSuffix(route, suffix) {
+				writeScope = models.AccessTokenScopeRepoWrite
+			}
+		}
+		checkTokenScope(ctx, models.AccessTokenScopeRepoRead, writeScope)
+	}
+}
+
+func checkTokenScope(ctx *context.APIContext, readScope, writeScope models.AccessTokenScope) {
+	t := ctx.AccessToken()
+	if t == nil || t.Scope.HasScope(writeScope) {
+		return
+	}
+	if ctx.Req.Method == http.MethodGet || ctx.Req.Method == http.MethodHead {
+		if t.Scope.HasScope(readScope) {
+			return
+		}
+		writeScope = readScope
+	}
+	ctx.Error(http.StatusForbidden, "reqTokenScope", fmt.Sprintf("token requires scope %s", writeScope))
+}
+
+// reqUnrestrictedToken rejects access tokens which are restricted to some repositories or organizations
+// for routes which don't target a single repository or organization.
+func reqUnrestrictedToken() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if t := ctx.AccessToken(); t != nil && t.IsRestricted() {
+			ctx.Error(http.StatusForbidden, "reqUnrestrictedToken", "token is restricted to some repositories")
+		}
+	}
+}
+
 // Contexter middleware already checks token for user sign in process.
 func reqToken() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
@@ -239,6 +306,11 @@ func reqBasicOrRevProxyAuth() func(ctx *context.APIContext) {
 			ctx.Error(http.StatusUnauthorized, "reqBasicOrRevProxyAuth", "auth required")
 			return
 		}
+		// an access token must not be able to create tokens with more permissions than its own
+		if t := ctx.AccessToken(); t != nil && (t.Scope != models.AccessTokenScopeAll || t.IsRestricted()) {
+			ctx.Error(http.StatusForbidden, "reqBasicOrRevProxyAuth", "token requires scope all")
+			return
+		}
 		ctx.CheckForOTP()
 	}
 }
@@ -464,6 +536,10 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				return
 			}
 			ctx.ContextUser = ctx.Org.Organization.AsUser()
+			if t := ctx.AccessToken(); t != nil && !t.AllowsOwner(ctx.Org.Organization.ID) {
+				ctx.Error(http.StatusForbidden, "orgAssignment", "token is not allowed to access the organization")
+				return
+			}
 		}
 
 		if assignTeam {
@@ -476,6 +552,10 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+			if t := ctx.AccessToken(); t != nil && !t.AllowsOwner(ctx.Org.Team.OrgID) {
+				ctx.Error(http.StatusForbidden, "orgAssignment", "token is not allowed to access the organization")
+				return
+			}
 		}
 	}
 }
@@ -627,6 +707,7 @@ func Routes() *web.Route {
 
 	// Get user from session if logged in.
 	m.Use(context.APIAuth(group))
+	m.Use(context.APIRateLimit())
 
 	m.Use(context.ToggleAPI(&context.ToggleOptions{
 		SignInRequired: setting.Service.RequireSignInView,
@@ -662,7 +743,7 @@ func Routes() *web.Route {
 			m.Combo("/threads/{id}").
 				Get(notify.GetThread).
 				Patch(notify.ReadThread)
-		}, reqToken())
+		}, reqToken(), reqTokenScope(models.AccessTokenScopeUser, models.AccessTokenScopeUser), reqUnrestrictedToken())
 
 		// Users
 		m.Group("/users", func() {
@@ -703,82 +784,88 @@ func Routes() *web.Route {
 
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
-			m.Group("/settings", func() {
-				m.Get("", user.GetUserSettings)
-				m.Patch("", bind(api.UserSettingsOptions{}), user.UpdateUserSettings)
-			}, reqToken())
-			m.Combo("/emails").Get(user.ListEmails).
-				Post(bind(api.CreateEmailOption{}), user.AddEmail).
-				Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
-
-			m.Get("/followers", user.ListMyFollowers)
-			m.Group("/following", func() {
-				m.Get("", user.ListMyFollowing)
-				m.Group("/{username}", func() {
-					m.Get("", user.CheckMyFollowing)
-					m.Put("", user.Follow)
-					m.Delete("", user.Unfollow)
-				}, context_service.UserAssignmentAPI())
-			})
+			m.Group("", func() {
+				m.Group("/settings", func() {
+					m.Get("", user.GetUserSettings)
+					m.Patch("", bind(api.UserSettingsOptions{}), user.UpdateUserSettings)
+				}, reqToken())
+				m.Combo("/emails").Get(user.ListEmails).
+					Post(bind(api.CreateEmailOption{}), user.AddEmail).
+					Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
 
-			m.Group("/keys", func() {
-				m.Combo("").Get(user.ListMyPublicKeys).
-					Post(bind(api.CreateKeyOption{}), user.CreatePublicKey)
-				m.Combo("/{id}").Get(user.GetPublicKey).
-					Delete(user.DeletePublicKey)
-			})
-			m.Group("/applications", func() {
-				m.Combo("/oauth2").
-					Get(user.ListOauth2Applications).
-					Post(bind(api.CreateOAuth2ApplicationOptions{}), user.CreateOauth2Application)
-				m.Combo("/oauth2/{id}").
-					Delete(user.DeleteOauth2Application).
-					Patch(bind(api.CreateOAuth2ApplicationOptions{}), user.UpdateOauth2Application).
-					Get(user.GetOauth2Application)
-			}, reqToken())
-
-			m.Group("/gpg_keys", func() {
-				m.Combo("").Get(user.ListMyGPGKeys).
-					Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
-				m.Combo("/{id}").Get(user.GetGPGKey).
-					Delete(user.DeleteGPGKey)
-			})
+				m.Get("/followers", user.ListMyFollowers)
+				m.Group("/following", func() {
+					m.Get("", user.ListMyFollowing)
+					m.Group("/{username}", func() {
+						m.Get("", user.CheckMyFollowing)
+						m.Put("", user.Follow)
+						m.Delete("", user.Unfollow)
+					}, context_service.UserAssignmentAPI())
+				})
 
-			m.Get("/gpg_key_token", user.GetVerificationToken)
-			m.Post("/gpg_key_verify", bind(api.VerifyGPGKeyOption{}), user.VerifyUserGPGKey)
+				m.Group("/keys", func() {
+					m.Combo("").Get(user.ListMyPublicKeys).
+						Post(bind(api.CreateKeyOption{}), user.CreatePublicKey)
+					m.Combo("/{id}").Get(user.GetPublicKey).
+						Delete(user.DeletePublicKey)
+				})
+				m.Group("/applications", func() {
+					m.Combo("/oauth2").
+						Get(user.ListOauth2Applications).
+						Post(bind(api.CreateOAuth2ApplicationOptions{}), user.CreateOauth2Application)
+					m.Combo("/oauth2/{id}").
+						Delete(user.DeleteOauth2Application).
+						Patch(bind(api.CreateOAuth2ApplicationOptions{}), user.UpdateOauth2Application).
+						Get(user.GetOauth2Application)
+				}, reqToken())
 
-			m.Combo("/repos").Get(user.ListMyRepos).
-				Post(bind(api.CreateRepoOption{}), repo.Create)
+				m.Group("/gpg_keys", func() {
+					m.Combo("").Get(user.ListMyGPGKeys).
+						Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
+					m.Combo("/{id}").Get(user.GetGPGKey).
+						Delete(user.DeleteGPGKey)
+				})
 
-			m.Group("/starred", func() {
-				m.Get("", user.GetMyStarredRepos)
-				m.Group("/{username}/{reponame}", func() {
-					m.Get("", user.IsStarring)
-					m.Put("", user.Star)
-					m.Delete("", user.Unstar)
-				}, repoAssignment())
-			})
-			m.Get("/times", repo.ListMyTrackedTimes)
+				m.Get("/gpg_key_token", user.GetVerificationToken)
+				m.Post("/gpg_key_verify", bind(api.VerifyGPGKeyOption{}), user.VerifyUserGPGKey)
 
-			m.Get("/stopwatches", repo.GetStopwatches)
+				m.Group("/starred", func() {
+					m.Get("", reqUnrestrictedToken(), user.GetMyStarredRepos)
+					m.Group("/{username}/{reponame}", func() {
+						m.Get("", user.IsStarring)
+						m.Put("", user.Star)
+						m.Delete("", user.Unstar)
+					}, repoAssignment())
+				})
+
+				m.Get("/subscriptions", reqUnrestrictedToken(), user.GetMyWatchedRepos)
+			}, reqTokenScope(models.AccessTokenScopeUser, models.AccessTokenScopeUser))
+
+			m.Combo("/repos", reqTokenScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite), reqUnrestrictedToken()).Get(user.ListMyRepos).
+				Post(bind(api.CreateRepoOption{}), repo.Create)
 
-			m.Get("/subscriptions", user.GetMyWatchedRepos)
+			m.Group("", func() {
+				m.Get("/times", repo.ListMyTrackedTimes)
+				m.Get("/stopwatches", repo.GetStopwatches)
+			}, reqTokenScope(models.AccessTokenScopeIssue, models.AccessTokenScopeIssue), reqUnrestrictedToken())
 
-			m.Get("/teams", org.ListUserTeams)
+			m.Get("/teams", reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg), reqUnrestrictedToken(), org.ListUserTeams)
 		}, reqToken())
 
 		// Repositories
-		m.Post("/org/{org}/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
+		m.Group("", func() {
+			m.Post("/org/{org}/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepoDeprecated)
 
-		m.Combo("/repositories/{id}", reqToken()).Get(repo.GetByID)
+			m.Combo("/repositories/{id}", reqToken()).Get(repo.GetByID)
 
-		m.Group("/repos", func() {
-			m.Get("/search", repo.Search)
+			m.Get("/repos/search", repo.Search)
 
-			m.Get("/issues/search", repo.SearchIssues)
+			m.Get("/repos/issues/search", repo.SearchIssues)
 
-			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
+			m.Post("/repos/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
+		}, reqTokenScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite), reqUnrestrictedToken())
 
+		m.Group("/repos", func() {
 			m.Group("/{username}/{reponame}", func() {
 				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
 					Delete(reqToken(), reqOwner(), repo.Delete).
@@ -984,7 +1071,9 @@ func Routes() *web.Route {
 						m.Post("/update", reqToken(), repo.UpdatePullRequest)
 						m.Get("/commits", repo.GetPullRequestCommits)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
-							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest)
+							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest).
+							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
+						m.Delete("/merge_queue", reqToken(), mustNotBeArchived, repo.RemoveFromMergeQueue)
 						m.Group("/reviews", func() {
 							m.Combo("").
 								Get(repo.ListPullReviews).
@@ -1049,7 +1138,7 @@ func Routes() *web.Route {
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(), repo.GetIssueTemplates)
 				m.Get("/languages", reqRepoReader(unit.TypeCode), repo.GetLanguages)
-			}, repoAssignment())
+			}, reqRepoTokenScope(), repoAssignment())
 		})
 
 		m.Group("/packages/{username}", func() {
@@ -1058,53 +1147,60 @@ func Routes() *web.Route {
 				m.Delete("", reqPackageAccess(perm.AccessModeWrite), packages.DeletePackage)
 				m.Get("/files", packages.ListPackageFiles)
 			})
+			m.Group("/{type}/{name}/-", func() {
+				m.Post("/link/{repo_name}", packages.LinkPackage)
+				m.Post("/unlink", packages.UnlinkPackage)
+			}, reqPackageAccess(perm.AccessModeWrite))
 			m.Get("/", packages.ListPackages)
 		}, context_service.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead))
 
 		// Organizations
-		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
+		m.Get("/user/orgs", reqToken(), reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg), reqUnrestrictedToken(), org.ListMyOrgs)
 		m.Group("/users/{username}/orgs", func() {
 			m.Get("", org.ListUserOrgs)
 			m.Get("/{org}/permissions", reqToken(), org.GetUserOrgsPermissions)
-		}, context_service.UserAssignmentAPI())
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
+		}, reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg), context_service.UserAssignmentAPI())
+		m.Post("/orgs", reqToken(), reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg), reqUnrestrictedToken(), bind(api.CreateOrgOption{}), org.Create)
 		m.Get("/orgs", org.GetAll)
 		m.Group("/orgs/{org}", func() {
-			m.Combo("").Get(org.Get).
-				Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit).
-				Delete(reqToken(), reqOrgOwnership(), org.Delete)
-			m.Combo("/repos").Get(user.ListOrgRepos).
+			m.Combo("/repos", reqTokenScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeRepoWrite)).Get(user.ListOrgRepos).
 				Post(reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
-			m.Group("/members", func() {
-				m.Get("", org.ListMembers)
-				m.Combo("/{username}").Get(org.IsMember).
-					Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
-			})
-			m.Group("/public_members", func() {
-				m.Get("", org.ListPublicMembers)
-				m.Combo("/{username}").Get(org.IsPublicMember).
-					Put(reqToken(), reqOrgMembership(), org.PublicizeMember).
-					Delete(reqToken(), reqOrgMembership(), org.ConcealMember)
-			})
-			m.Group("/teams", func() {
-				m.Get("", org.ListTeams)
-				m.Post("", reqOrgOwnership(), bind(api.CreateTeamOption{}), org.CreateTeam)
-				m.Get("/search", org.SearchTeam)
-			}, reqToken(), reqOrgMembership())
-			m.Group("/labels", func() {
-				m.Get("", org.ListLabels)
-				m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateLabelOption{}), org.CreateLabel)
-				m.Combo("/{id}").Get(org.GetLabel).
-					Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
-					Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
-			})
-			m.Group("/hooks", func() {
-				m.Combo("").Get(org.ListHooks).
-					Post(bind(api.CreateHookOption{}), org.CreateHook)
-				m.Combo("/{id}").Get(org.GetHook).
-					Patch(bind(api.EditHookOption{}), org.EditHook).
-					Delete(org.DeleteHook)
-			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			m.Group("", func() {
+				m.Combo("").Get(org.Get).
+					Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit).
+					Delete(reqToken(), reqOrgOwnership(), org.Delete)
+				m.Group("/members", func() {
+					m.Get("", org.ListMembers)
+					m.Combo("/{username}").Get(org.IsMember).
+						Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
+				})
+				m.Group("/public_members", func() {
+					m.Get("", org.ListPublicMembers)
+					m.Combo("/{username}").Get(org.IsPublicMember).
+						Put(reqToken(), reqOrgMembership(), org.PublicizeMember).
+						Delete(reqToken(), reqOrgMembership(), org.ConcealMember)
+				})
+				m.Group("/teams", func() {
+					m.Get("", org.ListTeams)
+					m.Post("", reqOrgOwnership(), bind(api.CreateTeamOption{}), org.CreateTeam)
+					m.Get("/search", org.SearchTeam)
+				}, reqToken(), reqOrgMembership())
+				m.Get("/audit", reqToken(), reqOrgOwnership(), org.ExportAuditEvents)
+				m.Group("/labels", func() {
+					m.Get("", org.ListLabels)
+					m.Post("", reqToken(), reqOrgOwnership(), bind(api.CreateLabelOption{}), org.CreateLabel)
+					m.Combo("/{id}").Get(org.GetLabel).
+						Patch(reqToken(), reqOrgOwnership(), bind(api.EditLabelOption{}), org.EditLabel).
+						Delete(reqToken(), reqOrgOwnership(), org.DeleteLabel)
+				})
+				m.Group("/hooks", func() {
+					m.Combo("").Get(org.ListHooks).
+						Post(bind(api.CreateHookOption{}), org.CreateHook)
+					m.Combo("/{id}").Get(org.GetHook).
+						Patch(bind(api.EditHookOption{}), org.EditHook).
+						Delete(org.DeleteHook)
+				}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			}, reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg))
 		}, orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
@@ -1124,13 +1220,14 @@ func Routes() *web.Route {
 					Delete(org.RemoveTeamRepository).
 					Get(org.GetTeamRepo)
 			})
-		}, orgAssignment(false, true), reqToken(), reqTeamMembership())
+		}, orgAssignment(false, true), reqToken(), reqTokenScope(models.AccessTokenScopeOrg, models.AccessTokenScopeOrg), reqTeamMembership())
 
 		m.Group("/admin", func() {
 			m.Group("/cron", func() {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			m.Get("/audit", admin.ExportAuditEvents)
 			m.Get("/orgs", admin.GetAllOrgs)
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
@@ -1152,7 +1249,7 @@ func Routes() *web.Route {
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
 				m.Delete("/{username}/{reponame}", admin.DeleteUnadoptedRepository)
 			})
-		}, reqToken(), reqSiteAdmin())
+		}, reqToken(), reqTokenScope(models.AccessTokenScopeAdmin, models.AccessTokenScopeAdmin), reqSiteAdmin())
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
@@ -40,7 +41,7 @@ func ListPackages(ctx *context.APIContext) {
 	//   in: query
 	//   description: package type filter
 	//   type: string
-	//   enum: [composer, conan, container, generic, helm, maven, npm, nuget, pypi, rubygems]
+	//   enum: [alpine, cargo, composer, conan, container, debian, generic, go, helm, maven, npm, nuget, pypi, rubygems]
 	// - name: q
 	//   in: query
 	//   description: name filter
@@ -158,6 +159,109 @@ func DeletePackage(ctx *context.APIContext) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// LinkPackage sets a repository link for a package
+func LinkPackage(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/{type}/{name}/-/link/{repo_name} package linkPackage
+	// ---
+	// summary: Link a package to a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// - name: repo_name
+	//   in: path
+	//   description: name of the repository to link.
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p, err := packages.GetPackageByName(ctx, ctx.Package.Owner.ID, packages.Type(ctx.Params("type")), ctx.Params("name"))
+	if err != nil {
+		if err == packages.ErrPackageNotExist {
+			ctx.Error(http.StatusNotFound, "GetPackageByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPackageByName", err)
+		}
+		return
+	}
+
+	repo, err := repo_model.GetRepositoryByName(ctx.Package.Owner.ID, ctx.Params("repo_name"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.Error(http.StatusNotFound, "GetRepositoryByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByName", err)
+		}
+		return
+	}
+
+	if err := packages.SetRepositoryLink(ctx, p.ID, repo.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetRepositoryLink", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnlinkPackage removes the repository link of a package
+func UnlinkPackage(ctx *context.APIContext) {
+	// swagger:operation POST /packages/{owner}/{type}/{name}/-/unlink package unlinkPackage
+	// ---
+	// summary: Unlink a package from a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the package
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: path
+	//   description: type of the package
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the package
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	p, err := packages.GetPackageByName(ctx, ctx.Package.Owner.ID, packages.Type(ctx.Params("type")), ctx.Params("name"))
+	if err != nil {
+		if err == packages.ErrPackageNotExist {
+			ctx.Error(http.StatusNotFound, "GetPackageByName", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPackageByName", err)
+		}
+		return
+	}
+
+	if err := packages.SetRepositoryLink(ctx, p.ID, 0); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetRepositoryLink", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
 // ListPackageFiles gets all files of a package
 func ListPackageFiles(ctx *context.APIContext) {
 	// swagger:operation GET /packages/{owner}/{type}/{name}/{version}/files package listPackageFiles
@@ -11,6 +11,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
@@ -19,6 +20,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -70,7 +72,7 @@ func GetBranch(ctx *context.APIContext) {
 		return
 	}
 
-	branchProtection, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, branchName)
+	branchProtection, err := models.GetMatchingProtectedBranchRule(ctx.Repo.Repository.ID, branchName)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
 		return
@@ -206,7 +208,7 @@ func CreateBranch(ctx *context.APIContext) {
 		return
 	}
 
-	branchProtection, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, branch.Name)
+	branchProtection, err := models.GetMatchingProtectedBranchRule(ctx.Repo.Repository.ID, branch.Name)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
 		return
@@ -259,6 +261,12 @@ func ListBranches(ctx *context.APIContext) {
 		return
 	}
 
+	rules, err := models.GetProtectedBranches(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProtectedBranches", err)
+		return
+	}
+
 	apiBranches := make([]*api.Branch, 0, len(branches))
 	for i := range branches {
 		c, err := branches[i].GetCommit()
@@ -271,11 +279,7 @@ func ListBranches(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
 			return
 		}
-		branchProtection, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, branches[i].Name)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
-			return
-		}
+		branchProtection := models.FindMatchingProtectedBranchRule(rules, branches[i].Name)
 		apiBranch, err := convert.ToBranch(ctx.Repo.Repository, branches[i], c, branchProtection, ctx.Doer, ctx.Repo.IsAdmin())
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "convert.ToBranch", err)
@@ -406,8 +410,13 @@ func CreateBranchProtection(ctx *context.APIContext) {
 	form := web.GetForm(ctx).(*api.CreateBranchProtectionOption)
 	repo := ctx.Repo.Repository
 
-	// Currently protection must match an actual branch
-	if !git.IsBranchExist(ctx.Req.Context(), ctx.Repo.Repository.RepoPath(), form.BranchName) {
+	// Protection must either match an actual branch or be a glob pattern for branches
+	if models.IsBranchNameGlob(form.BranchName) {
+		if !models.IsValidBranchNameGlob(form.BranchName) {
+			ctx.Error(http.StatusUnprocessableEntity, "Invalid branch name pattern", fmt.Errorf("invalid glob pattern: %s", form.BranchName))
+			return
+		}
+	} else if !git.IsBranchExist(ctx.Req.Context(), ctx.Repo.Repository.RepoPath(), form.BranchName) {
 		ctx.NotFound()
 		return
 	}
@@ -493,11 +502,13 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		WhitelistDeployKeys:           form.EnablePush && form.EnablePushWhitelist && form.PushWhitelistDeployKeys,
 		EnableStatusCheck:             form.EnableStatusCheck,
 		StatusCheckContexts:           form.StatusCheckContexts,
+		EnableMergeQueue:              form.EnableMergeQueue,
 		EnableApprovalsWhitelist:      form.EnableApprovalsWhitelist,
 		RequiredApprovals:             requiredApprovals,
 		BlockOnRejectedReviews:        form.BlockOnRejectedReviews,
 		BlockOnOfficialReviewRequests: form.BlockOnOfficialReviewRequests,
 		DismissStaleApprovals:         form.DismissStaleApprovals,
+		RequireCodeOwnerReview:        form.RequireCodeOwnerReview,
 		RequireSignedCommits:          form.RequireSignedCommits,
 		ProtectedFilePatterns:         form.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       form.UnprotectedFilePatterns,
@@ -517,8 +528,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
-	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
-		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
+	if err = pull_service.CheckPrsForProtectedBranchRule(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CheckPrsForProtectedBranchRule", err)
 		return
 	}
 
@@ -532,6 +543,9 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "New branch protection not found", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoBranchProtectEdit,
+		audit.RepoObjectTarget(repo, audit_model.TargetProtectedBranch, bp.ID, bp.BranchName),
+		audit.ProtectedBranchChanges(&models.ProtectedBranch{}, bp)...)
 
 	ctx.JSON(http.StatusCreated, convert.ToBranchProtection(bp))
 }
@@ -584,6 +598,7 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
+	before := *protectBranch
 
 	if form.EnablePush != nil {
 		if !*form.EnablePush {
@@ -617,6 +632,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.StatusCheckContexts = form.StatusCheckContexts
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
 	if form.RequiredApprovals != nil && *form.RequiredApprovals >= 0 {
 		protectBranch.RequiredApprovals = *form.RequiredApprovals
 	}
@@ -637,6 +656,10 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.DismissStaleApprovals = *form.DismissStaleApprovals
 	}
 
+	if form.RequireCodeOwnerReview != nil {
+		protectBranch.RequireCodeOwnerReview = *form.RequireCodeOwnerReview
+	}
+
 	if form.RequireSignedCommits != nil {
 		protectBranch.RequireSignedCommits = *form.RequireSignedCommits
 	}
@@ -752,8 +775,8 @@ func EditBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
-	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
-		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
+	if err = pull_service.CheckPrsForProtectedBranchRule(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CheckPrsForProtectedBranchRule", err)
 		return
 	}
 
@@ -767,6 +790,9 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "New branch protection not found", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoBranchProtectEdit,
+		audit.RepoObjectTarget(repo, audit_model.TargetProtectedBranch, bp.ID, bp.BranchName),
+		audit.ProtectedBranchChanges(&before, bp)...)
 
 	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
 }
@@ -816,6 +842,8 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoBranchProtectRemove,
+		audit.RepoObjectTarget(repo, audit_model.TargetProtectedBranch, bp.ID, bp.BranchName))
 
 	ctx.Status(http.StatusNoContent)
 }
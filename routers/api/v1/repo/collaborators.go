@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/perm"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
@@ -17,6 +18,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // ListCollaborators list a repository's collaborators
@@ -172,6 +174,12 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	before, err := models.GetCollaboration(ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+
 	if err := models.AddCollaborator(ctx.Repo.Repository, collaborator); err != nil {
 		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
 		return
@@ -184,6 +192,17 @@ func AddCollaborator(ctx *context.APIContext) {
 		}
 	}
 
+	after, err := models.GetCollaboration(ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+	if before == nil {
+		recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorAdd, collaborator, perm.AccessModeNone, after.Mode)
+	} else if before.Mode != after.Mode {
+		recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorEdit, collaborator, before.Mode, after.Mode)
+	}
+
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -226,13 +245,29 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	collaboration, err := models.GetCollaboration(ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+
 	if err := models.DeleteCollaboration(ctx.Repo.Repository, collaborator.ID); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	if collaboration != nil {
+		recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorRemove, collaborator, collaboration.Mode, perm.AccessModeNone)
+	}
 	ctx.Status(http.StatusNoContent)
 }
 
+// recordCollaboratorEvent records an audit event of a collaborator of the repository
+func recordCollaboratorEvent(ctx *context.APIContext, action audit_model.Action, u *user_model.User, oldMode, newMode perm.AccessMode) {
+	var changes audit_model.Changes
+	changes.Add("access_mode", oldMode.String(), newMode.String())
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), action, audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetCollaborator, u.ID, u.Name), changes...)
+}
+
 // GetRepoPermissions gets repository permissions for a user
 func GetRepoPermissions(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/collaborators/{collaborator}/permission repository repoGetRepoPermissions
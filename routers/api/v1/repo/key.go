@@ -11,6 +11,7 @@ import (
 	"net/url"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -21,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // appendPrivateInformation appends the owner and key type information to api.PublicKey
@@ -237,6 +239,10 @@ func CreateDeployKey(ctx *context.APIContext) {
 		return
 	}
 
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoDeployKeyAdd,
+		audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetDeployKey, key.ID, key.Name),
+		audit.DeployKeyChanges(key, true)...)
+
 	key.Content = content
 	apiLink := composeDeployKeysAPILink(ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
 	ctx.JSON(http.StatusCreated, convert.ToDeployKey(apiLink, key))
@@ -270,6 +276,12 @@ func DeleteDeploykey(ctx *context.APIContext) {
 	//   "403":
 	//     "$ref": "#/responses/forbidden"
 
+	key, err := asymkey_model.GetDeployKeyByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil && !asymkey_model.IsErrDeployKeyNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "GetDeployKeyByID", err)
+		return
+	}
+
 	if err := asymkey_service.DeleteDeployKey(ctx.Doer, ctx.ParamsInt64(":id")); err != nil {
 		if asymkey_model.IsErrKeyAccessDenied(err) {
 			ctx.Error(http.StatusForbidden, "", "You do not have access to this key")
@@ -278,6 +290,11 @@ func DeleteDeploykey(ctx *context.APIContext) {
 		}
 		return
 	}
+	if key != nil {
+		audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoDeployKeyRemove,
+			audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetDeployKey, key.ID, key.Name),
+			audit.DeployKeyChanges(key, false)...)
+	}
 
 	ctx.Status(http.StatusNoContent)
 }
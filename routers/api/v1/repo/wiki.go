@@ -194,7 +194,7 @@ func getWikiPage(ctx *context.APIContext, title string) *api.WikiPage {
 	}
 
 	return &api.WikiPage{
-		WikiPageMetaData: convert.ToWikiPageMetaData(title, lastCommit, ctx.Repo.Repository),
+		WikiPageMetaData: convert.ToWikiPageMetaData(title, wiki_service.NameToSubURL(title), lastCommit, ctx.Repo.Repository),
 		ContentBase64:    content,
 		CommitCount:      commitsCount,
 		Sidebar:          sidebarContent,
@@ -320,7 +320,7 @@ func ListWikiPages(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "WikiFilenameToName", err)
 			return
 		}
-		pages = append(pages, convert.ToWikiPageMetaData(wikiName, c, ctx.Repo.Repository))
+		pages = append(pages, convert.ToWikiPageMetaData(wikiName, wiki_service.NameToSubURL(wikiName), c, ctx.Repo.Repository))
 	}
 
 	ctx.SetTotalCountHeader(int64(len(entries)))
@@ -10,14 +10,18 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // ListAccessTokens list all the access tokens
@@ -60,11 +64,11 @@ func ListAccessTokens(ctx *context.APIContext) {
 
 	apiTokens := make([]*api.AccessToken, len(tokens))
 	for i := range tokens {
-		apiTokens[i] = &api.AccessToken{
-			ID:             tokens[i].ID,
-			Name:           tokens[i].Name,
-			TokenLastEight: tokens[i].TokenLastEight,
+		if err := tokens[i].LoadAllowedRepos(ctx); err != nil {
+			ctx.InternalServerError(err)
+			return
 		}
+		apiTokens[i] = convert.ToAccessToken(tokens[i])
 	}
 
 	ctx.SetTotalCountHeader(count)
@@ -95,6 +99,8 @@ func CreateAccessToken(ctx *context.APIContext) {
 	//     "$ref": "#/responses/AccessToken"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.CreateAccessTokenOption)
 
@@ -103,6 +109,27 @@ func CreateAccessToken(ctx *context.APIContext) {
 		Name: form.Name,
 	}
 
+	var err error
+	if t.Scope, err = models.ParseAccessTokenScopes(form.Scopes); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseAccessTokenScopes", err)
+		return
+	}
+	if err = t.SetAllowedRepos(ctx, form.AllowedRepos); err != nil {
+		if models.IsErrAccessTokenInvalidAllowedRepo(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "SetAllowedRepos", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetAllowedRepos", err)
+		}
+		return
+	}
+	if form.ExpiresAt != nil {
+		if !form.ExpiresAt.After(time.Now()) {
+			ctx.Error(http.StatusUnprocessableEntity, "ExpiresAt", errors.New("expiry date must be in the future"))
+			return
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(form.ExpiresAt.Unix())
+	}
+
 	exist, err := models.AccessTokenByNameExists(t)
 	if err != nil {
 		ctx.InternalServerError(err)
@@ -117,12 +144,9 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
-	ctx.JSON(http.StatusCreated, &api.AccessToken{
-		Name:           t.Name,
-		Token:          t.Token,
-		ID:             t.ID,
-		TokenLastEight: t.TokenLastEight,
-	})
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserAccessTokenAdd,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetAccessToken, t.ID, t.Name), audit.AccessTokenChanges(t)...)
+	ctx.JSON(http.StatusCreated, convert.ToAccessToken(t))
 }
 
 // DeleteAccessToken delete access tokens
@@ -180,6 +204,16 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		return
 	}
 
+	t, err := models.GetAccessTokenByID(tokenID)
+	if err != nil {
+		if models.IsErrAccessTokenNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAccessTokenByID", err)
+		}
+		return
+	}
+
 	if err := models.DeleteAccessTokenByID(tokenID, ctx.Doer.ID); err != nil {
 		if models.IsErrAccessTokenNotExist(err) {
 			ctx.NotFound()
@@ -188,6 +222,8 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserAccessTokenRemove,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetAccessToken, t.ID, t.Name))
 
 	ctx.Status(http.StatusNoContent)
 }
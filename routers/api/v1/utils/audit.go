@@ -0,0 +1,62 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ExportAuditEvents writes the audit events matching the query as JSON lines, oldest first.
+// If ownerID is not 0 only the events of targets owned by this user or organization are exported.
+func ExportAuditEvents(ctx *context.APIContext, ownerID int64) {
+	before, since, err := context.GetQueryBeforeSince(ctx.Context)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	opts := &audit_model.FindEventsOptions{
+		Action:  audit_model.Action(ctx.FormTrim("action")),
+		OwnerID: ownerID,
+		Since:   timeutil.TimeStamp(since),
+		Until:   timeutil.TimeStamp(before),
+	}
+	if opts.Action != "" && !opts.Action.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "unknown action")
+		return
+	}
+
+	if actor := ctx.FormTrim("actor"); actor != "" {
+		u, err := user_model.GetUserByName(actor)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.ActorID = u.ID
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/x-ndjson")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(ctx.Resp)
+	if err := audit_model.IterateEvents(ctx, opts, func(e *audit_model.Event) error {
+		return enc.Encode(convert.ToAuditEvent(e))
+	}); err != nil {
+		// the status has already been sent, the client notices the truncated export
+		log.Error("Unable to export audit events: %v", err)
+	}
+}
@@ -129,6 +129,11 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(webhook.HookEventPullRequestSync)),
 				Repository:           util.IsStringInSlice(string(webhook.HookEventRepository), form.Events, true),
 				Release:              util.IsStringInSlice(string(webhook.HookEventRelease), form.Events, true),
+				Status:               util.IsStringInSlice(string(webhook.HookEventStatus), form.Events, true),
+				Wiki:                 util.IsStringInSlice(string(webhook.HookEventWiki), form.Events, true),
+				Star:                 util.IsStringInSlice(string(webhook.HookEventStar), form.Events, true),
+				Member:               util.IsStringInSlice(string(webhook.HookEventMember), form.Events, true),
+				Team:                 util.IsStringInSlice(string(webhook.HookEventTeam), form.Events, true),
 			},
 			BranchFilter: form.BranchFilter,
 		},
@@ -250,6 +255,11 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 	w.Fork = util.IsStringInSlice(string(webhook.HookEventFork), form.Events, true)
 	w.Repository = util.IsStringInSlice(string(webhook.HookEventRepository), form.Events, true)
 	w.Release = util.IsStringInSlice(string(webhook.HookEventRelease), form.Events, true)
+	w.Status = util.IsStringInSlice(string(webhook.HookEventStatus), form.Events, true)
+	w.Wiki = util.IsStringInSlice(string(webhook.HookEventWiki), form.Events, true)
+	w.Star = util.IsStringInSlice(string(webhook.HookEventStar), form.Events, true)
+	w.Member = util.IsStringInSlice(string(webhook.HookEventMember), form.Events, true)
+	w.Team = util.IsStringInSlice(string(webhook.HookEventTeam), form.Events, true)
 	w.BranchFilter = form.BranchFilter
 
 	// Issues
@@ -33,6 +33,7 @@ import (
 	"code.gitea.io/gitea/modules/translation"
 	"code.gitea.io/gitea/modules/web"
 	packages_router "code.gitea.io/gitea/routers/api/packages"
+	scim_router "code.gitea.io/gitea/routers/api/scim"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/private"
@@ -41,6 +42,7 @@ import (
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/cron"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mailer/incoming"
 	repo_migrations "code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
@@ -149,6 +151,7 @@ func GlobalInitInstalled(ctx context.Context) {
 	mustInit(pull_service.Init)
 	mustInit(task.Init)
 	mustInit(repo_migrations.Init)
+	incoming.Init()
 	eventsource.GetManager().Init()
 
 	mustInitCtx(ctx, syncAppPathForGit)
@@ -179,5 +182,8 @@ func NormalRoutes() *web.Route {
 		r.Mount("/api/packages", packages_router.Routes())
 		r.Mount("/v2", packages_router.ContainerRoutes())
 	}
+	if setting.SCIM.Enabled {
+		r.Mount("/scim/v2", scim_router.Routes())
+	}
 	return r
 }
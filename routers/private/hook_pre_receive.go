@@ -14,14 +14,19 @@ import (
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	perm_model "code.gitea.io/gitea/models/perm"
+	quota_model "code.gitea.io/gitea/models/quota"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	gitea_context "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
+	"code.gitea.io/gitea/services/repository/pushrule"
 )
 
 type preReceiveContext struct {
@@ -132,9 +137,114 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	preReceiveQuota(ourCtx)
+	if ctx.Written() {
+		return
+	}
+
+	preReceivePushRules(ourCtx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// maxReportedPushRuleViolations limits the number of violations reported to the git client
+const maxReportedPushRuleViolations = 20
+
+// preReceivePushRules rejects the push if a new commit violates the push rules of the repository or its owner
+func preReceivePushRules(ctx *preReceiveContext) {
+	repo := ctx.Repo.Repository
+
+	rule, err := repo_model.GetEffectivePushRule(ctx, repo)
+	if err != nil {
+		log.Error("Unable to get the push rule of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	if rule.IsEmpty() {
+		return
+	}
+
+	violations, err := pushrule.Check(ctx, repo.RepoPath(), ctx.env, rule, ctx.opts.NewCommitIDs, maxReportedPushRuleViolations+1)
+	if err != nil {
+		log.Error("Unable to check the push rules of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check the push rules: %v", err),
+		})
+		return
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("push rejected by the push rules of the repository:")
+	for i, v := range violations {
+		if i == maxReportedPushRuleViolations {
+			sb.WriteString("\n- ... and more")
+			break
+		}
+		sb.WriteString("\n- ")
+		sb.WriteString(v.String())
+	}
+
+	log.Warn("Forbidden: Push to %-v violates the push rules: %s", repo, violations[0])
+	ctx.JSON(http.StatusForbidden, private.Response{
+		Err: sb.String(),
+	})
+}
+
+// preReceiveQuota rejects the push if the received objects exceed the quota of the repository owner
+func preReceiveQuota(ctx *preReceiveContext) {
+	if !setting.Quota.Enabled {
+		return
+	}
+
+	onlyDeletes := true
+	for _, newCommitID := range ctx.opts.NewCommitIDs {
+		if newCommitID != git.EmptySHA {
+			onlyDeletes = false
+			break
+		}
+	}
+	if onlyDeletes {
+		return
+	}
+
+	// the received objects are still in the quarantine directory
+	var size int64
+	if ctx.opts.GitQuarantinePath != "" {
+		var err error
+		size, err = util.GetDirectorySize(ctx.opts.GitQuarantinePath)
+		if err != nil {
+			log.Error("Unable to get the size of the quarantine directory %s: %v", ctx.opts.GitQuarantinePath, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+	}
+
+	repo := ctx.Repo.Repository
+	if err := quota_model.CheckQuotaByOwnerID(ctx, repo.OwnerID, size); err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			log.Warn("Forbidden: Push to %-v exceeds the quota: %v", repo, err)
+			ctx.JSON(http.StatusForbidden, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+		log.Error("Unable to check the quota of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+	}
+}
+
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	ctx.branchName = branchName
@@ -154,7 +264,13 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 		return
 	}
 
-	protectBranch, err := models.GetProtectedBranchBy(repo.ID, branchName)
+	// The merge queue pushes its merge commits to branches of its own which must not be blocked
+	// by glob rules like "**" that were meant for the branches of the users
+	if ctx.opts.PullRequestID > 0 && strings.HasPrefix(branchName, pull_service.MergeQueueBranchPrefix) {
+		return
+	}
+
+	protectBranch, err := models.GetMatchingProtectedBranchRule(repo.ID, branchName)
 	if err != nil {
 		log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
 		ctx.JSON(http.StatusInternalServerError, private.Response{
@@ -343,7 +459,7 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 		}
 
 		// Check all status checks and reviews are ok
-		if err := pull_service.CheckPullBranchProtections(ctx, pr, true); err != nil {
+		if err := pull_service.CheckPullBranchProtectionsForPush(ctx, pr, newCommitID, true); err != nil {
 			if models.IsErrDisallowedToMerge(err) {
 				log.Warn("Forbidden: User %d is not allowed push to protected branch %s in %-v and pr #%d is not ready to be merged: %s", ctx.opts.UserID, branchName, repo, pr.Index, err.Error())
 				ctx.JSON(http.StatusForbidden, private.Response{
@@ -72,6 +72,7 @@ func Routes() *web.Route {
 	r.Post("/manager/remove-logger/{group}/{name}", RemoveLogger)
 	r.Get("/manager/processes", Processes)
 	r.Post("/mail/send", SendEmail)
+	r.Post("/mail/receive", ReceiveEmail)
 	r.Post("/restore_repo", RestoreRepo)
 
 	return r
@@ -5,7 +5,9 @@
 package private
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -16,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mailer/incoming"
 )
 
 // SendEmail pushes messages to mail queue
@@ -88,3 +91,40 @@ func sendEmail(ctx *context.PrivateContext, subject, message string, to []string
 
 	ctx.PlainText(http.StatusOK, wasSent)
 }
+
+// ReceiveEmail handles an incoming mail piped to the incoming-mail command
+func ReceiveEmail(ctx *context.PrivateContext) {
+	if !setting.IncomingEmail.Enabled {
+		ctx.JSON(http.StatusForbidden, private.Response{
+			Err: "Incoming email handling is not enabled.",
+		})
+		return
+	}
+
+	rd := ctx.Req.Body
+	defer rd.Close()
+
+	message, err := io.ReadAll(io.LimitReader(rd, setting.IncomingEmail.MaximumMessageSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	if int64(len(message)) > setting.IncomingEmail.MaximumMessageSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, private.Response{
+			Err: "The mail exceeds the maximum message size.",
+		})
+		return
+	}
+
+	if err := incoming.HandleMessage(ctx, bytes.NewReader(message)); err != nil {
+		log.Error("Unable to handle incoming mail: %v", err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+
+	ctx.PlainText(http.StatusOK, "success")
+}
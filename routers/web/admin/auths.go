@@ -25,9 +25,11 @@ import (
 	"code.gitea.io/gitea/services/auth/source/ldap"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	pam_service "code.gitea.io/gitea/services/auth/source/pam"
+	"code.gitea.io/gitea/services/auth/source/saml"
 	"code.gitea.io/gitea/services/auth/source/smtp"
 	"code.gitea.io/gitea/services/auth/source/sspi"
 	"code.gitea.io/gitea/services/forms"
+	org_service "code.gitea.io/gitea/services/org"
 
 	"xorm.io/xorm/convert"
 )
@@ -73,6 +75,7 @@ var (
 			{auth.SMTP.String(), auth.SMTP},
 			{auth.OAuth2.String(), auth.OAuth2},
 			{auth.SSPI.String(), auth.SSPI},
+			{auth.SAML.String(), auth.SAML},
 		}
 		if pam.Supported {
 			items = append(items, dropdownItem{auth.Names[auth.PAM], auth.PAM})
@@ -80,6 +83,13 @@ var (
 		return items
 	}()
 
+	samlNameIDFormats = []string{
+		saml.NameIDFormatUnspecified,
+		saml.NameIDFormatEmailAddress,
+		saml.NameIDFormatPersistent,
+		saml.NameIDFormatTransient,
+	}
+
 	securityProtocols = []dropdownItem{
 		{ldap.SecurityProtocolNames[ldap.SecurityProtocolUnencrypted], ldap.SecurityProtocolUnencrypted},
 		{ldap.SecurityProtocolNames[ldap.SecurityProtocolLDAPS], ldap.SecurityProtocolLDAPS},
@@ -114,6 +124,9 @@ func NewAuthSource(ctx *context.Context) {
 	// only the first as default
 	ctx.Data["oauth2_provider"] = oauth2providers[0].Name
 
+	ctx.Data["saml_name_id_format"] = saml.NameIDFormatUnspecified
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
+
 	ctx.HTML(http.StatusOK, tplAuthNew)
 }
 
@@ -170,7 +183,7 @@ func parseSMTPConfig(form forms.AuthenticationForm) *smtp.Source {
 	}
 }
 
-func parseOAuth2Config(form forms.AuthenticationForm) *oauth2.Source {
+func parseOAuth2Config(ctx *context.Context, form forms.AuthenticationForm) (*oauth2.Source, error) {
 	var customURLMapping *oauth2.CustomURLMapping
 	if form.Oauth2UseCustomURL {
 		customURLMapping = &oauth2.CustomURLMapping{
@@ -183,6 +196,11 @@ func parseOAuth2Config(form forms.AuthenticationForm) *oauth2.Source {
 	} else {
 		customURLMapping = nil
 	}
+	if _, err := org_service.ParseGroupTeamMap(form.Oauth2GroupTeamMap); err != nil {
+		ctx.Data["Err_Oauth2GroupTeamMap"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.oauth2_group_team_map_invalid", err.Error()))
+	}
+
 	var scopes []string
 	for _, s := range strings.Split(form.Oauth2Scopes, ",") {
 		s = strings.TrimSpace(s)
@@ -205,7 +223,9 @@ func parseOAuth2Config(form forms.AuthenticationForm) *oauth2.Source {
 		GroupClaimName:                form.Oauth2GroupClaimName,
 		RestrictedGroup:               form.Oauth2RestrictedGroup,
 		AdminGroup:                    form.Oauth2AdminGroup,
-	}
+		GroupTeamMap:                  form.Oauth2GroupTeamMap,
+		GroupTeamMapRemoval:           form.Oauth2GroupTeamMapRemoval,
+	}, nil
 }
 
 func parseSSPIConfig(ctx *context.Context, form forms.AuthenticationForm) (*sspi.Source, error) {
@@ -232,6 +252,48 @@ func parseSSPIConfig(ctx *context.Context, form forms.AuthenticationForm) (*sspi
 	}, nil
 }
 
+func parseSAMLConfig(ctx *context.Context, form forms.AuthenticationForm, existing *saml.Source) (*saml.Source, error) {
+	source := &saml.Source{
+		IdentityProviderMetadataURL: strings.TrimSpace(form.SAMLIdentityProviderMetadataURL),
+		IdentityProviderMetadata:    strings.TrimSpace(form.SAMLIdentityProviderMetadata),
+		SignRequests:                form.SAMLSignRequests,
+		NameIDFormat:                form.SAMLNameIDFormat,
+		UsernameAttribute:           form.SAMLUsernameAttribute,
+		EmailAttribute:              form.SAMLEmailAttribute,
+		FullNameAttribute:           form.SAMLFullNameAttribute,
+		GroupAttribute:              form.SAMLGroupAttribute,
+		AdminGroup:                  form.SAMLAdminGroup,
+		RestrictedGroup:             form.SAMLRestrictedGroup,
+		SkipLocalTwoFA:              form.SkipLocalTwoFA,
+	}
+
+	// the metadata is refreshed from the url on every save
+	if source.IdentityProviderMetadataURL != "" {
+		metadata, err := saml.FetchIdentityProviderMetadata(source.IdentityProviderMetadataURL)
+		if err != nil {
+			ctx.Data["Err_SAMLIdentityProviderMetadataURL"] = true
+			return nil, errors.New(ctx.Tr("admin.auths.saml_metadata_invalid", err.Error()))
+		}
+		source.IdentityProviderMetadata = string(metadata)
+	} else if source.IdentityProviderMetadata == "" {
+		ctx.Data["Err_SAMLIdentityProviderMetadata"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.saml_metadata_required"))
+	} else if _, err := saml.ParseIdentityProviderMetadata([]byte(source.IdentityProviderMetadata)); err != nil {
+		ctx.Data["Err_SAMLIdentityProviderMetadata"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.saml_metadata_invalid", err.Error()))
+	}
+
+	// the key pair is generated once and kept, otherwise the identity provider would need to be reconfigured
+	if existing != nil && existing.ServiceProviderPrivateKey != "" {
+		source.ServiceProviderCertificate = existing.ServiceProviderCertificate
+		source.ServiceProviderPrivateKey = existing.ServiceProviderPrivateKey
+	} else if err := source.GenerateServiceProviderKeyPair(); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*forms.AuthenticationForm)
@@ -252,6 +314,7 @@ func NewAuthSourcePost(ctx *context.Context) {
 	ctx.Data["SSPIStripDomainNames"] = true
 	ctx.Data["SSPISeparatorReplacement"] = "_"
 	ctx.Data["SSPIDefaultLanguage"] = ""
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	hasTLS := false
 	var config convert.Conversion
@@ -269,7 +332,12 @@ func NewAuthSourcePost(ctx *context.Context) {
 			SkipLocalTwoFA: form.SkipLocalTwoFA,
 		}
 	case auth.OAuth2:
-		config = parseOAuth2Config(form)
+		var err error
+		config, err = parseOAuth2Config(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthNew, form)
+			return
+		}
 	case auth.SSPI:
 		var err error
 		config, err = parseSSPIConfig(ctx, form)
@@ -283,6 +351,13 @@ func NewAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_of_type_exist"), tplAuthNew, form)
 			return
 		}
+	case auth.SAML:
+		var err error
+		config, err = parseSAMLConfig(ctx, form, nil)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthNew, form)
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
@@ -334,6 +409,7 @@ func EditAuthSource(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	if source.IsOAuth2() {
 		type Named interface {
@@ -369,6 +445,7 @@ func EditAuthSourcePost(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplAuthEdit)
@@ -387,13 +464,24 @@ func EditAuthSourcePost(ctx *context.Context) {
 			EmailDomain: form.PAMEmailDomain,
 		}
 	case auth.OAuth2:
-		config = parseOAuth2Config(form)
+		config, err = parseOAuth2Config(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
+			return
+		}
 	case auth.SSPI:
 		config, err = parseSSPIConfig(ctx, form)
 		if err != nil {
 			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
 			return
 		}
+	case auth.SAML:
+		existing, _ := source.Cfg.(*saml.Source)
+		config, err = parseSAMLConfig(ctx, form, existing)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
@@ -0,0 +1,88 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplQuotas base.TplName = "admin/quotas"
+
+// QuotaUsage is the storage usage of an owner shown in the quota list
+type QuotaUsage struct {
+	*quota_model.Usage
+	Owner *user_model.User
+	Limit int64
+}
+
+// Quotas shows the storage used by all users and organizations
+func Quotas(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.quotas")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminQuotas"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	pageSize := setting.UI.Admin.UserPagingNum
+
+	usages, err := quota_model.GetAllUsages(ctx)
+	if err != nil {
+		ctx.ServerError("GetAllUsages", err)
+		return
+	}
+	total := len(usages)
+
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	usages = usages[start:end]
+
+	ids := make([]int64, 0, len(usages))
+	for _, usage := range usages {
+		ids = append(ids, usage.OwnerID)
+	}
+	owners, err := user_model.GetUsersByIDs(ids)
+	if err != nil {
+		ctx.ServerError("GetUsersByIDs", err)
+		return
+	}
+	ownerMap := make(map[int64]*user_model.User, len(owners))
+	for _, owner := range owners {
+		ownerMap[owner.ID] = owner
+	}
+
+	quotaUsages := make([]*QuotaUsage, 0, len(usages))
+	for _, usage := range usages {
+		owner, ok := ownerMap[usage.OwnerID]
+		if !ok {
+			owner = user_model.NewGhostUser()
+		}
+		quotaUsages = append(quotaUsages, &QuotaUsage{
+			Usage: usage,
+			Owner: owner,
+			Limit: owner.MaxQuotaSize(),
+		})
+	}
+
+	ctx.Data["QuotaEnabled"] = setting.Quota.Enabled
+	ctx.Data["QuotaUsages"] = quotaUsages
+	ctx.Data["Total"] = total
+	ctx.Data["Page"] = context.NewPagination(total, pageSize, page, 5)
+
+	ctx.HTML(http.StatusOK, tplQuotas)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -24,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
@@ -196,6 +198,7 @@ func NewUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserCreate, audit.UserTarget(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -281,6 +284,15 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 
+	quotaSize, err := forms.ParseQuotaSize(form.QuotaSize)
+	if err != nil {
+		ctx.Data["Err_QuotaSize"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.users.quota_size_invalid"), tplUserEdit, &form)
+		return
+	}
+
+	before := *u
+
 	fields := strings.Split(form.LoginType, "-")
 	if len(fields) == 2 {
 		loginType, _ := strconv.ParseInt(fields[0], 10, 0)
@@ -373,6 +385,7 @@ func EditUserPost(ctx *context.Context) {
 	u.Website = form.Website
 	u.Location = form.Location
 	u.MaxRepoCreation = form.MaxRepoCreation
+	u.QuotaSize = quotaSize
 	u.IsActive = form.Active
 	u.IsAdmin = form.Admin
 	u.IsRestricted = form.Restricted
@@ -403,6 +416,11 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, u.Name)
+	changes := audit.UserChanges(&before, u)
+	if form.Reset2FA {
+		changes.Add("two_factor", "enrolled", "reset")
+	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserEdit, audit.UserTarget(u), changes...)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + url.PathEscape(ctx.Params(":userid")))
@@ -439,6 +457,7 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionAdminUserDelete, audit.UserTarget(u))
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
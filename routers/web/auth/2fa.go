@@ -93,6 +93,9 @@ func TwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	if u, err := user_model.GetUserByID(id); err == nil {
+		recordFailedSignIn(ctx, u.Name, errors.New("invalid two-factor passcode"))
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_passcode_incorrect"), tplTwofa, forms.TwoFactorAuthForm{})
 }
 
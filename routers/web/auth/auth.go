@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -26,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/audit"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/externalaccount"
@@ -161,11 +163,17 @@ func SignIn(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	ctx.Data["SAMLSources"], err = auth.ActiveSources(auth.SAML)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
 	ctx.Data["PageIsLogin"] = true
 	ctx.Data["EnableSSPI"] = auth.IsSSPIEnabled()
+	ctx.Data["EnablePasskeySignIn"] = setting.Service.EnablePasskeySignIn
 
 	ctx.HTML(http.StatusOK, tplSignIn)
 }
@@ -181,11 +189,17 @@ func SignInPost(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	ctx.Data["SAMLSources"], err = auth.ActiveSources(auth.SAML)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
 	ctx.Data["PageIsLogin"] = true
 	ctx.Data["EnableSSPI"] = auth.IsSSPIEnabled()
+	ctx.Data["EnablePasskeySignIn"] = setting.Service.EnablePasskeySignIn
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplSignIn)
@@ -195,12 +209,16 @@ func SignInPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.SignInForm)
 	u, source, err := auth_service.UserSignIn(form.UserName, form.Password)
 	if err != nil {
+		recordFailedSignIn(ctx, form.UserName, err)
 		if user_model.IsErrUserNotExist(err) || user_model.IsErrEmailAddressNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
 		} else if user_model.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+		} else if auth.IsErrPasskeyRequired(err) {
+			ctx.RenderWithErr(ctx.Tr("auth.passkey_required"), tplSignIn, &form)
+			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
 		} else if user_model.IsErrUserProhibitLogin(err) {
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
 			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
@@ -228,6 +246,12 @@ func SignInPost(ctx *context.Context) {
 		return
 	}
 
+	handleTwoFactorSignIn(ctx, u, form.Remember)
+}
+
+// handleTwoFactorSignIn redirects the user to the second factor authentication if the user is enrolled
+// and signs in the user otherwise.
+func handleTwoFactorSignIn(ctx *context.Context, u *user_model.User, remember bool) {
 	// If this user is enrolled in 2FA TOTP, we can't sign the user in just yet.
 	// Instead, redirect them to the 2FA authentication page.
 	hasTOTPtwofa, err := auth.HasTwoFactorByUID(u.ID)
@@ -245,7 +269,7 @@ func SignInPost(ctx *context.Context) {
 
 	if !hasTOTPtwofa && !hasWebAuthnTwofa {
 		// No two factor auth configured we can sign in the user
-		handleSignIn(ctx, u, form.Remember)
+		handleSignIn(ctx, u, remember)
 		return
 	}
 
@@ -260,7 +284,7 @@ func SignInPost(ctx *context.Context) {
 		return
 	}
 
-	if err := ctx.Session.Set("twofaRemember", form.Remember); err != nil {
+	if err := ctx.Session.Set("twofaRemember", remember); err != nil {
 		ctx.ServerError("UserSignIn: Unable to set twofaRemember in session", err)
 		return
 	}
@@ -354,6 +378,7 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 		ctx.ServerError("UpdateUserCols", err)
 		return setting.AppSubURL + "/"
 	}
+	audit.Record(u, ctx.RemoteAddr(), audit_model.ActionUserLogin, audit.UserTarget(u))
 
 	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
@@ -369,6 +394,17 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 	return setting.AppSubURL + "/"
 }
 
+// recordFailedSignIn records an audit event for a failed sign in attempt with the given user name or email
+func recordFailedSignIn(ctx *context.Context, name string, reason error) {
+	target := audit.Target{Type: audit_model.TargetUser, Name: name}
+	if u, err := user_model.GetUserByName(name); err == nil {
+		target = audit.UserTarget(u)
+	} else if u, err := user_model.GetUserByEmail(name); err == nil {
+		target = audit.UserTarget(u)
+	}
+	audit.Record(nil, ctx.RemoteAddr(), audit_model.ActionUserLoginFailed, target, &audit_model.Change{Field: "reason", New: reason.Error()})
+}
+
 func getUserName(gothUser *goth.User) string {
 	switch setting.OAuth2Client.Username {
 	case setting.OAuth2UsernameEmail:
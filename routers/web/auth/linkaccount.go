@@ -293,5 +293,7 @@ func LinkAccountPostRegister(ctx *context.Context) {
 		return
 	}
 
+	syncGroupClaimsToTeams(authSource, u, &gothUser)
+
 	handleSignIn(ctx, u, false)
 }
@@ -946,6 +946,23 @@ func setUserGroupClaims(loginSource *auth.Source, u *user_model.User, gothUser *
 	return wasAdmin != u.IsAdmin || wasRestricted != u.IsRestricted
 }
 
+// syncGroupClaimsToTeams updates the team memberships of the user from the group claim
+func syncGroupClaimsToTeams(loginSource *auth.Source, u *user_model.User, gothUser *goth.User) {
+	source := loginSource.Cfg.(*oauth2.Source)
+	if source.GroupClaimName == "" || source.GroupTeamMap == "" {
+		return
+	}
+
+	// providers usually omit the claim if the user is not a member of any group
+	var groups []string
+	if groupClaims, has := gothUser.RawData[source.GroupClaimName]; has {
+		groups = claimValueToStringSlice(groupClaims)
+	}
+	if err := source.SyncGroupsToTeams(u, groups); err != nil {
+		log.Error("OAuth2 group sync for user %s failed: %v", u.Name, err)
+	}
+}
+
 func showLinkingLogin(ctx *context.Context, gothUser goth.User) {
 	if _, err := session.RegenerateSession(ctx.Resp, ctx.Req); err != nil {
 		ctx.ServerError("RegenerateSession", err)
@@ -981,6 +998,7 @@ func updateAvatarIfNeed(url string, u *user_model.User) {
 
 func handleOAuth2SignIn(ctx *context.Context, source *auth.Source, u *user_model.User, gothUser goth.User) {
 	updateAvatarIfNeed(gothUser.AvatarURL, u)
+	syncGroupClaimsToTeams(source, u, &gothUser)
 
 	needs2FA := false
 	if !source.Cfg.(*oauth2.Source).SkipLocalTwoFA {
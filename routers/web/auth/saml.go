@@ -0,0 +1,196 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/auth/source/saml"
+)
+
+const tplSAMLRepost base.TplName = "user/auth/saml_repost"
+
+func getActiveSAMLSource(ctx *context.Context) (*auth.Source, *saml.Source) {
+	source, err := auth.GetActiveSourceByName(ctx.Params(":provider"), auth.SAML)
+	if err != nil {
+		if auth.IsErrSourceNotExist(err) {
+			ctx.NotFound("GetActiveSourceByName", err)
+		} else {
+			ctx.ServerError("GetActiveSourceByName", err)
+		}
+		return nil, nil
+	}
+	return source, source.Cfg.(*saml.Source)
+}
+
+// SAMLMetadata returns the service provider metadata of a SAML source
+func SAMLMetadata(ctx *context.Context) {
+	_, samlSource := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	metadata, err := samlSource.ServiceProviderMetadata()
+	if err != nil {
+		ctx.ServerError("ServiceProviderMetadata", err)
+		return
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write(metadata); err != nil {
+		log.Error("Error writing SAML metadata: %v", err)
+	}
+}
+
+// SignInSAML redirects the user to the identity provider of a SAML source
+func SignInSAML(ctx *context.Context) {
+	_, samlSource := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if redirectTo := ctx.FormString("redirect_to"); len(redirectTo) > 0 {
+		middleware.SetRedirectToCookie(ctx.Resp, redirectTo)
+	}
+
+	redirect, requestID, err := samlSource.AuthnRequestURL()
+	if err != nil {
+		ctx.ServerError("AuthnRequestURL", err)
+		return
+	}
+	// the response is only accepted in the session which started the authentication
+	if err := ctx.Session.Set("samlRequestID", requestID); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	ctx.Redirect(redirect)
+}
+
+// RepostSAMLResponse handles the response the identity provider posts cross-site to the assertion consumer service.
+// The session cookie is not sent with such a request and a new session would replace it, so this runs before
+// the session middleware and posts the response again from this site.
+func RepostSAMLResponse() func(next http.Handler) http.Handler {
+	rnd := templates.HTMLRenderer()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost || !strings.HasPrefix(req.URL.Path, "/user/saml/") || !strings.HasSuffix(req.URL.Path, "/acs") {
+				next.ServeHTTP(resp, req)
+				return
+			}
+			// the response is only posted again once, the handler rejects it if the session is still missing
+			if _, err := req.Cookie(setting.SessionConfig.CookieName); err == nil || req.FormValue("reposted") != "" {
+				next.ServeHTTP(resp, req)
+				return
+			}
+
+			data := templates.BaseVars().Merge(map[string]interface{}{
+				"i18n":         middleware.Locale(resp, req),
+				"Action":       setting.AppSubURL + req.URL.Path,
+				"SAMLResponse": req.FormValue("SAMLResponse"),
+				"RelayState":   req.FormValue("RelayState"),
+			})
+			if err := rnd.HTML(resp, http.StatusOK, string(tplSAMLRepost), data); err != nil {
+				log.Error("Error rendering SAML response form: %v", err)
+			}
+		})
+	}
+}
+
+// SignInSAMLAssertionConsumerService handles the response the identity provider posts after the authentication
+func SignInSAMLAssertionConsumerService(ctx *context.Context) {
+	source, samlSource := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	requestID, _ := ctx.Session.Get("samlRequestID").(string)
+	_ = ctx.Session.Delete("samlRequestID")
+
+	assertion, err := samlSource.ParseResponse(ctx.FormString("SAMLResponse"), ctx.FormString("RelayState"))
+	if err == nil && (requestID == "" || assertion.RequestID != requestID) {
+		// otherwise an attacker could sign in the user with the attacker's account
+		err = errors.New("the authentication request was not started in this session")
+	}
+	if err != nil {
+		log.Info("Failed SAML authentication with %s from %s: %v", source.Name, ctx.RemoteAddr(), err)
+		if saml.IsErrResponseStatus(err) {
+			ctx.Flash.Error(ctx.Tr("auth.saml_login_failed_status", err.(saml.ErrResponseStatus).Code))
+		} else {
+			ctx.Flash.Error(ctx.Tr("auth.saml_login_failed"))
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	// an assertion must be used only once
+	key := "saml_assertion_" + source.Name + "_" + assertion.ID
+	if c := cache.GetCache(); c != nil {
+		if c.IsExist(key) {
+			log.Info("Replayed SAML assertion %s of %s from %s", assertion.ID, source.Name, ctx.RemoteAddr())
+			ctx.Flash.Error(ctx.Tr("auth.saml_login_failed"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+			return
+		}
+		timeout := int64(time.Until(assertion.NotOnOrAfter).Seconds()) + 5*60
+		if err := c.Put(key, true, timeout); err != nil {
+			log.Error("Error caching SAML assertion id: %v", err)
+		}
+	}
+
+	u, err := samlSource.SignIn(assertion)
+	if err != nil {
+		switch {
+		case user_model.IsErrUserProhibitLogin(err), user_model.IsErrUserInactive(err):
+			log.Info("Failed authentication attempt for %s from %s: %v", assertion.NameID, ctx.RemoteAddr(), err)
+			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
+			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
+		case user_model.IsErrUserAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("form.username_been_taken"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case user_model.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case user_model.IsErrEmailCharIsNotSupported(err), user_model.IsErrEmailInvalid(err):
+			ctx.Flash.Error(ctx.Tr("form.email_invalid"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case db.IsErrNameReserved(err):
+			ctx.Flash.Error(ctx.Tr("user.form.name_reserved", err.(db.ErrNameReserved).Name))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case db.IsErrNamePatternNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("user.form.name_pattern_not_allowed", err.(db.ErrNamePatternNotAllowed).Pattern))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case db.IsErrNameCharsNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("user.form.name_chars_not_allowed", err.(db.ErrNameCharsNotAllowed).Name))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		case err == saml.ErrMissingUserAttributes:
+			log.Info("SAML authentication with %s from %s: %v", source.Name, ctx.RemoteAddr(), err)
+			ctx.Flash.Error(ctx.Tr("auth.saml_login_failed"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+		default:
+			ctx.ServerError("SignIn", err)
+		}
+		return
+	}
+
+	if samlSource.SkipLocalTwoFA {
+		handleSignIn(ctx, u, false)
+		return
+	}
+	handleTwoFactorSignIn(ctx, u, false)
+}
@@ -6,6 +6,7 @@ package auth
 
 import (
 	"encoding/base32"
+	"encoding/base64"
 	"errors"
 	"net/http"
 
@@ -168,3 +169,116 @@ func WebAuthnLoginAssertionPost(ctx *context.Context) {
 
 	ctx.JSON(http.StatusOK, map[string]string{"redirect": redirect})
 }
+
+// PasskeyLoginAssertion submits a WebAuthn challenge for a discoverable credential to the browser
+func PasskeyLoginAssertion(ctx *context.Context) {
+	challenge, err := protocol.CreateChallenge()
+	if err != nil {
+		ctx.ServerError("CreateChallenge", err)
+		return
+	}
+
+	// no credentials are allowed explicitly, the authenticator offers the passkeys it has stored for the site
+	requestOptions := protocol.PublicKeyCredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          wa.WebAuthn.Config.Timeout,
+		RelyingPartyID:   wa.WebAuthn.Config.RPID,
+		UserVerification: protocol.VerificationRequired,
+	}
+	sessionData := &webauthn.SessionData{
+		Challenge:        base64.RawURLEncoding.EncodeToString(challenge),
+		UserVerification: requestOptions.UserVerification,
+	}
+
+	if err := ctx.Session.Set("passkeyAssertion", sessionData); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	if err := ctx.Session.Set("passkeyRemember", ctx.FormBool("remember")); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, protocol.CredentialAssertion{Response: requestOptions})
+}
+
+// PasskeyLoginAssertionPost validates the signature of a passkey and logs its user in
+func PasskeyLoginAssertionPost(ctx *context.Context) {
+	sessionData, ok := ctx.Session.Get("passkeyAssertion").(*webauthn.SessionData)
+	if !ok || sessionData == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in passkey session"))
+		return
+	}
+	defer func() {
+		_ = ctx.Session.Delete("passkeyAssertion")
+	}()
+
+	parsedResponse, err := protocol.ParseCredentialRequestResponse(ctx.Req)
+	if err != nil {
+		log.Info("Failed passkey authentication attempt from %s: %v", ctx.RemoteAddr(), err)
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	// the passkey identifies the user
+	dbCred, err := auth.GetPasskeyByCredID(base32.HexEncoding.EncodeToString(parsedResponse.RawID))
+	if err != nil {
+		if auth.IsErrWebAuthnCredentialNotExist(err) {
+			log.Info("Failed passkey authentication attempt from %s: %v", ctx.RemoteAddr(), err)
+			ctx.Status(http.StatusForbidden)
+		} else {
+			ctx.ServerError("GetPasskeyByCredID", err)
+		}
+		return
+	}
+
+	user, err := user_model.GetUserByID(dbCred.UserID)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+
+	// ValidateLogin verifies that the session belongs to the user, which can only be known now
+	sessionData.UserID = (*wa.User)(user).WebAuthnID()
+	cred, err := wa.WebAuthn.ValidateLogin((*wa.User)(user), *sessionData, parsedResponse)
+	if err != nil {
+		recordFailedSignIn(ctx, user.Name, err)
+		log.Info("Failed passkey authentication attempt for %s from %s: %v", user.Name, ctx.RemoteAddr(), err)
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	if cred.Authenticator.CloneWarning {
+		log.Info("Failed passkey authentication attempt for %s from %s: cloned credential", user.Name, ctx.RemoteAddr())
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	if user.ProhibitLogin {
+		log.Info("Failed passkey authentication attempt for %s from %s: %v", user.Name, ctx.RemoteAddr(), user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name})
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	// inactive users have not confirmed their account yet or have been deactivated
+	if !user.IsActive {
+		log.Info("Failed passkey authentication attempt for %s from %s: %v", user.Name, ctx.RemoteAddr(), user_model.ErrUserInactive{UID: user.ID, Name: user.Name})
+		ctx.Status(http.StatusForbidden)
+		return
+	}
+
+	dbCred.SignCount = cred.Authenticator.SignCount
+	if err := dbCred.UpdateSignCount(); err != nil {
+		ctx.ServerError("UpdateSignCount", err)
+		return
+	}
+
+	// the user has been verified by the authenticator, so the passkey is both factors
+	remember, _ := ctx.Session.Get("passkeyRemember").(bool)
+	_ = ctx.Session.Delete("passkeyRemember")
+	redirect := handleSignInFull(ctx, user, remember, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+
+	ctx.JSON(http.StatusOK, map[string]string{"redirect": redirect})
+}
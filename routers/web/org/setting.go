@@ -61,6 +61,13 @@ func SettingsPost(ctx *context.Context) {
 		return
 	}
 
+	quotaSize, err := forms.ParseQuotaSize(form.QuotaSize)
+	if err != nil {
+		ctx.Data["Err_QuotaSize"] = true
+		ctx.RenderWithErr(ctx.Tr("admin.users.quota_size_invalid"), tplSettingsOptions, &form)
+		return
+	}
+
 	org := ctx.Org.Organization
 	nameChanged := org.Name != form.Name
 
@@ -99,6 +106,7 @@ func SettingsPost(ctx *context.Context) {
 
 	if ctx.Doer.IsAdmin {
 		org.MaxRepoCreation = form.MaxRepoCreation
+		org.QuotaSize = quotaSize
 	}
 
 	org.FullName = form.FullName
@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	shared "code.gitea.io/gitea/routers/web/shared/audit"
+)
+
+const tplSettingsAudit base.TplName = "org/settings/audit"
+
+// SettingsAudit shows the audit log of the organization
+func SettingsAudit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.audit")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsAudit"] = true
+
+	shared.SetAuditEventsContext(ctx, ctx.Org.Organization.ID)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsAudit)
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	shared "code.gitea.io/gitea/routers/web/shared/packages"
+)
+
+const (
+	tplSettingsPackages            base.TplName = "org/settings/packages"
+	tplSettingsPackagesRuleEdit    base.TplName = "org/settings/packages_cleanup_rules_edit"
+	tplSettingsPackagesRulePreview base.TplName = "org/settings/packages_cleanup_rules_preview"
+)
+
+// Packages render the package cleanup rules of the organization
+func Packages(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetPackagesContext(ctx, ctx.ContextUser)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackages)
+}
+
+// PackagesRuleAdd render the form to add a cleanup rule
+func PackagesRuleAdd(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRuleAddContext(ctx)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRuleEdit)
+}
+
+// PackagesRuleEdit render the form to edit a cleanup rule
+func PackagesRuleEdit(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRuleEditContext(ctx, ctx.ContextUser)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRuleEdit)
+}
+
+// PackagesRuleAddPost creates a cleanup rule
+func PackagesRuleAddPost(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.PerformRuleAddPost(
+		ctx,
+		ctx.ContextUser,
+		ctx.Data["PackagesSettingsLink"].(string),
+		tplSettingsPackagesRuleEdit,
+	)
+}
+
+// PackagesRuleEditPost updates or removes a cleanup rule
+func PackagesRuleEditPost(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.PerformRuleEditPost(
+		ctx,
+		ctx.ContextUser,
+		ctx.Data["PackagesSettingsLink"].(string),
+		tplSettingsPackagesRuleEdit,
+	)
+}
+
+// PackagesRulePreview render the package versions a cleanup rule would remove
+func PackagesRulePreview(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRulePreviewContext(ctx, ctx.ContextUser)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRulePreview)
+}
+
+func setPackagesSettingsContext(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPackages"] = true
+	ctx.Data["PackagesSettingsLink"] = ctx.Org.OrgLink + "/settings/packages"
+}
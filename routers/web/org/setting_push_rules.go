@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	shared "code.gitea.io/gitea/routers/web/shared/pushrule"
+)
+
+const tplSettingsPushRules base.TplName = "org/settings/push_rules"
+
+// SettingsPushRules shows the push rule of the organization
+func SettingsPushRules(ctx *context.Context) {
+	pr := preparePushRulesContext(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	shared.SetPushRuleContext(ctx, pr)
+
+	ctx.HTML(http.StatusOK, tplSettingsPushRules)
+}
+
+// SettingsPushRulesPost updates the push rule of the organization
+func SettingsPushRulesPost(ctx *context.Context) {
+	pr := preparePushRulesContext(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	shared.PerformPushRulePost(ctx, pr, ctx.Org.OrgLink+"/settings/push_rules", tplSettingsPushRules)
+}
+
+func preparePushRulesContext(ctx *context.Context) *repo_model.PushRule {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.push_rules")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPushRules"] = true
+
+	pr, err := repo_model.GetOwnerPushRule(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnerPushRule", err)
+		return nil
+	}
+	return pr
+}
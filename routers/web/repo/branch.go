@@ -38,6 +38,7 @@ type Branch struct {
 	Name              string
 	Commit            *git.Commit
 	IsProtected       bool
+	ProtectionRule    *models.ProtectedBranch
 	IsDeleted         bool
 	IsIncluded        bool
 	DeletedBranch     *models.DeletedBranch
@@ -244,13 +245,7 @@ func loadOneBranch(ctx *context.Context, rawBranch, defaultBranch *git.Branch, p
 	}
 
 	branchName := rawBranch.Name
-	var isProtected bool
-	for _, b := range protectedBranches {
-		if b.BranchName == branchName {
-			isProtected = true
-			break
-		}
-	}
+	protectionRule := models.FindMatchingProtectedBranchRule(protectedBranches, branchName)
 
 	divergence := &git.DivergeObject{
 		Ahead:  -1,
@@ -314,7 +309,8 @@ func loadOneBranch(ctx *context.Context, rawBranch, defaultBranch *git.Branch, p
 	return &Branch{
 		Name:              branchName,
 		Commit:            commit,
-		IsProtected:       isProtected,
+		IsProtected:       protectionRule != nil,
+		ProtectionRule:    protectionRule,
 		IsIncluded:        isIncluded,
 		CommitsAhead:      divergence.Ahead,
 		CommitsBehind:     divergence.Behind,
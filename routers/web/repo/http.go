@@ -180,6 +180,19 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
+		if t := ctx.AccessToken(); t != nil {
+			requiredScope := models.AccessTokenScopeRepoRead
+			if !isPull {
+				requiredScope = models.AccessTokenScopeRepoWrite
+			}
+			// a repository created by the push belongs to the owner
+			allowed := t.AllowsOwner(owner.ID) || (repoExist && t.AllowsRepo(repo))
+			if !t.Scope.HasScope(requiredScope) || !allowed {
+				ctx.PlainText(http.StatusForbidden, "The access token is not allowed to access this repository.")
+				return
+			}
+		}
+
 		if repoExist {
 			p, err := models.GetUserRepoPermission(ctx, repo, ctx.Doer)
 			if err != nil {
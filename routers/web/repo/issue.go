@@ -1590,6 +1590,8 @@ func ViewIssue(ctx *context.Context) {
 				ctx.Data["MergeStyle"] = repo_model.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				ctx.Data["MergeStyle"] = repo_model.MergeStyleSquash
+			} else if prConfig.AllowFastForwardOnly {
+				ctx.Data["MergeStyle"] = repo_model.MergeStyleFastForwardOnly
 			} else if prConfig.AllowManualMerge {
 				ctx.Data["MergeStyle"] = repo_model.MergeStyleManuallyMerged
 			} else {
@@ -1611,6 +1613,14 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByRejection"] = pull.ProtectedBranch.MergeBlockedByRejectedReview(pull)
 			ctx.Data["IsBlockedByOfficialReviewRequests"] = pull.ProtectedBranch.MergeBlockedByOfficialReviewRequests(pull)
 			ctx.Data["IsBlockedByOutdatedBranch"] = pull.ProtectedBranch.MergeBlockedByOutdatedBranch(pull)
+			missingCodeOwnerApproval, err := pull_service.GetFilesMissingCodeOwnerApproval(ctx, pull)
+			if err != nil {
+				// e.g. an unparsable CODEOWNERS file, the merge is refused in this case as well
+				log.Error("GetFilesMissingCodeOwnerApproval[%d]: %v", pull.ID, err)
+			}
+			ctx.Data["IsBlockedByCodeOwners"] = err != nil || len(missingCodeOwnerApproval) != 0
+			ctx.Data["MissingCodeOwnerApprovalFiles"] = missingCodeOwnerApproval
+			ctx.Data["MissingCodeOwnerApprovalFilesNum"] = len(missingCodeOwnerApproval)
 			ctx.Data["GrantedApprovals"] = cnt
 			ctx.Data["RequireSigned"] = pull.ProtectedBranch.RequireSignedCommits
 			ctx.Data["ChangedProtectedFiles"] = pull.ChangedProtectedFiles
@@ -1666,6 +1676,51 @@ func ViewIssue(ctx *context.Context) {
 		}
 
 		ctx.Data["StillCanManualMerge"] = stillCanManualMerge()
+
+		// Check if there is a pending scheduled merge
+		exist, autoMerge, err := models.GetScheduledAutoMergeByPullID(ctx, pull.ID)
+		if err != nil {
+			ctx.ServerError("GetScheduledAutoMergeByPullID", err)
+			return
+		}
+		if exist {
+			if err := autoMerge.LoadDoer(ctx); err != nil {
+				if !user_model.IsErrUserNotExist(err) {
+					ctx.ServerError("LoadDoer", err)
+					return
+				}
+				autoMerge.Doer = user_model.NewGhostUser()
+			}
+			ctx.Data["PullAutoMerge"] = autoMerge
+			ctx.Data["CanCancelAutoMerge"] = ctx.IsSigned && (autoMerge.DoerID == ctx.Doer.ID || ctx.Data["AllowMerge"] == true)
+		}
+		ctx.Data["IsPullRequestAutoMergeScheduled"] = exist
+
+		// Check if the pull request is waiting in the merge queue
+		ctx.Data["IsMergeQueueEnabled"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.IsMergeQueueEnabled()
+		exist, queueEntry, err := models.GetMergeQueueEntryByPullID(ctx, pull.ID)
+		if err != nil {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		}
+		if exist {
+			if err := queueEntry.LoadDoer(ctx); err != nil {
+				if !user_model.IsErrUserNotExist(err) {
+					ctx.ServerError("LoadDoer", err)
+					return
+				}
+				queueEntry.Doer = user_model.NewGhostUser()
+			}
+			position, err := pull_service.GetMergeQueuePosition(ctx, pull)
+			if err != nil {
+				ctx.ServerError("GetMergeQueuePosition", err)
+				return
+			}
+			ctx.Data["MergeQueueEntry"] = queueEntry
+			ctx.Data["MergeQueuePosition"] = position
+			ctx.Data["CanRemoveFromMergeQueue"] = ctx.IsSigned && (queueEntry.DoerID == ctx.Doer.ID || ctx.Data["AllowMerge"] == true)
+		}
+		ctx.Data["IsPullRequestInMergeQueue"] = exist
 	}
 
 	// Get Dependencies
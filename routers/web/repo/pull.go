@@ -866,7 +866,26 @@ func MergePullRequest(ctx *context.Context) {
 	manuallMerge := repo_model.MergeStyle(form.Do) == repo_model.MergeStyleManuallyMerged
 	forceMerge := form.ForceMerge != nil && *form.ForceMerge
 
-	if err := pull_service.CheckPullMergable(ctx, ctx.Doer, &ctx.Repo.Permission, pr, manuallMerge, forceMerge); err != nil {
+	// protected branches with a merge queue are only merged through the queue unless the merge is forced
+	useMergeQueue := false
+	if !manuallMerge && !forceMerge {
+		var err error
+		if useMergeQueue, err = pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+			ctx.ServerError("IsMergeQueueEnabled", err)
+			return
+		}
+	}
+
+	mergeCheckType := pull_service.MergeCheckTypeGeneral
+	if manuallMerge {
+		mergeCheckType = pull_service.MergeCheckTypeManually
+	} else if useMergeQueue {
+		mergeCheckType = pull_service.MergeCheckTypeQueue
+	} else if form.MergeWhenChecksSucceed {
+		mergeCheckType = pull_service.MergeCheckTypeAuto
+	}
+
+	if err := pull_service.CheckPullMergable(ctx, ctx.Doer, &ctx.Repo.Permission, pr, mergeCheckType, forceMerge); err != nil {
 		if errors.Is(err, pull_service.ErrIsClosed) {
 			if issue.IsPull {
 				ctx.Flash.Error(ctx.Tr("repo.pulls.is_closed"))
@@ -930,6 +949,47 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	if useMergeQueue {
+		if err := pull_service.AddToMergeQueue(ctx, ctx.Doer, &ctx.Repo.Permission, pr, repo_model.MergeStyle(form.Do), form.MergeTitleField); err != nil {
+			if models.IsErrAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_already_added"))
+				ctx.Redirect(issue.Link())
+				return
+			} else if models.IsErrInvalidMergeStyle(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+				ctx.Redirect(issue.Link())
+				return
+			} else if models.IsErrDisallowedToMerge(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_not_ready"))
+				ctx.Redirect(issue.Link())
+				return
+			}
+			ctx.ServerError("AddToMergeQueue", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_added"))
+		ctx.Redirect(issue.Link())
+		return
+	}
+
+	if form.MergeWhenChecksSucceed {
+		scheduled, err := pull_service.ScheduleAutoMerge(ctx, ctx.Doer, &ctx.Repo.Permission, pr, repo_model.MergeStyle(form.Do), form.MergeTitleField)
+		if err != nil {
+			if models.IsErrAlreadyScheduledToAutoMerge(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_already_scheduled"))
+				ctx.Redirect(issue.Link())
+				return
+			}
+			ctx.ServerError("ScheduleAutoMerge", err)
+			return
+		} else if scheduled {
+			ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		// the pull request is mergeable already, so it is merged right away
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, form.MergeTitleField); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -968,6 +1028,11 @@ func MergePullRequest(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.unrelated_histories"))
 			ctx.Redirect(issue.Link())
 			return
+		} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+			log.Debug("MergeDivergingFastForwardOnly error: %v", err)
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_ff_only_needs_update"))
+			ctx.Redirect(issue.Link())
+			return
 		} else if git.IsErrPushOutOfDate(err) {
 			log.Debug("MergePushOutOfDate error: %v", err)
 			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_out_of_date"))
@@ -1039,6 +1104,94 @@ func MergePullRequest(ctx *context.Context) {
 	ctx.Redirect(issue.Link())
 }
 
+// CancelAutoMergePullRequest cancels a scheduled merge of a pull request
+func CancelAutoMergePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if !canCancelAutoMerge(ctx, issue.PullRequest) {
+		ctx.NotFound("CanCancelAutoMerge", nil)
+		return
+	}
+
+	if err := pull_service.RemoveScheduledAutoMerge(ctx.Doer, issue.PullRequest); err != nil {
+		if models.IsErrNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_not_scheduled"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		ctx.ServerError("RemoveScheduledAutoMerge", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled_schedule"))
+	ctx.Redirect(issue.Link())
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if !canRemoveFromMergeQueue(ctx, issue.PullRequest) {
+		ctx.NotFound("CanRemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(ctx.Doer, issue.PullRequest, ""); err != nil {
+		if models.IsErrNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_not_added"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	ctx.Redirect(issue.Link())
+}
+
+// canRemoveFromMergeQueue checks if the doer added the pull request to the merge queue or is allowed to merge it
+func canRemoveFromMergeQueue(ctx *context.Context, pr *models.PullRequest) bool {
+	exist, entry, err := models.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		log.Error("GetMergeQueueEntryByPullID: %v", err)
+		return false
+	} else if exist && entry.DoerID == ctx.Doer.ID {
+		return true
+	}
+
+	allowed, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.Doer)
+	if err != nil {
+		log.Error("IsUserAllowedToMerge: %v", err)
+		return false
+	}
+	return allowed
+}
+
+// canCancelAutoMerge checks if the doer scheduled the merge or is allowed to merge the pull request
+func canCancelAutoMerge(ctx *context.Context, pr *models.PullRequest) bool {
+	exist, scheduled, err := models.GetScheduledAutoMergeByPullID(ctx, pr.ID)
+	if err != nil {
+		log.Error("GetScheduledAutoMergeByPullID: %v", err)
+		return false
+	} else if exist && scheduled.DoerID == ctx.Doer.ID {
+		return true
+	}
+
+	allowed, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.Doer)
+	if err != nil {
+		log.Error("IsUserAllowedToMerge: %v", err)
+		return false
+	}
+	return allowed
+}
+
 func stopTimerIfAvailable(user *user_model.User, issue *models.Issue) error {
 	if models.StopwatchExists(user.ID, issue.ID) {
 		if err := models.CreateOrStopIssueStopwatch(user, issue); err != nil {
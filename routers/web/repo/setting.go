@@ -16,6 +16,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -31,6 +32,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/ssh"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/typesniffer"
 	"code.gitea.io/gitea/modules/util"
@@ -38,6 +40,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	"code.gitea.io/gitea/services/migrations"
@@ -173,6 +176,9 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+		if visibilityChanged {
+			audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoVisibility, audit.RepoTarget(repo), audit.VisibilityChange(!repo.IsPrivate, repo.IsPrivate))
+		}
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
@@ -323,9 +329,17 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		address, err := forms.ParseRemoteAddr(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
-		if err == nil {
-			err = migrations.IsMigrateURLAllowed(address, ctx.Doer)
+		var address string
+		if form.PushMirrorUseSSH {
+			address, err = forms.ParseSSHRemoteAddr(form.PushMirrorAddress)
+			if err == nil {
+				err = migrations.IsPushMirrorSSHURLAllowed(address)
+			}
+		} else {
+			address, err = forms.ParseRemoteAddr(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
+			if err == nil {
+				err = migrations.IsMigrateURLAllowed(address, ctx.Doer)
+			}
 		}
 		if err != nil {
 			ctx.Data["Err_PushMirrorAddress"] = true
@@ -345,6 +359,29 @@ func SettingsPost(ctx *context.Context) {
 			RemoteName: fmt.Sprintf("remote_mirror_%s", remoteSuffix),
 			Interval:   interval,
 		}
+		if form.PushMirrorUseSSH {
+			publicKey, privateKey, err := ssh.GenEd25519KeyPair(fmt.Sprintf("%s@%s", repo.FullName(), setting.Domain))
+			if err != nil {
+				ctx.ServerError("GenEd25519KeyPair", err)
+				return
+			}
+			m.PublicKey = publicKey
+			if err := m.SetPrivateKey(privateKey); err != nil {
+				ctx.ServerError("SetPrivateKey", err)
+				return
+			}
+
+			// without a host key the key of the first connection is recorded
+			if strings.TrimSpace(form.PushMirrorHostKey) != "" {
+				hostKey, err := ssh.ParseHostKey(form.PushMirrorHostKey)
+				if err != nil {
+					ctx.Data["Err_PushMirrorHostKey"] = true
+					ctx.RenderWithErr(ctx.Tr("repo.settings.mirror_settings.push_mirror.host_key_invalid"), tplSettingsOptions, &form)
+					return
+				}
+				m.SetHostKey(hostKey)
+			}
+		}
 		if err := repo_model.InsertPushMirror(m); err != nil {
 			ctx.ServerError("InsertPushMirror", err)
 			return
@@ -466,6 +503,7 @@ func SettingsPost(ctx *context.Context) {
 					AllowRebase:                   form.PullsAllowRebase,
 					AllowRebaseMerge:              form.PullsAllowRebaseMerge,
 					AllowSquash:                   form.PullsAllowSquash,
+					AllowFastForwardOnly:          form.PullsAllowFastForwardOnly,
 					AllowManualMerge:              form.PullsAllowManualMerge,
 					AutodetectManualMerge:         form.EnableAutodetectManualMerge,
 					AllowRebaseUpdate:             form.PullsAllowRebaseUpdate,
@@ -799,6 +837,8 @@ func handleSettingRemoteAddrError(ctx *context.Context, err error, form *forms.R
 	if models.IsErrInvalidCloneAddr(err) {
 		addrErr := err.(*models.ErrInvalidCloneAddr)
 		switch {
+		case addrErr.IsProtocolInvalid && form.PushMirrorUseSSH:
+			ctx.RenderWithErr(ctx.Tr("repo.settings.mirror_settings.push_mirror.ssh_address_invalid"), tplSettingsOptions, form)
 		case addrErr.IsProtocolInvalid:
 			ctx.RenderWithErr(ctx.Tr("repo.mirror_address_protocol_invalid"), tplSettingsOptions, form)
 		case addrErr.IsURLError:
@@ -888,6 +928,7 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
+	recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorAdd, u, perm.AccessModeNone, perm.AccessModeWrite)
 
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.Doer, ctx.Repo.Repository)
@@ -897,21 +938,63 @@ func CollaborationPost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + ctx.Req.URL.EscapedPath())
 }
 
+// recordCollaboratorEvent records an audit event of a collaborator of the repository
+func recordCollaboratorEvent(ctx *context.Context, action audit_model.Action, u *user_model.User, oldMode, newMode perm.AccessMode) {
+	var changes audit_model.Changes
+	changes.Add("access_mode", oldMode.String(), newMode.String())
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), action, audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetCollaborator, u.ID, u.Name), changes...)
+}
+
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
+	uid := ctx.FormInt64("uid")
+	before, err := models.GetCollaboration(ctx.Repo.Repository.ID, uid)
+	if err != nil {
+		log.Error("GetCollaboration: %v", err)
+		return
+	} else if before == nil {
+		return
+	}
+
 	if err := models.ChangeCollaborationAccessMode(
 		ctx.Repo.Repository,
-		ctx.FormInt64("uid"),
+		uid,
 		perm.AccessMode(ctx.FormInt("mode"))); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
+	}
+
+	after, err := models.GetCollaboration(ctx.Repo.Repository.ID, uid)
+	if err != nil || after == nil || after.Mode == before.Mode {
+		return
 	}
+	u, err := user_model.GetUserByID(uid)
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return
+	}
+	recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorEdit, u, before.Mode, after.Mode)
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
-	if err := models.DeleteCollaboration(ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
+	u, err := user_model.GetUserByID(ctx.FormInt64("id"))
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+	collaboration, err := models.GetCollaboration(ctx.Repo.Repository.ID, u.ID)
+	if err != nil {
+		ctx.ServerError("GetCollaboration", err)
+		return
+	}
+
+	if err := models.DeleteCollaboration(ctx.Repo.Repository, u.ID); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		if collaboration != nil {
+			recordCollaboratorEvent(ctx, audit_model.ActionRepoCollaboratorRemove, u, collaboration.Mode, perm.AccessModeNone)
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -1118,15 +1201,32 @@ func DeployKeysPost(ctx *context.Context) {
 	}
 
 	log.Trace("Deploy key added: %d", ctx.Repo.Repository.ID)
+	recordDeployKeyEvent(ctx, audit_model.ActionRepoDeployKeyAdd, key)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_key_success", key.Name))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/keys")
 }
 
+// recordDeployKeyEvent records an audit event of a deploy key of the repository
+func recordDeployKeyEvent(ctx *context.Context, action audit_model.Action, key *asymkey_model.DeployKey) {
+	changes := audit.DeployKeyChanges(key, action == audit_model.ActionRepoDeployKeyAdd)
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), action, audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetDeployKey, key.ID, key.Name), changes...)
+}
+
 // DeleteDeployKey response for deleting a deploy key
 func DeleteDeployKey(ctx *context.Context) {
-	if err := asymkey_service.DeleteDeployKey(ctx.Doer, ctx.FormInt64("id")); err != nil {
+	id := ctx.FormInt64("id")
+	key, err := asymkey_model.GetDeployKeyByID(ctx, id)
+	if err != nil && !asymkey_model.IsErrDeployKeyNotExist(err) {
+		ctx.ServerError("GetDeployKeyByID", err)
+		return
+	}
+
+	if err := asymkey_service.DeleteDeployKey(ctx.Doer, id); err != nil {
 		ctx.Flash.Error("DeleteDeployKey: " + err.Error())
 	} else {
+		if key != nil {
+			recordDeployKeyEvent(ctx, audit_model.ActionRepoDeployKeyRemove, key)
+		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_key_deletion_success"))
 	}
 
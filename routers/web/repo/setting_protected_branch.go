@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -21,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -94,15 +96,31 @@ func ProtectedBranchPost(ctx *context.Context) {
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(setting.AppSubURL + ctx.Req.URL.EscapedPath())
+	case "protected_branch_rule":
+		ruleName := strings.TrimSpace(ctx.FormString("rule_name"))
+		if !isValidProtectedBranchRuleName(ctx.Repo.GitRepo, ruleName) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.protected_branch_rule_invalid", ruleName))
+			ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
+			return
+		}
+		ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(ruleName)))
 	default:
 		ctx.NotFound("", nil)
 	}
 }
 
+// isValidProtectedBranchRuleName checks if the rule name is an existing branch or a valid glob pattern
+func isValidProtectedBranchRuleName(gitRepo *git.Repository, name string) bool {
+	if models.IsBranchNameGlob(name) {
+		return models.IsValidBranchNameGlob(name)
+	}
+	return name != "" && gitRepo.IsBranchExist(name)
+}
+
 // SettingsProtectedBranch renders the protected branch setting page
 func SettingsProtectedBranch(c *context.Context) {
 	branch := c.Params("*")
-	if !c.Repo.GitRepo.IsBranchExist(branch) {
+	if !isValidProtectedBranchRuleName(c.Repo.GitRepo, branch) {
 		c.NotFound("IsBranchExist", nil)
 		return
 	}
@@ -178,7 +196,7 @@ func SettingsProtectedBranch(c *context.Context) {
 func SettingsProtectedBranchPost(ctx *context.Context) {
 	f := web.GetForm(ctx).(*forms.ProtectBranchForm)
 	branch := ctx.Params("*")
-	if !ctx.Repo.GitRepo.IsBranchExist(branch) {
+	if !isValidProtectedBranchRuleName(ctx.Repo.GitRepo, branch) {
 		ctx.NotFound("IsBranchExist", nil)
 		return
 	}
@@ -199,6 +217,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				BranchName: branch,
 			}
 		}
+		before := *protectBranch
 		if f.RequiredApprovals < 0 {
 			ctx.Flash.Error(ctx.Tr("repo.settings.protected_branch_required_approvals_min"))
 			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(branch)))
@@ -242,6 +261,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		} else {
 			protectBranch.StatusCheckContexts = nil
 		}
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
 
 		protectBranch.RequiredApprovals = f.RequiredApprovals
 		protectBranch.EnableApprovalsWhitelist = f.EnableApprovalsWhitelist
@@ -256,6 +276,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.BlockOnRejectedReviews = f.BlockOnRejectedReviews
 		protectBranch.BlockOnOfficialReviewRequests = f.BlockOnOfficialReviewRequests
 		protectBranch.DismissStaleApprovals = f.DismissStaleApprovals
+		protectBranch.RequireCodeOwnerReview = f.RequireCodeOwnerReview
 		protectBranch.RequireSignedCommits = f.RequireSignedCommits
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
@@ -273,8 +294,11 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
-		if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
-			ctx.ServerError("CheckPrsForBaseBranch", err)
+		audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoBranchProtectEdit,
+			audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetProtectedBranch, protectBranch.ID, branch),
+			audit.ProtectedBranchChanges(&before, protectBranch)...)
+		if err = pull_service.CheckPrsForProtectedBranchRule(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+			ctx.ServerError("CheckPrsForProtectedBranchRule", err)
 			return
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_protect_branch_success", branch))
@@ -285,6 +309,8 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionRepoBranchProtectRemove,
+				audit.RepoObjectTarget(ctx.Repo.Repository, audit_model.TargetProtectedBranch, protectBranch.ID, branch))
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	shared "code.gitea.io/gitea/routers/web/shared/pushrule"
+)
+
+const tplPushRules base.TplName = "repo/settings/push_rules"
+
+// PushRules shows the push rule of the repository
+func PushRules(ctx *context.Context) {
+	pr := preparePushRulesContext(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	shared.SetPushRuleContext(ctx, pr)
+
+	ctx.HTML(http.StatusOK, tplPushRules)
+}
+
+// PushRulesPost updates the push rule of the repository
+func PushRulesPost(ctx *context.Context) {
+	pr := preparePushRulesContext(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	shared.PerformPushRulePost(ctx, pr, ctx.Repo.RepoLink+"/settings/push_rules", tplPushRules)
+}
+
+func preparePushRulesContext(ctx *context.Context) *repo_model.PushRule {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.push_rules")
+	ctx.Data["PageIsSettingsPushRules"] = true
+
+	pr, err := repo_model.GetRepoPushRule(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoPushRule", err)
+		return nil
+	}
+
+	ownerRule, err := repo_model.GetOwnerPushRule(ctx, ctx.Repo.Repository.OwnerID)
+	if err != nil {
+		ctx.ServerError("GetOwnerPushRule", err)
+		return nil
+	}
+	ctx.Data["HasOwnerPushRule"] = !ownerRule.IsEmpty()
+
+	return pr
+}
@@ -180,6 +180,11 @@ func ParseHookEvent(form forms.WebhookForm) *webhook.HookEvent {
 			PullRequestSync:      form.PullRequestSync,
 			Repository:           form.Repository,
 			Package:              form.Package,
+			Status:               form.Status,
+			Wiki:                 form.Wiki,
+			Star:                 form.Star,
+			Member:               form.Member,
+			Team:                 form.Team,
 		},
 		BranchFilter: form.BranchFilter,
 	}
@@ -0,0 +1,97 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const dateFormat = "2006-01-02"
+
+// SetAuditEventsContext loads the audit events matching the filters of the request.
+// If ownerID is not 0 only the events of targets owned by this user or organization are loaded.
+func SetAuditEventsContext(ctx *context.Context, ownerID int64) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := &audit_model.FindEventsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.NoticePagingNum,
+		},
+		Action:  audit_model.Action(ctx.FormTrim("action")),
+		OwnerID: ownerID,
+	}
+	if !opts.Action.IsValid() {
+		opts.Action = ""
+	}
+
+	actor := ctx.FormTrim("actor")
+	if actor != "" {
+		u, err := user_model.GetUserByName(actor)
+		if err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				ctx.ServerError("GetUserByName", err)
+				return
+			}
+			// an unknown actor matches no events
+			u = &user_model.User{ID: -1}
+		}
+		opts.ActorID = u.ID
+	}
+
+	since, sinceStr := parseDate(ctx.FormTrim("since"))
+	if !since.IsZero() {
+		opts.Since = timeutil.TimeStamp(since.Unix())
+	}
+	until, untilStr := parseDate(ctx.FormTrim("until"))
+	if !until.IsZero() {
+		// the until date is inclusive
+		opts.Until = timeutil.TimeStamp(until.AddDate(0, 0, 1).Unix())
+	}
+
+	events, total, err := audit_model.FindEvents(ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindEvents", err)
+		return
+	}
+
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["AuditActions"] = audit_model.AllActions
+	ctx.Data["Action"] = string(opts.Action)
+	ctx.Data["Actor"] = actor
+	ctx.Data["Since"] = sinceStr
+	ctx.Data["Until"] = untilStr
+
+	pager := context.NewPagination(int(total), opts.PageSize, page, 5)
+	pager.AddParamString("action", string(opts.Action))
+	pager.AddParamString("actor", actor)
+	pager.AddParamString("since", sinceStr)
+	pager.AddParamString("until", untilStr)
+	ctx.Data["Page"] = pager
+}
+
+// parseDate parses a date of the form YYYY-MM-DD in the time zone of the UI.
+// Invalid dates are ignored.
+func parseDate(s string) (time.Time, string) {
+	if s == "" {
+		return time.Time{}, ""
+	}
+	t, err := time.ParseInLocation(dateFormat, s, setting.DefaultUILocation)
+	if err != nil {
+		return time.Time{}, ""
+	}
+	return t, s
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pushrule
+
+import (
+	"net/http"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/repository/pushrule"
+
+	"github.com/dustin/go-humanize"
+)
+
+// SetPushRuleContext prepares the form to edit the push rule
+func SetPushRuleContext(ctx *context.Context, pr *repo_model.PushRule) {
+	maxFileSize := ""
+	if pr.MaxFileSize > 0 {
+		maxFileSize = humanize.IBytes(uint64(pr.MaxFileSize))
+	}
+	ctx.Data["max_file_size"] = maxFileSize
+	ctx.Data["forbidden_file_patterns"] = strings.Join(pr.GetForbiddenFilePatterns(), "\n")
+	ctx.Data["reject_secrets"] = pr.RejectSecrets
+	ctx.Data["SecretPatternNames"] = strings.Join(pushrule.SecretPatternNames(), ", ")
+}
+
+// PerformPushRulePost updates or removes the push rule
+func PerformPushRulePost(ctx *context.Context, pr *repo_model.PushRule, redirectURL string, template base.TplName) {
+	form := web.GetForm(ctx).(*forms.PushRuleForm)
+
+	ctx.Data["SecretPatternNames"] = strings.Join(pushrule.SecretPatternNames(), ", ")
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, template)
+		return
+	}
+
+	var maxFileSize uint64
+	if value := strings.TrimSpace(form.MaxFileSize); value != "" {
+		var err error
+		if maxFileSize, err = humanize.ParseBytes(value); err != nil {
+			ctx.Data["Err_MaxFileSize"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.push_rules.max_file_size_invalid"), template, form)
+			return
+		}
+	}
+
+	pr.MaxFileSize = int64(maxFileSize)
+	pr.ForbiddenFilePatterns = form.ForbiddenFilePatterns
+	pr.RejectSecrets = form.RejectSecrets
+
+	if _, err := pr.CompileForbiddenFilePatterns(); err != nil {
+		ctx.Data["Err_ForbiddenFilePatterns"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.push_rules.forbidden_file_patterns_invalid", err.Error()), template, form)
+		return
+	}
+	pr.ForbiddenFilePatterns = strings.Join(pr.GetForbiddenFilePatterns(), "\n")
+
+	if err := repo_model.SavePushRule(ctx, pr); err != nil {
+		ctx.ServerError("SavePushRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.push_rules.update_success"))
+	ctx.Redirect(redirectURL)
+}
@@ -7,13 +7,19 @@ package setting
 
 import (
 	"net/http"
+	"strings"
+	"time"
+	"unicode"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 )
 
@@ -49,6 +55,34 @@ func ApplicationsPost(ctx *context.Context) {
 		Name: form.Name,
 	}
 
+	var err error
+	if t.Scope, err = models.ParseAccessTokenScopes(form.Scopes); err != nil {
+		ctx.Flash.Error(ctx.Tr("settings.token_scope_invalid", err.(models.ErrAccessTokenInvalidScope).Scope))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+	if err = t.SetAllowedRepos(ctx, strings.FieldsFunc(form.AllowedRepos, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})); err != nil {
+		if models.IsErrAccessTokenInvalidAllowedRepo(err) {
+			ctx.Flash.Error(ctx.Tr("settings.token_allowed_repos_invalid", err.(models.ErrAccessTokenInvalidAllowedRepo).Repo))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		} else {
+			ctx.ServerError("SetAllowedRepos", err)
+		}
+		return
+	}
+	if form.ExpiresAt != "" {
+		expiresAt, err := time.ParseInLocation("2006-01-02", form.ExpiresAt, setting.DefaultUILocation)
+		if err != nil || !expiresAt.After(time.Now()) {
+			ctx.Flash.Error(ctx.Tr("settings.token_expires_at_invalid"))
+			ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+			return
+		}
+		// the token is valid until the end of the selected day
+		t.ExpiresUnix = timeutil.TimeStamp(expiresAt.AddDate(0, 0, 1).Unix() - 1)
+	}
+
 	exist, err := models.AccessTokenByNameExists(t)
 	if err != nil {
 		ctx.ServerError("AccessTokenByNameExists", err)
@@ -64,6 +98,8 @@ func ApplicationsPost(ctx *context.Context) {
 		ctx.ServerError("NewAccessToken", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserAccessTokenAdd,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetAccessToken, t.ID, t.Name), audit.AccessTokenChanges(t)...)
 
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
@@ -73,9 +109,14 @@ func ApplicationsPost(ctx *context.Context) {
 
 // DeleteApplication response for delete user access token
 func DeleteApplication(ctx *context.Context) {
-	if err := models.DeleteAccessTokenByID(ctx.FormInt64("id"), ctx.Doer.ID); err != nil {
+	t, err := models.GetAccessTokenByID(ctx.FormInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetAccessTokenByID: " + err.Error())
+	} else if err := models.DeleteAccessTokenByID(t.ID, ctx.Doer.ID); err != nil {
 		ctx.Flash.Error("DeleteAccessTokenByID: " + err.Error())
 	} else {
+		audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserAccessTokenRemove,
+			audit.UserObjectTarget(ctx.Doer, audit_model.TargetAccessToken, t.ID, t.Name))
 		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
 	}
 
@@ -90,7 +131,14 @@ func loadApplicationsData(ctx *context.Context) {
 		ctx.ServerError("ListAccessTokens", err)
 		return
 	}
+	for _, t := range tokens {
+		if err := t.LoadAllowedRepos(ctx); err != nil {
+			ctx.ServerError("LoadAllowedRepos", err)
+			return
+		}
+	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["AccessTokenScopes"] = models.AllAccessTokenScopes
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = auth.GetOAuth2ApplicationsByUserID(ctx.Doer.ID)
@@ -0,0 +1,95 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	shared "code.gitea.io/gitea/routers/web/shared/packages"
+)
+
+const (
+	tplSettingsPackages            base.TplName = "user/settings/packages"
+	tplSettingsPackagesRuleEdit    base.TplName = "user/settings/packages_cleanup_rules_edit"
+	tplSettingsPackagesRulePreview base.TplName = "user/settings/packages_cleanup_rules_preview"
+)
+
+// Packages render the package cleanup rules of the user
+func Packages(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetPackagesContext(ctx, ctx.Doer)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackages)
+}
+
+// PackagesRuleAdd render the form to add a cleanup rule
+func PackagesRuleAdd(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRuleAddContext(ctx)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRuleEdit)
+}
+
+// PackagesRuleEdit render the form to edit a cleanup rule
+func PackagesRuleEdit(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRuleEditContext(ctx, ctx.Doer)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRuleEdit)
+}
+
+// PackagesRuleAddPost creates a cleanup rule
+func PackagesRuleAddPost(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.PerformRuleAddPost(
+		ctx,
+		ctx.Doer,
+		ctx.Data["PackagesSettingsLink"].(string),
+		tplSettingsPackagesRuleEdit,
+	)
+}
+
+// PackagesRuleEditPost updates or removes a cleanup rule
+func PackagesRuleEditPost(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.PerformRuleEditPost(
+		ctx,
+		ctx.Doer,
+		ctx.Data["PackagesSettingsLink"].(string),
+		tplSettingsPackagesRuleEdit,
+	)
+}
+
+// PackagesRulePreview render the package versions a cleanup rule would remove
+func PackagesRulePreview(ctx *context.Context) {
+	setPackagesSettingsContext(ctx)
+
+	shared.SetRulePreviewContext(ctx, ctx.Doer)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRulePreview)
+}
+
+func setPackagesSettingsContext(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsSettingsPackages"] = true
+	ctx.Data["PackagesSettingsLink"] = setting.AppSubURL + "/user/settings/packages"
+}
@@ -13,11 +13,13 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/pquerna/otp"
@@ -49,6 +51,8 @@ func RegenerateScratchTwoFactor(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to UpdateTwoFactor", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserTwoFactorScratch,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetTwoFactor, t.ID, "totp"))
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_scratch_token_regenerated", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -78,6 +82,8 @@ func DisableTwoFactor(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to DeleteTwoFactorByID", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserTwoFactorDisable,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetTwoFactor, t.ID, "totp"))
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_disabled"))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -244,6 +250,8 @@ func EnrollTwoFactorPost(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to save two factor", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserTwoFactorEnable,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetTwoFactor, t.ID, "totp"))
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -69,6 +69,7 @@ func loadSecurityData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["WebAuthnCredentials"] = credentials
+	ctx.Data["EnablePasskeySignIn"] = setting.Service.EnablePasskeySignIn
 
 	tokens, err := models.ListAccessTokens(models.ListAccessTokensOptions{UserID: ctx.Doer.ID})
 	if err != nil {
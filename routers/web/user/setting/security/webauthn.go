@@ -8,12 +8,14 @@ import (
 	"errors"
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	wa "code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 
 	"github.com/duo-labs/webauthn/protocol"
@@ -44,7 +46,23 @@ func WebAuthnRegister(ctx *context.Context) {
 		return
 	}
 
-	credentialOptions, sessionData, err := wa.WebAuthn.BeginRegistration((*wa.User)(ctx.Doer))
+	var opts []webauthn.RegistrationOption
+	passkey := form.Passkey && setting.Service.EnablePasskeySignIn
+	if passkey {
+		// a passkey replaces the password, so the authenticator has to store it and verify the user
+		opts = append(opts,
+			webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+				UserVerification: protocol.VerificationRequired,
+			}),
+			webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		)
+	}
+	if err := ctx.Session.Set("webauthnPasskey", passkey); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnPasskey", err)
+		return
+	}
+
+	credentialOptions, sessionData, err := wa.WebAuthn.BeginRegistration((*wa.User)(ctx.Doer), opts...)
 	if err != nil {
 		ctx.ServerError("Unable to BeginRegistration", err)
 		return
@@ -98,12 +116,19 @@ func WebauthnRegisterPost(ctx *context.Context) {
 	}
 
 	// Create the credential
-	_, err = auth.CreateCredential(ctx.Doer.ID, name, cred)
+	if passkey, _ := ctx.Session.Get("webauthnPasskey").(bool); passkey {
+		dbCred, err = auth.CreatePasskey(ctx.Doer.ID, name, cred)
+	} else {
+		dbCred, err = auth.CreateCredential(ctx.Doer.ID, name, cred)
+	}
 	if err != nil {
 		ctx.ServerError("CreateCredential", err)
 		return
 	}
+	audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserWebAuthnAdd,
+		audit.UserObjectTarget(ctx.Doer, audit_model.TargetWebAuthn, dbCred.ID, dbCred.Name))
 	_ = ctx.Session.Delete("webauthnName")
+	_ = ctx.Session.Delete("webauthnPasskey")
 
 	ctx.JSON(http.StatusCreated, cred)
 }
@@ -111,10 +136,20 @@ func WebauthnRegisterPost(ctx *context.Context) {
 // WebauthnDelete deletes an security key by id
 func WebauthnDelete(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.WebauthnDeleteForm)
-	if _, err := auth.DeleteCredential(form.ID, ctx.Doer.ID); err != nil {
+	cred, err := auth.GetWebAuthnCredentialByID(form.ID)
+	if err != nil {
 		ctx.ServerError("GetWebAuthnCredentialByID", err)
 		return
 	}
+	deleted, err := auth.DeleteCredential(form.ID, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("DeleteCredential", err)
+		return
+	}
+	if deleted {
+		audit.Record(ctx.Doer, ctx.RemoteAddr(), audit_model.ActionUserWebAuthnRemove,
+			audit.UserObjectTarget(ctx.Doer, audit_model.TargetWebAuthn, cred.ID, cred.Name))
+	}
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/user/settings/security",
 	})
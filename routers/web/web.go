@@ -117,6 +117,7 @@ func Routes() *web.Route {
 		SameSite:       setting.SessionConfig.SameSite,
 		Domain:         setting.SessionConfig.Domain,
 	})
+	routes.Use(auth.RepostSAMLResponse())
 	routes.Use(sessioner)
 
 	routes.Use(Recovery())
@@ -250,6 +251,13 @@ func RegisterRoutes(m *web.Route) {
 		}
 	}
 
+	passkeySignInEnabled := func(ctx *context.Context) {
+		if !setting.Service.EnablePasskeySignIn {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
+	}
+
 	openIDSignUpEnabled := func(ctx *context.Context) {
 		if !setting.Service.EnableOpenIDSignUp {
 			ctx.Error(http.StatusForbidden)
@@ -337,6 +345,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{provider}", auth.SignInOAuth)
 			m.Get("/{provider}/callback", auth.SignInOAuthCallback)
 		})
+		m.Group("/saml", func() {
+			m.Get("/{provider}", auth.SignInSAML)
+			m.Post("/{provider}/acs", auth.SignInSAMLAssertionConsumerService)
+		})
 		m.Get("/link_account", linkAccountEnabled, auth.LinkAccount)
 		m.Post("/link_account_signin", linkAccountEnabled, bindIgnErr(forms.SignInForm{}), auth.LinkAccountPostSignIn)
 		m.Post("/link_account_signup", linkAccountEnabled, bindIgnErr(forms.RegisterForm{}), auth.LinkAccountPostRegister)
@@ -351,6 +363,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/assertion", auth.WebAuthnLoginAssertion)
 			m.Post("/assertion", auth.WebAuthnLoginAssertionPost)
 		})
+		m.Group("/passkey", func() {
+			m.Get("/assertion", auth.PasskeyLoginAssertion)
+			m.Post("/assertion", auth.PasskeyLoginAssertionPost)
+		}, passkeySignInEnabled)
 	}, reqSignOut)
 
 	m.Any("/user/events", routing.MarkLongPolling, events.Events)
@@ -364,6 +380,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Get("/login/oauth/userinfo", ignSignInAndCsrf, auth.InfoOAuth)
 	m.Post("/login/oauth/access_token", CorsHandler(), bindIgnErr(forms.AccessTokenForm{}), ignSignInAndCsrf, auth.AccessTokenOAuth)
 	m.Get("/login/oauth/keys", ignSignInAndCsrf, auth.OIDCKeys)
+	m.Get("/user/saml/{provider}/metadata", ignSignInAndCsrf, auth.SAMLMetadata)
 	m.Post("/login/oauth/introspect", CorsHandler(), bindIgnErr(forms.IntrospectTokenForm{}), ignSignInAndCsrf, auth.IntrospectOAuth)
 
 	m.Group("/user/settings", func() {
@@ -422,9 +439,26 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/organization", user_setting.Organization)
 		m.Get("/repos", user_setting.Repos)
 		m.Post("/repos/unadopted", user_setting.AdoptOrDeleteRepository)
+		if setting.Packages.Enabled {
+			m.Group("/packages", func() {
+				m.Get("", user_setting.Packages)
+				m.Group("/rules", func() {
+					m.Group("/add", func() {
+						m.Get("", user_setting.PackagesRuleAdd)
+						m.Post("", bindIgnErr(forms.PackageCleanupRuleForm{}), user_setting.PackagesRuleAddPost)
+					})
+					m.Group("/{id}", func() {
+						m.Get("", user_setting.PackagesRuleEdit)
+						m.Post("", bindIgnErr(forms.PackageCleanupRuleForm{}), user_setting.PackagesRuleEditPost)
+						m.Get("/preview", user_setting.PackagesRulePreview)
+					})
+				})
+			})
+		}
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
 		ctx.Data["AllThemes"] = setting.UI.Themes
+		ctx.Data["IsPackageEnabled"] = setting.Packages.Enabled
 	})
 
 	m.Group("/user", func() {
@@ -548,6 +582,9 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
+
+		m.Get("/audit", admin.Audit)
+		m.Get("/quotas", admin.Quotas)
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -636,6 +673,9 @@ func RegisterRoutes(m *web.Route) {
 					Post(bindIgnErr(forms.UpdateOrgSettingForm{}), org.SettingsPost)
 				m.Post("/avatar", bindIgnErr(forms.AvatarForm{}), org.SettingsAvatar)
 				m.Post("/avatar/delete", org.SettingsDeleteAvatar)
+				m.Get("/audit", org.SettingsAudit)
+				m.Combo("/push_rules").Get(org.SettingsPushRules).
+					Post(bindIgnErr(forms.PushRuleForm{}), org.SettingsPushRulesPost)
 
 				m.Group("/hooks", func() {
 					m.Get("", org.Webhooks)
@@ -675,6 +715,23 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				if setting.Packages.Enabled {
+					m.Group("/packages", func() {
+						m.Get("", org.Packages)
+						m.Group("/rules", func() {
+							m.Group("/add", func() {
+								m.Get("", org.PackagesRuleAdd)
+								m.Post("", bindIgnErr(forms.PackageCleanupRuleForm{}), org.PackagesRuleAddPost)
+							})
+							m.Group("/{id}", func() {
+								m.Get("", org.PackagesRuleEdit)
+								m.Post("", bindIgnErr(forms.PackageCleanupRuleForm{}), org.PackagesRuleEditPost)
+								m.Get("/preview", org.PackagesRulePreview)
+							})
+						})
+					})
+				}
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -740,6 +797,8 @@ func RegisterRoutes(m *web.Route) {
 					Post(bindIgnErr(forms.ProtectBranchForm{}), context.RepoMustNotBeArchived(), repo.SettingsProtectedBranchPost)
 			}, repo.MustBeNotEmpty)
 			m.Post("/rename_branch", bindIgnErr(forms.RenameBranchForm{}), context.RepoMustNotBeArchived(), repo.RenameBranchPost)
+			m.Combo("/push_rules").Get(repo.PushRules).
+				Post(bindIgnErr(forms.PushRuleForm{}), context.RepoMustNotBeArchived(), repo.PushRulesPost)
 
 			m.Group("/tags", func() {
 				m.Get("", repo.Tags)
@@ -1105,6 +1164,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
+			m.Post("/remove_from_merge_queue", context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/set_allow_maintainer_edit", bindIgnErr(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
@@ -1214,7 +1275,7 @@ func RegisterRoutes(m *web.Route) {
 				m.GetOptions("/objects/{head:[0-9a-f]{2}}/{hash:[0-9a-f]{38}}", repo.GetLooseObject)
 				m.GetOptions("/objects/pack/pack-{file:[0-9a-f]{40}}.pack", repo.GetPackFile)
 				m.GetOptions("/objects/pack/pack-{file:[0-9a-f]{40}}.idx", repo.GetIdxFile)
-			}, ignSignInAndCsrf, context_service.UserAssignmentWeb())
+			}, ignSignInAndCsrf, context.RateLimit(), context_service.UserAssignmentWeb())
 		})
 	})
 	// ***** END: Repository *****
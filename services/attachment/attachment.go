@@ -11,6 +11,7 @@ import (
 	"io"
 
 	"code.gitea.io/gitea/models/db"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
@@ -20,6 +21,7 @@ import (
 )
 
 // NewAttachment creates a new attachment object, but do not verify.
+// ErrQuotaExceeded is returned if the attachment exceeds the quota of the repository owner.
 func NewAttachment(attach *repo_model.Attachment, file io.Reader) (*repo_model.Attachment, error) {
 	if attach.RepoID == 0 {
 		return nil, fmt.Errorf("attachment %s should belong to a repository", attach.Name)
@@ -33,6 +35,17 @@ func NewAttachment(attach *repo_model.Attachment, file io.Reader) (*repo_model.A
 		}
 		attach.Size = size
 
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, attach.RepoID)
+		if err != nil {
+			return err
+		}
+		if err := quota_model.CheckQuotaByOwnerID(ctx, repo.OwnerID, size); err != nil {
+			if err := storage.Attachments.Delete(attach.RelativePath()); err != nil {
+				return fmt.Errorf("Delete: %v", err)
+			}
+			return err
+		}
+
 		return db.Insert(ctx, attach)
 	})
 
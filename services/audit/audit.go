@@ -0,0 +1,195 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"net"
+
+	"code.gitea.io/gitea/models"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Target is the object an audited action was performed on
+type Target struct {
+	Type    audit_model.TargetType
+	ID      int64
+	Name    string
+	OwnerID int64
+}
+
+// UserTarget returns the audit target for a user
+func UserTarget(u *user_model.User) Target {
+	return Target{Type: audit_model.TargetUser, ID: u.ID, Name: u.Name, OwnerID: u.ID}
+}
+
+// UserObjectTarget returns the audit target for an object belonging to a user like an access token
+func UserObjectTarget(u *user_model.User, tp audit_model.TargetType, id int64, name string) Target {
+	return Target{Type: tp, ID: id, Name: name, OwnerID: u.ID}
+}
+
+// TeamTarget returns the audit target for an organization team
+func TeamTarget(org *organization.Organization, team *organization.Team) Target {
+	return Target{Type: audit_model.TargetTeam, ID: team.ID, Name: org.Name + "/" + team.Name, OwnerID: org.ID}
+}
+
+// RepoTarget returns the audit target for a repository
+func RepoTarget(repo *repo_model.Repository) Target {
+	return Target{Type: audit_model.TargetRepository, ID: repo.ID, Name: repo.FullName(), OwnerID: repo.OwnerID}
+}
+
+// RepoObjectTarget returns the audit target for an object belonging to a repository like a deploy key
+func RepoObjectTarget(repo *repo_model.Repository, tp audit_model.TargetType, id int64, name string) Target {
+	return Target{Type: tp, ID: id, Name: repo.FullName() + ":" + name, OwnerID: repo.OwnerID}
+}
+
+// Record stores an audit event. The audited action has already happened, so errors are only logged.
+func Record(actor *user_model.User, remoteAddr string, action audit_model.Action, target Target, changes ...*audit_model.Change) {
+	e := &audit_model.Event{
+		Action:     action,
+		IP:         remoteIP(remoteAddr),
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		OwnerID:    target.OwnerID,
+		Changes:    changes,
+	}
+	if actor != nil {
+		e.ActorID = actor.ID
+		e.ActorName = actor.Name
+	}
+
+	// the request context may already be cancelled, the event must be stored anyway
+	if err := audit_model.Insert(db.DefaultContext, e); err != nil {
+		log.Error("Unable to record audit event %s of %s: %v", action, target.Name, err)
+	}
+}
+
+// RemoteAddr returns the remote address of the request the context belongs to.
+// It is empty for background tasks like the group synchronization of authentication sources.
+func RemoteAddr(ctx context.Context) string {
+	if r, ok := ctx.(interface{ RemoteAddr() string }); ok {
+		return r.RemoteAddr()
+	}
+	return ""
+}
+
+func remoteIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// UserChanges returns the changes of the security relevant fields of a user.
+// The password hash is never recorded, only the fact that it was changed.
+func UserChanges(before, after *user_model.User) audit_model.Changes {
+	var changes audit_model.Changes
+	changes.Add("name", before.Name, after.Name)
+	changes.Add("email", before.Email, after.Email)
+	changes.Add("login_source", before.LoginSource, after.LoginSource)
+	changes.Add("login_name", before.LoginName, after.LoginName)
+	changes.Add("is_active", before.IsActive, after.IsActive)
+	changes.Add("is_admin", before.IsAdmin, after.IsAdmin)
+	changes.Add("is_restricted", before.IsRestricted, after.IsRestricted)
+	changes.Add("prohibit_login", before.ProhibitLogin, after.ProhibitLogin)
+	changes.Add("allow_git_hook", before.AllowGitHook, after.AllowGitHook)
+	changes.Add("allow_import_local", before.AllowImportLocal, after.AllowImportLocal)
+	changes.Add("allow_create_organization", before.AllowCreateOrganization, after.AllowCreateOrganization)
+	changes.Add("max_repo_creation", before.MaxRepoCreation, after.MaxRepoCreation)
+	changes.Add("quota_size", before.QuotaSize, after.QuotaSize)
+	changes.Add("visibility", before.Visibility.String(), after.Visibility.String())
+	if before.Passwd != after.Passwd {
+		changes = append(changes, &audit_model.Change{Field: "password"})
+	}
+	return changes
+}
+
+// TeamChanges returns the changes of the permissions of a team. The units of both teams must be loaded.
+func TeamChanges(before, after *organization.Team) audit_model.Changes {
+	var changes audit_model.Changes
+	changes.Add("name", before.Name, after.Name)
+	changes.Add("access_mode", before.AccessMode.String(), after.AccessMode.String())
+	changes.Add("includes_all_repositories", before.IncludesAllRepositories, after.IncludesAllRepositories)
+	changes.Add("can_create_org_repo", before.CanCreateOrgRepo, after.CanCreateOrgRepo)
+	changes.Add("units", before.GetUnitsMap(), after.GetUnitsMap())
+	return changes
+}
+
+// MemberAdded returns the change recorded when a user is added to a team
+func MemberAdded(u *user_model.User) *audit_model.Change {
+	return &audit_model.Change{Field: "member", New: u.Name}
+}
+
+// MemberRemoved returns the change recorded when a user is removed from a team
+func MemberRemoved(u *user_model.User) *audit_model.Change {
+	return &audit_model.Change{Field: "member", Old: u.Name}
+}
+
+// VisibilityChange returns the change of the visibility of a repository
+func VisibilityChange(wasPrivate, isPrivate bool) *audit_model.Change {
+	return &audit_model.Change{Field: "private", Old: wasPrivate, New: isPrivate}
+}
+
+// DeployKeyChanges returns the fingerprint and access mode of an added or removed deploy key
+func DeployKeyChanges(key *asymkey_model.DeployKey, added bool) audit_model.Changes {
+	var changes audit_model.Changes
+	if added {
+		changes.Add("fingerprint", nil, key.Fingerprint)
+		changes.Add("access_mode", nil, key.Mode.String())
+	} else {
+		changes.Add("fingerprint", key.Fingerprint, nil)
+		changes.Add("access_mode", key.Mode.String(), nil)
+	}
+	return changes
+}
+
+// ProtectedBranchChanges returns the changes of a protected branch rule
+func ProtectedBranchChanges(before, after *models.ProtectedBranch) audit_model.Changes {
+	var changes audit_model.Changes
+	changes.Add("can_push", before.CanPush, after.CanPush)
+	changes.Add("enable_whitelist", before.EnableWhitelist, after.EnableWhitelist)
+	changes.Add("whitelist_user_ids", before.WhitelistUserIDs, after.WhitelistUserIDs)
+	changes.Add("whitelist_team_ids", before.WhitelistTeamIDs, after.WhitelistTeamIDs)
+	changes.Add("whitelist_deploy_keys", before.WhitelistDeployKeys, after.WhitelistDeployKeys)
+	changes.Add("enable_merge_whitelist", before.EnableMergeWhitelist, after.EnableMergeWhitelist)
+	changes.Add("merge_whitelist_user_ids", before.MergeWhitelistUserIDs, after.MergeWhitelistUserIDs)
+	changes.Add("merge_whitelist_team_ids", before.MergeWhitelistTeamIDs, after.MergeWhitelistTeamIDs)
+	changes.Add("enable_status_check", before.EnableStatusCheck, after.EnableStatusCheck)
+	changes.Add("status_check_contexts", before.StatusCheckContexts, after.StatusCheckContexts)
+	changes.Add("enable_approvals_whitelist", before.EnableApprovalsWhitelist, after.EnableApprovalsWhitelist)
+	changes.Add("approvals_whitelist_user_ids", before.ApprovalsWhitelistUserIDs, after.ApprovalsWhitelistUserIDs)
+	changes.Add("approvals_whitelist_team_ids", before.ApprovalsWhitelistTeamIDs, after.ApprovalsWhitelistTeamIDs)
+	changes.Add("required_approvals", before.RequiredApprovals, after.RequiredApprovals)
+	changes.Add("block_on_rejected_reviews", before.BlockOnRejectedReviews, after.BlockOnRejectedReviews)
+	changes.Add("block_on_official_review_requests", before.BlockOnOfficialReviewRequests, after.BlockOnOfficialReviewRequests)
+	changes.Add("block_on_outdated_branch", before.BlockOnOutdatedBranch, after.BlockOnOutdatedBranch)
+	changes.Add("dismiss_stale_approvals", before.DismissStaleApprovals, after.DismissStaleApprovals)
+	changes.Add("require_signed_commits", before.RequireSignedCommits, after.RequireSignedCommits)
+	changes.Add("protected_file_patterns", before.ProtectedFilePatterns, after.ProtectedFilePatterns)
+	changes.Add("unprotected_file_patterns", before.UnprotectedFilePatterns, after.UnprotectedFilePatterns)
+	changes.Add("require_code_owner_review", before.RequireCodeOwnerReview, after.RequireCodeOwnerReview)
+	changes.Add("enable_merge_queue", before.EnableMergeQueue, after.EnableMergeQueue)
+	return changes
+}
+
+// AccessTokenChanges returns the permissions of a created access token
+func AccessTokenChanges(t *models.AccessToken) audit_model.Changes {
+	var changes audit_model.Changes
+	changes.Add("scope", nil, string(t.Scope))
+	if t.IsRestricted() {
+		changes.Add("allowed_repos", nil, t.AllowedRepos)
+	}
+	if t.ExpiresUnix > 0 {
+		changes.Add("expires_unix", nil, int64(t.ExpiresUnix))
+	}
+	return changes
+}
@@ -91,19 +91,51 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 		}
 		return uid
 	}
+	t := checkAccessToken(tokenSHA, store)
+	if t == nil {
+		return 0
+	}
+	return t.UID
+}
+
+// checkAccessToken returns the personal access token of the value if it exists and hasn't expired.
+// The token is stored for the scope checks of the routes.
+func checkAccessToken(tokenSHA string, store DataStore) *models.AccessToken {
 	t, err := models.GetAccessTokenBySHA(tokenSHA)
 	if err != nil {
 		if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
 			log.Error("GetAccessTokenBySHA: %v", err)
 		}
-		return 0
+		return nil
+	}
+	return useAccessToken(t, store)
+}
+
+// CheckAccessTokenByID returns the personal access token of the id if it exists and hasn't expired.
+// It is used by authentication methods which issue their own tokens to clients authenticated with an access token.
+func CheckAccessTokenByID(id int64, store DataStore) *models.AccessToken {
+	t, err := models.GetAccessTokenByID(id)
+	if err != nil {
+		if !models.IsErrAccessTokenNotExist(err) {
+			log.Error("GetAccessTokenByID: %v", err)
+		}
+		return nil
+	}
+	return useAccessToken(t, store)
+}
+
+func useAccessToken(t *models.AccessToken, store DataStore) *models.AccessToken {
+	if t.IsExpired() {
+		log.Trace("Access token [%d] of user [%d] has expired", t.ID, t.UID)
+		return nil
 	}
 	t.UpdatedUnix = timeutil.TimeStampNow()
-	if err = models.UpdateAccessToken(t); err != nil {
+	if err := models.UpdateAccessToken(t); err != nil {
 		log.Error("UpdateAccessToken: %v", err)
 	}
 	store.GetData()["IsApiToken"] = true
-	return t.UID
+	store.GetData()["ApiToken"] = t
+	return t
 }
 
 // Verify extracts the user ID from the OAuth token in the query parameters
@@ -11,12 +11,14 @@ import (
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/auth/source/smtp"
 
 	_ "code.gitea.io/gitea/services/auth/source/db"   // register the sources (and below)
 	_ "code.gitea.io/gitea/services/auth/source/ldap" // register the ldap source
 	_ "code.gitea.io/gitea/services/auth/source/pam"  // register the pam source
+	_ "code.gitea.io/gitea/services/auth/source/saml" // register the saml source
 	_ "code.gitea.io/gitea/services/auth/source/sspi" // register the sspi source
 )
 
@@ -81,6 +83,16 @@ func UserSignIn(username, password string) (*user_model.User, *auth.Source, erro
 				return nil, nil, user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name}
 			}
 
+			if setting.Service.RequirePasskeySignIn && user.IsLocal() {
+				hasPasskey, err := auth.HasPasskeyByUID(user.ID)
+				if err != nil {
+					return nil, nil, err
+				}
+				if hasPasskey {
+					return nil, nil, auth.ErrPasskeyRequired{UID: user.ID, Name: user.Name}
+				}
+			}
+
 			return user, source, nil
 		}
 	}
@@ -12,9 +12,8 @@ import (
 	"strconv"
 	"strings"
 
-	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/util"
+	org_service "code.gitea.io/gitea/services/org"
 
 	"github.com/go-ldap/ldap/v3"
 )
@@ -229,11 +228,10 @@ func (ls *Source) listLdapGroupMemberships(l *ldap.Conn, uid string) []string {
 
 // parse LDAP groups and return map of ldap groups to organizations teams
 func (ls *Source) mapLdapGroupsToTeams() map[string]map[string][]string {
-	ldapGroupsToTeams := make(map[string]map[string][]string)
-	err := json.Unmarshal([]byte(ls.GroupTeamMap), &ldapGroupsToTeams)
+	ldapGroupsToTeams, err := org_service.ParseGroupTeamMap(ls.GroupTeamMap)
 	if err != nil {
 		log.Error("Failed to unmarshall LDAP teams map: %v", err)
-		return ldapGroupsToTeams
+		return make(map[string]map[string][]string)
 	}
 	return ldapGroupsToTeams
 }
@@ -244,21 +242,7 @@ func (ls *Source) getMappedMemberships(l *ldap.Conn, uid string) (map[string][]s
 	usersLdapGroups := ls.listLdapGroupMemberships(l, uid)
 	// unmarshall LDAP group team map from configs
 	ldapGroupsToTeams := ls.mapLdapGroupsToTeams()
-	membershipsToAdd := map[string][]string{}
-	membershipsToRemove := map[string][]string{}
-	for group, memberships := range ldapGroupsToTeams {
-		isUserInGroup := util.IsStringInSlice(group, usersLdapGroups)
-		if isUserInGroup {
-			for org, teams := range memberships {
-				membershipsToAdd[org] = teams
-			}
-		} else if !isUserInGroup {
-			for org, teams := range memberships {
-				membershipsToRemove[org] = teams
-			}
-		}
-	}
-	return membershipsToAdd, membershipsToRemove
+	return org_service.GetMappedMemberships(usersLdapGroups, ldapGroupsToTeams)
 }
 
 // SearchEntry : search an LDAP source if an entry (name, passwd) is valid and in the specific filter
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	org_service "code.gitea.io/gitea/services/org"
+)
+
+// SyncGroupsToTeams maps the groups of the group claim to organization team memberships
+func (source *Source) SyncGroupsToTeams(user *user_model.User, groups []string) error {
+	if source.GroupTeamMap == "" {
+		return nil
+	}
+	groupsToTeams, err := org_service.ParseGroupTeamMap(source.GroupTeamMap)
+	if err != nil {
+		return err
+	}
+
+	membershipsToAdd, membershipsToRemove := org_service.GetMappedMemberships(groups, groupsToTeams)
+	if !source.GroupTeamMapRemoval {
+		// the memberships of teams mapped by groups the user is not a member of are only removed if configured
+		membershipsToRemove = nil
+	}
+	org_service.SyncGroupTeamMemberships(user, membershipsToAdd, membershipsToRemove, make(map[string]*organization.Organization), make(map[string]*organization.Team))
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SAML namespaces, bindings and name id formats
+const (
+	namespaceMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	namespaceAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	namespaceProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+
+	BindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	BindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+
+	NameIDFormatUnspecified  = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	NameIDFormatEmailAddress = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	NameIDFormatPersistent   = "urn:oasis:names:tc:SAML:2.0:nameid-format:persistent"
+	NameIDFormatTransient    = "urn:oasis:names:tc:SAML:2.0:nameid-format:transient"
+)
+
+// maxMetadataSize is the maximum size of identity provider metadata fetched from a url
+const maxMetadataSize = 5 * 1024 * 1024
+
+// IdentityProvider holds the settings of the identity provider read from its metadata
+type IdentityProvider struct {
+	EntityID                string
+	SingleSignOnURL         string
+	WantAuthnRequestsSigned bool
+	Certificates            []*x509.Certificate
+}
+
+type metadataKeyDescriptor struct {
+	Use          string   `xml:"use,attr"`
+	Certificates []string `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo>X509Data>X509Certificate"`
+}
+
+type metadataEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+type metadataIDPSSODescriptor struct {
+	WantAuthnRequestsSigned bool                    `xml:"WantAuthnRequestsSigned,attr"`
+	KeyDescriptors          []metadataKeyDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata KeyDescriptor"`
+	SingleSignOnServices    []metadataEndpoint      `xml:"urn:oasis:names:tc:SAML:2.0:metadata SingleSignOnService"`
+}
+
+type metadataEntityDescriptor struct {
+	EntityID          string                     `xml:"entityID,attr"`
+	IDPSSODescriptors []metadataIDPSSODescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata IDPSSODescriptor"`
+}
+
+type metadataEntitiesDescriptor struct {
+	EntityDescriptors []metadataEntityDescriptor `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+}
+
+// ParseIdentityProviderMetadata reads the settings of an identity provider from its metadata.
+// If the metadata contains multiple entities the first identity provider is used.
+func ParseIdentityProviderMetadata(data []byte) (*IdentityProvider, error) {
+	// check the document structure first, this rejects DTDs
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+
+	var entities []metadataEntityDescriptor
+	switch {
+	case isElement(root, namespaceMetadata, "EntityDescriptor"):
+		var entity metadataEntityDescriptor
+		if err := xml.Unmarshal(data, &entity); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %v", err)
+		}
+		entities = append(entities, entity)
+	case isElement(root, namespaceMetadata, "EntitiesDescriptor"):
+		var descriptor metadataEntitiesDescriptor
+		if err := xml.Unmarshal(data, &descriptor); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %v", err)
+		}
+		entities = descriptor.EntityDescriptors
+	default:
+		return nil, errors.New("invalid metadata: no EntityDescriptor found")
+	}
+
+	for _, entity := range entities {
+		for _, descriptor := range entity.IDPSSODescriptors {
+			idp := &IdentityProvider{
+				EntityID:                entity.EntityID,
+				WantAuthnRequestsSigned: descriptor.WantAuthnRequestsSigned,
+			}
+			for _, endpoint := range descriptor.SingleSignOnServices {
+				if endpoint.Binding == BindingHTTPRedirect {
+					idp.SingleSignOnURL = endpoint.Location
+					break
+				}
+			}
+			if idp.SingleSignOnURL == "" {
+				return nil, errors.New("invalid metadata: identity provider has no SingleSignOnService with the HTTP-Redirect binding")
+			}
+			for _, key := range descriptor.KeyDescriptors {
+				if key.Use != "" && key.Use != "signing" {
+					continue
+				}
+				for _, data := range key.Certificates {
+					der, err := decodeBase64(data)
+					if err != nil {
+						return nil, fmt.Errorf("invalid metadata certificate: %v", err)
+					}
+					cert, err := x509.ParseCertificate(der)
+					if err != nil {
+						return nil, fmt.Errorf("invalid metadata certificate: %v", err)
+					}
+					idp.Certificates = append(idp.Certificates, cert)
+				}
+			}
+			if len(idp.Certificates) == 0 {
+				return nil, errors.New("invalid metadata: identity provider has no signing certificate")
+			}
+			if idp.EntityID == "" {
+				return nil, errors.New("invalid metadata: identity provider has no entityID")
+			}
+			return idp, nil
+		}
+	}
+	return nil, errors.New("invalid metadata: no IDPSSODescriptor found")
+}
+
+// FetchIdentityProviderMetadata downloads the metadata of an identity provider and checks if it can be parsed
+func FetchIdentityProviderMetadata(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d while fetching %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseIdentityProviderMetadata(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type spMetadataKeyDescriptor struct {
+	Use         string `xml:"use,attr"`
+	Certificate string `xml:"ds:KeyInfo>ds:X509Data>ds:X509Certificate"`
+}
+
+type spMetadataEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+type spMetadataSPSSODescriptor struct {
+	AuthnRequestsSigned        bool                    `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                    `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                  `xml:"protocolSupportEnumeration,attr"`
+	KeyDescriptor              spMetadataKeyDescriptor `xml:"md:KeyDescriptor"`
+	NameIDFormat               string                  `xml:"md:NameIDFormat,omitempty"`
+	AssertionConsumerServices  []spMetadataEndpoint    `xml:"md:AssertionConsumerService"`
+}
+
+type spMetadataEntityDescriptor struct {
+	XMLName         xml.Name                  `xml:"md:EntityDescriptor"`
+	XMLNSMetadata   string                    `xml:"xmlns:md,attr"`
+	XMLNSSignature  string                    `xml:"xmlns:ds,attr"`
+	EntityID        string                    `xml:"entityID,attr"`
+	SPSSODescriptor spMetadataSPSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+// ServiceProviderMetadata returns the metadata of this service provider which has to be registered at the identity provider
+func (source *Source) ServiceProviderMetadata() ([]byte, error) {
+	cert, err := source.serviceProviderCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := spMetadataEntityDescriptor{
+		XMLNSMetadata:  namespaceMetadata,
+		XMLNSSignature: namespaceXMLDSig,
+		EntityID:       source.EntityID(),
+		SPSSODescriptor: spMetadataSPSSODescriptor{
+			AuthnRequestsSigned:        source.SignRequests,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: namespaceProtocol,
+			KeyDescriptor: spMetadataKeyDescriptor{
+				Use:         "signing",
+				Certificate: base64.StdEncoding.EncodeToString(cert.Raw),
+			},
+			NameIDFormat: source.NameIDFormat,
+			AssertionConsumerServices: []spMetadataEndpoint{
+				{
+					Binding:  BindingHTTPPost,
+					Location: source.AssertionConsumerServiceURL(),
+					Index:    0,
+				},
+			},
+		},
+	}
+
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(metadata); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
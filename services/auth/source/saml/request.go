@@ -0,0 +1,162 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// requestLifetime is the time the identity provider has to answer an authentication request
+const requestLifetime = 10 * time.Minute
+
+type authnRequestNameIDPolicy struct {
+	Format      string `xml:"Format,attr,omitempty"`
+	AllowCreate bool   `xml:"AllowCreate,attr"`
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name                 `xml:"samlp:AuthnRequest"`
+	XMLNSProtocol               string                   `xml:"xmlns:samlp,attr"`
+	XMLNSAssertion              string                   `xml:"xmlns:saml,attr"`
+	ID                          string                   `xml:"ID,attr"`
+	Version                     string                   `xml:"Version,attr"`
+	IssueInstant                string                   `xml:"IssueInstant,attr"`
+	Destination                 string                   `xml:"Destination,attr"`
+	ProtocolBinding             string                   `xml:"ProtocolBinding,attr"`
+	AssertionConsumerServiceURL string                   `xml:"AssertionConsumerServiceURL,attr"`
+	Issuer                      string                   `xml:"saml:Issuer"`
+	NameIDPolicy                authnRequestNameIDPolicy `xml:"samlp:NameIDPolicy"`
+}
+
+// AuthnRequestURL creates an authentication request and returns the url of the identity provider
+// the user has to be redirected to (HTTP-Redirect binding) and the id of the request.
+func (source *Source) AuthnRequestURL() (string, string, error) {
+	idp, err := source.IdentityProvider()
+	if err != nil {
+		return "", "", err
+	}
+
+	requestID, err := newID()
+	if err != nil {
+		return "", "", err
+	}
+
+	request := authnRequest{
+		XMLNSProtocol:               namespaceProtocol,
+		XMLNSAssertion:              namespaceAssertion,
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                timeNow().UTC().Format(time.RFC3339),
+		Destination:                 idp.SingleSignOnURL,
+		ProtocolBinding:             BindingHTTPPost,
+		AssertionConsumerServiceURL: source.AssertionConsumerServiceURL(),
+		Issuer:                      source.EntityID(),
+		NameIDPolicy: authnRequestNameIDPolicy{
+			Format:      source.NameIDFormat,
+			AllowCreate: true,
+		},
+	}
+	data, err := xml.Marshal(request)
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+
+	// the order of the parameters is relevant for the signature
+	query := "SAMLRequest=" + url.QueryEscape(base64.StdEncoding.EncodeToString(buf.Bytes())) +
+		"&RelayState=" + url.QueryEscape(source.createRelayState(requestID, timeNow().Add(requestLifetime)))
+
+	if source.SignRequests || idp.WantAuthnRequestsSigned {
+		key, err := source.serviceProviderPrivateKey()
+		if err != nil {
+			return "", "", err
+		}
+		query += "&SigAlg=" + url.QueryEscape(dsig.RSASHA256SignatureMethod)
+
+		hashed := sha256.Sum256([]byte(query))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		if err != nil {
+			return "", "", err
+		}
+		query += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+	}
+
+	if strings.Contains(idp.SingleSignOnURL, "?") {
+		return idp.SingleSignOnURL + "&" + query, requestID, nil
+	}
+	return idp.SingleSignOnURL + "?" + query, requestID, nil
+}
+
+// newID creates a random id which is a valid xml NCName
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "_" + hex.EncodeToString(b), nil
+}
+
+// The RelayState is returned unchanged by the identity provider. It carries the id and the expiry of the request
+// so the response can be checked without the session, the caller still has to compare the id with the one
+// stored in the session of the user. The RelayState is limited to 80 bytes.
+
+func (source *Source) relayStateMAC(requestID, expires string) string {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = mac.Write([]byte(source.authSource.Name + "|" + requestID + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+func (source *Source) createRelayState(requestID string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return requestID + "." + exp + "." + source.relayStateMAC(requestID, exp)
+}
+
+// verifyRelayState checks the RelayState and returns the id of the request
+func (source *Source) verifyRelayState(relayState string) (string, error) {
+	parts := strings.Split(relayState, ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid RelayState")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(source.relayStateMAC(parts[0], parts[1]))) {
+		return "", errors.New("invalid RelayState")
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", errors.New("invalid RelayState")
+	}
+	if timeNow().Unix() > expires {
+		return "", errors.New("the authentication request has expired")
+	}
+	return parts[0], nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+// maxClockSkew is the tolerated time difference between the identity provider and this server
+const maxClockSkew = 3 * time.Minute
+
+const statusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+// timeNow can be replaced in tests
+var timeNow = time.Now
+
+// Assertion contains the verified data of the identity provider about the user
+type Assertion struct {
+	ID           string
+	NameID       string
+	NotOnOrAfter time.Time
+	// RequestID is the id of the authentication request the assertion answers
+	RequestID string
+	// Attributes are stored by their name and their friendly name
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of the attribute
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ErrResponseStatus is returned if the identity provider did not authenticate the user
+type ErrResponseStatus struct {
+	Code    string
+	Message string
+}
+
+// IsErrResponseStatus checks if an error is a ErrResponseStatus.
+func IsErrResponseStatus(err error) bool {
+	_, ok := err.(ErrResponseStatus)
+	return ok
+}
+
+func (err ErrResponseStatus) Error() string {
+	return fmt.Sprintf("identity provider returned status %s: %s", err.Code, err.Message)
+}
+
+// ParseResponse verifies the base64 encoded response of the identity provider (HTTP-POST binding) and
+// returns the contained assertion. Only the data of signed elements is used.
+// Encrypted assertions are not supported.
+func (source *Source) ParseResponse(samlResponse, relayState string) (*Assertion, error) {
+	idp, err := source.IdentityProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	requestID, err := source.verifyRelayState(relayState)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %v", err)
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %v", err)
+	}
+
+	return source.verifyResponse(root, idp, requestID)
+}
+
+func (source *Source) verifyResponse(response *etree.Element, idp *IdentityProvider, requestID string) (*Assertion, error) {
+	if !isElement(response, namespaceProtocol, "Response") {
+		return nil, errors.New("document is not a SAML response")
+	}
+	if err := checkUniqueIDs(response, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	responseSigned := true
+	if verified, err := verifySignature(response, idp.Certificates); err == nil {
+		response = verified
+	} else if err == ErrSignatureMissing {
+		responseSigned = false
+	} else {
+		return nil, fmt.Errorf("invalid response signature: %v", err)
+	}
+
+	if attrValue(response, "Version") != "2.0" {
+		return nil, errors.New("unsupported SAML version")
+	}
+	if destination, has := attr(response, "Destination"); has && destination != source.AssertionConsumerServiceURL() {
+		return nil, fmt.Errorf("response destination %s does not match", destination)
+	}
+	if attrValue(response, "InResponseTo") != requestID {
+		return nil, errors.New("response does not belong to the authentication request")
+	}
+	if issuer := findChild(response, namespaceAssertion, "Issuer"); issuer != nil && text(issuer) != idp.EntityID {
+		return nil, fmt.Errorf("response issuer %s does not match", text(issuer))
+	}
+
+	status := findChild(response, namespaceProtocol, "Status")
+	if status == nil {
+		return nil, errors.New("response has no status")
+	}
+	statusCode := findChild(status, namespaceProtocol, "StatusCode")
+	if statusCode == nil {
+		return nil, errors.New("response has no status code")
+	}
+	if code := attrValue(statusCode, "Value"); code != statusSuccess {
+		if sub := findChild(statusCode, namespaceProtocol, "StatusCode"); sub != nil {
+			code += " (" + attrValue(sub, "Value") + ")"
+		}
+		var message string
+		if msg := findChild(status, namespaceProtocol, "StatusMessage"); msg != nil {
+			message = text(msg)
+		}
+		return nil, ErrResponseStatus{Code: code, Message: message}
+	}
+
+	if len(findChildren(response, namespaceAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+	assertions := findChildren(response, namespaceAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("response must contain exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	// either the whole response or the assertion must be signed, only the verified copies are read
+	if verified, err := verifySignature(assertion, idp.Certificates); err == nil {
+		assertion = verified
+	} else if err != ErrSignatureMissing || !responseSigned {
+		return nil, fmt.Errorf("invalid assertion signature: %v", err)
+	}
+
+	return source.verifyAssertion(assertion, idp, requestID)
+}
+
+func (source *Source) verifyAssertion(assertion *etree.Element, idp *IdentityProvider, requestID string) (*Assertion, error) {
+	now := timeNow()
+
+	if attrValue(assertion, "Version") != "2.0" {
+		return nil, errors.New("unsupported assertion version")
+	}
+	issuer := findChild(assertion, namespaceAssertion, "Issuer")
+	if issuer == nil || text(issuer) != idp.EntityID {
+		return nil, errors.New("assertion issuer does not match")
+	}
+
+	result := &Assertion{
+		ID:         attrValue(assertion, "ID"),
+		RequestID:  requestID,
+		Attributes: map[string][]string{},
+	}
+	if result.ID == "" {
+		return nil, errors.New("assertion has no ID")
+	}
+
+	subject := findChild(assertion, namespaceAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+	nameID := findChild(subject, namespaceAssertion, "NameID")
+	if nameID == nil || text(nameID) == "" {
+		return nil, errors.New("assertion has no NameID")
+	}
+	result.NameID = text(nameID)
+
+	confirmed := false
+	for _, confirmation := range findChildren(subject, namespaceAssertion, "SubjectConfirmation") {
+		if attrValue(confirmation, "Method") != "urn:oasis:names:tc:SAML:2.0:cm:bearer" {
+			continue
+		}
+		data := findChild(confirmation, namespaceAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if attrValue(data, "Recipient") != source.AssertionConsumerServiceURL() {
+			continue
+		}
+		if attrValue(data, "InResponseTo") != requestID {
+			continue
+		}
+		notOnOrAfter, err := time.Parse(time.RFC3339, attrValue(data, "NotOnOrAfter"))
+		if err != nil || !now.Before(notOnOrAfter.Add(maxClockSkew)) {
+			continue
+		}
+		if notBefore, has := attr(data, "NotBefore"); has {
+			t, err := time.Parse(time.RFC3339, notBefore)
+			if err != nil || now.Add(maxClockSkew).Before(t) {
+				continue
+			}
+		}
+		confirmed = true
+		result.NotOnOrAfter = notOnOrAfter
+		break
+	}
+	if !confirmed {
+		return nil, errors.New("assertion has no valid bearer subject confirmation")
+	}
+
+	conditions := findChild(assertion, namespaceAssertion, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("assertion has no conditions")
+	}
+	if notBefore, has := attr(conditions, "NotBefore"); has {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil || now.Add(maxClockSkew).Before(t) {
+			return nil, errors.New("assertion is not yet valid")
+		}
+	}
+	if notOnOrAfter, has := attr(conditions, "NotOnOrAfter"); has {
+		t, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil || !now.Before(t.Add(maxClockSkew)) {
+			return nil, errors.New("assertion has expired")
+		}
+		if t.Before(result.NotOnOrAfter) {
+			result.NotOnOrAfter = t
+		}
+	}
+	restrictions := findChildren(conditions, namespaceAssertion, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, errors.New("assertion has no audience restriction")
+	}
+	for _, restriction := range restrictions {
+		found := false
+		for _, audience := range findChildren(restriction, namespaceAssertion, "Audience") {
+			if text(audience) == source.EntityID() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("assertion is not intended for this service provider")
+		}
+	}
+
+	for _, statement := range findChildren(assertion, namespaceAssertion, "AttributeStatement") {
+		for _, attribute := range findChildren(statement, namespaceAssertion, "Attribute") {
+			var values []string
+			for _, value := range findChildren(attribute, namespaceAssertion, "AttributeValue") {
+				values = append(values, text(value))
+			}
+			name, friendlyName := attrValue(attribute, "Name"), attrValue(attribute, "FriendlyName")
+			if name != "" {
+				result.Attributes[name] = append(result.Attributes[name], values...)
+			}
+			if friendlyName != "" && friendlyName != name {
+				result.Attributes[friendlyName] = append(result.Attributes[friendlyName], values...)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// checkUniqueIDs rejects documents with duplicate ID attributes which could confuse the reference resolution
+func checkUniqueIDs(el *etree.Element, ids map[string]bool) error {
+	if id, has := attr(el, "ID"); has {
+		if ids[id] {
+			return fmt.Errorf("duplicate ID %s", id)
+		}
+		ids[id] = true
+	}
+	for _, child := range el.ChildElements() {
+		if err := checkUniqueIDs(child, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
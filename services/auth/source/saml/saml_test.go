@@ -0,0 +1,445 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXML(t *testing.T) {
+	for _, doc := range []string{
+		``,
+		`<a>`,
+		`<a></b>`,
+		`<a/><b/>`,
+		`<p:a/>`,
+		`<a b="1" b="2"/>`,
+		`<!DOCTYPE a [<!ENTITY e "x">]><a>&e;</a>`,
+	} {
+		_, err := parseXML([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+// testIdentityProvider is a local stand-in for a SAML identity provider
+type testIdentityProvider struct {
+	EntityID string
+	SSOURL   string
+	key      *rsa.PrivateKey
+	cert     *x509.Certificate
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &testIdentityProvider{
+		EntityID: "https://idp.example.com/metadata",
+		SSOURL:   "https://idp.example.com/sso",
+		key:      key,
+		cert:     cert,
+	}
+}
+
+func (idp *testIdentityProvider) Metadata() string {
+	return `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + idp.EntityID + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idp.cert.Raw) + `</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="` + idp.SSOURL + `/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + idp.SSOURL + `"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+type testResponseOptions struct {
+	RequestID    string
+	Audience     string
+	Recipient    string
+	NotOnOrAfter time.Time
+	NameID       string
+	Attributes   map[string][]string
+}
+
+func (idp *testIdentityProvider) Response(opts testResponseOptions) string {
+	now := time.Now().UTC()
+	var attributes strings.Builder
+	for name, values := range opts.Attributes {
+		attributes.WriteString(`<saml:Attribute Name="` + name + `">`)
+		for _, value := range values {
+			attributes.WriteString(`<saml:AttributeValue xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="xs:string">` + value + `</saml:AttributeValue>`)
+		}
+		attributes.WriteString(`</saml:Attribute>`)
+	}
+
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response1" Version="2.0" IssueInstant="` + now.Format(time.RFC3339) + `" Destination="` + opts.Recipient + `" InResponseTo="` + opts.RequestID + `">
+  <saml:Issuer>` + idp.EntityID + `</saml:Issuer>
+  <samlp:Status>
+    <samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>
+  </samlp:Status>
+  <saml:Assertion xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_assertion1" Version="2.0" IssueInstant="` + now.Format(time.RFC3339) + `">
+    <saml:Issuer>` + idp.EntityID + `</saml:Issuer>
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:2.0:nameid-format:persistent">` + opts.NameID + `</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData NotOnOrAfter="` + opts.NotOnOrAfter.UTC().Format(time.RFC3339) + `" Recipient="` + opts.Recipient + `" InResponseTo="` + opts.RequestID + `"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="` + now.Add(-time.Minute).Format(time.RFC3339) + `" NotOnOrAfter="` + opts.NotOnOrAfter.UTC().Format(time.RFC3339) + `">
+      <saml:AudienceRestriction>
+        <saml:Audience>` + opts.Audience + `</saml:Audience>
+      </saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AuthnStatement AuthnInstant="` + now.Format(time.RFC3339) + `">
+      <saml:AuthnContext>
+        <saml:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:Password</saml:AuthnContextClassRef>
+      </saml:AuthnContext>
+    </saml:AuthnStatement>
+    <saml:AttributeStatement>` + attributes.String() + `</saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`
+}
+
+// Sign adds an enveloped signature to the element with the id. The signature is inserted after the Issuer of the element.
+func (idp *testIdentityProvider) Sign(t *testing.T, doc, id string) string {
+	document := etree.NewDocument()
+	assert.NoError(t, document.ReadFromString(doc))
+
+	var find func(el *etree.Element) *etree.Element
+	find = func(el *etree.Element) *etree.Element {
+		if attrValue(el, "ID") == id {
+			return el
+		}
+		for _, child := range el.ChildElements() {
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	el := find(document.Root())
+	if !assert.NotNil(t, el) {
+		return doc
+	}
+
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	assert.NoError(t, err)
+	signed, err := etreeutils.NSDetatch(ctx, el)
+	assert.NoError(t, err)
+
+	signingContext := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{idp.cert.Raw},
+		PrivateKey:  idp.key,
+	}))
+	signingContext.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signature, err := signingContext.ConstructSignature(signed, true)
+	assert.NoError(t, err)
+	signed.InsertChildAt(findChild(signed, namespaceAssertion, "Issuer").Index()+1, signature)
+
+	parent := el.Parent()
+	parent.InsertChildAt(el.Index(), signed)
+	parent.RemoveChild(el)
+
+	result, err := document.WriteToString()
+	assert.NoError(t, err)
+	return result
+}
+
+func newTestSource(t *testing.T, idp *testIdentityProvider) *Source {
+	oldAppURL := setting.AppURL
+	setting.AppURL = "https://gitea.example.com/"
+	t.Cleanup(func() {
+		setting.AppURL = oldAppURL
+	})
+
+	source := &Source{
+		IdentityProviderMetadata: idp.Metadata(),
+		SignRequests:             true,
+		NameIDFormat:             NameIDFormatPersistent,
+	}
+	source.SetAuthSource(&auth.Source{ID: 1, Name: "test idp", Type: auth.SAML})
+	assert.NoError(t, source.GenerateServiceProviderKeyPair())
+	return source
+}
+
+func TestParseIdentityProviderMetadata(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+
+	parsed, err := ParseIdentityProviderMetadata([]byte(idp.Metadata()))
+	assert.NoError(t, err)
+	assert.Equal(t, idp.EntityID, parsed.EntityID)
+	assert.Equal(t, idp.SSOURL, parsed.SingleSignOnURL)
+	assert.False(t, parsed.WantAuthnRequestsSigned)
+	if assert.Len(t, parsed.Certificates, 1) {
+		assert.True(t, parsed.Certificates[0].Equal(idp.cert))
+	}
+
+	wrapped := `<md:EntitiesDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata">` + strings.TrimPrefix(idp.Metadata(), `<?xml version="1.0"?>`) + `</md:EntitiesDescriptor>`
+	parsed, err = ParseIdentityProviderMetadata([]byte(wrapped))
+	assert.NoError(t, err)
+	assert.Equal(t, idp.EntityID, parsed.EntityID)
+
+	_, err = ParseIdentityProviderMetadata([]byte(strings.ReplaceAll(idp.Metadata(), "HTTP-Redirect", "SOAP")))
+	assert.Error(t, err)
+	_, err = ParseIdentityProviderMetadata([]byte(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="x"/>`))
+	assert.Error(t, err)
+}
+
+func TestServiceProviderMetadata(t *testing.T) {
+	source := newTestSource(t, newTestIdentityProvider(t))
+
+	metadata, err := source.ServiceProviderMetadata()
+	assert.NoError(t, err)
+
+	root, err := parseXML(metadata)
+	assert.NoError(t, err)
+	assert.True(t, isElement(root, namespaceMetadata, "EntityDescriptor"))
+	assert.Equal(t, "https://gitea.example.com/user/saml/test%20idp/metadata", attrValue(root, "entityID"))
+
+	descriptor := findChild(root, namespaceMetadata, "SPSSODescriptor")
+	if assert.NotNil(t, descriptor) {
+		assert.Equal(t, "true", attrValue(descriptor, "AuthnRequestsSigned"))
+		acs := findChild(descriptor, namespaceMetadata, "AssertionConsumerService")
+		if assert.NotNil(t, acs) {
+			assert.Equal(t, BindingHTTPPost, attrValue(acs, "Binding"))
+			assert.Equal(t, "https://gitea.example.com/user/saml/test%20idp/acs", attrValue(acs, "Location"))
+		}
+		assert.Equal(t, NameIDFormatPersistent, text(findChild(descriptor, namespaceMetadata, "NameIDFormat")))
+		cert := findChild(findChild(findChild(findChild(descriptor, namespaceMetadata, "KeyDescriptor"), namespaceXMLDSig, "KeyInfo"), namespaceXMLDSig, "X509Data"), namespaceXMLDSig, "X509Certificate")
+		if assert.NotNil(t, cert) {
+			spCert, err := source.serviceProviderCertificate()
+			assert.NoError(t, err)
+			assert.Equal(t, base64.StdEncoding.EncodeToString(spCert.Raw), text(cert))
+		}
+	}
+}
+
+// receiveAuthnRequest checks the authentication request like an identity provider and returns the request id and the RelayState
+func receiveAuthnRequest(t *testing.T, source *Source, idp *testIdentityProvider, requestURL string) (string, string) {
+	u, err := url.Parse(requestURL)
+	assert.NoError(t, err)
+	assert.Equal(t, idp.SSOURL, u.Scheme+"://"+u.Host+u.Path)
+
+	// the signature is computed over the raw query parameters
+	parts := strings.Split(u.RawQuery, "&Signature=")
+	if assert.Len(t, parts, 2) {
+		signature, err := url.QueryUnescape(parts[1])
+		assert.NoError(t, err)
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		assert.NoError(t, err)
+		spCert, err := source.serviceProviderCertificate()
+		assert.NoError(t, err)
+		hashed := sha256.Sum256([]byte(parts[0]))
+		assert.NoError(t, rsa.VerifyPKCS1v15(spCert.PublicKey.(*rsa.PublicKey), crypto.SHA256, hashed[:], sig))
+	}
+
+	query := u.Query()
+	assert.Equal(t, dsig.RSASHA256SignatureMethod, query.Get("SigAlg"))
+	compressed, err := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	assert.NoError(t, err)
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	assert.NoError(t, err)
+
+	request, err := parseXML(data)
+	assert.NoError(t, err)
+	assert.True(t, isElement(request, namespaceProtocol, "AuthnRequest"))
+	assert.Equal(t, idp.SSOURL, attrValue(request, "Destination"))
+	assert.Equal(t, source.AssertionConsumerServiceURL(), attrValue(request, "AssertionConsumerServiceURL"))
+	assert.Equal(t, source.EntityID(), text(findChild(request, namespaceAssertion, "Issuer")))
+	assert.Equal(t, NameIDFormatPersistent, attrValue(findChild(request, namespaceProtocol, "NameIDPolicy"), "Format"))
+
+	relayState := query.Get("RelayState")
+	assert.LessOrEqual(t, len(relayState), 80)
+	return attrValue(request, "ID"), relayState
+}
+
+func TestLogin(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	source := newTestSource(t, idp)
+
+	requestURL, sentRequestID, err := source.AuthnRequestURL()
+	assert.NoError(t, err)
+	requestID, relayState := receiveAuthnRequest(t, source, idp, requestURL)
+	assert.Equal(t, sentRequestID, requestID)
+
+	validOptions := func() testResponseOptions {
+		return testResponseOptions{
+			RequestID:    requestID,
+			Audience:     source.EntityID(),
+			Recipient:    source.AssertionConsumerServiceURL(),
+			NotOnOrAfter: time.Now().Add(5 * time.Minute),
+			NameID:       "user-1234",
+			Attributes: map[string][]string{
+				"uid":    {"user1"},
+				"mail":   {"user1@example.com"},
+				"groups": {"developers", "admins"},
+			},
+		}
+	}
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	t.Run("SignedAssertion", func(t *testing.T) {
+		response := idp.Sign(t, idp.Response(validOptions()), "_assertion1")
+		assertion, err := source.ParseResponse(encode(response), relayState)
+		assert.NoError(t, err)
+		if assert.NotNil(t, assertion) {
+			assert.Equal(t, "_assertion1", assertion.ID)
+			assert.Equal(t, requestID, assertion.RequestID)
+			assert.Equal(t, "user-1234", assertion.NameID)
+			assert.Equal(t, "user1", assertion.Attribute("uid"))
+			assert.Equal(t, "user1@example.com", assertion.Attribute("mail"))
+			assert.Equal(t, []string{"developers", "admins"}, assertion.Attributes["groups"])
+		}
+	})
+
+	t.Run("SignedResponse", func(t *testing.T) {
+		response := idp.Sign(t, idp.Response(validOptions()), "_response1")
+		_, err := source.ParseResponse(encode(response), relayState)
+		assert.NoError(t, err)
+
+		// signing both is fine too
+		response = idp.Sign(t, idp.Sign(t, idp.Response(validOptions()), "_assertion1"), "_response1")
+		_, err = source.ParseResponse(encode(response), relayState)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		_, err := source.ParseResponse(encode(idp.Response(validOptions())), relayState)
+		assert.Error(t, err)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		response := idp.Sign(t, idp.Response(validOptions()), "_assertion1")
+		response = strings.Replace(response, "user-1234", "admin", 1)
+		_, err := source.ParseResponse(encode(response), relayState)
+		assert.Error(t, err)
+
+		// the signature of the response covers the unsigned assertion
+		response = idp.Sign(t, idp.Response(validOptions()), "_response1")
+		response = strings.Replace(response, "user-1234", "admin", 1)
+		_, err = source.ParseResponse(encode(response), relayState)
+		assert.Error(t, err)
+	})
+
+	t.Run("Comment", func(t *testing.T) {
+		// comments are not covered by the signature and must not truncate the signed value
+		response := idp.Sign(t, idp.Response(validOptions()), "_assertion1")
+		response = strings.Replace(response, "user-1234", "user-<!---->1234", 1)
+		assertion, err := source.ParseResponse(encode(response), relayState)
+		assert.NoError(t, err)
+		if assert.NotNil(t, assertion) {
+			assert.Equal(t, "user-1234", assertion.NameID)
+		}
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		other := newTestIdentityProvider(t)
+		response := other.Sign(t, idp.Response(validOptions()), "_assertion1")
+		_, err := source.ParseResponse(encode(response), relayState)
+		assert.Error(t, err)
+	})
+
+	t.Run("SignatureWrapping", func(t *testing.T) {
+		// a valid signed assertion is moved into an unsigned one with different content
+		signed := idp.Sign(t, idp.Response(validOptions()), "_assertion1")
+		start := strings.Index(signed, "<saml:Assertion")
+		end := strings.Index(signed, "</saml:Assertion>") + len("</saml:Assertion>")
+		original := signed[start:end]
+
+		evil := strings.Replace(strings.Replace(idp.Response(validOptions()), "_assertion1", "_evil", 1), "user-1234", "admin", 1)
+		evilStart := strings.Index(evil, "<saml:Assertion")
+		evilEnd := strings.Index(evil, "</saml:Assertion>")
+		evil = evil[:evilEnd] + "<saml:Advice>" + original + "</saml:Advice>" + evil[evilEnd:]
+		_, err := source.ParseResponse(encode(evil), relayState)
+		assert.Error(t, err)
+
+		// a second assertion next to the signed one
+		twice := signed[:end] + evil[evilStart:strings.Index(evil, "<saml:Advice>")] + "</saml:Assertion>" + signed[end:]
+		_, err = source.ParseResponse(encode(twice), relayState)
+		assert.Error(t, err)
+
+		// duplicated ids
+		duplicated := strings.Replace(signed, "<saml:Subject>", `<saml:Subject><saml:Foo ID="_assertion1"/>`, 1)
+		_, err = source.ParseResponse(encode(duplicated), relayState)
+		assert.Error(t, err)
+	})
+
+	for name, modify := range map[string]func(*testResponseOptions){
+		"WrongRequest":   func(opts *testResponseOptions) { opts.RequestID = "_other" },
+		"WrongAudience":  func(opts *testResponseOptions) { opts.Audience = "https://other.example.com" },
+		"WrongRecipient": func(opts *testResponseOptions) { opts.Recipient = "https://other.example.com/acs" },
+		"Expired":        func(opts *testResponseOptions) { opts.NotOnOrAfter = time.Now().Add(-10 * time.Minute) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := validOptions()
+			modify(&opts)
+			response := idp.Sign(t, idp.Response(opts), "_assertion1")
+			_, err := source.ParseResponse(encode(response), relayState)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("RelayState", func(t *testing.T) {
+		response := encode(idp.Sign(t, idp.Response(validOptions()), "_assertion1"))
+
+		_, err := source.ParseResponse(response, "")
+		assert.Error(t, err)
+		_, err = source.ParseResponse(response, strings.Replace(relayState, requestID, "_other", 1))
+		assert.Error(t, err)
+
+		expired := source.createRelayState(requestID, time.Now().Add(-time.Minute))
+		_, err = source.ParseResponse(response, expired)
+		assert.Error(t, err)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		response := strings.Replace(idp.Response(validOptions()), `<samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/>`,
+			`<samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Responder"><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:AuthnFailed"/></samlp:StatusCode>`, 1)
+		_, err := source.ParseResponse(encode(response), relayState)
+		assert.True(t, IsErrResponseStatus(err), fmt.Sprint(err))
+	})
+}
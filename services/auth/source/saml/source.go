@@ -0,0 +1,156 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/url"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+//   _________   _____      _____  .____
+//  /   _____/  /  _  \    /     \ |    |
+//  \_____  \  /  /_\  \  /  \ /  \|    |
+//  /        \/    |    \/    Y    \    |___
+// /_______  /\____|__  /\____|__  /_______ \
+//         \/         \/         \/        \/
+
+// Source holds configuration for the SAML 2.0 login source.
+type Source struct {
+	IdentityProviderMetadataURL string
+	IdentityProviderMetadata    string // metadata XML of the identity provider
+
+	// key pair of the service provider used to sign the authentication requests
+	ServiceProviderCertificate string
+	ServiceProviderPrivateKey  string
+	SignRequests               bool
+	NameIDFormat               string
+
+	// names of the assertion attributes, the NameID is used if an attribute is empty
+	UsernameAttribute string
+	EmailAttribute    string
+	FullNameAttribute string
+
+	GroupAttribute  string
+	AdminGroup      string
+	RestrictedGroup string
+	SkipLocalTwoFA  bool `json:",omitempty"`
+
+	// reference to the authSource
+	authSource *auth.Source
+}
+
+// FromDB fills up a SAMLConfig from serialized format.
+func (source *Source) FromDB(bs []byte) error {
+	return json.UnmarshalHandleDoubleEncode(bs, &source)
+}
+
+// ToDB exports a SAMLConfig to a serialized format.
+func (source *Source) ToDB() ([]byte, error) {
+	return json.Marshal(source)
+}
+
+// SetAuthSource sets the related AuthSource
+func (source *Source) SetAuthSource(authSource *auth.Source) {
+	source.authSource = authSource
+}
+
+func (source *Source) baseURL() string {
+	return setting.AppURL + "user/saml/" + url.PathEscape(source.authSource.Name)
+}
+
+// EntityID returns the entity id of this service provider. It is the url of the metadata.
+func (source *Source) EntityID() string {
+	return source.baseURL() + "/metadata"
+}
+
+// AssertionConsumerServiceURL returns the url the identity provider posts its responses to
+func (source *Source) AssertionConsumerServiceURL() string {
+	return source.baseURL() + "/acs"
+}
+
+// IdentityProvider parses the stored metadata of the identity provider
+func (source *Source) IdentityProvider() (*IdentityProvider, error) {
+	if source.IdentityProviderMetadata == "" {
+		return nil, errors.New("the metadata of the identity provider is missing")
+	}
+	return ParseIdentityProviderMetadata([]byte(source.IdentityProviderMetadata))
+}
+
+// GenerateServiceProviderKeyPair creates a new private key and a self-signed certificate for the service provider
+func (source *Source) GenerateServiceProviderKeyPair() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	commonName := setting.Domain
+	if u, err := url.Parse(setting.AppURL); err == nil && u.Hostname() != "" {
+		commonName = u.Hostname()
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+
+	source.ServiceProviderCertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	source.ServiceProviderPrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return nil
+}
+
+func (source *Source) serviceProviderCertificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(source.ServiceProviderCertificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("invalid service provider certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func (source *Source) serviceProviderPrivateKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(source.ServiceProviderPrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid service provider private key")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the service provider private key must be a RSA key")
+	}
+	return rsaKey, nil
+}
+
+func init() {
+	auth.RegisterTypeConfig(auth.SAML, &Source{})
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/services/auth/source/db"
+)
+
+// Authenticate falls back to the db authenticator
+func (source *Source) Authenticate(user *user_model.User, login, password string) (*user_model.User, error) {
+	return db.Authenticate(user, login, password)
+}
+
+// NB: SAML does not implement LocalTwoFASkipper for password authentication
+// as its password authentication drops to db authentication
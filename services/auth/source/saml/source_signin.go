@@ -0,0 +1,117 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"errors"
+	"strings"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// ErrMissingUserAttributes is returned if a new user can't be created because the assertion contains no username or email address
+var ErrMissingUserAttributes = errors.New("the identity provider did not provide a username and an email address")
+
+// SignIn returns the user of the verified assertion. Like users of LDAP sources, the user is created on the first login.
+// The admin and restricted flags are updated if a group attribute is configured.
+func (source *Source) SignIn(assertion *Assertion) (*user_model.User, error) {
+	user := &user_model.User{
+		LoginName:   assertion.NameID,
+		LoginType:   auth.SAML,
+		LoginSource: source.authSource.ID,
+	}
+	hasUser, err := user_model.GetUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasUser {
+		if user.ProhibitLogin {
+			return nil, user_model.ErrUserProhibitLogin{UID: user.ID, Name: user.Name}
+		}
+		if !user.IsActive {
+			return nil, user_model.ErrUserInactive{UID: user.ID, Name: user.Name}
+		}
+		if source.setUserGroups(user, assertion) {
+			if err := user_model.UpdateUserCols(db.DefaultContext, user, "is_admin", "is_restricted"); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+
+	username := assertion.NameID
+	if source.UsernameAttribute != "" {
+		username = assertion.Attribute(source.UsernameAttribute)
+	}
+	email := assertion.NameID
+	if source.EmailAttribute != "" {
+		email = assertion.Attribute(source.EmailAttribute)
+	}
+	if username == "" || !strings.Contains(email, "@") {
+		return nil, ErrMissingUserAttributes
+	}
+
+	user = &user_model.User{
+		LowerName:   strings.ToLower(username),
+		Name:        username,
+		Email:       email,
+		LoginType:   auth.SAML,
+		LoginSource: source.authSource.ID,
+		LoginName:   assertion.NameID,
+	}
+	if source.FullNameAttribute != "" {
+		user.FullName = assertion.Attribute(source.FullNameAttribute)
+	}
+	source.setUserGroups(user, assertion)
+
+	overwriteDefault := &user_model.CreateUserOverwriteOptions{
+		IsRestricted: util.OptionalBoolOf(user.IsRestricted),
+		IsActive:     util.OptionalBoolTrue,
+	}
+	if err := user_model.CreateUser(user, overwriteDefault); err != nil {
+		return nil, err
+	}
+
+	mailer.SendRegisterNotifyMail(user)
+
+	return user, nil
+}
+
+// setUserGroups sets the admin and restricted flags of the user from the group attribute.
+// It returns true if a flag was changed.
+func (source *Source) setUserGroups(user *user_model.User, assertion *Assertion) bool {
+	if source.GroupAttribute == "" || (source.AdminGroup == "" && source.RestrictedGroup == "") {
+		return false
+	}
+
+	groups, has := assertion.Attributes[source.GroupAttribute]
+	if !has {
+		return false
+	}
+
+	wasAdmin, wasRestricted := user.IsAdmin, user.IsRestricted
+
+	if source.AdminGroup != "" {
+		user.IsAdmin = false
+	}
+	if source.RestrictedGroup != "" {
+		user.IsRestricted = false
+	}
+
+	for _, g := range groups {
+		if source.AdminGroup != "" && g == source.AdminGroup {
+			user.IsAdmin = true
+		} else if source.RestrictedGroup != "" && g == source.RestrictedGroup {
+			user.IsRestricted = true
+		}
+	}
+
+	return wasAdmin != user.IsAdmin || wasRestricted != user.IsRestricted
+}
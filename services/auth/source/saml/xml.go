@@ -0,0 +1,161 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// The documents are parsed with etree because the signature verification of goxmldsig works on its tree.
+// etree does not check that the document is well-formed, so it is read with encoding/xml first.
+
+const (
+	namespaceXML     = "http://www.w3.org/XML/1998/namespace"
+	namespaceXMLDSig = "http://www.w3.org/2000/09/xmldsig#"
+)
+
+// parseXML parses a document and returns its root element. Documents containing a DTD are rejected.
+func parseXML(data []byte) (*etree.Element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			depth++
+			// etree keeps only one of duplicate attributes
+			seen := map[xml.Name]bool{}
+			for _, a := range t.Attr {
+				if seen[a.Name] {
+					return nil, fmt.Errorf("duplicate attribute %s on element %s", a.Name.Local, t.Name.Local)
+				}
+				seen[a.Name] = true
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Directive:
+			return nil, errors.New("documents containing a DTD are not supported")
+		}
+	}
+	if roots == 0 {
+		return nil, errors.New("empty document")
+	} else if roots > 1 {
+		return nil, errors.New("multiple root elements")
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	root := doc.Root()
+	if err := checkElement(root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkElement rejects undeclared namespace prefixes
+func checkElement(el *etree.Element) error {
+	if el.Space != "" && el.NamespaceURI() == "" {
+		return fmt.Errorf("undeclared namespace prefix %s", el.Space)
+	}
+	for i := range el.Attr {
+		a := &el.Attr[i]
+		if a.Space != "" && a.Space != "xmlns" && a.Space != "xml" && a.NamespaceURI() == "" {
+			return fmt.Errorf("undeclared namespace prefix %s", a.Space)
+		}
+	}
+	for _, child := range el.ChildElements() {
+		if err := checkElement(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespaceURI returns the namespace of the element
+func namespaceURI(el *etree.Element) string {
+	if el.Space == "xml" {
+		return namespaceXML
+	}
+	return el.NamespaceURI()
+}
+
+// isElement checks the namespace and local name of the element
+func isElement(el *etree.Element, namespace, local string) bool {
+	return el.Tag == local && namespaceURI(el) == namespace
+}
+
+// findChildren returns the child elements with the namespace and local name
+func findChildren(el *etree.Element, namespace, local string) []*etree.Element {
+	var elements []*etree.Element
+	for _, child := range el.ChildElements() {
+		if isElement(child, namespace, local) {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// findChild returns the first child element with the namespace and local name
+func findChild(el *etree.Element, namespace, local string) *etree.Element {
+	for _, child := range el.ChildElements() {
+		if isElement(child, namespace, local) {
+			return child
+		}
+	}
+	return nil
+}
+
+// attr returns the value of an attribute without namespace
+func attr(el *etree.Element, name string) (string, bool) {
+	for _, a := range el.Attr {
+		if a.Space == "" && a.Key == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of an attribute without namespace or an empty string
+func attrValue(el *etree.Element, name string) string {
+	value, _ := attr(el, name)
+	return value
+}
+
+// text returns the concatenated character data of the element. Unlike etree's Text it does not stop
+// at comments, which are not covered by the signature and could be used to truncate a signed value.
+func text(el *etree.Element) string {
+	var sb strings.Builder
+	for _, token := range el.Child {
+		if data, ok := token.(*etree.CharData); ok {
+			sb.WriteString(data.Data)
+		}
+	}
+	return sb.String()
+}
+
+// decodeBase64 decodes base64 content which may contain whitespace
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// ErrSignatureMissing is returned if an element contains no signature
+var ErrSignatureMissing = errors.New("element is not signed")
+
+// verifySignature verifies the enveloped signature of the element with one of the certificates.
+// It returns the verified copy of the element without the signature: only data read from the returned
+// element is covered by the signature, the passed element must not be used afterwards.
+func verifySignature(el *etree.Element, certificates []*x509.Certificate) (*etree.Element, error) {
+	if _, has := attr(el, "ID"); !has {
+		return nil, errors.New("signed element has no ID")
+	}
+
+	// the element is verified on its own, so the namespaces declared by its ancestors have to be copied into it
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(ctx, el)
+	if err != nil {
+		return nil, err
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: certificates})
+	validationContext.Clock = dsig.NewFakeClockAt(timeNow())
+	verified, err := validationContext.Validate(detached)
+	if err == dsig.ErrMissingSignature {
+		return nil, ErrSignatureMissing
+	}
+	return verified, err
+}
@@ -6,12 +6,14 @@ package forms
 
 import (
 	"net/http"
+	"strings"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web/middleware"
 
 	"gitea.com/go-chi/binding"
+	"github.com/dustin/go-humanize"
 )
 
 // AdminCreateUserForm form for admin to create user
@@ -43,6 +45,7 @@ type AdminEditUserForm struct {
 	Website                 string `binding:"ValidUrl;MaxSize(255)"`
 	Location                string `binding:"MaxSize(50)"`
 	MaxRepoCreation         int
+	QuotaSize               string
 	Active                  bool
 	Admin                   bool
 	Restricted              bool
@@ -60,6 +63,20 @@ func (f *AdminEditUserForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// ParseQuotaSize parses a human readable quota size like "1 GiB" into bytes.
+// An empty or negative value returns -1 to use the default quota.
+func ParseQuotaSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "-") {
+		return -1, nil
+	}
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
 // AdminDashboardForm form for admin dashboard operations
 type AdminDashboardForm struct {
 	Op   string `binding:"required"`
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package forms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuotaSize(t *testing.T) {
+	cases := []struct {
+		Value string
+		Size  int64
+	}{
+		{"", -1},
+		{"-1", -1},
+		{"0", 0},
+		{"1024", 1024},
+		{"1 KiB", 1024},
+		{"1.5GiB", 1536 << 20},
+		{"2 MB", 2000000},
+	}
+	for _, c := range cases {
+		size, err := ParseQuotaSize(c.Value)
+		assert.NoError(t, err, c.Value)
+		assert.EqualValues(t, c.Size, size, c.Value)
+	}
+
+	_, err := ParseQuotaSize("lots")
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package forms
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web/middleware"
+
+	"gitea.com/go-chi/binding"
+)
+
+// PackageCleanupRuleForm form for creating or editing a package cleanup rule
+type PackageCleanupRuleForm struct {
+	ID             int64
+	Enabled        bool
+	Type           string `binding:"Required;In(alpine,cargo,composer,conan,container,debian,generic,go,helm,maven,npm,nuget,pypi,rubygems)"`
+	NamePattern    string `binding:"GlobPattern;MaxSize(255)" locale:"packages.owner.settings.cleanuprules.name_pattern"`
+	KeepCount      int    `binding:"Range(0,1000)" locale:"packages.owner.settings.cleanuprules.keep_count"`
+	RemoveDays     int    `binding:"Range(0,3650)" locale:"packages.owner.settings.cleanuprules.remove_days"`
+	VersionPattern string `binding:"RegexPattern;MaxSize(255)" locale:"packages.owner.settings.cleanuprules.version_pattern"`
+	Action         string `binding:"Required;In(save,remove)"`
+}
+
+// Validate validates the fields
+func (f *PackageCleanupRuleForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
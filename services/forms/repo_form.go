@@ -8,6 +8,7 @@ package forms
 import (
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -112,6 +113,30 @@ func ParseRemoteAddr(remoteAddr, authUsername, authPassword string) (string, err
 	return remoteAddr, nil
 }
 
+var scpLikeSSHAddr = regexp.MustCompile(`^([^@/:\s]+)@(\[[0-9a-fA-F:.]+\]|[^/:\[\]\s]+):([^/\s].*)$`)
+
+// ParseSSHRemoteAddr checks if given remote address is an SSH address and converts
+// the scp-like syntax "user@host:path" into an ssh URL.
+func ParseSSHRemoteAddr(remoteAddr string) (string, error) {
+	remoteAddr = strings.TrimSpace(remoteAddr)
+	if !strings.HasPrefix(remoteAddr, "ssh://") {
+		m := scpLikeSSHAddr.FindStringSubmatch(remoteAddr)
+		if m == nil {
+			return "", &models.ErrInvalidCloneAddr{IsProtocolInvalid: true, IsURLError: true}
+		}
+		remoteAddr = "ssh://" + m[1] + "@" + m[2] + "/" + m[3]
+	}
+
+	u, err := url.Parse(remoteAddr)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return "", &models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	if _, has := u.User.Password(); has {
+		return "", &models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+	return u.String(), nil
+}
+
 // RepoSettingForm form for changing repository settings
 type RepoSettingForm struct {
 	RepoName           string `binding:"Required;AlphaDashDot;MaxSize(100)"`
@@ -127,6 +152,8 @@ type RepoSettingForm struct {
 	PushMirrorAddress  string
 	PushMirrorUsername string
 	PushMirrorPassword string
+	PushMirrorUseSSH   bool   `form:"push_mirror_use_ssh"`
+	PushMirrorHostKey  string `form:"push_mirror_host_key"`
 	PushMirrorInterval string
 	Private            bool
 	Template           bool
@@ -149,6 +176,7 @@ type RepoSettingForm struct {
 	PullsAllowRebase                      bool
 	PullsAllowRebaseMerge                 bool
 	PullsAllowSquash                      bool
+	PullsAllowFastForwardOnly             bool
 	PullsAllowManualMerge                 bool
 	PullsDefaultMergeStyle                string
 	EnableAutodetectManualMerge           bool
@@ -192,6 +220,7 @@ type ProtectBranchForm struct {
 	MergeWhitelistTeams           string
 	EnableStatusCheck             bool
 	StatusCheckContexts           []string
+	EnableMergeQueue              bool
 	RequiredApprovals             int64
 	EnableApprovalsWhitelist      bool
 	ApprovalsWhitelistUsers       string
@@ -200,6 +229,7 @@ type ProtectBranchForm struct {
 	BlockOnOfficialReviewRequests bool
 	BlockOnOutdatedBranch         bool
 	DismissStaleApprovals         bool
+	RequireCodeOwnerReview        bool
 	RequireSignedCommits          bool
 	ProtectedFilePatterns         string
 	UnprotectedFilePatterns       string
@@ -240,6 +270,11 @@ type WebhookForm struct {
 	PullRequestSync      bool
 	Repository           bool
 	Package              bool
+	Status               bool
+	Wiki                 bool
+	Star                 bool
+	Member               bool
+	Team                 bool
 	Active               bool
 	BranchFilter         string `binding:"GlobPattern"`
 }
@@ -584,14 +619,15 @@ func (f *InitializeLabelsForm) Validate(req *http.Request, errs binding.Errors)
 // swagger:model MergePullRequestOption
 type MergePullRequestForm struct {
 	// required: true
-	// enum: merge,rebase,rebase-merge,squash,manually-merged
-	Do                     string `binding:"Required;In(merge,rebase,rebase-merge,squash,manually-merged)"`
+	// enum: merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged
+	Do                     string `binding:"Required;In(merge,rebase,rebase-merge,squash,fast-forward-only,manually-merged)"`
 	MergeTitleField        string
 	MergeMessageField      string
 	MergeCommitID          string // only used for manually-merged
 	HeadCommitID           string `json:"head_commit_id,omitempty"`
 	ForceMerge             *bool  `json:"force_merge,omitempty"`
 	DeleteBranchAfterMerge bool   `json:"delete_branch_after_merge,omitempty"`
+	MergeWhenChecksSucceed bool   `json:"merge_when_checks_succeed,omitempty"`
 }
 
 // Validate validates the fields
@@ -366,7 +366,10 @@ func (f *AddKeyForm) Validate(req *http.Request, errs binding.Errors) binding.Er
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name         string `binding:"Required;MaxSize(255)"`
+	Scopes       []string
+	ExpiresAt    string `form:"expires_at"`
+	AllowedRepos string `form:"allowed_repos"`
 }
 
 // Validate validates the fields
@@ -411,7 +414,8 @@ func (f *TwoFactorScratchAuthForm) Validate(req *http.Request, errs binding.Erro
 
 // WebauthnRegistrationForm for reserving an WebAuthn name
 type WebauthnRegistrationForm struct {
-	Name string `binding:"Required"`
+	Name    string `binding:"Required"`
+	Passkey bool
 }
 
 // Validate validates the fields
@@ -20,6 +20,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/perm"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -176,6 +177,27 @@ func BatchHandler(ctx *context.Context) {
 		return
 	}
 
+	if isUpload && setting.Quota.Enabled {
+		var size int64
+		for _, p := range br.Objects {
+			if !p.IsValid() {
+				continue
+			}
+			meta, err := models.GetLFSMetaObjectByOid(repository.ID, p.Oid)
+			if err != nil && err != models.ErrLFSObjectNotExist {
+				log.Error("Unable to get LFS MetaObject [%s] for %s/%s. Error: %v", p.Oid, rc.User, rc.Repo, err)
+				writeStatus(ctx, http.StatusInternalServerError)
+				return
+			}
+			if meta == nil {
+				size += p.Size
+			}
+		}
+		if !checkQuota(ctx, repository, size) {
+			return
+		}
+	}
+
 	contentStore := lfs_module.NewContentStore()
 
 	var responseObjects []*lfs_module.ObjectResponse
@@ -286,6 +308,10 @@ func UploadHandler(ctx *context.Context) {
 		return
 	}
 
+	if !checkQuota(ctx, repository, p.Size) {
+		return
+	}
+
 	contentStore := lfs_module.NewContentStore()
 	exists, err := contentStore.Exists(p)
 	if err != nil {
@@ -463,6 +489,20 @@ func buildObjectResponse(rc *requestContext, pointer lfs_module.Pointer, downloa
 	return rep
 }
 
+// checkQuota returns false and writes the error response if storing the objects exceeds the quota of the repository owner
+func checkQuota(ctx *context.Context, repository *repo_model.Repository, size int64) bool {
+	if err := quota_model.CheckQuotaByOwnerID(ctx, repository.OwnerID, size); err != nil {
+		if quota_model.IsErrQuotaExceeded(err) {
+			writeStatusMessage(ctx, http.StatusInsufficientStorage, err.Error())
+			return false
+		}
+		log.Error("Unable to check the quota of %-v: %v", repository, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
 func writeStatus(ctx *context.Context, status int) {
 	writeStatusMessage(ctx, status, http.StatusText(status))
 }
@@ -487,6 +527,17 @@ func authenticate(ctx *context.Context, repository *repo_model.Repository, autho
 		accessMode = perm.AccessModeWrite
 	}
 
+	if t := ctx.AccessToken(); t != nil {
+		requiredScope := models.AccessTokenScopeRepoRead
+		if requireWrite {
+			requiredScope = models.AccessTokenScopeRepoWrite
+		}
+		if !t.Scope.HasScope(requiredScope) || !t.AllowsRepo(repository) {
+			log.Warn("Authentication failure for access token [%d]: missing scope %s or repository %s not allowed", t.ID, requiredScope, repository.FullName())
+			return false
+		}
+	}
+
 	// ctx.IsSigned is unnecessary here, this will be checked in perm.CanAccess
 	perm, err := models.GetUserRepoPermission(ctx, repository, ctx.Doer)
 	if err != nil {
@@ -0,0 +1,199 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+
+	"github.com/jaytaylor/html2text"
+	"golang.org/x/net/html/charset"
+)
+
+// MailContent is the content of an incoming mail
+type MailContent struct {
+	Content     string
+	Attachments []*Attachment
+}
+
+// Attachment is a file attached to an incoming mail
+type Attachment struct {
+	Name    string
+	Content []byte
+}
+
+type contentParts struct {
+	plain       strings.Builder
+	html        strings.Builder
+	attachments []*Attachment
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// getContentFromMailReader extracts the reply text and the attachments of the mail
+func getContentFromMailReader(msg *mail.Message) (*MailContent, error) {
+	parts := &contentParts{}
+	if err := parts.walk(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+
+	text := parts.plain.String()
+	if strings.TrimSpace(text) == "" && parts.html.Len() > 0 {
+		var err error
+		text, err = html2text.FromString(parts.html.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &MailContent{
+		Content:     extractReply(text),
+		Attachments: parts.attachments,
+	}, nil
+}
+
+func (p *contentParts) walk(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	// multipart.Reader decodes quoted-printable parts on its own
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if name := attachmentName(header, params); name != "" {
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		p.attachments = append(p.attachments, &Attachment{Name: name, Content: content})
+		return nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return nil
+	}
+
+	if cs := params["charset"]; cs != "" {
+		body, err = charset.NewReaderLabel(cs, body)
+		if err != nil {
+			return fmt.Errorf("unsupported charset %s: %v", cs, err)
+		}
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if mediaType == "text/html" {
+		p.html.Write(content)
+	} else {
+		p.plain.Write(content)
+	}
+	return nil
+}
+
+// attachmentName returns the file name of a part which is an attachment
+func attachmentName(header textproto.MIMEHeader, contentTypeParams map[string]string) string {
+	disposition, params, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := params["filename"]
+	if name == "" {
+		name = contentTypeParams["name"]
+	}
+	if name == "" && disposition != "attachment" {
+		return ""
+	}
+	if decoded, err := wordDecoder.DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	if name == "" {
+		name = "attachment"
+	}
+	return name
+}
+
+var (
+	quoteHeaderPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^On\s.+wrote:$`),
+		regexp.MustCompile(`^-+\s*Original Message\s*-+$`),
+		regexp.MustCompile(`^_{10,}$`),
+		regexp.MustCompile(`^From:\s.+`),
+	}
+	onLinePattern = regexp.MustCompile(`^On\s`)
+)
+
+// extractReply removes the quoted message and the signature from a reply
+func extractReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	end := len(lines)
+	for i := 0; i < end; i++ {
+		// signature delimiter, some clients strip the trailing space
+		if lines[i] == "-- " || lines[i] == "--" {
+			end = i
+			break
+		}
+		line := strings.TrimSpace(lines[i])
+		if isQuoteHeader(line) {
+			end = i
+			break
+		}
+		// some clients wrap the quote header
+		if i+1 < end && onLinePattern.MatchString(line) && isQuoteHeader(line+" "+strings.TrimSpace(lines[i+1])) {
+			end = i
+			break
+		}
+	}
+	lines = lines[:end]
+
+	// drop quoted lines at the end of the reply
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[len(lines)-1])
+		if line != "" && !strings.HasPrefix(line, ">") {
+			break
+		}
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isQuoteHeader(line string) bool {
+	for _, pattern := range quoteHeaderPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
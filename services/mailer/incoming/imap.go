@@ -0,0 +1,234 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imapClient is a minimal IMAP4rev1 (RFC 3501) client which supports just
+// enough commands to fetch and flag messages of a single mailbox.
+type imapClient struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	tag     int
+	timeout time.Duration
+}
+
+// imapResponse is an untagged server response. Literals are removed from
+// the text and returned separately.
+type imapResponse struct {
+	Text     string
+	Literals [][]byte
+}
+
+func dialIMAP(ctx context.Context, host string, port int, useTLS, skipVerify bool) (*imapClient, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if useTLS {
+		conn, err = (&tls.Dialer{
+			NetDialer: dialer,
+			Config: &tls.Config{
+				ServerName:         host,
+				InsecureSkipVerify: skipVerify,
+			},
+		}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := newIMAPClient(conn)
+	resp, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(resp.Text, "* OK") && !strings.HasPrefix(resp.Text, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting: %s", resp.Text)
+	}
+	return c, nil
+}
+
+func newIMAPClient(conn net.Conn) *imapClient {
+	return &imapClient{
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+		timeout: 5 * time.Minute,
+	}
+}
+
+// Close closes the connection without logging out
+func (c *imapClient) Close() error {
+	return c.conn.Close()
+}
+
+// Login authenticates with username and password
+func (c *imapClient) Login(username, password string) error {
+	_, err := c.execute("LOGIN " + quoteIMAP(username) + " " + quoteIMAP(password))
+	return err
+}
+
+// Select opens the mailbox for reading and writing
+func (c *imapClient) Select(mailbox string) error {
+	_, err := c.execute("SELECT " + quoteIMAP(mailbox))
+	return err
+}
+
+// Search returns the UIDs of the messages matching the search criteria
+func (c *imapClient) Search(criteria string) ([]uint32, error) {
+	responses, err := c.execute("UID SEARCH " + criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, resp := range responses {
+		if !strings.HasPrefix(resp.Text, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(resp.Text[len("* SEARCH"):]) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IMAP search response: %s", resp.Text)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// Fetch returns the raw message without setting the \Seen flag
+func (c *imapClient) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.execute(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resp := range responses {
+		if strings.Contains(resp.Text, " FETCH ") && len(resp.Literals) > 0 {
+			return resp.Literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", uid)
+}
+
+// AddFlags adds the flags to the message
+func (c *imapClient) AddFlags(uid uint32, flags ...string) error {
+	_, err := c.execute(fmt.Sprintf("UID STORE %d +FLAGS.SILENT (%s)", uid, strings.Join(flags, " ")))
+	return err
+}
+
+// Expunge permanently removes all messages flagged as \Deleted
+func (c *imapClient) Expunge() error {
+	_, err := c.execute("EXPUNGE")
+	return err
+}
+
+// Logout ends the session and closes the connection
+func (c *imapClient) Logout() error {
+	_, err := c.execute("LOGOUT")
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// execute sends the command and collects the untagged responses until the
+// tagged completion response is received
+func (c *imapClient) execute(command string) ([]*imapResponse, error) {
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(c.conn, tag+" "+command+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	var responses []*imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(resp.Text, tag+" ") {
+			responses = append(responses, resp)
+			continue
+		}
+
+		status := resp.Text[len(tag)+1:]
+		if !strings.HasPrefix(status, "OK") {
+			verb := command
+			if i := strings.IndexByte(verb, ' '); i > 0 {
+				verb = verb[:i]
+			}
+			return nil, fmt.Errorf("IMAP %s failed: %s", verb, status)
+		}
+		return responses, nil
+	}
+}
+
+// readResponse reads a response line including the literals it announces
+func (c *imapClient) readResponse() (*imapResponse, error) {
+	resp := &imapResponse{}
+	var text strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		size, ok := literalSize(line)
+		if !ok {
+			text.WriteString(line)
+			resp.Text = text.String()
+			return resp, nil
+		}
+
+		text.WriteString(line[:strings.LastIndexByte(line, '{')])
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return nil, err
+		}
+		resp.Literals = append(resp.Literals, literal)
+	}
+}
+
+// literalSize returns the size of the literal announced at the end of the line
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	start := strings.LastIndexByte(line, '{')
+	if start < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(line[start+1 : len(line)-1])
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+func quoteIMAP(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
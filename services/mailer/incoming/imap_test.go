@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveIMAP answers each expected command with the scripted response
+func serveIMAP(t *testing.T, conn net.Conn, script [][2]string) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for _, step := range script {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, step[0], strings.TrimRight(line, "\r\n"))
+		if _, err := conn.Write([]byte(step[1])); !assert.NoError(t, err) {
+			return
+		}
+	}
+}
+
+func TestIMAPClient(t *testing.T) {
+	message := "Subject: test\r\n\r\nHello {5}\r\n"
+
+	client, server := net.Pipe()
+	go serveIMAP(t, server, [][2]string{
+		{`a1 LOGIN "user" "p\"ss"`, "a1 OK LOGIN completed\r\n"},
+		{`a2 SELECT "INBOX"`, "* 2 EXISTS\r\n* OK [UIDVALIDITY 1] UIDs valid\r\na2 OK [READ-WRITE] SELECT completed\r\n"},
+		{`a3 UID SEARCH UNSEEN`, "* SEARCH 3 7\r\na3 OK SEARCH completed\r\n"},
+		{`a4 UID FETCH 7 BODY.PEEK[]`, "* 2 FETCH (UID 7 BODY[] {" + strconv.Itoa(len(message)) + "}\r\n" + message + ")\r\na4 OK FETCH completed\r\n"},
+		{`a5 UID STORE 7 +FLAGS.SILENT (\Seen \Deleted)`, "a5 OK STORE completed\r\n"},
+		{`a6 UID FETCH 8 BODY.PEEK[]`, "a6 NO no such message\r\n"},
+		{`a7 LOGOUT`, "* BYE\r\na7 OK LOGOUT completed\r\n"},
+	})
+
+	c := newIMAPClient(client)
+	assert.NoError(t, c.Login("user", `p"ss`))
+	assert.NoError(t, c.Select("INBOX"))
+
+	uids, err := c.Search("UNSEEN")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{3, 7}, uids)
+
+	raw, err := c.Fetch(7)
+	assert.NoError(t, err)
+	assert.Equal(t, message, string(raw))
+
+	assert.NoError(t, c.AddFlags(7, `\Seen`, `\Deleted`))
+
+	_, err = c.Fetch(8)
+	assert.Error(t, err)
+
+	assert.NoError(t, c.Logout())
+}
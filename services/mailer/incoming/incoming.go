@@ -0,0 +1,208 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer/token"
+)
+
+// Init starts polling the configured mailbox
+func Init() {
+	if !setting.IncomingEmail.Enabled || setting.IncomingEmail.Host == "" {
+		return
+	}
+
+	go graceful.GetManager().RunWithShutdownContext(poll)
+}
+
+func poll(ctx context.Context) {
+	ticker := time.NewTicker(setting.IncomingEmail.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := processMailbox(ctx); err != nil {
+			log.Error("Unable to process incoming mails: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processMailbox handles all unseen messages of the mailbox
+func processMailbox(ctx context.Context) error {
+	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Service: IncomingEmail", process.SystemProcessType, true)
+	defer finished()
+
+	cfg := setting.IncomingEmail
+	c, err := dialIMAP(ctx, cfg.Host, cfg.Port, cfg.UseTLS, cfg.SkipTLSVerify)
+	if err != nil {
+		return fmt.Errorf("connect: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Login(cfg.Username, cfg.Password); err != nil {
+		return err
+	}
+	if err := c.Select(cfg.Mailbox); err != nil {
+		return err
+	}
+
+	handledFlags := []string{`\Seen`}
+	if cfg.DeleteHandledMessage {
+		handledFlags = append(handledFlags, `\Deleted`)
+	}
+
+	// oversized messages are never fetched
+	oversized, err := c.Search(fmt.Sprintf("UNSEEN LARGER %d", cfg.MaximumMessageSize))
+	if err != nil {
+		return err
+	}
+	for _, uid := range oversized {
+		log.Warn("Incoming mail %d exceeds the maximum message size", uid)
+		if err := c.AddFlags(uid, handledFlags...); err != nil {
+			return err
+		}
+	}
+
+	uids, err := c.Search("UNSEEN")
+	if err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		select {
+		case <-ctx.Done():
+			return c.Logout()
+		default:
+		}
+
+		raw, err := c.Fetch(uid)
+		if err != nil {
+			return err
+		}
+		if err := HandleMessage(ctx, bytes.NewReader(raw)); err != nil {
+			// keep the message so it is retried with the next poll
+			log.Error("Unable to handle incoming mail %d: %v", uid, err)
+			continue
+		}
+		if err := c.AddFlags(uid, handledFlags...); err != nil {
+			return err
+		}
+	}
+
+	if cfg.DeleteHandledMessage && len(uids)+len(oversized) > 0 {
+		if err := c.Expunge(); err != nil {
+			return err
+		}
+	}
+	return c.Logout()
+}
+
+// HandleMessage processes a raw incoming mail. Mails which can not be
+// attributed to a valid token are dropped without an error, an error is only
+// returned if processing should be retried.
+func HandleMessage(ctx context.Context, r io.Reader) error {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		log.Warn("Unable to parse incoming mail: %v", err)
+		return nil
+	}
+
+	if isAutomaticReply(msg.Header) {
+		log.Debug("Dropped automatic reply %q", msg.Header.Get("Message-ID"))
+		return nil
+	}
+
+	t := searchTokenInHeaders(msg.Header)
+	if t == "" {
+		log.Debug("Dropped incoming mail %q without token", msg.Header.Get("Message-ID"))
+		return nil
+	}
+
+	ht, user, payload, err := token.ExtractToken(ctx, t)
+	if err != nil {
+		if err == token.ErrInvalidToken {
+			log.Warn("Dropped incoming mail %q with an invalid token", msg.Header.Get("Message-ID"))
+			return nil
+		}
+		return err
+	}
+
+	handler, ok := handlers[ht]
+	if !ok {
+		log.Warn("Dropped incoming mail %q with unknown handler type %d", msg.Header.Get("Message-ID"), ht)
+		return nil
+	}
+
+	content, err := getContentFromMailReader(msg)
+	if err != nil {
+		log.Warn("Unable to read the content of incoming mail %q: %v", msg.Header.Get("Message-ID"), err)
+		return nil
+	}
+
+	if err := handler.Handle(ctx, content, user, payload); err != nil {
+		log.Warn("Dropped incoming mail %q of %s: %v", msg.Header.Get("Message-ID"), user.Name, err)
+	}
+	return nil
+}
+
+// searchTokenInHeaders returns the token of the first recipient address
+// which matches the configured reply address
+func searchTokenInHeaders(header mail.Header) string {
+	parts := strings.SplitN(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	pattern, err := regexp.Compile(`(?i)^` + regexp.QuoteMeta(parts[0]) + `([a-z0-9]+)` + regexp.QuoteMeta(parts[1]) + `$`)
+	if err != nil {
+		return ""
+	}
+
+	for _, key := range []string{"To", "Cc", "Delivered-To", "X-Original-To", "Envelope-To"} {
+		for _, value := range header[key] {
+			addresses, err := mail.ParseAddressList(value)
+			if err != nil {
+				continue
+			}
+			for _, address := range addresses {
+				if match := pattern.FindStringSubmatch(address.Address); match != nil {
+					return match[1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isAutomaticReply reports whether the mail was sent by an auto responder
+func isAutomaticReply(header mail.Header) bool {
+	if autoSubmitted := strings.ToLower(header.Get("Auto-Submitted")); autoSubmitted != "" && autoSubmitted != "no" {
+		return true
+	}
+	if header.Get("X-Autoreply") != "" || header.Get("X-Autorespond") != "" {
+		return true
+	}
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "auto_reply", "bulk", "junk":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	quota_model "code.gitea.io/gitea/models/quota"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/upload"
+	attachment_service "code.gitea.io/gitea/services/attachment"
+	comment_service "code.gitea.io/gitea/services/comments"
+	"code.gitea.io/gitea/services/mailer/token"
+)
+
+// Handler processes the content of an incoming mail for a token
+type Handler interface {
+	Handle(ctx context.Context, content *MailContent, doer *user_model.User, payload []byte) error
+}
+
+var handlers = map[token.HandlerType]Handler{
+	token.ReplyHandlerType:       &ReplyHandler{},
+	token.UnsubscribeHandlerType: &UnsubscribeHandler{},
+}
+
+// loadIssue loads the issue referenced by the payload if the doer can read it.
+// The payload is the varint encoded issue id, see mailer.createReplyAddress.
+func loadIssue(ctx context.Context, doer *user_model.User, payload []byte) (*models.Issue, *models.Permission, error) {
+	issueID, n := binary.Varint(payload)
+	if n <= 0 || n != len(payload) {
+		return nil, nil, fmt.Errorf("invalid issue payload")
+	}
+
+	issue, err := models.GetIssueByID(issueID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	perm, err := models.GetUserRepoPermission(ctx, issue.Repo, doer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !perm.CanReadIssuesOrPulls(issue.IsPull) {
+		return nil, nil, fmt.Errorf("%s can not read issue %d", doer.Name, issue.ID)
+	}
+	return issue, &perm, nil
+}
+
+// ReplyHandler posts the content of the mail as a comment
+type ReplyHandler struct{}
+
+// Handle posts the reply and its attachments as a comment on the issue
+func (h *ReplyHandler) Handle(ctx context.Context, content *MailContent, doer *user_model.User, payload []byte) error {
+	if doer.ProhibitLogin || !doer.IsActive {
+		return fmt.Errorf("%s is not allowed to sign in", doer.Name)
+	}
+
+	issue, perm, err := loadIssue(ctx, doer, payload)
+	if err != nil {
+		return err
+	}
+	if issue.Repo.IsArchived {
+		return fmt.Errorf("repository %s of issue %d is archived", issue.Repo.FullName(), issue.ID)
+	}
+	if issue.IsLocked && !perm.CanWriteIssuesOrPulls(issue.IsPull) && !doer.IsAdmin {
+		return fmt.Errorf("issue %d is locked", issue.ID)
+	}
+
+	var attachmentUUIDs []string
+	if setting.Attachment.Enabled {
+		for _, attachment := range content.Attachments {
+			if len(attachmentUUIDs) >= setting.Attachment.MaxFiles {
+				log.Warn("Incoming mail for issue %d has more than %d attachments", issue.ID, setting.Attachment.MaxFiles)
+				break
+			}
+			if int64(len(attachment.Content)) > setting.Attachment.MaxSize<<20 {
+				log.Warn("Attachment %s of incoming mail for issue %d is too large", attachment.Name, issue.ID)
+				continue
+			}
+
+			attach, err := attachment_service.UploadAttachment(bytes.NewReader(attachment.Content), doer.ID, issue.Repo.ID, 0, attachment.Name, setting.Attachment.AllowedTypes)
+			if err != nil {
+				if upload.IsErrFileTypeForbidden(err) || quota_model.IsErrQuotaExceeded(err) {
+					log.Warn("Attachment %s of incoming mail for issue %d: %v", attachment.Name, issue.ID, err)
+					continue
+				}
+				return err
+			}
+			attachmentUUIDs = append(attachmentUUIDs, attach.UUID)
+		}
+	}
+
+	if content.Content == "" && len(attachmentUUIDs) == 0 {
+		return nil
+	}
+
+	_, err = comment_service.CreateIssueComment(doer, issue.Repo, issue, content.Content, attachmentUUIDs)
+	return err
+}
+
+// UnsubscribeHandler stops the notifications of an issue
+type UnsubscribeHandler struct{}
+
+// Handle unwatches the issue
+func (h *UnsubscribeHandler) Handle(ctx context.Context, _ *MailContent, doer *user_model.User, payload []byte) error {
+	issue, _, err := loadIssue(ctx, doer, payload)
+	if err != nil {
+		return err
+	}
+	return models.CreateOrUpdateIssueWatch(doer.ID, issue.ID, false)
+}
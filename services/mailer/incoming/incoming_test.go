@@ -0,0 +1,138 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package incoming
+
+import (
+	"encoding/binary"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTokenInHeaders(t *testing.T) {
+	defer func(address string) {
+		setting.IncomingEmail.ReplyToAddress = address
+	}(setting.IncomingEmail.ReplyToAddress)
+	setting.IncomingEmail.ReplyToAddress = "incoming+%{token}@example.com"
+
+	cases := []struct {
+		Header mail.Header
+		Token  string
+	}{
+		{mail.Header{"To": {"incoming+abc123@example.com"}}, "abc123"},
+		{mail.Header{"To": {"Gitea <INCOMING+ABC123@example.com>"}}, "ABC123"},
+		{mail.Header{"To": {"someone@example.com"}, "Cc": {"a@b.c, incoming+abc@example.com"}}, "abc"},
+		{mail.Header{"Delivered-To": {"incoming+abc@example.com"}}, "abc"},
+		{mail.Header{"To": {"incoming+abc@example.com.evil"}}, ""},
+		{mail.Header{"To": {"incoming+@example.com"}}, ""},
+		{mail.Header{"From": {"incoming+abc@example.com"}}, ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.Token, searchTokenInHeaders(c.Header), c.Header)
+	}
+}
+
+func TestIsAutomaticReply(t *testing.T) {
+	assert.False(t, isAutomaticReply(mail.Header{}))
+	assert.False(t, isAutomaticReply(mail.Header{"Auto-Submitted": {"no"}}))
+	assert.True(t, isAutomaticReply(mail.Header{"Auto-Submitted": {"auto-replied"}}))
+	assert.True(t, isAutomaticReply(mail.Header{"X-Autoreply": {"yes"}}))
+	assert.True(t, isAutomaticReply(mail.Header{"Precedence": {"bulk"}}))
+}
+
+func TestExtractReply(t *testing.T) {
+	cases := []struct {
+		Text  string
+		Reply string
+	}{
+		{"Looks good to me.\n", "Looks good to me."},
+		{"Looks good.\r\n\r\nOn Mon, 1 Aug 2022 at 10:00, Gitea <gitea@example.com> wrote:\r\n> Original\r\n", "Looks good."},
+		{"Looks good.\n\nOn Mon, 1 Aug 2022 at 10:00, Gitea\n<gitea@example.com> wrote:\n> Original\n", "Looks good."},
+		{"Looks good.\n\n-- \nJane Doe\n", "Looks good."},
+		{"Looks good.\n\n-----Original Message-----\nFrom: Gitea\n", "Looks good."},
+		{"> quoted\n\nAgreed.\n\n> more quoted\n", "> quoted\n\nAgreed."},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.Reply, extractReply(c.Text), c.Text)
+	}
+}
+
+func TestGetContentFromMailReader(t *testing.T) {
+	raw := "From: user@example.com\r\n" +
+		"To: incoming+abc@example.com\r\n" +
+		"Subject: Re: issue\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Gr=FC=DFe\r\n" +
+		"\r\n" +
+		"On Mon, 1 Aug 2022, Gitea wrote:\r\n" +
+		"> Original\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<p>Grüße</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: text/plain; name=\"log.txt\"\r\n" +
+		"Content-Disposition: attachment; filename=\"log.txt\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"aGVsbG8g\r\n" +
+		"d29ybGQ=\r\n" +
+		"--outer--\r\n"
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	assert.NoError(t, err)
+
+	content, err := getContentFromMailReader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "Grüße", content.Content)
+	if assert.Len(t, content.Attachments, 1) {
+		assert.Equal(t, "log.txt", content.Attachments[0].Name)
+		assert.Equal(t, "hello world", string(content.Attachments[0].Content))
+	}
+
+	// HTML only mails are converted to text
+	msg, err = mail.ReadMessage(strings.NewReader("Content-Type: text/html\r\n\r\n<p>Hello <b>there</b></p>"))
+	assert.NoError(t, err)
+	content, err = getContentFromMailReader(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello *there*", content.Content)
+	assert.Empty(t, content.Attachments)
+}
+
+func TestReplyHandlerArchivedRepo(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	issue := unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: issue.RepoID}).(*repo_model.Repository)
+	repo.IsArchived = true
+	assert.NoError(t, repo_model.UpdateRepositoryCols(repo, "is_archived"))
+
+	payload := make([]byte, binary.MaxVarintLen64)
+	payload = payload[:binary.PutVarint(payload, issue.ID)]
+
+	err := (&ReplyHandler{}).Handle(db.DefaultContext, &MailContent{Content: "reply to an archived repository"}, doer, payload)
+	assert.Error(t, err)
+	unittest.AssertNotExistsBean(t, &models.Comment{IssueID: issue.ID, Content: "reply to an archived repository"})
+}
@@ -8,6 +8,7 @@ package mailer
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"html/template"
 	"mime"
@@ -30,6 +31,7 @@ import (
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/services/mailer/token"
 
 	"gopkg.in/gomail.v2"
 )
@@ -322,6 +324,20 @@ func composeIssueCommentMessages(ctx *mailCommentContext, lang string, recipient
 			msg.SetHeader(key, value)
 		}
 
+		if setting.IncomingEmail.Enabled {
+			replyAddress, err := createReplyAddress(token.ReplyHandlerType, recipient, ctx.Issue)
+			if err != nil {
+				return nil, err
+			}
+			msg.SetHeader("Reply-To", replyAddress)
+
+			unsubscribeAddress, err := createReplyAddress(token.UnsubscribeHandlerType, recipient, ctx.Issue)
+			if err != nil {
+				return nil, err
+			}
+			msg.SetHeader("List-Unsubscribe", fmt.Sprintf("<mailto:%s>, <%s>", unsubscribeAddress, ctx.Issue.HTMLURL()))
+		}
+
 		msgs = append(msgs, msg)
 	}
 
@@ -355,6 +371,17 @@ func createReference(issue *models.Issue, comment *models.Comment, actionType mo
 	return fmt.Sprintf("%s/%s/%d%s@%s", issue.Repo.FullName(), path, issue.Index, extra, setting.Domain)
 }
 
+// createReplyAddress returns the incoming mail address which performs the
+// action of the handler type for the recipient on the issue
+func createReplyAddress(ht token.HandlerType, recipient *user_model.User, issue *models.Issue) (string, error) {
+	payload := make([]byte, binary.MaxVarintLen64)
+	t, err := token.CreateToken(ht, recipient, payload[:binary.PutVarint(payload, issue.ID)])
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(setting.IncomingEmail.ReplyToAddress, setting.IncomingEmailTokenPlaceholder, t, 1), nil
+}
+
 func generateAdditionalHeaders(ctx *mailCommentContext, reason string, recipient *user_model.User) map[string]string {
 	repo := ctx.Issue.Repo
 
@@ -93,6 +93,40 @@ func TestComposeIssueCommentMessage(t *testing.T) {
 	assert.Equal(t, "<user2/repo1/issues/1/comment/2@localhost>", messageID[0], "Message-ID header doesn't match")
 }
 
+func TestComposeIssueCommentMessageReplyTo(t *testing.T) {
+	doer, _, issue, comment := prepareMailerTest(t)
+
+	defer func(enabled bool, address string) {
+		setting.IncomingEmail.Enabled = enabled
+		setting.IncomingEmail.ReplyToAddress = address
+	}(setting.IncomingEmail.Enabled, setting.IncomingEmail.ReplyToAddress)
+	setting.IncomingEmail.Enabled = true
+	setting.IncomingEmail.ReplyToAddress = "incoming+%{token}@localhost"
+
+	stpl := texttmpl.Must(texttmpl.New("issue/comment").Parse(subjectTpl))
+	btpl := template.Must(template.New("issue/comment").Parse(bodyTpl))
+	InitMailRender(stpl, btpl)
+
+	recipients := []*user_model.User{{ID: 2, Name: "Test", Email: "test@gitea.com"}}
+	msgs, err := composeIssueCommentMessages(&mailCommentContext{
+		Context: context.TODO(), // TODO: use a correct context
+		Issue:   issue, Doer: doer, ActionType: models.ActionCommentIssue,
+		Content: "test body", Comment: comment,
+	}, "en-US", recipients, false, "issue comment")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+
+	gomailMsg := msgs[0].ToMessage()
+	replyTo := gomailMsg.GetHeader("Reply-To")
+	assert.Len(t, replyTo, 1)
+	assert.Regexp(t, `^incoming\+[a-z0-9]+@localhost$`, replyTo[0])
+
+	unsubscribe := gomailMsg.GetHeader("List-Unsubscribe")
+	assert.Len(t, unsubscribe, 1)
+	assert.Regexp(t, `^<mailto:incoming\+[a-z0-9]+@localhost>, <https?://.+/user2/repo1/issues/1>$`, unsubscribe[0])
+	assert.NotContains(t, unsubscribe[0], replyTo[0])
+}
+
 func TestComposeIssueMessage(t *testing.T) {
 	doer, _, issue, _ := prepareMailerTest(t)
 
@@ -0,0 +1,127 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// A token is a signed payload embedded in the reply address of a notification mail:
+//
+//	version (1 byte) | handler type (1 byte) | user id (uvarint) | expiry (uvarint) | data
+//
+// followed by a truncated HMAC-SHA256 of the payload keyed with the
+// instance secret and the user's random string. It is encoded as lowercase
+// base32 without padding because mail servers may change the case of the
+// local part of an address.
+
+// HandlerType defines the type of action a token performs
+type HandlerType byte
+
+const (
+	// UnknownHandlerType is the zero value of HandlerType
+	UnknownHandlerType HandlerType = iota
+	// ReplyHandlerType posts the mail content as a comment
+	ReplyHandlerType
+	// UnsubscribeHandlerType unwatches the issue
+	UnsubscribeHandlerType
+)
+
+const (
+	tokenVersion1  byte = 1
+	signatureSize       = 10
+	tokenLifetime       = 180 * 24 * time.Hour
+	maxPayloadSize      = 64
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidToken represents an invalid, expired or forged token
+var ErrInvalidToken = errors.New("invalid token")
+
+// CreateToken creates a token for the user, the handler type and the handler data
+func CreateToken(ht HandlerType, user *user_model.User, data []byte) (string, error) {
+	if len(data) > maxPayloadSize {
+		return "", fmt.Errorf("token data too large: %d bytes", len(data))
+	}
+
+	payload := make([]byte, 2, 2+2*binary.MaxVarintLen64+len(data)+signatureSize)
+	payload[0] = tokenVersion1
+	payload[1] = byte(ht)
+	payload = appendUvarint(payload, uint64(user.ID))
+	payload = appendUvarint(payload, uint64(time.Now().Add(tokenLifetime).Unix()))
+	payload = append(payload, data...)
+	payload = append(payload, sign(user, payload)...)
+
+	return strings.ToLower(encoding.EncodeToString(payload)), nil
+}
+
+// ExtractToken validates the token and returns its handler type, user and data
+func ExtractToken(ctx context.Context, token string) (HandlerType, *user_model.User, []byte, error) {
+	payload, err := encoding.DecodeString(strings.ToUpper(token))
+	if err != nil {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	if len(payload) < 2+signatureSize || payload[0] != tokenVersion1 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+
+	signature := payload[len(payload)-signatureSize:]
+	payload = payload[:len(payload)-signatureSize]
+
+	ht := HandlerType(payload[1])
+	rest := payload[2:]
+	userID, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	rest = rest[n:]
+	expiry, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	data := rest[n:]
+
+	user, err := user_model.GetUserByIDCtx(ctx, int64(userID))
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			return UnknownHandlerType, nil, nil, ErrInvalidToken
+		}
+		return UnknownHandlerType, nil, nil, err
+	}
+
+	if !hmac.Equal(signature, sign(user, payload)) {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+	if time.Now().Unix() > int64(expiry) {
+		return UnknownHandlerType, nil, nil, ErrInvalidToken
+	}
+
+	return ht, user, data, nil
+}
+
+func sign(user *user_model.User, payload []byte) []byte {
+	key := sha256.Sum256([]byte(setting.SecretKey + user.Rands))
+	mac := hmac.New(sha256.New, key[:])
+	_, _ = mac.Write(payload)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package token
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", "..", ".."),
+	})
+}
+
+func TestToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	token, err := CreateToken(ReplyHandlerType, user, []byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ToLower(token), token)
+	assert.LessOrEqual(t, len(token), 64)
+
+	ht, u, data, err := ExtractToken(db.DefaultContext, token)
+	assert.NoError(t, err)
+	assert.Equal(t, ReplyHandlerType, ht)
+	assert.Equal(t, user.ID, u.ID)
+	assert.Equal(t, []byte{1, 2, 3}, data)
+
+	// mail servers may change the case of the address
+	_, u, _, err = ExtractToken(db.DefaultContext, strings.ToUpper(token))
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, u.ID)
+
+	tampered := []byte(token)
+	tampered[len(tampered)-3] ^= 1
+	_, _, _, err = ExtractToken(db.DefaultContext, string(tampered))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, _, err = ExtractToken(db.DefaultContext, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
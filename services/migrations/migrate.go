@@ -75,10 +75,33 @@ func IsMigrateURLAllowed(remoteURL string, doer *user_model.User) error {
 		return &models.ErrInvalidCloneAddr{Host: u.Host, IsProtocolInvalid: true, IsPermissionDenied: true, IsURLError: true}
 	}
 
+	return isHostAllowed(u)
+}
+
+// IsPushMirrorSSHURLAllowed checks if an ssh URL is allowed to be pushed to
+func IsPushMirrorSSHURLAllowed(remoteURL string) error {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return &models.ErrInvalidCloneAddr{IsURLError: true}
+	}
+
+	if u.Scheme != "ssh" || u.Opaque != "" {
+		return &models.ErrInvalidCloneAddr{Host: u.Host, IsProtocolInvalid: true, IsPermissionDenied: true, IsURLError: true}
+	}
+
+	// the host and the user are passed to the ssh command line and must not be mistaken for options
+	if u.Hostname() == "" || strings.HasPrefix(u.Host, "-") || u.User == nil || strings.HasPrefix(u.User.Username(), "-") {
+		return &models.ErrInvalidCloneAddr{Host: u.Host, IsURLError: true}
+	}
+
+	return isHostAllowed(u)
+}
+
+// isHostAllowed checks the host of an URL against the allow and block lists
+func isHostAllowed(u *url.URL) error {
 	hostName, _, err := net.SplitHostPort(u.Host)
 	if err != nil {
 		// u.Host can be "host" or "host:port"
-		err = nil //nolint
 		hostName = u.Host
 	}
 
@@ -74,3 +74,17 @@ func TestMigrateWhiteBlocklist(t *testing.T) {
 
 	setting.ImportLocalPaths = old
 }
+
+func TestPushMirrorSSHURLAllowed(t *testing.T) {
+	setting.Migrations.AllowedDomains = ""
+	setting.Migrations.BlockedDomains = "github.com"
+	setting.Migrations.AllowLocalNetworks = false
+	assert.NoError(t, Init())
+
+	assert.NoError(t, IsPushMirrorSSHURLAllowed("ssh://git@gitlab.com/gitlab/gitlab.git"))
+	assert.Error(t, IsPushMirrorSSHURLAllowed("ssh://git@github.com/go-gitea/gitea.git"))
+	assert.Error(t, IsPushMirrorSSHURLAllowed("ssh://git@10.0.0.1/go-gitea/gitea.git"))
+	assert.Error(t, IsPushMirrorSSHURLAllowed("https://gitlab.com/gitlab/gitlab.git"))
+	assert.Error(t, IsPushMirrorSSHURLAllowed("ssh://gitlab.com/gitlab/gitlab.git"))
+	assert.Error(t, IsPushMirrorSSHURLAllowed("ssh://git@-oProxyCommand=id/gitea.git"))
+}
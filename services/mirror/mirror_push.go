@@ -9,6 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -20,12 +23,18 @@ import (
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/ssh"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
+
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 var stripExitStatus = regexp.MustCompile(`exit status \d+ - `)
 
+// hostKeyScanTimeout limits the connection to an SSH server to get its host key
+const hostKeyScanTimeout = 30 * time.Second
+
 // AddPushMirrorRemote registers the push mirror remote.
 func AddPushMirrorRemote(ctx context.Context, m *repo_model.PushMirror, addr string) error {
 	addRemoteAndConfig := func(addr, path string) error {
@@ -51,7 +60,8 @@ func AddPushMirrorRemote(ctx context.Context, m *repo_model.PushMirror, addr str
 		return err
 	}
 
-	if m.Repo.HasWiki() {
+	// the wiki remote can't be probed with the keypair of the mirror
+	if m.Repo.HasWiki() && !m.UseSSH() {
 		wikiRemoteURL := repository.WikiRemoteURL(ctx, addr)
 		if len(wikiRemoteURL) > 0 {
 			if err := addRemoteAndConfig(wikiRemoteURL, m.Repo.WikiPath()); err != nil {
@@ -127,6 +137,41 @@ func SyncPushMirror(ctx context.Context, mirrorID int64) bool {
 func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
 
+	var env []string
+	if m.UseSSH() {
+		remoteAddr, err := git.GetRemoteAddress(ctx, m.Repo.RepoPath(), m.RemoteName)
+		if err != nil {
+			log.Error("GetRemoteAddress(%s) Error %v", m.Repo.RepoPath(), err)
+			return errors.New("Unexpected error")
+		}
+		hostPort := remoteAddr.Host
+		if remoteAddr.Port() == "" {
+			hostPort = net.JoinHostPort(remoteAddr.Hostname(), "22")
+		}
+
+		// the host key is recorded on the first connection and verified on all later ones,
+		// it is stored together with the result of the sync
+		if m.HostKey == "" {
+			if err := recordHostKey(ctx, m, hostPort); err != nil {
+				log.Error("Unable to record the host key of push mirror[%d]: %v", m.ID, err)
+				return err
+			}
+		}
+
+		sshDir, err := writeSSHFiles(m, hostPort)
+		if err != nil {
+			log.Error("Unable to write the SSH files of push mirror[%d]: %v", m.ID, err)
+			return errors.New("Unexpected error")
+		}
+		defer func() {
+			if err := util.RemoveAll(sshDir); err != nil {
+				log.Error("Unable to remove the SSH files of push mirror[%d]: %v", m.ID, err)
+			}
+		}()
+
+		env = append(os.Environ(), "GIT_SSH_COMMAND="+sshCommand(filepath.Join(sshDir, "id"), filepath.Join(sshDir, "known_hosts")))
+	}
+
 	performPush := func(path string) error {
 		remoteAddr, err := git.GetRemoteAddress(ctx, path, m.RemoteName)
 		if err != nil {
@@ -134,7 +179,8 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 			return errors.New("Unexpected error")
 		}
 
-		if setting.LFS.StartServer {
+		// LFS objects are pushed over HTTP, which can't use the keypair of the mirror
+		if setting.LFS.StartServer && !m.UseSSH() {
 			log.Trace("SyncMirrors [repo: %-v]: syncing LFS objects...", m.Repo)
 
 			gitRepo, err := git.OpenRepository(ctx, path)
@@ -157,6 +203,7 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 			Remote:  m.RemoteName,
 			Force:   true,
 			Mirror:  true,
+			Env:     env,
 			Timeout: timeout,
 		}); err != nil {
 			log.Error("Error pushing %s mirror[%d] remote %s: %v", path, m.ID, m.RemoteName, err)
@@ -188,6 +235,56 @@ func runPushSync(ctx context.Context, m *repo_model.PushMirror) error {
 	return nil
 }
 
+// recordHostKey gets the host key of the SSH server of the mirror
+func recordHostKey(ctx context.Context, m *repo_model.PushMirror, hostPort string) error {
+	ctx, cancel := context.WithTimeout(ctx, hostKeyScanTimeout)
+	defer cancel()
+
+	key, err := ssh.ScanHostKey(ctx, hostPort)
+	if err != nil {
+		return fmt.Errorf("unable to get the host key of %s: %w", hostPort, err)
+	}
+	m.SetHostKey(key)
+	log.Info("Recorded host key %s of %s for push mirror[%d]", m.HostKeyFingerprint(), hostPort, m.ID)
+	return nil
+}
+
+// writeSSHFiles writes the private key of the mirror and a known_hosts file with its host key
+// to a temporary directory only readable by the current user
+func writeSSHFiles(m *repo_model.PushMirror, hostPort string) (string, error) {
+	hostKey, err := ssh.ParseHostKey(m.HostKey)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := m.GetPrivateKey()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "gitea-push-mirror-ssh-")
+	if err != nil {
+		return "", err
+	}
+	knownHosts := knownhosts.Line([]string{knownhosts.Normalize(hostPort)}, hostKey) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "known_hosts"), []byte(knownHosts), 0o600); err != nil {
+		_ = util.RemoveAll(dir)
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "id"), privateKey, 0o600); err != nil {
+		_ = util.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// sshCommand returns the ssh command which authenticates only with the given key, never prompts
+// and only accepts the host key of the given known_hosts file
+func sshCommand(keyFile, knownHostsFile string) string {
+	return "ssh -i " + util.ShellEscape(keyFile) +
+		" -o IdentitiesOnly=yes -o BatchMode=yes -o StrictHostKeyChecking=yes" +
+		" -o UserKnownHostsFile=" + util.ShellEscape(knownHostsFile) + " -o GlobalKnownHostsFile=/dev/null"
+}
+
 func pushAllLFSObjects(ctx context.Context, gitRepo *git.Repository, lfsClient lfs.Client) error {
 	contentStore := lfs.NewContentStore()
 
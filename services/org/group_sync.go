@@ -0,0 +1,115 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ParseGroupTeamMap parses a group team map of the form {"group": {"Organization": ["Team1", "Team2"]}}
+func ParseGroupTeamMap(groupTeamMap string) (map[string]map[string][]string, error) {
+	groupsToTeams := make(map[string]map[string][]string)
+	if groupTeamMap == "" {
+		return groupsToTeams, nil
+	}
+	if err := json.Unmarshal([]byte(groupTeamMap), &groupsToTeams); err != nil {
+		return nil, err
+	}
+	return groupsToTeams, nil
+}
+
+// GetMappedMemberships returns the organization teams the user has to be added to and removed from.
+// A team which is mapped by a group of the user is never removed even if another group maps it too.
+func GetMappedMemberships(groups []string, groupsToTeams map[string]map[string][]string) (map[string][]string, map[string][]string) {
+	membershipsToAdd := map[string][]string{}
+	membershipsToRemove := map[string][]string{}
+	for group, memberships := range groupsToTeams {
+		target := membershipsToRemove
+		if util.IsStringInSlice(group, groups) {
+			target = membershipsToAdd
+		}
+		for org, teams := range memberships {
+			for _, team := range teams {
+				if !util.IsStringInSlice(team, target[org]) {
+					target[org] = append(target[org], team)
+				}
+			}
+		}
+	}
+
+	for org, teams := range membershipsToRemove {
+		remaining := teams[:0]
+		for _, team := range teams {
+			if !util.IsStringInSlice(team, membershipsToAdd[org]) {
+				remaining = append(remaining, team)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(membershipsToRemove, org)
+		} else {
+			membershipsToRemove[org] = remaining
+		}
+	}
+	return membershipsToAdd, membershipsToRemove
+}
+
+// SyncGroupTeamMemberships adds the user to the teams of membershipsToAdd and removes it from the teams of
+// membershipsToRemove, both map organization names to team names. Organizations and teams must be created
+// before the group sync, missing ones are skipped. The looked up organizations and teams are stored in the
+// caches which may be shared by the syncs of several users.
+func SyncGroupTeamMemberships(user *user_model.User, membershipsToAdd, membershipsToRemove map[string][]string, orgCache map[string]*organization.Organization, teamCache map[string]*organization.Team) {
+	syncGroupTeamMemberships(user, membershipsToRemove, false, orgCache, teamCache)
+	syncGroupTeamMemberships(user, membershipsToAdd, true, orgCache, teamCache)
+}
+
+func syncGroupTeamMemberships(user *user_model.User, memberships map[string][]string, add bool, orgCache map[string]*organization.Organization, teamCache map[string]*organization.Team) {
+	for orgName, teamNames := range memberships {
+		org, ok := orgCache[orgName]
+		if !ok {
+			var err error
+			org, err = organization.GetOrgByName(orgName)
+			if err != nil {
+				log.Warn("Group sync: Could not find organisation %s: %v", orgName, err)
+				continue
+			}
+			orgCache[orgName] = org
+		}
+
+		for _, teamName := range teamNames {
+			team, ok := teamCache[orgName+teamName]
+			if !ok {
+				var err error
+				team, err = org.GetTeam(teamName)
+				if err != nil {
+					log.Warn("Group sync: Could not find team %s of organisation %s: %v", teamName, orgName, err)
+					continue
+				}
+				teamCache[orgName+teamName] = team
+			}
+
+			isMember, err := organization.IsTeamMember(db.DefaultContext, org.ID, team.ID, user.ID)
+			if err != nil {
+				log.Error("Group sync: Could not check team membership: %v", err)
+				continue
+			}
+			if add && !isMember {
+				log.Trace("Group sync: adding user [%s] to team [%s] of [%s]", user.Name, team.Name, org.Name)
+				if err := AddTeamMember(db.DefaultContext, org.AsUser(), team, user.ID); err != nil {
+					log.Error("Group sync: Could not add user to team: %v", err)
+				}
+			} else if !add && isMember {
+				log.Trace("Group sync: removing user [%s] from team [%s] of [%s]", user.Name, team.Name, org.Name)
+				if err := RemoveTeamMember(db.DefaultContext, org.AsUser(), team, user.ID); err != nil {
+					log.Error("Group sync: Could not remove user from team: %v", err)
+				}
+			}
+		}
+	}
+}
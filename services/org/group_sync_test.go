@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGroupTeamMap(t *testing.T) {
+	m, err := ParseGroupTeamMap("")
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	m, err = ParseGroupTeamMap(`{"dev": {"org1": ["team1", "team2"]}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]string{"dev": {"org1": {"team1", "team2"}}}, m)
+
+	_, err = ParseGroupTeamMap(`{"dev": ["team1"]}`)
+	assert.Error(t, err)
+}
+
+func TestGetMappedMemberships(t *testing.T) {
+	groupsToTeams := map[string]map[string][]string{
+		"dev":   {"org1": {"developers", "readers"}, "org2": {"developers"}},
+		"ops":   {"org1": {"operators", "readers"}},
+		"admin": {"org3": {"Owners"}},
+	}
+
+	add, remove := GetMappedMemberships([]string{"dev"}, groupsToTeams)
+	assert.Len(t, add, 2)
+	assert.ElementsMatch(t, []string{"developers", "readers"}, add["org1"])
+	assert.Equal(t, []string{"developers"}, add["org2"])
+	// readers is mapped by the group of the user and must not be removed
+	assert.Equal(t, map[string][]string{"org1": {"operators"}, "org3": {"Owners"}}, remove)
+
+	add, remove = GetMappedMemberships(nil, groupsToTeams)
+	assert.Empty(t, add)
+	assert.Len(t, remove, 3)
+	assert.ElementsMatch(t, []string{"developers", "readers", "operators"}, remove["org1"])
+}
@@ -40,6 +40,14 @@ func DeleteOrganization(org *organization.Organization) error {
 		return models.ErrUserOwnPackages{UID: org.ID}
 	}
 
+	if err := packages_model.DeleteCleanupRulesByOwner(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteCleanupRulesByOwner: %v", err)
+	}
+
+	if err := repo_model.DeletePushRuleByOwner(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeletePushRuleByOwner: %v", err)
+	}
+
 	if err := organization.DeleteOrganization(ctx, org); err != nil {
 		return fmt.Errorf("DeleteOrganization: %v", err)
 	}
@@ -0,0 +1,114 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/services/audit"
+)
+
+// recordTeamEvent records an audit event of the team, the remote address is taken from the request of ctx if there is one
+func recordTeamEvent(ctx context.Context, doer *user_model.User, action audit_model.Action, t *organization.Team, changes ...*audit_model.Change) {
+	org, err := organization.GetOrgByIDCtx(ctx, t.OrgID)
+	if err != nil {
+		log.Error("GetOrgByID: %v", err)
+		return
+	}
+	audit.Record(doer, audit.RemoteAddr(ctx), action, audit.TeamTarget(org, t), changes...)
+}
+
+// NewTeam creates a team of the organization
+func NewTeam(ctx context.Context, doer *user_model.User, t *organization.Team) error {
+	if err := models.NewTeam(t); err != nil {
+		return err
+	}
+
+	notification.NotifyNewTeam(doer, t)
+	recordTeamEvent(ctx, doer, audit_model.ActionTeamCreate, t)
+	return nil
+}
+
+// UpdateTeam updates the information and permissions of a team. The units of t are replaced if there are any.
+func UpdateTeam(ctx context.Context, doer *user_model.User, t *organization.Team, authChanged, includeAllChanged bool) error {
+	before, err := organization.GetTeamByIDCtx(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if err := before.GetUnits(); err != nil {
+		return err
+	}
+
+	if err := models.UpdateTeam(t, authChanged, includeAllChanged); err != nil {
+		return err
+	}
+
+	notification.NotifyUpdateTeam(doer, t)
+
+	t.Units = nil
+	if err := t.GetUnits(); err != nil {
+		log.Error("GetUnits: %v", err)
+	}
+	recordTeamEvent(ctx, doer, audit_model.ActionTeamEdit, t, audit.TeamChanges(before, t)...)
+	return nil
+}
+
+// DeleteTeam deletes a team of the organization
+func DeleteTeam(ctx context.Context, doer *user_model.User, t *organization.Team) error {
+	if err := models.DeleteTeam(t); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteTeam(doer, t)
+	recordTeamEvent(ctx, doer, audit_model.ActionTeamDelete, t)
+	return nil
+}
+
+// AddTeamMember adds a user to the team
+func AddTeamMember(ctx context.Context, doer *user_model.User, team *organization.Team, userID int64) error {
+	isMember, err := organization.IsTeamMember(db.DefaultContext, team.OrgID, team.ID, userID)
+	if err != nil || isMember {
+		return err
+	}
+
+	if err := models.AddTeamMember(team, userID); err != nil {
+		return err
+	}
+
+	member, err := user_model.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	notification.NotifyAddTeamMember(doer, team, member)
+	recordTeamEvent(ctx, doer, audit_model.ActionTeamMemberAdd, team, audit.MemberAdded(member))
+	return nil
+}
+
+// RemoveTeamMember removes a user from the team
+func RemoveTeamMember(ctx context.Context, doer *user_model.User, team *organization.Team, userID int64) error {
+	isMember, err := organization.IsTeamMember(db.DefaultContext, team.OrgID, team.ID, userID)
+	if err != nil || !isMember {
+		return err
+	}
+
+	if err := models.RemoveTeamMember(team, userID); err != nil {
+		return err
+	}
+
+	member, err := user_model.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	notification.NotifyRemoveTeamMember(doer, team, member)
+	recordTeamEvent(ctx, doer, audit_model.ActionTeamMemberRemove, team, audit.MemberRemoved(member))
+	return nil
+}
@@ -0,0 +1,371 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package alpine
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	alpine_model "code.gitea.io/gitea/models/packages/alpine"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	// IndexFilename is the name of the index file of a branch, repository and architecture
+	IndexFilename = "APKINDEX.tar.gz"
+)
+
+// GetOrCreateRepositoryVersion gets or creates the internal repository package version
+// which holds the generated repository files
+func GetOrCreateRepositoryVersion(ctx context.Context, ownerID int64) (*packages_model.PackageVersion, error) {
+	p := &packages_model.Package{
+		OwnerID:   ownerID,
+		Type:      packages_model.TypeAlpine,
+		Name:      alpine_module.RepositoryPackage,
+		LowerName: alpine_module.RepositoryPackage,
+	}
+	var err error
+	if p, err = packages_model.TryInsertPackage(ctx, p); err != nil {
+		if err != packages_model.ErrDuplicatePackage {
+			log.Error("Error inserting package: %v", err)
+			return nil, err
+		}
+	}
+
+	pv := &packages_model.PackageVersion{
+		PackageID:    p.ID,
+		CreatorID:    ownerID,
+		Version:      alpine_module.RepositoryVersion,
+		LowerVersion: alpine_module.RepositoryVersion,
+		IsInternal:   true,
+		MetadataJSON: "null",
+	}
+	if pv, err = packages_model.GetOrInsertVersion(ctx, pv); err != nil {
+		if err != packages_model.ErrDuplicatePackageVersion {
+			log.Error("Error inserting package version: %v", err)
+			return nil, err
+		}
+	}
+
+	return pv, nil
+}
+
+// GetOrCreateKeyPair gets or creates the RSA keys used to sign the repository files
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetUserSetting(ownerID, alpine_module.SettingKeyPrivate)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetUserSetting(ownerID, alpine_module.SettingKeyPublic)
+	if err != nil {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeypair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, alpine_module.SettingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, alpine_module.SettingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+// KeyFileName gets the name of the public key file. The client must store the key under this name
+// because apk looks up the key by the name embedded in the index signature.
+func KeyFileName(owner *user_model.User) string {
+	return fmt.Sprintf("%s@%s.rsa.pub", owner.LowerName, setting.Domain)
+}
+
+func generateKeypair() (string, string, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		return "", "", err
+	}
+
+	privPem := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	})
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	pubPem := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	})
+
+	return string(privPem), string(pubPem), nil
+}
+
+// BuildAllRepositoryFiles (re)builds all repository files for every available branch, repository and architecture
+func BuildAllRepositoryFiles(ownerID int64) error {
+	owner, err := user_model.GetUserByID(ownerID)
+	if err != nil {
+		return err
+	}
+
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode([]byte(priv))
+	if block == nil {
+		return errors.New("failed to decode private key pem")
+	}
+
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		pv, err := GetOrCreateRepositoryVersion(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+
+		// 1. Delete all existing repository files
+		pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, pf := range pfs {
+			if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeFile, pf.ID); err != nil {
+				return err
+			}
+			if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+				return err
+			}
+		}
+
+		// 2. (Re)Build repository files for existing packages
+		branches, err := alpine_model.GetBranches(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+		for _, branch := range branches {
+			repositories, err := alpine_model.GetRepositories(ctx, ownerID, branch)
+			if err != nil {
+				return err
+			}
+			for _, repository := range repositories {
+				architectures, err := alpine_model.GetArchitectures(ctx, ownerID, branch, repository)
+				if err != nil {
+					return err
+				}
+				for _, architecture := range architectures {
+					if err := buildPackagesIndex(ctx, owner, pv, privateKey, branch, repository, architecture); err != nil {
+						return fmt.Errorf("failed to build repository files [%s/%s/%s]: %w", branch, repository, architecture, err)
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// https://wiki.alpinelinux.org/wiki/Apk_spec#APKINDEX_Format
+func buildPackagesIndex(ctx context.Context, owner *user_model.User, repoVersion *packages_model.PackageVersion, privateKey *rsa.PrivateKey, branch, repository, architecture string) error {
+	pfds, err := alpine_model.SearchPackages(ctx, &alpine_model.PackageSearchOptions{
+		OwnerID:      owner.ID,
+		Branch:       branch,
+		Repository:   repository,
+		Architecture: architecture,
+	})
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[int64]*packages_model.PackageDescriptor)
+
+	var buf bytes.Buffer
+	for _, pfd := range pfds {
+		pd, has := cache[pfd.File.VersionID]
+		if !has {
+			pv, err := packages_model.GetVersionByID(ctx, pfd.File.VersionID)
+			if err != nil {
+				return err
+			}
+			pd, err = packages_model.GetPackageDescriptor(ctx, pv)
+			if err != nil {
+				return err
+			}
+			cache[pv.ID] = pd
+		}
+
+		var fm alpine_module.FileMetadata
+		if err := json.Unmarshal([]byte(pfd.Properties.GetByName(alpine_module.PropertyMetadata)), &fm); err != nil {
+			return err
+		}
+		vm := pd.Metadata.(*alpine_module.VersionMetadata)
+
+		fmt.Fprintf(&buf, "C:%s\n", fm.Checksum)
+		fmt.Fprintf(&buf, "P:%s\n", pd.Package.Name)
+		fmt.Fprintf(&buf, "V:%s\n", pd.Version.Version)
+		fmt.Fprintf(&buf, "A:%s\n", fm.Architecture)
+		fmt.Fprintf(&buf, "S:%d\n", pfd.Blob.Size)
+		fmt.Fprintf(&buf, "I:%d\n", fm.Size)
+		fmt.Fprintf(&buf, "T:%s\n", vm.Description)
+		fmt.Fprintf(&buf, "U:%s\n", vm.ProjectURL)
+		fmt.Fprintf(&buf, "L:%s\n", vm.License)
+		if fm.Origin != "" {
+			fmt.Fprintf(&buf, "o:%s\n", fm.Origin)
+		}
+		if vm.Maintainer != "" {
+			fmt.Fprintf(&buf, "m:%s\n", vm.Maintainer)
+		}
+		if fm.BuildDate != 0 {
+			fmt.Fprintf(&buf, "t:%d\n", fm.BuildDate)
+		}
+		if fm.CommitHash != "" {
+			fmt.Fprintf(&buf, "c:%s\n", fm.CommitHash)
+		}
+		if len(fm.Dependencies) > 0 {
+			fmt.Fprintf(&buf, "D:%s\n", strings.Join(fm.Dependencies, " "))
+		}
+		if len(fm.Provides) > 0 {
+			fmt.Fprintf(&buf, "p:%s\n", strings.Join(fm.Provides, " "))
+		}
+		if fm.InstallIf != "" {
+			fmt.Fprintf(&buf, "i:%s\n", fm.InstallIf)
+		}
+		fmt.Fprint(&buf, "\n")
+	}
+
+	unsignedIndexContent, err := createTarGz([]*tarEntry{{Name: "APKINDEX", Content: buf.Bytes()}}, true)
+	if err != nil {
+		return err
+	}
+
+	// the signature covers the compressed index stream and is prepended as its own stream
+	h := sha1.Sum(unsignedIndexContent)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA1, h[:])
+	if err != nil {
+		return err
+	}
+
+	// the signature stream must not contain the tar end-of-archive marker
+	signatureContent, err := createTarGz([]*tarEntry{{Name: ".SIGN.RSA." + KeyFileName(owner), Content: signature}}, false)
+	if err != nil {
+		return err
+	}
+
+	return addRepositoryFile(
+		ctx,
+		repoVersion,
+		IndexFilename,
+		fmt.Sprintf("%s|%s|%s", branch, repository, architecture),
+		append(signatureContent, unsignedIndexContent...),
+	)
+}
+
+type tarEntry struct {
+	Name    string
+	Content []byte
+}
+
+func createTarGz(entries []*tarEntry, withEndOfArchive bool) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: e.Name,
+			Mode: 0o600,
+			Size: int64(len(e.Content)),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(e.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if withEndOfArchive {
+		if err := tw.Close(); err != nil {
+			return nil, err
+		}
+	} else if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func addRepositoryFile(ctx context.Context, pv *packages_model.PackageVersion, filename, compositeKey string, data []byte) error {
+	hb, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(data), 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer hb.Close()
+
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := hb.Sums()
+
+	pb, exists, err := packages_model.GetOrInsertBlob(ctx, &packages_model.PackageBlob{
+		Size:       hb.Size(),
+		HashMD5:    fmt.Sprintf("%x", hashMD5),
+		HashSHA1:   fmt.Sprintf("%x", hashSHA1),
+		HashSHA256: fmt.Sprintf("%x", hashSHA256),
+		HashSHA512: fmt.Sprintf("%x", hashSHA512),
+	})
+	if err != nil {
+		log.Error("Error inserting package blob: %v", err)
+		return err
+	}
+	if !exists {
+		if err := packages_module.NewContentStore().Save(packages_module.BlobHash256Key(pb.HashSHA256), hb, hb.Size()); err != nil {
+			log.Error("Error saving package blob in content store: %v", err)
+			return err
+		}
+	}
+
+	_, err = packages_model.TryInsertFile(ctx, &packages_model.PackageFile{
+		VersionID:    pv.ID,
+		BlobID:       pb.ID,
+		Name:         filename,
+		LowerName:    strings.ToLower(filename),
+		CompositeKey: compositeKey,
+	})
+	return err
+}
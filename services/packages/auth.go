@@ -18,10 +18,13 @@ import (
 
 type packageClaims struct {
 	jwt.RegisteredClaims
-	UserID int64
+	UserID        int64
+	AccessTokenID int64 `json:",omitempty"`
 }
 
-func CreateAuthorizationToken(u *user_model.User) (string, error) {
+// CreateAuthorizationToken creates a token for the user. If the user authenticated with a personal access token,
+// its id is kept in the token to apply the same restrictions.
+func CreateAuthorizationToken(u *user_model.User, accessTokenID int64) (string, error) {
 	now := time.Now()
 
 	claims := packageClaims{
@@ -29,7 +32,8 @@ func CreateAuthorizationToken(u *user_model.User) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
-		UserID: u.ID,
+		UserID:        u.ID,
+		AccessTokenID: accessTokenID,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
@@ -41,10 +45,11 @@ func CreateAuthorizationToken(u *user_model.User) (string, error) {
 	return tokenString, nil
 }
 
-func ParseAuthorizationToken(req *http.Request) (int64, error) {
+// ParseAuthorizationToken returns the user id and the personal access token id of the token
+func ParseAuthorizationToken(req *http.Request) (int64, int64, error) {
 	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
 	if len(parts) != 2 {
-		return 0, fmt.Errorf("no token")
+		return 0, 0, fmt.Errorf("no token")
 	}
 
 	token, err := jwt.ParseWithClaims(parts[1], &packageClaims{}, func(t *jwt.Token) (interface{}, error) {
@@ -54,13 +59,13 @@ func ParseAuthorizationToken(req *http.Request) (int64, error) {
 		return []byte(setting.SecretKey), nil
 	})
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	c, ok := token.Claims.(*packageClaims)
 	if !token.Valid || !ok {
-		return 0, fmt.Errorf("invalid token claim")
+		return 0, 0, fmt.Errorf("invalid token claim")
 	}
 
-	return c.UserID, nil
+	return c.UserID, c.AccessTokenID, nil
 }
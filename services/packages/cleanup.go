@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// GetCleanupRuleCandidates gets all package versions which are removed if the cleanup rule is executed.
+// The newest KeepCount versions of every matching package are always kept. Of the remaining versions
+// only those older than RemoveDays and matching the version pattern are selected.
+func GetCleanupRuleCandidates(ctx context.Context, pcr *packages_model.PackageCleanupRule) ([]*packages_model.PackageVersion, error) {
+	if err := pcr.CompilePatterns(); err != nil {
+		return nil, err
+	}
+
+	ps, err := packages_model.GetPackagesByType(ctx, pcr.OwnerID, pcr.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	olderThan := timeutil.TimeStamp(time.Now().AddDate(0, 0, -pcr.RemoveDays).Unix())
+
+	candidates := make([]*packages_model.PackageVersion, 0, 10)
+	for _, p := range ps {
+		if !pcr.MatchesName(p.Name) {
+			continue
+		}
+
+		// the default order returns the newest versions first
+		pvs, _, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			PackageID:  p.ID,
+			IsInternal: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, pv := range pvs {
+			if i < pcr.KeepCount {
+				continue
+			}
+			if pcr.RemoveDays > 0 && pv.CreatedUnix > olderThan {
+				continue
+			}
+			if !pcr.MatchesVersion(pv.Version) {
+				continue
+			}
+			candidates = append(candidates, pv)
+		}
+	}
+
+	return candidates, nil
+}
+
+// ExecuteCleanupRules removes the package versions selected by every enabled cleanup rule.
+// A failing rule is logged and does not stop the execution of the other rules.
+func ExecuteCleanupRules(ctx context.Context) error {
+	pcrs, err := packages_model.GetEnabledCleanupRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	doer := user_model.NewGhostUser()
+
+	for _, pcr := range pcrs {
+		if err := executeCleanupRule(ctx, doer, pcr); err != nil {
+			log.Error("CleanupRule [%d]: %v", pcr.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func executeCleanupRule(ctx context.Context, doer *user_model.User, pcr *packages_model.PackageCleanupRule) error {
+	pvs, err := GetCleanupRuleCandidates(ctx, pcr)
+	if err != nil {
+		return fmt.Errorf("GetCleanupRuleCandidates failed: %w", err)
+	}
+
+	for _, pv := range pvs {
+		log.Debug("CleanupRule [%d]: Removing package version %d", pcr.ID, pv.ID)
+
+		if err := RemovePackageVersion(doer, pv); err != nil {
+			return fmt.Errorf("RemovePackageVersion failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	debian_model "code.gitea.io/gitea/models/packages/debian"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/clearsign"
+)
+
+// GetOrCreateRepositoryVersion gets or creates the internal repository package version
+// which holds the generated repository files
+func GetOrCreateRepositoryVersion(ctx context.Context, ownerID int64) (*packages_model.PackageVersion, error) {
+	p := &packages_model.Package{
+		OwnerID:   ownerID,
+		Type:      packages_model.TypeDebian,
+		Name:      debian_module.RepositoryPackage,
+		LowerName: debian_module.RepositoryPackage,
+	}
+	var err error
+	if p, err = packages_model.TryInsertPackage(ctx, p); err != nil {
+		if err != packages_model.ErrDuplicatePackage {
+			log.Error("Error inserting package: %v", err)
+			return nil, err
+		}
+	}
+
+	pv := &packages_model.PackageVersion{
+		PackageID:    p.ID,
+		CreatorID:    ownerID,
+		Version:      debian_module.RepositoryVersion,
+		LowerVersion: debian_module.RepositoryVersion,
+		IsInternal:   true,
+		MetadataJSON: "null",
+	}
+	if pv, err = packages_model.GetOrInsertVersion(ctx, pv); err != nil {
+		if err != packages_model.ErrDuplicatePackageVersion {
+			log.Error("Error inserting package version: %v", err)
+			return nil, err
+		}
+	}
+
+	return pv, nil
+}
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign the repository files.
+// If an instance-wide signing key is configured it is used for every owner.
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	if setting.Packages.DebianSigningKeyPath != "" {
+		return readInstanceKeyPair()
+	}
+
+	priv, err := user_model.GetUserSetting(ownerID, debian_module.SettingKeyPrivate)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetUserSetting(ownerID, debian_module.SettingKeyPublic)
+	if err != nil {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeypair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, debian_module.SettingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, debian_module.SettingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+func readInstanceKeyPair() (string, string, error) {
+	priv, err := os.ReadFile(setting.Packages.DebianSigningKeyPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	e, err := readEntity(string(priv))
+	if err != nil {
+		return "", "", err
+	}
+
+	var pub bytes.Buffer
+	if err := writeArmored(&pub, openpgp.PublicKeyType, e.Serialize); err != nil {
+		return "", "", err
+	}
+
+	return string(priv), pub.String(), nil
+}
+
+func generateKeypair() (string, string, error) {
+	e, err := openpgp.NewEntity(setting.AppName, "Debian Registry", "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var priv bytes.Buffer
+	if err := writeArmored(&priv, openpgp.PrivateKeyType, func(w io.Writer) error {
+		return e.SerializePrivate(w, nil)
+	}); err != nil {
+		return "", "", err
+	}
+
+	var pub bytes.Buffer
+	if err := writeArmored(&pub, openpgp.PublicKeyType, e.Serialize); err != nil {
+		return "", "", err
+	}
+
+	return priv.String(), pub.String(), nil
+}
+
+func writeArmored(w io.Writer, blockType string, serialize func(io.Writer) error) error {
+	aw, err := armor.Encode(w, blockType, nil)
+	if err != nil {
+		return err
+	}
+	if err := serialize(aw); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+func readEntity(armored string) (*openpgp.Entity, error) {
+	el, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	if len(el) == 0 || el[0].PrivateKey == nil {
+		return nil, fmt.Errorf("no private key found")
+	}
+	return el[0], nil
+}
+
+// BuildAllRepositoryFiles (re)builds all repository files for every available distribution, component and architecture
+func BuildAllRepositoryFiles(ownerID int64) error {
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return err
+	}
+
+	e, err := readEntity(priv)
+	if err != nil {
+		return err
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		pv, err := GetOrCreateRepositoryVersion(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+
+		// 1. Delete all existing repository files
+		pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, pf := range pfs {
+			if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeFile, pf.ID); err != nil {
+				return err
+			}
+			if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+				return err
+			}
+		}
+
+		// 2. (Re)Build repository files for existing packages
+		distributions, err := debian_model.GetDistributions(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+		for _, distribution := range distributions {
+			if err := buildRepositoryFiles(ctx, ownerID, pv, e, distribution); err != nil {
+				return fmt.Errorf("failed to build repository files [%s]: %w", distribution, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+type indexFile struct {
+	Path string
+	Data []byte
+}
+
+func buildRepositoryFiles(ctx context.Context, ownerID int64, repoVersion *packages_model.PackageVersion, e *openpgp.Entity, distribution string) error {
+	components, err := debian_model.GetComponents(ctx, ownerID, distribution)
+	if err != nil {
+		return err
+	}
+	architectures, err := debian_model.GetArchitectures(ctx, ownerID, distribution)
+	if err != nil {
+		return err
+	}
+
+	indexFiles := make([]*indexFile, 0, len(components)*len(architectures)*2)
+	for _, component := range components {
+		for _, architecture := range architectures {
+			files, err := buildPackagesIndices(ctx, ownerID, repoVersion, distribution, component, architecture)
+			if err != nil {
+				return err
+			}
+			indexFiles = append(indexFiles, files...)
+		}
+	}
+
+	return buildReleaseFiles(ctx, repoVersion, e, distribution, components, architectures, indexFiles)
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func buildPackagesIndices(ctx context.Context, ownerID int64, repoVersion *packages_model.PackageVersion, distribution, component, architecture string) ([]*indexFile, error) {
+	pfds, err := debian_model.SearchPackages(ctx, &debian_model.PackageSearchOptions{
+		OwnerID:      ownerID,
+		Distribution: distribution,
+		Component:    component,
+		Architecture: architecture,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i, pfd := range pfds {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		fmt.Fprintf(&buf, "%s\n", pfd.Properties.GetByName(debian_module.PropertyControl))
+		fmt.Fprintf(&buf, "Filename: pool/%s/%s/%s\n", distribution, component, pfd.File.Name)
+		fmt.Fprintf(&buf, "Size: %d\n", pfd.Blob.Size)
+		fmt.Fprintf(&buf, "MD5sum: %s\n", pfd.Blob.HashMD5)
+		fmt.Fprintf(&buf, "SHA1: %s\n", pfd.Blob.HashSHA1)
+		fmt.Fprintf(&buf, "SHA256: %s\n", pfd.Blob.HashSHA256)
+		fmt.Fprintf(&buf, "SHA512: %s\n", pfd.Blob.HashSHA512)
+	}
+
+	var gzbuf bytes.Buffer
+	zw := gzip.NewWriter(&gzbuf)
+	if _, err := zw.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", distribution, component, architecture)
+	files := []*indexFile{
+		{Path: "Packages", Data: buf.Bytes()},
+		{Path: "Packages.gz", Data: gzbuf.Bytes()},
+	}
+	for _, f := range files {
+		if err := addRepositoryFile(ctx, repoVersion, f.Path, key, f.Data); err != nil {
+			return nil, err
+		}
+		f.Path = fmt.Sprintf("%s/binary-%s/%s", component, architecture, f.Path)
+	}
+
+	return files, nil
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func buildReleaseFiles(ctx context.Context, repoVersion *packages_model.PackageVersion, e *openpgp.Entity, distribution string, components, architectures []string, indexFiles []*indexFile) error {
+	sort.Slice(indexFiles, func(i, j int) bool {
+		return indexFiles[i].Path < indexFiles[j].Path
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Origin: %s\n", setting.AppName)
+	fmt.Fprintf(&buf, "Label: %s\n", setting.AppName)
+	fmt.Fprintf(&buf, "Suite: %s\n", distribution)
+	fmt.Fprintf(&buf, "Codename: %s\n", distribution)
+	fmt.Fprintf(&buf, "Components: %s\n", strings.Join(components, " "))
+	fmt.Fprintf(&buf, "Architectures: %s\n", strings.Join(architectures, " "))
+	fmt.Fprintf(&buf, "Date: %s\n", time.Now().UTC().Format(time.RFC1123))
+	buf.WriteString("Acquire-By-Hash: no\n")
+
+	type fileSums struct {
+		Size                      int
+		MD5, SHA1, SHA256, SHA512 string
+	}
+	sums := make([]*fileSums, 0, len(indexFiles))
+	for _, f := range indexFiles {
+		h := packages_module.NewMultiHasher()
+		if _, err := h.Write(f.Data); err != nil {
+			return err
+		}
+		hashMD5, hashSHA1, hashSHA256, hashSHA512 := h.Sums()
+		sums = append(sums, &fileSums{
+			Size:   len(f.Data),
+			MD5:    fmt.Sprintf("%x", hashMD5),
+			SHA1:   fmt.Sprintf("%x", hashSHA1),
+			SHA256: fmt.Sprintf("%x", hashSHA256),
+			SHA512: fmt.Sprintf("%x", hashSHA512),
+		})
+	}
+
+	writeSums := func(name string, sum func(*fileSums) string) {
+		fmt.Fprintf(&buf, "%s:\n", name)
+		for i, f := range indexFiles {
+			fmt.Fprintf(&buf, " %s %d %s\n", sum(sums[i]), sums[i].Size, f.Path)
+		}
+	}
+	writeSums("MD5Sum", func(s *fileSums) string { return s.MD5 })
+	writeSums("SHA1", func(s *fileSums) string { return s.SHA1 })
+	writeSums("SHA256", func(s *fileSums) string { return s.SHA256 })
+	writeSums("SHA512", func(s *fileSums) string { return s.SHA512 })
+
+	release := buf.Bytes()
+
+	var inRelease bytes.Buffer
+	w, err := clearsign.Encode(&inRelease, e.PrivateKey, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(release); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	var releaseGPG bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&releaseGPG, e, bytes.NewReader(release), nil); err != nil {
+		return err
+	}
+
+	for name, data := range map[string][]byte{
+		"Release":     release,
+		"Release.gpg": releaseGPG.Bytes(),
+		"InRelease":   inRelease.Bytes(),
+	} {
+		if err := addRepositoryFile(ctx, repoVersion, name, distribution, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addRepositoryFile(ctx context.Context, pv *packages_model.PackageVersion, filename, compositeKey string, data []byte) error {
+	hb, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(data), 32*1024*1024)
+	if err != nil {
+		return err
+	}
+	defer hb.Close()
+
+	hashMD5, hashSHA1, hashSHA256, hashSHA512 := hb.Sums()
+
+	pb, exists, err := packages_model.GetOrInsertBlob(ctx, &packages_model.PackageBlob{
+		Size:       hb.Size(),
+		HashMD5:    fmt.Sprintf("%x", hashMD5),
+		HashSHA1:   fmt.Sprintf("%x", hashSHA1),
+		HashSHA256: fmt.Sprintf("%x", hashSHA256),
+		HashSHA512: fmt.Sprintf("%x", hashSHA512),
+	})
+	if err != nil {
+		log.Error("Error inserting package blob: %v", err)
+		return err
+	}
+	if !exists {
+		if err := packages_module.NewContentStore().Save(packages_module.BlobHash256Key(pb.HashSHA256), hb, hb.Size()); err != nil {
+			log.Error("Error saving package blob in content store: %v", err)
+			return err
+		}
+	}
+
+	_, err = packages_model.TryInsertFile(ctx, &packages_model.PackageFile{
+		VersionID:    pv.ID,
+		BlobID:       pb.ID,
+		Name:         filename,
+		LowerName:    strings.ToLower(filename),
+		CompositeKey: compositeKey,
+	})
+	return err
+}
@@ -13,12 +13,15 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	packages_module "code.gitea.io/gitea/modules/packages"
+	alpine_service "code.gitea.io/gitea/services/packages/alpine"
 	container_service "code.gitea.io/gitea/services/packages/container"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
 )
 
 // PackageInfo describes a package
@@ -53,7 +56,8 @@ type PackageFileCreationInfo struct {
 	OverwriteExisting bool
 }
 
-// CreatePackageAndAddFile creates a package with a file. If the same package exists already, ErrDuplicatePackageVersion is returned
+// CreatePackageAndAddFile creates a package with a file. If the same package exists already, ErrDuplicatePackageVersion is returned.
+// If the file exceeds the quota of the owner, ErrQuotaExceeded is returned.
 func CreatePackageAndAddFile(pvci *PackageCreationInfo, pfci *PackageFileCreationInfo) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
 	return createPackageAndAddFile(pvci, pfci, false)
 }
@@ -64,6 +68,10 @@ func CreatePackageOrAddFileToExisting(pvci *PackageCreationInfo, pfci *PackageFi
 }
 
 func createPackageAndAddFile(pvci *PackageCreationInfo, pfci *PackageFileCreationInfo, allowDuplicate bool) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+	if err := quota_model.CheckQuota(db.DefaultContext, pvci.Owner, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return nil, nil, err
@@ -95,6 +103,10 @@ func createPackageAndAddFile(pvci *PackageCreationInfo, pfci *PackageFileCreatio
 		return nil, nil, err
 	}
 
+	if err := buildRepositoryFiles(pvci.Owner.ID, pvci.PackageType); err != nil {
+		return nil, nil, err
+	}
+
 	if created {
 		pd, err := packages_model.GetPackageDescriptor(ctx, pv)
 		if err != nil {
@@ -125,6 +137,11 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 		}
 	}
 
+	if err := LinkToRepositoryFromURL(ctx, p, pvci.Owner, repositoryURLFromMetadata(pvci.Metadata)); err != nil {
+		log.Error("Error linking package to repository: %v", err)
+		return nil, false, err
+	}
+
 	metadataJSON, err := json.Marshal(pvci.Metadata)
 	if err != nil {
 		return nil, false, err
@@ -160,8 +177,13 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 	return pv, created, nil
 }
 
-// AddFileToExistingPackage adds a file to an existing package. If the package does not exist, ErrPackageNotExist is returned
+// AddFileToExistingPackage adds a file to an existing package. If the package does not exist, ErrPackageNotExist is returned.
+// If the file exceeds the quota of the owner, ErrQuotaExceeded is returned.
 func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+	if err := quota_model.CheckQuota(db.DefaultContext, pvi.Owner, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return nil, nil, err
@@ -193,6 +215,10 @@ func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (
 		return nil, nil, err
 	}
 
+	if err := buildRepositoryFiles(pvi.Owner.ID, pvi.PackageType); err != nil {
+		return nil, nil, err
+	}
+
 	return pv, pf, nil
 }
 
@@ -303,11 +329,73 @@ func RemovePackageVersion(doer *user_model.User, pv *packages_model.PackageVersi
 		return err
 	}
 
+	if err := buildRepositoryFiles(pd.Owner.ID, pd.Package.Type); err != nil {
+		return err
+	}
+
 	notification.NotifyPackageDelete(doer, pd)
 
 	return nil
 }
 
+// RemovePackageFileAndVersionIfUnreferenced deletes the package file and the package version if it has no more files
+func RemovePackageFileAndVersionIfUnreferenced(doer *user_model.User, pf *packages_model.PackageFile) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+	if err != nil {
+		return err
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		return err
+	}
+
+	if err := DeletePackageFile(ctx, pf); err != nil {
+		return err
+	}
+
+	has, err := packages_model.HasVersionFileReferences(ctx, pv.ID)
+	if err != nil {
+		return err
+	}
+	if !has {
+		if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
+			return err
+		}
+	}
+
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
+	if err := buildRepositoryFiles(pd.Owner.ID, pd.Package.Type); err != nil {
+		return err
+	}
+
+	if !has {
+		notification.NotifyPackageDelete(doer, pd)
+	}
+
+	return nil
+}
+
+// buildRepositoryFiles rebuilds the repository index files of package types which need them
+func buildRepositoryFiles(ownerID int64, packageType packages_model.Type) error {
+	switch packageType {
+	case packages_model.TypeAlpine:
+		return alpine_service.BuildAllRepositoryFiles(ownerID)
+	case packages_model.TypeDebian:
+		return debian_service.BuildAllRepositoryFiles(ownerID)
+	}
+	return nil
+}
+
 // DeletePackageVersionAndReferences deletes the package version and its properties and files
 func DeletePackageVersionAndReferences(ctx context.Context, pv *packages_model.PackageVersion) error {
 	if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeVersion, pv.ID); err != nil {
@@ -336,8 +424,13 @@ func DeletePackageFile(ctx context.Context, pf *packages_model.PackageFile) erro
 	return packages_model.DeleteFileByID(ctx, pf.ID)
 }
 
-// Cleanup removes expired package data
+// Cleanup removes expired package data and executes the cleanup rules of all owners
 func Cleanup(unused context.Context, olderThan time.Duration) error {
+	// the removal of expired data must not depend on the cleanup rules
+	if err := ExecuteCleanupRules(db.DefaultContext); err != nil {
+		log.Error("Unable to execute the package cleanup rules: %v", err)
+	}
+
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return err
@@ -0,0 +1,85 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	maven_module "code.gitea.io/gitea/modules/packages/maven"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// LinkToRepositoryFromURL links the package to the repository the url points to.
+// Packages which are linked already are left untouched. Only repositories of this
+// instance which belong to the package owner are considered.
+func LinkToRepositoryFromURL(ctx context.Context, p *packages_model.Package, owner *user_model.User, repositoryURL string) error {
+	if p.RepoID != 0 || repositoryURL == "" {
+		return nil
+	}
+
+	ownerName, repoName, ok := parseRepositoryURL(repositoryURL)
+	if !ok || !strings.EqualFold(ownerName, owner.Name) {
+		return nil
+	}
+
+	repo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, owner.Name, repoName)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := packages_model.SetRepositoryLink(ctx, p.ID, repo.ID); err != nil {
+		return err
+	}
+	p.RepoID = repo.ID
+
+	return nil
+}
+
+// repositoryURLFromMetadata extracts the source repository url from the package metadata
+func repositoryURLFromMetadata(metadata interface{}) string {
+	switch m := metadata.(type) {
+	case *maven_module.Metadata:
+		return m.RepositoryURL
+	case *npm_module.Metadata:
+		return m.RepositoryURL
+	case npm_module.Metadata:
+		return m.RepositoryURL
+	}
+	return ""
+}
+
+// parseRepositoryURL extracts the owner and repository name from an url which points to this instance.
+// Clone urls (git+https://host/owner/repo.git) and links to sub pages (https://host/owner/repo/src/branch/main) are supported.
+func parseRepositoryURL(repositoryURL string) (string, string, bool) {
+	s := strings.TrimPrefix(repositoryURL, "git+")
+	if i := strings.IndexAny(s, "?#"); i != -1 {
+		s = s[:i]
+	}
+
+	appURL := strings.ToLower(setting.AppURL)
+	if !strings.HasPrefix(strings.ToLower(s), appURL) {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(s[len(appURL):], "/", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+
+	ownerName := parts[0]
+	repoName := strings.TrimSuffix(parts[1], ".git")
+	if ownerName == "" || repoName == "" {
+		return "", "", false
+	}
+	return ownerName, repoName, true
+}
@@ -0,0 +1,249 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// prAutoMergeQueue represents a queue to handle pull requests which are scheduled to be merged automatically
+var prAutoMergeQueue queue.UniqueQueue
+
+// ScheduleAutoMerge schedules the pull request to be merged by the doer when all checks succeed.
+// If the pull request can be merged already nothing is scheduled and false is returned.
+func ScheduleAutoMerge(ctx context.Context, doer *user_model.User, perm *models.Permission, pr *models.PullRequest, style repo_model.MergeStyle, message string) (scheduled bool, err error) {
+	if err := checkReadyToAutoMerge(ctx, doer, perm, pr); err == nil {
+		return false, nil
+	} else if !isWaitingForChecks(err) {
+		return false, err
+	}
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return false, err
+	}
+	defer committer.Close()
+
+	if err := models.ScheduleAutoMerge(ctx, doer, pr.ID, style, message); err != nil {
+		return false, err
+	}
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return false, err
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return false, err
+	}
+
+	if _, err := models.CreateCommentCtx(ctx, &models.CreateCommentOptions{
+		Type:  models.CommentTypePRScheduledToAutoMerge,
+		Doer:  doer,
+		Repo:  pr.BaseRepo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return false, err
+	}
+
+	return true, committer.Commit()
+}
+
+// RemoveScheduledAutoMerge cancels the scheduled merge of the pull request
+func RemoveScheduledAutoMerge(doer *user_model.User, pr *models.PullRequest) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	exist, _, err := models.GetScheduledAutoMergeByPullID(ctx, pr.ID)
+	if err != nil {
+		return err
+	} else if !exist {
+		return models.ErrNotExist{ID: pr.ID}
+	}
+
+	if err := models.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil {
+		return err
+	}
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	if _, err := models.CreateCommentCtx(ctx, &models.CreateCommentOptions{
+		Type:  models.CommentTypePRUnScheduledToAutoMerge,
+		Doer:  doer,
+		Repo:  pr.BaseRepo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return err
+	}
+
+	return committer.Commit()
+}
+
+// checkReadyToAutoMerge checks if the pull request can be merged by the doer right now.
+// Unlike CheckPullMergable the branch protections are enforced for every user as an automatic merge is never forced.
+func checkReadyToAutoMerge(ctx context.Context, doer *user_model.User, perm *models.Permission, pr *models.PullRequest) error {
+	if err := CheckPullMergable(ctx, doer, perm, pr, MergeCheckTypeGeneral, false); err != nil {
+		return err
+	}
+	return CheckPullBranchProtections(ctx, pr, false)
+}
+
+// isWaitingForChecks tests if the error only indicates that the pull request is not ready to be merged yet
+func isWaitingForChecks(err error) bool {
+	if errors.Is(err, ErrUserNotAllowedToMerge) {
+		return false
+	}
+	return errors.Is(err, ErrIsChecking) ||
+		errors.Is(err, ErrNotMergableState) ||
+		errors.Is(err, ErrDependenciesLeft) ||
+		models.IsErrDisallowedToMerge(err)
+}
+
+// AddToAutoMergeQueue adds the pull request to the queue which checks if a scheduled merge can be performed
+func AddToAutoMergeQueue(pr *models.PullRequest) {
+	if err := prAutoMergeQueue.PushFunc(strconv.FormatInt(pr.ID, 10), func() error {
+		log.Trace("Adding PR ID: %d to the pull requests auto merge queue", pr.ID)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding prID %d to the pull requests auto merge queue: %v", pr.ID, err)
+	}
+}
+
+// StartPullRequestAutoMergeCheckByRepo queues all pull requests of the base repository which are scheduled to auto merge
+func StartPullRequestAutoMergeCheckByRepo(ctx context.Context, repo *repo_model.Repository) {
+	ids, err := models.GetScheduledAutoMergePullIDsByBaseRepo(ctx, repo.ID)
+	if err != nil {
+		log.Error("GetScheduledAutoMergePullIDsByBaseRepo[%d]: %v", repo.ID, err)
+		return
+	}
+
+	for _, id := range ids {
+		AddToAutoMergeQueue(&models.PullRequest{ID: id})
+	}
+}
+
+// handleAutoMerge handles the passed PR IDs and merges the PRs which are ready
+func handleAutoMerge(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		id, _ := strconv.ParseInt(datum.(string), 10, 64)
+
+		autoMergePR(id)
+	}
+	return nil
+}
+
+func autoMergePR(id int64) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("Auto merge PR[%d] from auto merge queue", id))
+	defer finished()
+
+	exist, scheduled, err := models.GetScheduledAutoMergeByPullID(ctx, id)
+	if err != nil {
+		log.Error("GetScheduledAutoMergeByPullID[%d]: %v", id, err)
+		return
+	} else if !exist {
+		return
+	}
+
+	pr, err := models.GetPullRequestByID(ctx, id)
+	if err != nil {
+		log.Error("GetPullRequestByID[%d]: %v", id, err)
+		return
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", id, err)
+		return
+	}
+
+	if err := scheduled.LoadDoer(ctx); err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			removeObsoleteAutoMerge(ctx, pr)
+		} else {
+			log.Error("LoadDoer[%d]: %v", id, err)
+		}
+		return
+	}
+
+	perm, err := models.GetUserRepoPermission(ctx, pr.BaseRepo, scheduled.Doer)
+	if err != nil {
+		log.Error("GetUserRepoPermission[%d]: %v", id, err)
+		return
+	}
+
+	if err := checkReadyToAutoMerge(ctx, scheduled.Doer, &perm, pr); err != nil {
+		if isWaitingForChecks(err) {
+			log.Trace("PR[%d] is not ready to be merged automatically: %v", id, err)
+		} else if errors.Is(err, ErrUserNotAllowedToMerge) || errors.Is(err, ErrIsClosed) || errors.Is(err, ErrHasMerged) {
+			removeObsoleteAutoMerge(ctx, pr)
+		} else {
+			log.Error("checkReadyToAutoMerge[%d]: %v", id, err)
+		}
+		return
+	}
+
+	// protected branches with a merge queue only accept merges through the queue
+	if isQueueEnabled, err := IsMergeQueueEnabled(ctx, pr); err != nil {
+		log.Error("IsMergeQueueEnabled[%d]: %v", id, err)
+		return
+	} else if isQueueEnabled {
+		if err := AddToMergeQueue(ctx, scheduled.Doer, &perm, pr, scheduled.MergeStyle, scheduled.Message); err != nil && !models.IsErrAlreadyInMergeQueue(err) {
+			log.Error("AddToMergeQueue[%d]: %v", id, err)
+			return
+		}
+		removeObsoleteAutoMerge(ctx, pr)
+		return
+	}
+
+	baseGitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", pr.BaseRepo.RepoPath(), err)
+		return
+	}
+	defer baseGitRepo.Close()
+
+	if err := Merge(ctx, pr, scheduled.Doer, baseGitRepo, scheduled.MergeStyle, "", scheduled.Message); err != nil {
+		log.Error("Merge[%d]: %v", id, err)
+		return
+	}
+
+	log.Trace("Pull request merged automatically: %d", pr.ID)
+}
+
+// removeObsoleteAutoMerge removes a scheduled merge which can't be performed anymore
+func removeObsoleteAutoMerge(ctx context.Context, pr *models.PullRequest) {
+	log.Trace("Removing obsolete scheduled merge of PR[%d]", pr.ID)
+
+	if err := models.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil {
+		log.Error("DeleteScheduledAutoMerge[%d]: %v", pr.ID, err)
+	}
+}
+
+func initAutoMergeQueue() error {
+	prAutoMergeQueue = queue.CreateUniqueQueue("pr_auto_merge", handleAutoMerge, "")
+	if prAutoMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_auto_merge Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(prAutoMergeQueue.Run)
+	return nil
+}
@@ -58,8 +58,22 @@ func AddToTaskQueue(pr *models.PullRequest) {
 	}
 }
 
+// MergeCheckType indicates the kind of merge the checks are performed for
+type MergeCheckType int
+
+const (
+	// MergeCheckTypeGeneral checks a direct merge like "merge", "rebase" or "squash"
+	MergeCheckTypeGeneral MergeCheckType = iota
+	// MergeCheckTypeManually checks marking a pull request as manually merged
+	MergeCheckTypeManually
+	// MergeCheckTypeAuto checks scheduling a merge which is performed when all checks succeed
+	MergeCheckTypeAuto
+	// MergeCheckTypeQueue checks adding a pull request to the merge queue which runs the status checks itself
+	MergeCheckTypeQueue
+)
+
 // CheckPullMergable check if the pull mergable based on all conditions (branch protection, merge options, ...)
-func CheckPullMergable(ctx context.Context, doer *user_model.User, perm *models.Permission, pr *models.PullRequest, manuallMerge, force bool) error {
+func CheckPullMergable(ctx context.Context, doer *user_model.User, perm *models.Permission, pr *models.PullRequest, mergeCheckType MergeCheckType, force bool) error {
 	if pr.HasMerged {
 		return ErrHasMerged
 	}
@@ -76,7 +90,7 @@ func CheckPullMergable(ctx context.Context, doer *user_model.User, perm *models.
 		return ErrUserNotAllowedToMerge
 	}
 
-	if manuallMerge {
+	if mergeCheckType == MergeCheckTypeManually {
 		// don't check rules to "auto merge", doer is going to mark this pull as merged manually
 		return nil
 	}
@@ -85,6 +99,12 @@ func CheckPullMergable(ctx context.Context, doer *user_model.User, perm *models.
 		return ErrIsWorkInProgress
 	}
 
+	if mergeCheckType == MergeCheckTypeAuto {
+		// status checks, reviews, conflicts and dependencies are checked again when the scheduled merge is performed
+		_, err := isSignedIfRequired(ctx, pr, doer)
+		return err
+	}
+
 	if !pr.CanAutoMerge() {
 		return ErrNotMergableState
 	}
@@ -93,7 +113,13 @@ func CheckPullMergable(ctx context.Context, doer *user_model.User, perm *models.
 		return ErrIsChecking
 	}
 
-	if err := CheckPullBranchProtections(ctx, pr, false); err != nil {
+	var err error
+	if mergeCheckType == MergeCheckTypeQueue {
+		err = checkPullBranchProtections(ctx, pr, false, nil)
+	} else {
+		err = CheckPullBranchProtections(ctx, pr, false)
+	}
+	if err != nil {
 		if models.IsErrDisallowedToMerge(err) {
 			if force {
 				if isRepoAdmin, err2 := models.IsUserRepoAdmin(pr.BaseRepo, doer); err2 != nil {
@@ -340,6 +366,11 @@ func testPR(id int64) {
 		return
 	}
 	checkAndUpdateStatus(pr)
+
+	// the conflict check may have been the last thing a scheduled merge waited for
+	if pr.Status == models.PullRequestStatusMergeable {
+		AddToAutoMergeQueue(pr)
+	}
 }
 
 // CheckPrsForBaseBranch check all pulls with bseBrannch
@@ -356,6 +387,33 @@ func CheckPrsForBaseBranch(baseRepo *repo_model.Repository, baseBranchName strin
 	return nil
 }
 
+// CheckPrsForProtectedBranchRule checks all pulls whose base branch is protected by the rule
+func CheckPrsForProtectedBranchRule(ctx context.Context, baseRepo *repo_model.Repository, rule *models.ProtectedBranch) error {
+	if !rule.IsGlob() {
+		return CheckPrsForBaseBranch(baseRepo, rule.BranchName)
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, baseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	branches, _, err := gitRepo.GetBranchNames(0, 0)
+	if err != nil {
+		return err
+	}
+	for _, branch := range branches {
+		if !rule.Match(branch) {
+			continue
+		}
+		if err := CheckPrsForBaseBranch(baseRepo, branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Init runs the task queue to test all the checking status pull requests
 func Init() error {
 	prPatchCheckerQueue = queue.CreateUniqueQueue("pr_patch_checker", handle, "")
@@ -366,5 +424,8 @@ func Init() error {
 
 	go graceful.GetManager().RunWithShutdownFns(prPatchCheckerQueue.Run)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
-	return nil
+	if err := initAutoMergeQueue(); err != nil {
+		return err
+	}
+	return initMergeQueue()
 }
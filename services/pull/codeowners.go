@@ -0,0 +1,307 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/codeowners"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// maxCodeOwnersFileSize is the maximum size of a CODEOWNERS file which gets parsed
+const maxCodeOwnersFileSize = 3 * 1024 * 1024
+
+// codeOwner is a resolved owner entry of a CODEOWNERS file
+type codeOwner struct {
+	User *user_model.User
+	Team *organization.Team
+}
+
+// GetCodeOwners reads the CODEOWNERS file of the branch. nil is returned if the branch contains no such file.
+func GetCodeOwners(gitRepo *git.Repository, branch string) (*codeowners.File, error) {
+	commit, err := gitRepo.GetBranchCommit(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range codeowners.Paths {
+		blob, err := commit.GetBlobByPath(path)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		rc, err := blob.DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		f, warnings, err := codeowners.Parse(io.LimitReader(rc, maxCodeOwnersFileSize))
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range warnings {
+			log.Trace("%s on branch %s of %s: %s", path, branch, gitRepo.Path, warning)
+		}
+		return f, nil
+	}
+	return nil, nil
+}
+
+// resolveCodeOwners maps the owner names of a CODEOWNERS file to users and teams of the repository owner.
+// Owners which can't be resolved are ignored. Resolved owners are cached in the passed map.
+func resolveCodeOwners(ctx context.Context, repo *repo_model.Repository, names []string, cache map[string]*codeOwner) []*codeOwner {
+	owners := make([]*codeOwner, 0, len(names))
+	for _, name := range names {
+		owner, ok := cache[name]
+		if !ok {
+			owner = resolveCodeOwner(ctx, repo, name)
+			cache[name] = owner
+		}
+		if owner != nil {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+func resolveCodeOwner(ctx context.Context, repo *repo_model.Repository, name string) *codeOwner {
+	if !strings.HasPrefix(name, "@") {
+		u, err := user_model.GetUserByEmailContext(ctx, name)
+		if err != nil {
+			log.Trace("Unable to resolve code owner %s of %-v: %v", name, repo, err)
+			return nil
+		}
+		return &codeOwner{User: u}
+	}
+
+	name = name[1:]
+	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+		orgName, teamName := parts[0], parts[1]
+		if err := repo.GetOwner(ctx); err != nil {
+			log.Error("GetOwner: %v", err)
+			return nil
+		}
+		if !repo.Owner.IsOrganization() || !strings.EqualFold(orgName, repo.Owner.Name) {
+			log.Trace("Code owner team %s does not belong to the owner of %-v", name, repo)
+			return nil
+		}
+		t, err := organization.GetTeam(repo.OwnerID, teamName)
+		if err != nil {
+			log.Trace("Unable to resolve code owner team %s of %-v: %v", name, repo, err)
+			return nil
+		}
+		return &codeOwner{Team: t}
+	}
+
+	u, err := user_model.GetUserByNameCtx(ctx, name)
+	if err != nil {
+		log.Trace("Unable to resolve code owner %s of %-v: %v", name, repo, err)
+		return nil
+	}
+	return &codeOwner{User: u}
+}
+
+// getPullChangedFiles returns the files changed by the pull request.
+// The merge base is computed if it was not stored yet, the changes must never be skipped.
+func getPullChangedFiles(gitRepo *git.Repository, pr *models.PullRequest) ([]string, error) {
+	mergeBase := pr.MergeBase
+	if mergeBase == "" {
+		var err error
+		mergeBase, _, err = gitRepo.GetMergeBase("", git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+		if err != nil {
+			return nil, fmt.Errorf("GetMergeBase: %v", err)
+		}
+	}
+	return git.GetAffectedFiles(gitRepo, mergeBase, pr.GetGitRefName(), nil)
+}
+
+// RequestCodeOwnerReviews requests reviews from the code owners of the files changed by the pull request.
+// The review requests are made on behalf of the poster of the pull request.
+func RequestCodeOwnerReviews(ctx context.Context, pr *models.PullRequest) error {
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
+	}
+	if pr.Issue.IsClosed {
+		return nil
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.Issue.LoadPoster(); err != nil {
+		return fmt.Errorf("LoadPoster: %v", err)
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	file, err := GetCodeOwners(gitRepo, pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("GetCodeOwners: %v", err)
+	} else if file == nil {
+		return nil
+	}
+
+	files, err := getPullChangedFiles(gitRepo, pr)
+	if err != nil {
+		return fmt.Errorf("getPullChangedFiles: %v", err)
+	}
+
+	cache := make(map[string]*codeOwner)
+	users := make(map[int64]*user_model.User)
+	teams := make(map[int64]*organization.Team)
+	for _, path := range files {
+		for _, owner := range resolveCodeOwners(ctx, pr.BaseRepo, file.Owners(path), cache) {
+			if owner.User != nil {
+				users[owner.User.ID] = owner.User
+			} else {
+				teams[owner.Team.ID] = owner.Team
+			}
+		}
+	}
+
+	doer := pr.Issue.Poster
+	for _, u := range users {
+		if u.ID == doer.ID || !u.IsActive || u.ProhibitLogin {
+			continue
+		}
+
+		// an existing review of the user must not be replaced by a new request
+		review, err := models.GetReviewByIssueIDAndUserID(pr.IssueID, u.ID)
+		if err != nil && !models.IsErrReviewNotExist(err) {
+			return err
+		} else if review != nil {
+			continue
+		}
+
+		perm, err := models.GetUserRepoPermission(ctx, pr.BaseRepo, u)
+		if err != nil {
+			return err
+		}
+		if !perm.CanRead(unit.TypePullRequests) {
+			continue
+		}
+
+		if _, err := issue_service.ReviewRequest(pr.Issue, doer, u, true); err != nil {
+			return fmt.Errorf("ReviewRequest: %v", err)
+		}
+	}
+
+	for _, t := range teams {
+		if pr.BaseRepo.IsPrivate && !organization.HasTeamRepo(ctx, t.OrgID, t.ID, pr.BaseRepo.ID) {
+			continue
+		}
+
+		if _, err := issue_service.TeamReviewRequest(pr.Issue, doer, t, true); err != nil {
+			return fmt.Errorf("TeamReviewRequest: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// GetFilesMissingCodeOwnerApproval returns the files changed by the pull request which are not approved by one of their code owners
+func GetFilesMissingCodeOwnerApproval(ctx context.Context, pr *models.PullRequest) ([]string, error) {
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return nil, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return nil, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.RequireCodeOwnerReview {
+		return nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	file, err := GetCodeOwners(gitRepo, pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetCodeOwners: %v", err)
+	} else if file == nil {
+		return nil, nil
+	}
+
+	files, err := getPullChangedFiles(gitRepo, pr)
+	if err != nil {
+		return nil, fmt.Errorf("getPullChangedFiles: %v", err)
+	}
+
+	reviews, err := models.FindReviews(models.FindReviewOptions{
+		Type:    models.ReviewTypeApprove,
+		IssueID: pr.IssueID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	approverIDs := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Dismissed || (review.Stale && pr.ProtectedBranch.DismissStaleApprovals) {
+			continue
+		}
+		approverIDs = append(approverIDs, review.ReviewerID)
+	}
+
+	cache := make(map[string]*codeOwner)
+	missing := make([]string, 0, 5)
+	for _, path := range files {
+		owners := resolveCodeOwners(ctx, pr.BaseRepo, file.Owners(path), cache)
+		if len(owners) == 0 {
+			continue
+		}
+
+		approved, err := isApprovedByCodeOwner(ctx, owners, approverIDs)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			missing = append(missing, path)
+		}
+	}
+	return missing, nil
+}
+
+func isApprovedByCodeOwner(ctx context.Context, owners []*codeOwner, approverIDs []int64) (bool, error) {
+	for _, approverID := range approverIDs {
+		for _, owner := range owners {
+			if owner.User != nil {
+				if owner.User.ID == approverID {
+					return true, nil
+				}
+				continue
+			}
+
+			isMember, err := organization.IsTeamMember(ctx, owner.Team.OrgID, owner.Team.ID, approverID)
+			if err != nil {
+				return false, err
+			}
+			if isMember {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
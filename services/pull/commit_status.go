@@ -97,6 +97,16 @@ func IsPullCommitStatusPass(ctx context.Context, pr *models.PullRequest) (bool,
 	return state.IsSuccess(), nil
 }
 
+// getCommitStatusState returns the state of the required status checks of a commit
+func getCommitStatusState(ctx context.Context, repoID int64, sha string, requiredContexts []string) (structs.CommitStatusState, error) {
+	commitStatuses, _, err := models.GetLatestCommitStatusCtx(ctx, repoID, sha, db.ListOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "GetLatestCommitStatus")
+	}
+
+	return MergeRequiredContextsCommitStatus(commitStatuses, requiredContexts), nil
+}
+
 // GetPullRequestCommitStatusState returns pull request merged commit status state
 func GetPullRequestCommitStatusState(ctx context.Context, pr *models.PullRequest) (structs.CommitStatusState, error) {
 	// Ensure HeadRepo is loaded
@@ -132,10 +142,5 @@ func GetPullRequestCommitStatusState(ctx context.Context, pr *models.PullRequest
 		return "", errors.Wrap(err, "LoadBaseRepo")
 	}
 
-	commitStatuses, _, err := models.GetLatestCommitStatusCtx(ctx, pr.BaseRepo.ID, sha, db.ListOptions{})
-	if err != nil {
-		return "", errors.Wrap(err, "GetLatestCommitStatus")
-	}
-
-	return MergeRequiredContextsCommitStatus(commitStatuses, pr.ProtectedBranch.StatusCheckContexts), nil
+	return getCommitStatusState(ctx, pr.BaseRepo.ID, sha, pr.ProtectedBranch.StatusCheckContexts)
 }
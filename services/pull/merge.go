@@ -68,6 +68,11 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 	pr.Merger = doer
 	pr.MergerID = doer.ID
 
+	return afterMerge(ctx, pr, doer)
+}
+
+// afterMerge marks the pull request as merged and resolves the issues it references
+func afterMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User) (err error) {
 	if _, err := pr.SetMerged(); err != nil {
 		log.Error("setMerged [%d]: %v", pr.ID, err)
 	}
@@ -118,6 +123,12 @@ func Merge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, b
 
 // rawMerge perform the merge operation without changing any pull information in database
 func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) (string, error) {
+	return rawMergeOnto(ctx, pr, doer, mergeStyle, expectedHeadCommitID, message, "", git.BranchPrefix+pr.BaseBranch)
+}
+
+// rawMergeOnto merges the pull request onto the commit ontoCommitID instead of the head of the base branch
+// and pushes the result to targetRef. An empty ontoCommitID uses the head of the base branch.
+func rawMergeOnto(ctx context.Context, pr *models.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message, ontoCommitID, targetRef string) (string, error) {
 	err := git.LoadGitVersion()
 	if err != nil {
 		log.Error("git.LoadGitVersion: %v", err)
@@ -140,6 +151,14 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 	trackingBranch := "tracking"
 	stagingBranch := "staging"
 
+	if ontoCommitID != "" {
+		// the objects of the base repository are available through the alternates of the temporary repository
+		if err := git.NewCommand(ctx, "update-ref", git.BranchPrefix+baseBranch, ontoCommitID).Run(&git.RunOpts{Dir: tmpBasePath}); err != nil {
+			log.Error("update-ref[%s] %s -> %s: %v", tmpBasePath, baseBranch, ontoCommitID, err)
+			return "", fmt.Errorf("Unable to reset base branch to %s: %v", ontoCommitID, err)
+		}
+	}
+
 	if expectedHeadCommitID != "" {
 		trackingCommitID, _, err := git.NewCommand(ctx, "show-ref", "--hash", git.BranchPrefix+trackingBranch).RunStdString(&git.RunOpts{Dir: tmpBasePath})
 		if err != nil {
@@ -302,6 +321,12 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 			log.Error("Unable to make final commit: %v", err)
 			return "", err
 		}
+	case repo_model.MergeStyleFastForwardOnly:
+		cmd := git.NewCommand(ctx, "merge", "--ff-only", trackingBranch)
+		if err := runMergeCommand(pr, mergeStyle, cmd, tmpBasePath); err != nil {
+			log.Error("Unable to fast-forward base to tracking: %v", err)
+			return "", err
+		}
 	case repo_model.MergeStyleRebase:
 		fallthrough
 	case repo_model.MergeStyleRebaseUpdate:
@@ -499,8 +524,11 @@ func rawMerge(ctx context.Context, pr *models.PullRequest, doer *user_model.User
 	if mergeStyle == repo_model.MergeStyleRebaseUpdate {
 		// force push the rebase result to head branch
 		pushCmd = git.NewCommand(ctx, "push", "-f", "head_repo", stagingBranch+":"+git.BranchPrefix+pr.HeadBranch)
+	} else if targetRef != git.BranchPrefix+pr.BaseBranch {
+		// temporary refs like the ones of the merge queue are rebuilt and therefore force pushed
+		pushCmd = git.NewCommand(ctx, "push", "-f", "origin", baseBranch+":"+targetRef)
 	} else {
-		pushCmd = git.NewCommand(ctx, "push", "origin", baseBranch+":"+git.BranchPrefix+pr.BaseBranch)
+		pushCmd = git.NewCommand(ctx, "push", "origin", baseBranch+":"+targetRef)
 	}
 
 	// Push back to upstream.
@@ -586,6 +614,14 @@ func runMergeCommand(pr *models.PullRequest, mergeStyle repo_model.MergeStyle, c
 				StdErr: errbuf.String(),
 				Err:    err,
 			}
+		} else if mergeStyle == repo_model.MergeStyleFastForwardOnly && (strings.Contains(errbuf.String(), "Not possible to fast-forward") ||
+			strings.Contains(errbuf.String(), "Diverging branches can't be fast-forwarded")) {
+			log.Debug("MergeDivergingFastForwardOnly [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
+			return models.ErrMergeDivergingFastForwardOnly{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
 		}
 		log.Error("git merge [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
 		return fmt.Errorf("git merge [%s:%s -> %s:%s]: %v\n%s\n%s", pr.HeadRepo.FullName(), pr.HeadBranch, pr.BaseRepo.FullName(), pr.BaseBranch, err, outbuf.String(), errbuf.String())
@@ -664,6 +700,32 @@ func IsUserAllowedToMerge(pr *models.PullRequest, p models.Permission, user *use
 
 // CheckPullBranchProtections checks whether the PR is ready to be merged (reviews and status checks)
 func CheckPullBranchProtections(ctx context.Context, pr *models.PullRequest, skipProtectedFilesCheck bool) (err error) {
+	return checkPullBranchProtections(ctx, pr, skipProtectedFilesCheck, func() (bool, error) {
+		return IsPullCommitStatusPass(ctx, pr)
+	})
+}
+
+// CheckPullBranchProtectionsForPush checks whether the push of newCommitID to the base branch is allowed to merge the PR.
+// If newCommitID is the merge commit tested by the merge queue, its status checks are used instead of the ones of the PR head.
+func CheckPullBranchProtectionsForPush(ctx context.Context, pr *models.PullRequest, newCommitID string, skipProtectedFilesCheck bool) error {
+	exist, entry, err := models.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+	if !exist || entry.CommitID != newCommitID {
+		return CheckPullBranchProtections(ctx, pr, skipProtectedFilesCheck)
+	}
+	return checkPullBranchProtections(ctx, pr, skipProtectedFilesCheck, func() (bool, error) {
+		state, err := getCommitStatusState(ctx, pr.BaseRepoID, newCommitID, pr.ProtectedBranch.StatusCheckContexts)
+		if err != nil {
+			return false, err
+		}
+		return state.IsSuccess(), nil
+	})
+}
+
+// checkPullBranchProtections checks the branch protections of the PR. The status checks are skipped if isStatusPass is nil.
+func checkPullBranchProtections(ctx context.Context, pr *models.PullRequest, skipProtectedFilesCheck bool, isStatusPass func() (bool, error)) (err error) {
 	if err = pr.LoadBaseRepoCtx(ctx); err != nil {
 		return fmt.Errorf("LoadBaseRepo: %v", err)
 	}
@@ -675,13 +737,15 @@ func CheckPullBranchProtections(ctx context.Context, pr *models.PullRequest, ski
 		return nil
 	}
 
-	isPass, err := IsPullCommitStatusPass(ctx, pr)
-	if err != nil {
-		return err
-	}
-	if !isPass {
-		return models.ErrDisallowedToMerge{
-			Reason: "Not all required status checks successful",
+	if isStatusPass != nil {
+		isPass, err := isStatusPass()
+		if err != nil {
+			return err
+		}
+		if !isPass {
+			return models.ErrDisallowedToMerge{
+				Reason: "Not all required status checks successful",
+			}
 		}
 	}
 
@@ -707,6 +771,18 @@ func CheckPullBranchProtections(ctx context.Context, pr *models.PullRequest, ski
 		}
 	}
 
+	if pr.ProtectedBranch.RequireCodeOwnerReview {
+		missing, err := GetFilesMissingCodeOwnerApproval(ctx, pr)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return models.ErrDisallowedToMerge{
+				Reason: "Not all changed files are approved by their code owners",
+			}
+		}
+	}
+
 	if skipProtectedFilesCheck {
 		return nil
 	}
@@ -0,0 +1,408 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueBranchPrefix is the prefix of the branches the merge queue pushes the merge commits to
+const MergeQueueBranchPrefix = "gitea-mq/"
+
+// mergeQueue represents a queue to handle the base branches whose merge queue needs to be processed
+var mergeQueue queue.UniqueQueue
+
+// IsMergeQueueEnabled returns true if the pull request gets merged through the merge queue of its base branch
+func IsMergeQueueEnabled(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	if err := pr.LoadProtectedBranchCtx(ctx); err != nil {
+		return false, err
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.IsMergeQueueEnabled(), nil
+}
+
+// mergeQueueBranchName returns the name of the branch the merge commit of the pull request is tested on
+func mergeQueueBranchName(pr *models.PullRequest) string {
+	return fmt.Sprintf("%s%s/%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index)
+}
+
+// AddToMergeQueue adds the pull request to the merge queue of its base branch.
+// It gets merged with the given style when the required status checks succeed on its merge commit.
+func AddToMergeQueue(ctx context.Context, doer *user_model.User, perm *models.Permission, pr *models.PullRequest, style repo_model.MergeStyle, message string) error {
+	if err := CheckPullMergable(ctx, doer, perm, pr, MergeCheckTypeQueue, false); err != nil {
+		return err
+	}
+	// CheckPullMergable ignores violated branch protections, but they would reject the push of the queue
+	if err := checkPullBranchProtections(ctx, pr, false, nil); err != nil {
+		return err
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnitCtx(ctx, unit.TypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) || style == repo_model.MergeStyleManuallyMerged {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: style}
+	}
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := models.AddToMergeQueue(ctx, doer, pr, style, message); err != nil {
+		return err
+	}
+
+	if _, err := models.CreateCommentCtx(ctx, &models.CreateCommentOptions{
+		Type:  models.CommentTypePRAddedToMergeQueue,
+		Doer:  doer,
+		Repo:  pr.BaseRepo,
+		Issue: pr.Issue,
+	}); err != nil {
+		return err
+	}
+
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
+	AddToMergeQueueCheck(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue. The reason is shown in the comment of the removal.
+func RemoveFromMergeQueue(doer *user_model.User, pr *models.PullRequest, reason string) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	exist, entry, err := models.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		return err
+	} else if !exist {
+		return models.ErrNotExist{ID: pr.ID}
+	}
+
+	if err := models.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+		return err
+	}
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return err
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	if _, err := models.CreateCommentCtx(ctx, &models.CreateCommentOptions{
+		Type:    models.CommentTypePRRemovedFromMergeQueue,
+		Doer:    doer,
+		Repo:    pr.BaseRepo,
+		Issue:   pr.Issue,
+		Content: reason,
+	}); err != nil {
+		return err
+	}
+
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
+	deleteMergeQueueBranch(pr)
+
+	// the following entries have to be rebuilt without this pull request
+	AddToMergeQueueCheck(entry.RepoID, entry.BaseBranch)
+	return nil
+}
+
+// GetMergeQueuePosition returns the 1-based position of the pull request in the merge queue or 0 if it is not queued
+func GetMergeQueuePosition(ctx context.Context, pr *models.PullRequest) (int, error) {
+	entries, err := models.GetMergeQueueEntries(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range entries {
+		if entry.PullID == pr.ID {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// AddToMergeQueueCheck adds the base branch to the queue which processes its merge queue
+func AddToMergeQueueCheck(repoID int64, branch string) {
+	if err := mergeQueue.PushFunc(fmt.Sprintf("%d:%s", repoID, branch), func() error {
+		log.Trace("Adding branch %s of repo %d to the merge queue processing queue", branch, repoID)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding branch %s of repo %d to the merge queue processing queue: %v", branch, repoID, err)
+	}
+}
+
+// StartMergeQueueCheckByRepo processes the merge queues of all branches of the repository
+func StartMergeQueueCheckByRepo(ctx context.Context, repo *repo_model.Repository) {
+	branches, err := models.GetMergeQueueBranches(ctx, repo.ID)
+	if err != nil {
+		log.Error("GetMergeQueueBranches[%d]: %v", repo.ID, err)
+		return
+	}
+
+	for _, branch := range branches {
+		AddToMergeQueueCheck(repo.ID, branch)
+	}
+}
+
+// handleMergeQueue handles the passed "repoID:branch" entries and processes their merge queues
+func handleMergeQueue(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		parts := strings.SplitN(datum.(string), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		repoID, _ := strconv.ParseInt(parts[0], 10, 64)
+
+		processMergeQueue(repoID, parts[1])
+	}
+	return nil
+}
+
+// processMergeQueue walks through the merge queue of the branch. Every entry gets a merge commit
+// on top of the merge commit of its predecessor. The first entry is merged into the base branch
+// by fast-forwarding it to the merge commit as soon as its required status checks succeed.
+// Entries whose checks fail are removed and the merge commits of their successors are rebuilt.
+// The merge commit of an entry is rebuilt as well if the head of its pull request moved.
+func processMergeQueue(repoID int64, branch string) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("Process merge queue of branch %s in repo[%d]", branch, repoID))
+	defer finished()
+
+	entries, err := models.GetMergeQueueEntries(ctx, repoID, branch)
+	if err != nil {
+		log.Error("GetMergeQueueEntries[%d, %s]: %v", repoID, branch, err)
+		return
+	} else if len(entries) == 0 {
+		return
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		log.Error("GetRepositoryByID[%d]: %v", repoID, err)
+		return
+	}
+
+	protectBranch, err := models.GetMatchingProtectedBranchRule(repoID, branch)
+	if err != nil {
+		log.Error("GetMatchingProtectedBranchRule[%d, %s]: %v", repoID, branch, err)
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", repo.RepoPath(), err)
+		return
+	}
+	defer gitRepo.Close()
+
+	parentCommitID, err := gitRepo.GetBranchCommitID(branch)
+	if err != nil {
+		log.Error("GetBranchCommitID[%s, %s]: %v", repo.RepoPath(), branch, err)
+		return
+	}
+
+	// only the head of the queue is allowed to be merged
+	isHead := true
+	for _, entry := range entries {
+		pr, err := models.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			log.Error("GetPullRequestByID[%d]: %v", entry.PullID, err)
+			return
+		}
+		pr.BaseRepo = repo
+
+		if err := entry.LoadDoer(ctx); err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ejectFromMergeQueue(pr, user_model.NewGhostUser(), "The user who added the pull request to the merge queue does not exist anymore.")
+				continue
+			}
+			log.Error("LoadDoer[%d]: %v", entry.PullID, err)
+			return
+		}
+
+		if protectBranch == nil || !protectBranch.IsMergeQueueEnabled() {
+			ejectFromMergeQueue(pr, entry.Doer, "The merge queue of the base branch was disabled.")
+			continue
+		}
+
+		if err := pr.LoadIssueCtx(ctx); err != nil {
+			log.Error("LoadIssue[%d]: %v", pr.ID, err)
+			return
+		}
+		if pr.HasMerged || pr.Issue.IsClosed || pr.BaseBranch != entry.BaseBranch {
+			ejectFromMergeQueue(pr, entry.Doer, "The pull request was closed or its base branch was changed.")
+			continue
+		}
+
+		headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+			return
+		}
+		if entry.HeadCommitID != "" && entry.HeadCommitID != headCommitID {
+			// a push which changes the diff has not been reviewed, a rebase or an amended commit message only needs a new merge commit
+			changed, err := checkIfPRContentChanged(ctx, pr, entry.HeadCommitID, headCommitID)
+			if err != nil {
+				log.Error("checkIfPRContentChanged[%d]: %v", pr.ID, err)
+				return
+			}
+			if changed {
+				ejectFromMergeQueue(pr, entry.Doer, "New commits were pushed to the pull request.")
+				continue
+			}
+		}
+
+		if entry.CommitID == "" || entry.ParentCommitID != parentCommitID || entry.HeadCommitID != headCommitID {
+			commitID, err := rawMergeOnto(ctx, pr, entry.Doer, entry.MergeStyle, headCommitID, entry.Message, parentCommitID, git.BranchPrefix+mergeQueueBranchName(pr))
+			if err != nil {
+				if models.IsErrSHADoesNotMatch(err) {
+					// the head branch was pushed to again, the queue is processed once more after the push
+					log.Trace("Head of PR[%d] moved while building its merge queue commit: %v", pr.ID, err)
+					return
+				} else if models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) || models.IsErrMergeUnrelatedHistories(err) {
+					ejectFromMergeQueue(pr, entry.Doer, "The pull request conflicts with the pull requests ahead of it in the merge queue.")
+					continue
+				} else if models.IsErrMergeDivergingFastForwardOnly(err) {
+					ejectFromMergeQueue(pr, entry.Doer, "The pull request can not be fast-forwarded onto the pull requests ahead of it in the merge queue and needs to be updated.")
+					continue
+				}
+				log.Error("Unable to build merge queue commit of PR[%d]: %v", pr.ID, err)
+				return
+			}
+
+			entry.ParentCommitID = parentCommitID
+			entry.HeadCommitID = headCommitID
+			entry.CommitID = commitID
+			if err := models.UpdateMergeQueueEntryCommit(ctx, entry); err != nil {
+				log.Error("UpdateMergeQueueEntryCommit[%d]: %v", pr.ID, err)
+				return
+			}
+		}
+
+		state, err := getCommitStatusState(ctx, repoID, entry.CommitID, protectBranch.StatusCheckContexts)
+		if err != nil {
+			log.Error("getCommitStatusState[%s]: %v", entry.CommitID, err)
+			return
+		}
+
+		if state.IsFailure() || state.IsError() {
+			ejectFromMergeQueue(pr, entry.Doer, "The required status checks of the merge queue commit failed.")
+			continue
+		}
+
+		if isHead && state.IsSuccess() {
+			// reviews may have been dismissed or requested since the pull request was queued
+			if err := checkPullBranchProtections(ctx, pr, false, nil); err != nil {
+				if models.IsErrDisallowedToMerge(err) {
+					ejectFromMergeQueue(pr, entry.Doer, "The pull request does not satisfy the branch protection anymore.")
+					continue
+				}
+				log.Error("checkPullBranchProtections[%d]: %v", pr.ID, err)
+				return
+			}
+			if err := mergeFromMergeQueue(ctx, pr, entry, gitRepo); err != nil {
+				log.Error("Unable to merge PR[%d] from the merge queue: %v", pr.ID, err)
+				return
+			}
+		} else {
+			isHead = false
+		}
+
+		parentCommitID = entry.CommitID
+	}
+}
+
+// mergeFromMergeQueue fast-forwards the base branch to the tested merge commit of the entry and marks the pull request as merged
+func mergeFromMergeQueue(ctx context.Context, pr *models.PullRequest, entry *models.MergeQueueEntry, baseGitRepo *git.Repository) error {
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return err
+	}
+
+	pushUser := entry.Doer
+	if pr.HeadRepo != nil {
+		if err := pr.HeadRepo.GetOwner(ctx); err == nil {
+			pushUser = pr.HeadRepo.Owner
+		}
+	}
+
+	defer func() {
+		go AddTestPullRequestTask(entry.Doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+	}()
+
+	// a plain push is only possible as fast-forward, so the base branch can't have moved in the meantime
+	if err := git.NewCommand(ctx, "push", ".", entry.CommitID+":"+git.BranchPrefix+pr.BaseBranch).
+		Run(&git.RunOpts{
+			Env: models.FullPushingEnvironment(pushUser, entry.Doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID),
+			Dir: baseGitRepo.Path,
+		}); err != nil {
+		return fmt.Errorf("git push: %v", err)
+	}
+
+	deleteMergeQueueBranch(pr)
+
+	pr.MergedCommitID = entry.CommitID
+	pr.MergedUnix = timeutil.TimeStampNow()
+	pr.Merger = entry.Doer
+	pr.MergerID = entry.Doer.ID
+
+	return afterMerge(ctx, pr, entry.Doer)
+}
+
+// ejectFromMergeQueue removes a pull request which can't be merged from the merge queue
+func ejectFromMergeQueue(pr *models.PullRequest, doer *user_model.User, reason string) {
+	log.Trace("Removing PR[%d] from the merge queue: %s", pr.ID, reason)
+
+	if err := RemoveFromMergeQueue(doer, pr, reason); err != nil && !models.IsErrNotExist(err) {
+		log.Error("RemoveFromMergeQueue[%d]: %v", pr.ID, err)
+	}
+}
+
+// deleteMergeQueueBranch deletes the branch the merge commit of the pull request was tested on
+func deleteMergeQueueBranch(pr *models.PullRequest) {
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pr.ID, err)
+		return
+	}
+
+	if err := git.NewCommand(graceful.GetManager().HammerContext(), "update-ref", "-d", git.BranchPrefix+mergeQueueBranchName(pr)).
+		Run(&git.RunOpts{Dir: pr.BaseRepo.RepoPath()}); err != nil {
+		log.Error("Unable to delete merge queue branch of PR[%d]: %v", pr.ID, err)
+	}
+}
+
+func initMergeQueue() error {
+	mergeQueue = queue.CreateUniqueQueue("pr_merge_queue", handleMergeQueue, "")
+	if mergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(mergeQueue.Run)
+	return nil
+}
@@ -119,6 +119,10 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *mode
 		_, _ = models.CreateComment(ops)
 	}
 
+	if err := RequestCodeOwnerReviews(prCtx, pr); err != nil {
+		log.Error("RequestCodeOwnerReviews: %v", err)
+	}
+
 	return nil
 }
 
@@ -274,6 +278,16 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 							if err := models.MarkReviewsAsStale(pr.IssueID); err != nil {
 								log.Error("MarkReviewsAsStale: %v", err)
 							}
+							// Cancel a scheduled merge as the pushed changes were not checked by the scheduling user
+							if setting.Repository.PullRequest.CancelAutoMergeOnPush {
+								if err := RemoveScheduledAutoMerge(doer, pr); err != nil && !models.IsErrNotExist(err) {
+									log.Error("RemoveScheduledAutoMerge: %v", err)
+								}
+							}
+							// The queued merge commit does not contain the pushed changes
+							if err := RemoveFromMergeQueue(doer, pr, "New commits were pushed to the pull request."); err != nil && !models.IsErrNotExist(err) {
+								log.Error("RemoveFromMergeQueue: %v", err)
+							}
 						}
 						if err := models.MarkReviewsAsNotStale(pr.IssueID, newCommitID); err != nil {
 							log.Error("MarkReviewsAsNotStale: %v", err)
@@ -307,6 +321,14 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			}
 
 			AddToTaskQueue(pr)
+			// A queued merge commit has to be rebuilt from the new head
+			AddToMergeQueueCheck(pr.BaseRepoID, pr.BaseBranch)
+			if isSync {
+				// Request reviews from the owners of the files touched by the pushed commits
+				if err := RequestCodeOwnerReviews(ctx, pr); err != nil {
+					log.Error("RequestCodeOwnerReviews: %v", err)
+				}
+			}
 			comment, err := models.CreatePushPullComment(ctx, doer, pr, oldCommitID, newCommitID)
 			if err == nil && comment != nil {
 				notification.NotifyPullRequestPushCommits(doer, pr, comment)
@@ -335,6 +357,9 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			}
 			AddToTaskQueue(pr)
 		}
+
+		// The merge commits of the merge queue have to be rebuilt on top of the new head
+		AddToMergeQueueCheck(repoID, branch)
 	})
 }
 
@@ -255,6 +255,10 @@ func SubmitReview(ctx context.Context, doer *user_model.User, gitRepo *git.Repos
 
 	notification.NotifyPullRequestReview(pr, review, comm, mentions)
 
+	if reviewType == models.ReviewTypeApprove {
+		AddToAutoMergeQueue(pr)
+	}
+
 	for _, lines := range review.CodeComments {
 		for _, comments := range lines {
 			for _, codeComment := range comments {
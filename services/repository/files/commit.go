@@ -13,7 +13,9 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/structs"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 // CreateCommitStatus creates a new CommitStatus given a bunch of parameters
@@ -44,6 +46,13 @@ func CreateCommitStatus(ctx context.Context, repo *repo_model.Repository, creato
 		return fmt.Errorf("NewCommitStatus[repo_id: %d, user_id: %d, sha: %s]: %v", repo.ID, creator.ID, sha, err)
 	}
 
+	notification.NotifyCreateCommitStatus(creator, repo, status)
+
+	// the new status may complete the required checks of a pull request which is scheduled to auto merge
+	pull_service.StartPullRequestAutoMergeCheckByRepo(ctx, repo)
+	// or the checks of a merge commit in the merge queue
+	pull_service.StartMergeQueueCheckByRepo(ctx, repo)
+
 	return nil
 }
 
@@ -0,0 +1,330 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pushrule
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gobwas/glob"
+)
+
+// Violation describes a file of a pushed commit which violates a push rule
+type Violation struct {
+	CommitID string
+	Path     string
+	Reason   string
+}
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("commit %s, %s: %s", base.ShortSha(v.CommitID), v.Path, v.Reason)
+}
+
+type changedFile struct {
+	Path   string
+	BlobID string
+}
+
+type checker struct {
+	ctx      context.Context
+	repoPath string
+	env      []string
+	rule     *repo_model.PushRule
+	globs    []glob.Glob
+
+	maxViolations int
+	violations    []*Violation
+}
+
+func (c *checker) add(commitID, path, reason string) {
+	if c.isFull() {
+		return
+	}
+	c.violations = append(c.violations, &Violation{CommitID: commitID, Path: path, Reason: reason})
+}
+
+func (c *checker) isFull() bool {
+	return c.maxViolations > 0 && len(c.violations) >= c.maxViolations
+}
+
+func (c *checker) runOpts() *git.RunOpts {
+	return &git.RunOpts{Dir: c.repoPath, Env: c.env}
+}
+
+// Check returns the violations of the rule by all commits which are reachable from the new commit ids
+// but not from any existing ref of the repository. At most maxViolations are returned if it is positive.
+// The env must contain the quarantine environment of the pre-receive hook.
+func Check(ctx context.Context, repoPath string, env []string, rule *repo_model.PushRule, newCommitIDs []string, maxViolations int) ([]*Violation, error) {
+	if rule.IsEmpty() {
+		return nil, nil
+	}
+
+	globs, err := rule.CompileForbiddenFilePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{
+		ctx:           ctx,
+		repoPath:      repoPath,
+		env:           env,
+		rule:          rule,
+		globs:         globs,
+		maxViolations: maxViolations,
+	}
+
+	commitIDs, err := c.listNewCommits(newCommitIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(commitIDs) == 0 {
+		return nil, nil
+	}
+
+	// a blob may be added by several commits, its size is checked only once
+	blobs := make(map[string][]changedFile)
+	blobCommits := make(map[string][]string)
+	// a secret is reported once per file of a commit
+	reported := make(map[string]bool)
+
+	err = c.logChanges(commitIDs, func(commitID string, file changedFile) {
+		if pattern, ok := rule.MatchForbiddenFilePattern(globs, file.Path); ok {
+			c.add(commitID, file.Path, fmt.Sprintf("file name matches the forbidden pattern %q", pattern))
+		}
+		if rule.MaxFileSize > 0 {
+			blobs[file.BlobID] = append(blobs[file.BlobID], file)
+			blobCommits[file.BlobID] = append(blobCommits[file.BlobID], commitID)
+		}
+	}, func(commitID, path, line string) {
+		key := commitID + ":" + path
+		if reported[key] {
+			return
+		}
+		if name, ok := detectSecret(line); ok {
+			reported[key] = true
+			c.add(commitID, path, fmt.Sprintf("added content contains a secret (%s)", name))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blobs) > 0 && !c.isFull() {
+		sizes, err := c.getBlobSizes(blobs)
+		if err != nil {
+			return nil, err
+		}
+		for blobID, files := range blobs {
+			size := sizes[blobID]
+			if size <= rule.MaxFileSize {
+				continue
+			}
+			for i, file := range files {
+				c.add(blobCommits[blobID][i], file.Path, fmt.Sprintf("file size %s exceeds the limit of %s", humanize.IBytes(uint64(size)), humanize.IBytes(uint64(rule.MaxFileSize))))
+			}
+		}
+	}
+
+	return c.violations, nil
+}
+
+// listNewCommits returns the commits which are not yet reachable from any ref
+func (c *checker) listNewCommits(newCommitIDs []string) ([]string, error) {
+	args := []string{"rev-list"}
+	for _, commitID := range newCommitIDs {
+		if commitID != git.EmptySHA {
+			args = append(args, commitID)
+		}
+	}
+	if len(args) == 1 {
+		return nil, nil
+	}
+	args = append(args, "--not", "--all")
+
+	stdout, _, err := git.NewCommand(c.ctx, args...).RunStdString(c.runOpts())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pushed commits: %w", err)
+	}
+	return strings.Fields(stdout), nil
+}
+
+// logChanges lists the changes of all commits with a single git log. The file callback is called for every
+// added or modified file, the line callback for every added line if secrets are rejected.
+// Merge commits are diffed with --cc, so only the changes which differ from all parents are reported,
+// the other changes are checked with the merged commits.
+func (c *checker) logChanges(commitIDs []string, fileCb func(commitID string, file changedFile), lineCb func(commitID, path, line string)) error {
+	args := []string{"log", "--stdin", "--no-walk=unsorted", "--format=commit %H", "--raw", "--no-abbrev", "--cc", "--root", "--no-renames"}
+	if c.rule.RejectSecrets {
+		args = append(args, "-p", "-U0", "--no-color", "--no-ext-diff", "--no-textconv")
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	err = git.NewCommand(c.ctx, args...).
+		Run(&git.RunOpts{
+			Dir:    c.repoPath,
+			Env:    c.env,
+			Stdin:  strings.NewReader(strings.Join(commitIDs, "\n") + "\n"),
+			Stdout: stdoutWriter,
+			PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+				_ = stdoutWriter.Close()
+				defer stdoutReader.Close()
+
+				return parseLog(stdoutReader, func(commitID string, file changedFile) {
+					if !c.isFull() {
+						fileCb(commitID, file)
+					}
+				}, func(commitID, path, line string) {
+					if !c.isFull() {
+						lineCb(commitID, path, line)
+					}
+				})
+			},
+		})
+	if err != nil {
+		return fmt.Errorf("unable to list the changes of the pushed commits: %w", err)
+	}
+	return nil
+}
+
+// parseLog parses the output of "git log --format='commit %H' --raw --cc [-p]". The file callback is called for the
+// added and modified files of the raw output, the line callback for the lines of the patches which are added
+// compared to all parents of the commit.
+func parseLog(r io.Reader, fileCb func(commitID string, file changedFile), lineCb func(commitID, path, line string)) error {
+	reader := bufio.NewReader(r)
+
+	var commitID, path string
+	inHunk := false
+	parents := 1
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "commit "):
+				commitID = line[7:]
+				path = ""
+				inHunk = false
+			case strings.HasPrefix(line, "diff "):
+				path = ""
+				inHunk = false
+			case !inHunk && strings.HasPrefix(line, ":"):
+				if file, ok := parseRawLine(line); ok {
+					fileCb(commitID, file)
+				}
+			case !inHunk && strings.HasPrefix(line, "+++ "):
+				path = parsePatchPath(line[4:])
+			case strings.HasPrefix(line, "@@"):
+				// a combined diff has one @ more than the number of parents
+				inHunk = true
+				parents = len(line) - len(strings.TrimLeft(line, "@")) - 1
+			case inHunk && path != "" && len(line) >= parents && strings.Count(line[:parents], "+") == parents:
+				lineCb(commitID, path, line[parents:])
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseRawLine parses a line of the raw diff output and returns the file if it has been added or modified.
+// A line of a combined diff has a mode, a sha and a status for every parent, the last mode and sha belong to the commit.
+//
+//	:<old mode> <new mode> <old sha> <new sha> <status>\t<path>
+//	::<mode 1> <mode 2> <new mode> <sha 1> <sha 2> <new sha> <status 1><status 2>\t<path>
+func parseRawLine(line string) (changedFile, bool) {
+	fields := strings.SplitN(line, "\t", 2)
+	if len(fields) != 2 {
+		return changedFile{}, false
+	}
+	parents := len(fields[0]) - len(strings.TrimLeft(fields[0], ":"))
+	info := strings.Fields(fields[0][parents:])
+	if len(info) != 2*(parents+1)+1 {
+		return changedFile{}, false
+	}
+	newMode, newSHA, status := info[parents], info[2*parents+1], info[2*parents+2]
+	if newMode == "160000" || newSHA == git.EmptySHA {
+		// submodules and deleted files
+		return changedFile{}, false
+	}
+	if strings.Trim(status, "AMT") != "" {
+		return changedFile{}, false
+	}
+	return changedFile{Path: unquotePath(fields[1]), BlobID: newSHA}, true
+}
+
+// getBlobSizes returns the sizes of the blobs
+func (c *checker) getBlobSizes(blobs map[string][]changedFile) (map[string]int64, error) {
+	var input strings.Builder
+	for blobID := range blobs {
+		input.WriteString(blobID)
+		input.WriteByte('\n')
+	}
+
+	stdout, _, err := git.NewCommand(c.ctx, "cat-file", "--batch-check").RunStdString(&git.RunOpts{
+		Dir:   c.repoPath,
+		Env:   c.env,
+		Stdin: strings.NewReader(input.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the sizes of the pushed files: %w", err)
+	}
+
+	sizes := make(map[string]int64, len(blobs))
+	for _, line := range strings.Split(stdout, "\n") {
+		// <sha> <type> <size>
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[fields[0]] = size
+	}
+	return sizes, nil
+}
+
+// parsePatchPath returns the path of a "+++" line, quoted paths are unquoted
+func parsePatchPath(s string) string {
+	// git appends a tab to paths which contain a space
+	s = strings.TrimSuffix(s, "\t")
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(unquotePath(s), "b/")
+}
+
+// unquotePath unquotes a path which git has quoted because of special characters
+func unquotePath(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pushrule
+
+import (
+	"regexp"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// builtinSecretPatterns only contains patterns with a low rate of false positives
+var builtinSecretPatterns = []setting.SecretPattern{
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{Name: "AWS access key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{Name: "Azure storage account key", Pattern: regexp.MustCompile(`AccountKey=[0-9A-Za-z+/]{86}==`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\bgh[pousr]_[0-9A-Za-z]{36}\b`)},
+	{Name: "GitLab personal access token", Pattern: regexp.MustCompile(`\bglpat-[0-9A-Za-z_\-]{20}\b`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z\-]{10,}\b`)},
+	{Name: "Stripe secret key", Pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+}
+
+// detectSecret returns the name of the first secret pattern which matches the line
+func detectSecret(line string) (string, bool) {
+	for _, patterns := range [][]setting.SecretPattern{builtinSecretPatterns, setting.PushRules.SecretPatterns} {
+		for _, p := range patterns {
+			if p.Pattern.MatchString(line) {
+				return p.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SecretPatternNames returns the names of all secret patterns which are checked
+func SecretPatternNames() []string {
+	names := make([]string, 0, len(builtinSecretPatterns)+len(setting.PushRules.SecretPatterns))
+	for _, patterns := range [][]setting.SecretPattern{builtinSecretPatterns, setting.PushRules.SecretPatterns} {
+		for _, p := range patterns {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// StarRepository stars or unstars a repository for the doer
+func StarRepository(doer *user_model.User, repo *repo_model.Repository, star bool) error {
+	if repo_model.IsStaring(doer.ID, repo.ID) == star {
+		return nil
+	}
+
+	if err := repo_model.StarRepo(doer.ID, repo.ID, star); err != nil {
+		return err
+	}
+
+	if star {
+		repo.NumStars++
+	} else {
+		repo.NumStars--
+	}
+	notification.NotifyStarRepository(doer, repo, star)
+	return nil
+}
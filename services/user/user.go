@@ -66,6 +66,10 @@ func DeleteUser(u *user_model.User) error {
 		return models.ErrUserOwnPackages{UID: u.ID}
 	}
 
+	if err := packages_model.DeleteCleanupRulesByOwner(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeleteCleanupRulesByOwner: %v", err)
+	}
+
 	if err := models.DeleteUser(ctx, u); err != nil {
 		return fmt.Errorf("DeleteUser: %v", err)
 	}
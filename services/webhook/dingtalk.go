@@ -166,6 +166,45 @@ func (d *DingtalkPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	return createDingtalkPayload(text, text, "view release", p.Release.URL), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (d *DingtalkPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	link := p.TargetURL
+	if link == "" {
+		link = p.Repository.HTMLURL + "/commit/" + url.PathEscape(p.SHA)
+	}
+	return createDingtalkPayload(text, text, "view status", link), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (d *DingtalkPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view wiki", wikiPageURL(p.Repository, p.Page)), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (d *DingtalkPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _ := getStarPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view repository", p.Repository.HTMLURL), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (d *DingtalkPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _ := getMemberPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view team", teamURL(p.Organization, p.Team)), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (d *DingtalkPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, noneLinkFormatter, true)
+
+	return createDingtalkPayload(text, text, "view team", teamURL(p.Organization, p.Team)), nil
+}
+
 func createDingtalkPayload(title, text, singleTitle, singleURL string) *DingtalkPayload {
 	return &DingtalkPayload{
 		MsgType: "actionCard",
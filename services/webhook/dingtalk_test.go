@@ -203,6 +203,48 @@ func TestDingTalkPayload(t *testing.T) {
 		assert.Equal(t, "view release", pl.(*DingtalkPayload).ActionCard.SingleTitle)
 		assert.Equal(t, "http://localhost:3000/api/v1/repos/test/repo/releases/2", parseRealSingleURL(pl.(*DingtalkPayload).ActionCard.SingleURL))
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(DingtalkPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DingtalkPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: failure (#12) by user1", pl.(*DingtalkPayload).ActionCard.Text)
+		assert.Equal(t, "view status", pl.(*DingtalkPayload).ActionCard.SingleTitle)
+		assert.Equal(t, "https://ci.example.com/build/1", parseRealSingleURL(pl.(*DingtalkPayload).ActionCard.SingleURL))
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(DingtalkPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DingtalkPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] New wiki page 'Getting Started' by user1", pl.(*DingtalkPayload).ActionCard.Text)
+		assert.Equal(t, "view wiki", pl.(*DingtalkPayload).ActionCard.SingleTitle)
+		assert.Equal(t, "http://localhost:3000/test/repo/wiki/Getting%20Started", parseRealSingleURL(pl.(*DingtalkPayload).ActionCard.SingleURL))
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(DingtalkPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DingtalkPayload{}, pl)
+
+		assert.Equal(t, "[org3] user2 added to team Developers by user1", pl.(*DingtalkPayload).ActionCard.Text)
+		assert.Equal(t, "view team", pl.(*DingtalkPayload).ActionCard.SingleTitle)
+		assert.Equal(t, "https://try.gitea.io/org/org3/teams/Developers", parseRealSingleURL(pl.(*DingtalkPayload).ActionCard.SingleURL))
+	})
 }
 
 func TestDingTalkJSONPayload(t *testing.T) {
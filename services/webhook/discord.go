@@ -242,6 +242,41 @@ func (d *DiscordPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return d.createPayload(p.Sender, text, p.Release.Note, p.Release.URL, color), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (d *DiscordPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Description, p.TargetURL, color), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (d *DiscordPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Comment, wikiPageURL(p.Repository, p.Page), color), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (d *DiscordPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, color := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", p.Repository.HTMLURL, color), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (d *DiscordPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, color := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, "", teamURL(p.Organization, p.Team), color), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (d *DiscordPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, color := getTeamPayloadInfo(p, noneLinkFormatter, false)
+
+	return d.createPayload(p.Sender, text, p.Team.Description, teamURL(p.Organization, p.Team), color), nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event webhook_model.HookEventType, meta string) (api.Payloader, error) {
 	s := new(DiscordPayload)
@@ -229,6 +229,53 @@ func TestDiscordPayload(t *testing.T) {
 		assert.Equal(t, setting.AppURL+p.Sender.UserName, pl.(*DiscordPayload).Embeds[0].Author.URL)
 		assert.Equal(t, p.Sender.AvatarURL, pl.(*DiscordPayload).Embeds[0].Author.IconURL)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: failure (#12)", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "Build failed", pl.(*DiscordPayload).Embeds[0].Description)
+		assert.Equal(t, "https://ci.example.com/build/1", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, p.Sender.UserName, pl.(*DiscordPayload).Embeds[0].Author.Name)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[test/repo] New wiki page 'Getting Started'", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "Add getting started guide", pl.(*DiscordPayload).Embeds[0].Description)
+		assert.Equal(t, "http://localhost:3000/test/repo/wiki/Getting%20Started", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, p.Sender.UserName, pl.(*DiscordPayload).Embeds[0].Author.Name)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(DiscordPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &DiscordPayload{}, pl)
+
+		assert.Len(t, pl.(*DiscordPayload).Embeds, 1)
+		assert.Equal(t, "[org3] user2 added to team Developers", pl.(*DiscordPayload).Embeds[0].Title)
+		assert.Equal(t, "https://try.gitea.io/org/org3/teams/Developers", pl.(*DiscordPayload).Embeds[0].URL)
+		assert.Equal(t, p.Sender.UserName, pl.(*DiscordPayload).Embeds[0].Author.Name)
+	})
 }
 
 func TestDiscordJSONPayload(t *testing.T) {
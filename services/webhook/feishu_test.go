@@ -156,6 +156,42 @@ func TestFeishuPayload(t *testing.T) {
 
 		assert.Equal(t, "[test/repo] Release created: v1.0 by user1", pl.(*FeishuPayload).Content.Text)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(FeishuPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &FeishuPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: failure (#12) by user1", pl.(*FeishuPayload).Content.Text)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(FeishuPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &FeishuPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] New wiki page 'Getting Started' by user1", pl.(*FeishuPayload).Content.Text)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(FeishuPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &FeishuPayload{}, pl)
+
+		assert.Equal(t, "[org3] user2 added to team Developers by user1", pl.(*FeishuPayload).Content.Text)
+	})
 }
 
 func TestFeishuJSONPayload(t *testing.T) {
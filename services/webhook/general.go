@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
@@ -161,6 +162,134 @@ func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, w
 	return text, color
 }
 
+func getStatusPayloadInfo(p *api.CommitStatusPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	commitLink := linkFormatter(p.Repository.HTMLURL+"/commit/"+url.PathEscape(p.SHA), base.ShortSha(p.SHA))
+	contextLink := p.Context
+	if p.TargetURL != "" {
+		contextLink = linkFormatter(p.TargetURL, p.Context)
+	}
+
+	text = fmt.Sprintf("[%s] Commit status %s on %s: %s", repoLink, contextLink, commitLink, p.State)
+	if len(p.PullRequests) > 0 {
+		list := make([]string, len(p.PullRequests))
+		for i, pr := range p.PullRequests {
+			list[i] = linkFormatter(pr.HTMLURL, fmt.Sprintf("#%d", pr.Index))
+		}
+		text += fmt.Sprintf(" (%s)", strings.Join(list, ", "))
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	switch p.State {
+	case api.CommitStatusSuccess:
+		color = greenColor
+	case api.CommitStatusPending:
+		color = yellowColor
+	case api.CommitStatusWarning:
+		color = orangeColor
+	default:
+		color = redColor
+	}
+	return text, color
+}
+
+func getWikiPayloadInfo(p *api.WikiPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	pageLink := linkFormatter(wikiPageURL(p.Repository, p.Page), p.Page)
+
+	switch p.Action {
+	case api.HookWikiCreated:
+		text = fmt.Sprintf("[%s] New wiki page '%s'", repoLink, pageLink)
+		color = greenColor
+	case api.HookWikiEdited:
+		text = fmt.Sprintf("[%s] Wiki page '%s' edited", repoLink, pageLink)
+		color = yellowColor
+	case api.HookWikiRenamed:
+		text = fmt.Sprintf("[%s] Wiki page '%s' renamed to '%s'", repoLink, p.OldPage, pageLink)
+		color = yellowColor
+	case api.HookWikiDeleted:
+		text = fmt.Sprintf("[%s] Wiki page '%s' deleted", repoLink, p.Page)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+func getStarPayloadInfo(p *api.StarPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+
+	switch p.Action {
+	case api.HookStarStarred:
+		text = fmt.Sprintf("[%s] Repository starred", repoLink)
+		color = yellowColor
+	case api.HookStarUnstarred:
+		text = fmt.Sprintf("[%s] Repository unstarred", repoLink)
+		color = greyColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+func getMemberPayloadInfo(p *api.MemberPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	orgLink := linkFormatter(setting.AppURL+url.PathEscape(p.Organization.UserName), p.Organization.UserName)
+	teamLink := linkFormatter(teamURL(p.Organization, p.Team), p.Team.Name)
+	memberLink := linkFormatter(setting.AppURL+url.PathEscape(p.Member.UserName), p.Member.UserName)
+
+	switch p.Action {
+	case api.HookMemberAdded:
+		text = fmt.Sprintf("[%s] %s added to team %s", orgLink, memberLink, teamLink)
+		color = greenColor
+	case api.HookMemberRemoved:
+		text = fmt.Sprintf("[%s] %s removed from team %s", orgLink, memberLink, teamLink)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+func getTeamPayloadInfo(p *api.TeamPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	orgLink := linkFormatter(setting.AppURL+url.PathEscape(p.Organization.UserName), p.Organization.UserName)
+	teamLink := linkFormatter(teamURL(p.Organization, p.Team), p.Team.Name)
+
+	switch p.Action {
+	case api.HookTeamCreated:
+		text = fmt.Sprintf("[%s] Team %s created", orgLink, teamLink)
+		color = greenColor
+	case api.HookTeamEdited:
+		text = fmt.Sprintf("[%s] Team %s edited (permission: %s)", orgLink, teamLink, p.Team.Permission)
+		color = yellowColor
+	case api.HookTeamDeleted:
+		text = fmt.Sprintf("[%s] Team %s deleted", orgLink, p.Team.Name)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+url.PathEscape(p.Sender.UserName), p.Sender.UserName))
+	}
+
+	return text, color
+}
+
+// wikiPageURL returns the web url of the wiki page
+func wikiPageURL(repo *api.Repository, page string) string {
+	return repo.HTMLURL + "/wiki/" + url.PathEscape(page)
+}
+
+// teamURL returns the web url of the team
+func teamURL(org *api.Organization, team *api.Team) string {
+	return setting.AppURL + "org/" + url.PathEscape(org.UserName) + "/teams/" + url.PathEscape(team.Name)
+}
+
 func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFormatter, withSender bool) (string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
@@ -217,6 +217,105 @@ func pullReleaseTestPayload() *api.ReleasePayload {
 	}
 }
 
+func statusTestPayload() *api.CommitStatusPayload {
+	return &api.CommitStatusPayload{
+		ID:          1,
+		SHA:         "2020558fe2e34debb818a514715839cabd25e778",
+		Context:     "ci/build",
+		State:       api.CommitStatusFailure,
+		Description: "Build failed",
+		TargetURL:   "https://ci.example.com/build/1",
+		PullRequests: []*api.PullRequest{
+			{
+				Index:   12,
+				HTMLURL: "http://localhost:3000/test/repo/pulls/12",
+			},
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+	}
+}
+
+func wikiTestPayload() *api.WikiPayload {
+	return &api.WikiPayload{
+		Action:  api.HookWikiCreated,
+		Page:    "Getting Started",
+		Comment: "Add getting started guide",
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+	}
+}
+
+func starTestPayload() *api.StarPayload {
+	return &api.StarPayload{
+		Action: api.HookStarStarred,
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+			Stars:    3,
+		},
+	}
+}
+
+func memberTestPayload() *api.MemberPayload {
+	return &api.MemberPayload{
+		Action: api.HookMemberAdded,
+		Member: &api.User{
+			UserName: "user2",
+		},
+		Team: &api.Team{
+			ID:         2,
+			Name:       "Developers",
+			Permission: "write",
+		},
+		Organization: &api.Organization{
+			UserName: "org3",
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+	}
+}
+
+func teamTestPayload() *api.TeamPayload {
+	return &api.TeamPayload{
+		Action: api.HookTeamEdited,
+		Team: &api.Team{
+			ID:          2,
+			Name:        "Developers",
+			Description: "All developers",
+			Permission:  "write",
+		},
+		Organization: &api.Organization{
+			UserName: "org3",
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+	}
+}
+
 func pullRequestTestPayload() *api.PullRequestPayload {
 	return &api.PullRequestPayload{
 		Action: api.HookIssueOpened,
@@ -539,3 +638,95 @@ func TestGetIssueCommentPayloadInfo(t *testing.T) {
 		assert.Equal(t, c.color, color, "case %d", i)
 	}
 }
+
+func TestGetStatusPayloadInfo(t *testing.T) {
+	p := statusTestPayload()
+
+	text, color := getStatusPayloadInfo(p, noneLinkFormatter, true)
+	assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: failure (#12) by user1", text)
+	assert.Equal(t, redColor, color)
+
+	p.State = api.CommitStatusSuccess
+	p.PullRequests = nil
+	text, color = getStatusPayloadInfo(p, noneLinkFormatter, false)
+	assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: success", text)
+	assert.Equal(t, greenColor, color)
+
+	p.State = api.CommitStatusPending
+	p.TargetURL = ""
+	text, color = getStatusPayloadInfo(p, htmlLinkFormatter, false)
+	assert.Equal(t, `[<a href="http://localhost:3000/test/repo">test/repo</a>] Commit status ci/build on <a href="http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778">2020558fe2</a>: pending`, text)
+	assert.Equal(t, yellowColor, color)
+}
+
+func TestGetWikiPayloadInfo(t *testing.T) {
+	p := wikiTestPayload()
+
+	cases := []struct {
+		action api.HookWikiAction
+		text   string
+		color  int
+	}{
+		{api.HookWikiCreated, "[test/repo] New wiki page 'Getting Started' by user1", greenColor},
+		{api.HookWikiEdited, "[test/repo] Wiki page 'Getting Started' edited by user1", yellowColor},
+		{api.HookWikiRenamed, "[test/repo] Wiki page 'Setup' renamed to 'Getting Started' by user1", yellowColor},
+		{api.HookWikiDeleted, "[test/repo] Wiki page 'Getting Started' deleted by user1", redColor},
+	}
+	p.OldPage = "Setup"
+	for i, c := range cases {
+		p.Action = c.action
+		text, color := getWikiPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+
+	p.Action = api.HookWikiCreated
+	text, _ := getWikiPayloadInfo(p, htmlLinkFormatter, false)
+	assert.Equal(t, `[<a href="http://localhost:3000/test/repo">test/repo</a>] New wiki page '<a href="http://localhost:3000/test/repo/wiki/Getting%20Started">Getting Started</a>'`, text)
+}
+
+func TestGetStarPayloadInfo(t *testing.T) {
+	p := starTestPayload()
+
+	text, color := getStarPayloadInfo(p, noneLinkFormatter, true)
+	assert.Equal(t, "[test/repo] Repository starred by user1", text)
+	assert.Equal(t, yellowColor, color)
+
+	p.Action = api.HookStarUnstarred
+	text, color = getStarPayloadInfo(p, noneLinkFormatter, false)
+	assert.Equal(t, "[test/repo] Repository unstarred", text)
+	assert.Equal(t, greyColor, color)
+}
+
+func TestGetMemberPayloadInfo(t *testing.T) {
+	p := memberTestPayload()
+
+	text, color := getMemberPayloadInfo(p, noneLinkFormatter, true)
+	assert.Equal(t, "[org3] user2 added to team Developers by user1", text)
+	assert.Equal(t, greenColor, color)
+
+	p.Action = api.HookMemberRemoved
+	text, color = getMemberPayloadInfo(p, htmlLinkFormatter, false)
+	assert.Equal(t, `[<a href="https://try.gitea.io/org3">org3</a>] <a href="https://try.gitea.io/user2">user2</a> removed from team <a href="https://try.gitea.io/org/org3/teams/Developers">Developers</a>`, text)
+	assert.Equal(t, redColor, color)
+}
+
+func TestGetTeamPayloadInfo(t *testing.T) {
+	p := teamTestPayload()
+
+	cases := []struct {
+		action api.HookTeamAction
+		text   string
+		color  int
+	}{
+		{api.HookTeamCreated, "[org3] Team Developers created by user1", greenColor},
+		{api.HookTeamEdited, "[org3] Team Developers edited (permission: write) by user1", yellowColor},
+		{api.HookTeamDeleted, "[org3] Team Developers deleted by user1", redColor},
+	}
+	for i, c := range cases {
+		p.Action = c.action
+		text, color := getTeamPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
@@ -168,6 +168,42 @@ func TestMatrixPayload(t *testing.T) {
 		assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Release created: [v1.0](http://localhost:3000/test/repo/src/v1.0) by [user1](https://try.gitea.io/user1)", pl.(*MatrixPayloadUnsafe).Body)
 		assert.Equal(t, `[<a href="http://localhost:3000/test/repo">test/repo</a>] Release created: <a href="http://localhost:3000/test/repo/src/v1.0">v1.0</a> by <a href="https://try.gitea.io/user1">user1</a>`, pl.(*MatrixPayloadUnsafe).FormattedBody)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(MatrixPayloadUnsafe)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MatrixPayloadUnsafe{}, pl)
+
+		assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Commit status [ci/build](https://ci.example.com/build/1) on [2020558fe2](http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778): failure ([#12](http://localhost:3000/test/repo/pulls/12)) by [user1](https://try.gitea.io/user1)", pl.(*MatrixPayloadUnsafe).Body)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(MatrixPayloadUnsafe)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MatrixPayloadUnsafe{}, pl)
+
+		assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] New wiki page '[Getting Started](http://localhost:3000/test/repo/wiki/Getting%20Started)' by [user1](https://try.gitea.io/user1)", pl.(*MatrixPayloadUnsafe).Body)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(MatrixPayloadUnsafe)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MatrixPayloadUnsafe{}, pl)
+
+		assert.Equal(t, "[[org3](https://try.gitea.io/org3)] [user2](https://try.gitea.io/user2) added to team [Developers](https://try.gitea.io/org/org3/teams/Developers) by [user1](https://try.gitea.io/user1)", pl.(*MatrixPayloadUnsafe).Body)
+	})
 }
 
 func TestMatrixJSONPayload(t *testing.T) {
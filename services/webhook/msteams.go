@@ -281,17 +281,93 @@ func (m *MSTeamsPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (m *MSTeamsPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	title, color := getStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		p.Description,
+		p.TargetURL,
+		color,
+		&MSTeamsFact{"Commit:", p.SHA},
+	), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (m *MSTeamsPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	title, color := getWikiPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		p.Comment,
+		wikiPageURL(p.Repository, p.Page),
+		color,
+		&MSTeamsFact{"Page:", p.Page},
+	), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (m *MSTeamsPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	title, color := getStarPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		p.Repository,
+		p.Sender,
+		title,
+		"",
+		p.Repository.HTMLURL,
+		color,
+		&MSTeamsFact{"Stars:", fmt.Sprintf("%d", p.Repository.Stars)},
+	), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (m *MSTeamsPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	title, color := getMemberPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		nil,
+		p.Sender,
+		title,
+		"",
+		teamURL(p.Organization, p.Team),
+		color,
+		&MSTeamsFact{"Organization:", p.Organization.UserName},
+	), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (m *MSTeamsPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	title, color := getTeamPayloadInfo(p, noneLinkFormatter, false)
+
+	return createMSTeamsPayload(
+		nil,
+		p.Sender,
+		title,
+		p.Team.Description,
+		teamURL(p.Organization, p.Team),
+		color,
+		&MSTeamsFact{"Organization:", p.Organization.UserName},
+	), nil
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event webhook_model.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(MSTeamsPayload), p, event)
 }
 
 func createMSTeamsPayload(r *api.Repository, s *api.User, title, text, actionTarget string, color int, fact *MSTeamsFact) *MSTeamsPayload {
-	facts := []MSTeamsFact{
-		{
+	var facts []MSTeamsFact
+	if r != nil {
+		facts = append(facts, MSTeamsFact{
 			Name:  "Repository:",
 			Value: r.FullName,
-		},
+		})
 	}
 	if fact != nil {
 		facts = append(facts, *fact)
@@ -358,6 +358,66 @@ func TestMSTeamsPayload(t *testing.T) {
 		assert.Len(t, pl.(*MSTeamsPayload).PotentialAction[0].Targets, 1)
 		assert.Equal(t, "http://localhost:3000/api/v1/repos/test/repo/releases/2", pl.(*MSTeamsPayload).PotentialAction[0].Targets[0].URI)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(MSTeamsPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MSTeamsPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] Commit status ci/build on 2020558fe2: failure (#12)", pl.(*MSTeamsPayload).Title)
+		assert.Len(t, pl.(*MSTeamsPayload).Sections, 1)
+		assert.Equal(t, "Build failed", pl.(*MSTeamsPayload).Sections[0].Text)
+		assert.Len(t, pl.(*MSTeamsPayload).Sections[0].Facts, 2)
+		for _, fact := range pl.(*MSTeamsPayload).Sections[0].Facts {
+			if fact.Name == "Commit:" {
+				assert.Equal(t, p.SHA, fact.Value)
+			}
+		}
+		assert.Len(t, pl.(*MSTeamsPayload).PotentialAction, 1)
+		assert.Equal(t, "https://ci.example.com/build/1", pl.(*MSTeamsPayload).PotentialAction[0].Targets[0].URI)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(MSTeamsPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MSTeamsPayload{}, pl)
+
+		assert.Equal(t, "[test/repo] New wiki page 'Getting Started'", pl.(*MSTeamsPayload).Title)
+		assert.Len(t, pl.(*MSTeamsPayload).Sections, 1)
+		assert.Equal(t, "Add getting started guide", pl.(*MSTeamsPayload).Sections[0].Text)
+		assert.Len(t, pl.(*MSTeamsPayload).Sections[0].Facts, 2)
+		for _, fact := range pl.(*MSTeamsPayload).Sections[0].Facts {
+			if fact.Name == "Page:" {
+				assert.Equal(t, p.Page, fact.Value)
+			}
+		}
+		assert.Len(t, pl.(*MSTeamsPayload).PotentialAction, 1)
+		assert.Equal(t, "http://localhost:3000/test/repo/wiki/Getting%20Started", pl.(*MSTeamsPayload).PotentialAction[0].Targets[0].URI)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(MSTeamsPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &MSTeamsPayload{}, pl)
+
+		assert.Equal(t, "[org3] user2 added to team Developers", pl.(*MSTeamsPayload).Title)
+		assert.Len(t, pl.(*MSTeamsPayload).Sections, 1)
+		assert.Equal(t, []MSTeamsFact{{"Organization:", "org3"}}, pl.(*MSTeamsPayload).Sections[0].Facts)
+		assert.Len(t, pl.(*MSTeamsPayload).PotentialAction, 1)
+		assert.Equal(t, "https://try.gitea.io/org/org3/teams/Developers", pl.(*MSTeamsPayload).PotentialAction[0].Targets[0].URI)
+	})
 }
 
 func TestMSTeamsJSONPayload(t *testing.T) {
@@ -99,6 +99,31 @@ func (f *PackagistPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	return nil, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (f *PackagistPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	return nil, nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (f *PackagistPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	return nil, nil
+}
+
+// Star implements PayloadConvertor Star method
+func (f *PackagistPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	return nil, nil
+}
+
+// Member implements PayloadConvertor Member method
+func (f *PackagistPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	return nil, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (f *PackagistPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	return nil, nil
+}
+
 // GetPackagistPayload converts a packagist webhook into a PackagistPayload
 func GetPackagistPayload(p api.Payloader, event webhook_model.HookEventType, meta string) (api.Payloader, error) {
 	s := new(PackagistPayload)
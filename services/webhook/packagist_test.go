@@ -124,6 +124,33 @@ func TestPackagistPayload(t *testing.T) {
 		require.NoError(t, err)
 		require.Nil(t, pl)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(PackagistPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.Nil(t, pl)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(PackagistPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.Nil(t, pl)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(PackagistPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.Nil(t, pl)
+	})
 }
 
 func TestPackagistJSONPayload(t *testing.T) {
@@ -22,6 +22,11 @@ type PayloadConvertor interface {
 	Review(*api.PullRequestPayload, webhook_model.HookEventType) (api.Payloader, error)
 	Repository(*api.RepositoryPayload) (api.Payloader, error)
 	Release(*api.ReleasePayload) (api.Payloader, error)
+	Status(*api.CommitStatusPayload) (api.Payloader, error)
+	Wiki(*api.WikiPayload) (api.Payloader, error)
+	Star(*api.StarPayload) (api.Payloader, error)
+	Member(*api.MemberPayload) (api.Payloader, error)
+	Team(*api.TeamPayload) (api.Payloader, error)
 }
 
 func convertPayloader(s PayloadConvertor, p api.Payloader, event webhook_model.HookEventType) (api.Payloader, error) {
@@ -51,6 +56,16 @@ func convertPayloader(s PayloadConvertor, p api.Payloader, event webhook_model.H
 		return s.Repository(p.(*api.RepositoryPayload))
 	case webhook_model.HookEventRelease:
 		return s.Release(p.(*api.ReleasePayload))
+	case webhook_model.HookEventStatus:
+		return s.Status(p.(*api.CommitStatusPayload))
+	case webhook_model.HookEventWiki:
+		return s.Wiki(p.(*api.WikiPayload))
+	case webhook_model.HookEventStar:
+		return s.Star(p.(*api.StarPayload))
+	case webhook_model.HookEventMember:
+		return s.Member(p.(*api.MemberPayload))
+	case webhook_model.HookEventTeam:
+		return s.Team(p.(*api.TeamPayload))
 	}
 	return s, nil
 }
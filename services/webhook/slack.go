@@ -163,6 +163,49 @@ func (s *SlackPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return s.createPayload(text, nil), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (s *SlackPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getStatusPayloadInfo(p, SlackLinkFormatter, true)
+
+	var attachments []SlackAttachment
+	if p.Description != "" {
+		attachments = append(attachments, SlackAttachment{
+			Color: fmt.Sprintf("%x", color),
+			Text:  SlackTextFormatter(p.Description),
+		})
+	}
+
+	return s.createPayload(text, attachments), nil
+}
+
+// Wiki implements PayloadConvertor Wiki method
+func (s *SlackPayload) Wiki(p *api.WikiPayload) (api.Payloader, error) {
+	text, _ := getWikiPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Star implements PayloadConvertor Star method
+func (s *SlackPayload) Star(p *api.StarPayload) (api.Payloader, error) {
+	text, _ := getStarPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Member implements PayloadConvertor Member method
+func (s *SlackPayload) Member(p *api.MemberPayload) (api.Payloader, error) {
+	text, _ := getMemberPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (s *SlackPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, SlackLinkFormatter, true)
+
+	return s.createPayload(text, nil), nil
+}
+
 // Push implements PayloadConvertor Push method
 func (s *SlackPayload) Push(p *api.PushPayload) (api.Payloader, error) {
 	// n new commits
@@ -156,6 +156,44 @@ func TestSlackPayload(t *testing.T) {
 
 		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Release created: <http://localhost:3000/test/repo/src/v1.0|v1.0> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
 	})
+
+	t.Run("Status", func(t *testing.T) {
+		p := statusTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Status(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Commit status <https://ci.example.com/build/1|ci/build> on <http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778|2020558fe2>: failure (<http://localhost:3000/test/repo/pulls/12|#12>) by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+		assert.Len(t, pl.(*SlackPayload).Attachments, 1)
+		assert.Equal(t, "Build failed", pl.(*SlackPayload).Attachments[0].Text)
+	})
+
+	t.Run("Wiki", func(t *testing.T) {
+		p := wikiTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Wiki(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] New wiki page '<http://localhost:3000/test/repo/wiki/Getting%20Started|Getting Started>' by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		p := memberTestPayload()
+
+		d := new(SlackPayload)
+		pl, err := d.Member(p)
+		require.NoError(t, err)
+		require.NotNil(t, pl)
+		require.IsType(t, &SlackPayload{}, pl)
+
+		assert.Equal(t, "[<https://try.gitea.io/org3|org3>] <https://try.gitea.io/user2|user2> added to team <https://try.gitea.io/org/org3/teams/Developers|Developers> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	})
 }
 
 func TestSlackJSONPayload(t *testing.T) {
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"code.gitea.io/gitea/models/organization"
 	repo_model "code.gitea.io/gitea/models/repo"
 	webhook_model "code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/git"
@@ -138,7 +139,7 @@ func addToTask(repoID int64) error {
 
 // PrepareWebhook adds special webhook to task queue for given payload.
 func PrepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event webhook_model.HookEventType, p api.Payloader) error {
-	if err := prepareWebhook(w, repo, event, p); err != nil {
+	if err := prepareWebhook(w, repo.ID, event, p); err != nil {
 		return err
 	}
 
@@ -160,7 +161,7 @@ func checkBranch(w *webhook_model.Webhook, branch string) bool {
 	return g.Match(branch)
 }
 
-func prepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event webhook_model.HookEventType, p api.Payloader) error {
+func prepareWebhook(w *webhook_model.Webhook, repoID int64, event webhook_model.HookEventType, p api.Payloader) error {
 	// Skip sending if webhooks are disabled.
 	if setting.DisableWebhooks {
 		return nil
@@ -206,7 +207,7 @@ func prepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event
 	}
 
 	if err = webhook_model.CreateHookTask(&webhook_model.HookTask{
-		RepoID:    repo.ID,
+		RepoID:    repoID,
 		HookID:    w.ID,
 		Payloader: payloader,
 		EventType: event,
@@ -259,7 +260,41 @@ func prepareWebhooks(repo *repo_model.Repository, event webhook_model.HookEventT
 	}
 
 	for _, w := range ws {
-		if err = prepareWebhook(w, repo, event, p); err != nil {
+		if err = prepareWebhook(w, repo.ID, event, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrepareOrgWebhooks adds new webhooks of the organization and the system
+// webhooks to task queue for given payload which is not related to a repository.
+func PrepareOrgWebhooks(org *organization.Organization, event webhook_model.HookEventType, p api.Payloader) error {
+	if err := prepareOrgWebhooks(org, event, p); err != nil {
+		return err
+	}
+
+	// organization events are not bound to a repository and are queued as repository 0
+	return addToTask(0)
+}
+
+func prepareOrgWebhooks(org *organization.Organization, event webhook_model.HookEventType, p api.Payloader) error {
+	ws, err := webhook_model.ListWebhooksByOpts(&webhook_model.ListWebhookOptions{
+		OrgID:    org.ID,
+		IsActive: util.OptionalBoolTrue,
+	})
+	if err != nil {
+		return fmt.Errorf("GetActiveWebhooksByOrgID: %v", err)
+	}
+
+	systemHooks, err := webhook_model.GetSystemWebhooks(util.OptionalBoolTrue)
+	if err != nil {
+		return fmt.Errorf("GetSystemWebhooks: %v", err)
+	}
+	ws = append(ws, systemHooks...)
+
+	for _, w := range ws {
+		if err = prepareWebhook(w, 0, event, p); err != nil {
 			return err
 		}
 	}
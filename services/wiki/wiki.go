@@ -19,6 +19,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/util"
@@ -268,13 +269,27 @@ func updateWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model
 
 // AddWikiPage adds a new wiki page with a given wikiPath.
 func AddWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, wikiName, content, message string) error {
-	return updateWikiPage(ctx, doer, repo, "", wikiName, content, message, true)
+	if err := updateWikiPage(ctx, doer, repo, "", wikiName, content, message, true); err != nil {
+		return err
+	}
+
+	notification.NotifyNewWikiPage(doer, repo, wikiName, message)
+	return nil
 }
 
 // EditWikiPage updates a wiki page identified by its wikiPath,
 // optionally also changing wikiPath.
 func EditWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldWikiName, newWikiName, content, message string) error {
-	return updateWikiPage(ctx, doer, repo, oldWikiName, newWikiName, content, message, false)
+	if err := updateWikiPage(ctx, doer, repo, oldWikiName, newWikiName, content, message, false); err != nil {
+		return err
+	}
+
+	if oldWikiName != newWikiName {
+		notification.NotifyRenameWikiPage(doer, repo, oldWikiName, newWikiName, message)
+	} else {
+		notification.NotifyEditWikiPage(doer, repo, newWikiName, message)
+	}
+	return nil
 }
 
 // DeleteWikiPage deletes a wiki page identified by its path.
@@ -370,6 +385,8 @@ func DeleteWikiPage(ctx context.Context, doer *user_model.User, repo *repo_model
 		return fmt.Errorf("Push: %v", err)
 	}
 
+	notification.NotifyDeleteWikiPage(doer, repo, wikiName)
+
 	return nil
 }
 
@@ -44,7 +44,36 @@ export function initUserAuthWebAuthn() {
     });
 }
 
-function verifyAssertion(assertedCredential) {
+export function initUserAuthPasskey() {
+  if ($('#passkey-signin').length === 0) {
+    return;
+  }
+
+  $('#passkey-signin').on('click', (e) => {
+    e.preventDefault();
+    if (!detectWebAuthnSupport()) {
+      return;
+    }
+
+    $.getJSON(`${appSubUrl}/user/passkey/assertion`, {
+      remember: $('#remember').is(':checked'),
+    }).done((makeAssertionOptions) => {
+      makeAssertionOptions.publicKey.challenge = decode(makeAssertionOptions.publicKey.challenge);
+      navigator.credentials.get({
+        publicKey: makeAssertionOptions.publicKey
+      })
+        .then((credential) => {
+          verifyAssertion(credential, `${appSubUrl}/user/passkey/assertion`);
+        }).catch((err) => {
+          webAuthnError('general', err.message);
+        });
+    }).fail(() => {
+      webAuthnError('unknown');
+    });
+  });
+}
+
+function verifyAssertion(assertedCredential, url = `${appSubUrl}/user/webauthn/assertion`) {
   // Move data into Arrays incase it is super long
   const authData = new Uint8Array(assertedCredential.response.authenticatorData);
   const clientDataJSON = new Uint8Array(assertedCredential.response.clientDataJSON);
@@ -52,7 +81,7 @@ function verifyAssertion(assertedCredential) {
   const sig = new Uint8Array(assertedCredential.response.signature);
   const userHandle = new Uint8Array(assertedCredential.response.userHandle);
   $.ajax({
-    url: `${appSubUrl}/user/webauthn/assertion`,
+    url,
     type: 'POST',
     data: JSON.stringify({
       id: assertedCredential.id,
@@ -181,6 +210,7 @@ function webAuthnRegisterRequest() {
   $.post(`${appSubUrl}/user/settings/security/webauthn/request_register`, {
     _csrf: csrfToken,
     name: $('#nickname').val(),
+    passkey: $('#passkey').is(':checked'),
   }).done((makeCredentialOptions) => {
     $('#nickname').closest('div.field').removeClass('error');
 